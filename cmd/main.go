@@ -1,29 +1,54 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"net/url"
 	"os"
+	"strings"
+	"time"
 
 	mlog "log/slog"
 
+	mailerConfig "github.com/ghulammuzz/misterblast/config/mailer"
 	config "github.com/ghulammuzz/misterblast/config/postgres"
+	redisConfig "github.com/ghulammuzz/misterblast/config/redis"
+	storageConfig "github.com/ghulammuzz/misterblast/config/storage"
 	"github.com/ghulammuzz/misterblast/config/validator"
+	assignment "github.com/ghulammuzz/misterblast/internal/assignment/di"
+	attempt "github.com/ghulammuzz/misterblast/internal/attempt/di"
+	attemptRepo "github.com/ghulammuzz/misterblast/internal/attempt/repo"
+	attemptSvc "github.com/ghulammuzz/misterblast/internal/attempt/svc"
 	class "github.com/ghulammuzz/misterblast/internal/class/di"
+	classroom "github.com/ghulammuzz/misterblast/internal/classroom/di"
 	email "github.com/ghulammuzz/misterblast/internal/email/di"
+	emailRepo "github.com/ghulammuzz/misterblast/internal/email/repo"
+	emailSvc "github.com/ghulammuzz/misterblast/internal/email/svc"
 	"github.com/ghulammuzz/misterblast/internal/health"
 	lesson "github.com/ghulammuzz/misterblast/internal/lesson/di"
+	media "github.com/ghulammuzz/misterblast/internal/media/di"
 	question "github.com/ghulammuzz/misterblast/internal/question/di"
+	school "github.com/ghulammuzz/misterblast/internal/school/di"
 	set "github.com/ghulammuzz/misterblast/internal/set/di"
 	user "github.com/ghulammuzz/misterblast/internal/user/di"
+	userRepo "github.com/ghulammuzz/misterblast/internal/user/repo"
 
+	"github.com/ghulammuzz/misterblast/migrations"
+	"github.com/ghulammuzz/misterblast/pkg/cache"
 	"github.com/ghulammuzz/misterblast/pkg/log"
+	"github.com/ghulammuzz/misterblast/pkg/middleware"
+	"github.com/ghulammuzz/misterblast/pkg/migrate"
+	"github.com/ghulammuzz/misterblast/pkg/storage"
 	"github.com/gofiber/fiber/v2"
 	"github.com/joho/godotenv"
 )
 
+var autoMigrate bool
+
 func init() {
 	env := flag.String("env", "prod", "Environment for (stg/prod)")
+	flag.BoolVar(&autoMigrate, "migrate", false, "Apply pending database migrations on startup")
 	flag.Parse()
 
 	if *env == "stg" {
@@ -50,21 +75,98 @@ func main() {
 	}
 	defer db.Close()
 
+	if autoMigrate {
+		migrator, err := migrate.New(db, migrations.FS)
+		if err == nil {
+			err = migrator.Up(context.Background(), 0)
+		}
+		if err != nil {
+			log.Error("Failed to migrate database: %v", err)
+			os.Exit(1)
+		}
+	}
+
+	mail, err := mailerConfig.InitMailer()
+	if err != nil {
+		log.Error("Failed to initialize mailer: %v", err)
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go emailSvc.NewOutboxWorker(emailRepo.NewEmailRepository(db), mail).Run(ctx)
+	go attemptSvc.NewDeadlineSweeper(attemptRepo.NewAttemptRepository(db)).Run(ctx)
+
+	store, err := storageConfig.InitStorage()
+	if err != nil {
+		log.Error("Failed to initialize storage: %v", err)
+		os.Exit(1)
+	}
+
 	app := fiber.New(fiber.Config{
 		DisableStartupMessage: true,
+		// Room for media uploads and question sheets with multipart overhead.
+		BodyLimit: 8 << 20,
+		// c.IP(), which keys the rate limits and OTP limits, takes the client
+		// address from the proxy header only on requests from TRUSTED_PROXIES
+		// (comma-separated IPs or CIDRs); anyone else could forge it.
+		ProxyHeader:             proxyHeader(),
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          trustedProxies(),
+		EnableIPValidation:      true,
 	})
 
+	middleware.SetRevocationList(userRepo.NewTokenRepository(db))
+
+	rdb, err := redisConfig.InitRedis()
+	if err != nil {
+		log.Error("Failed to initialize redis, rate limiting per instance: %v", err)
+	}
+	var catalogCache cache.Cache = cache.NewLRU(1000)
+	if rdb != nil {
+		defer rdb.Close()
+		middleware.SetRateLimiter(middleware.NewRedisLimiter(rdb, middleware.NewMemoryLimiter()))
+		catalogCache = cache.NewRedis(rdb)
+	}
+
 	app.Get("/hc", health.HealthCheck(db))
-	api := app.Group("/api")
+	if local, ok := store.(*storage.Local); ok {
+		if base, err := url.Parse(local.BaseURL); err == nil && base.Path != "" {
+			app.Static(base.Path, local.Dir)
+		}
+	}
+	api := app.Group("/api", middleware.RateLimit(middleware.RateLimitConfig{Name: "api", Limit: 300, Window: time.Minute}))
 
-	class.InitializedClassService(db).Router(api)
-	lesson.InitializedLessonService(db, validator.Validate).Router(api)
-	set.InitializedSetService(db, validator.Validate).Router(api)
-	question.InitializedQuestionService(db, validator.Validate).Router(api)
+	class.InitializedClassService(db, catalogCache).Router(api)
+	lesson.InitializedLessonService(db, validator.Validate, catalogCache).Router(api)
+	set.InitializedSetService(db, validator.Validate, catalogCache).Router(api)
+	question.InitializedQuestionService(db, validator.Validate, catalogCache, store).Router(api)
 	user.InitializedUserService(db, validator.Validate).Router(api)
+	school.InitializedSchoolService(db, validator.Validate).Router(api)
 	email.InitializedEmailService(db, validator.Validate).Router(api)
+	attempt.InitializedAttemptService(db, validator.Validate, catalogCache).Router(api)
+	classroom.InitializedClassroomService(db, validator.Validate).Router(api)
+	assignment.InitializedAssignmentService(db, validator.Validate).Router(api)
+	media.InitializedMediaService(db, validator.Validate, store).Router(api)
 
 	if err := app.Listen(fmt.Sprint(":", os.Getenv("APP_PORT"))); err != nil {
 		log.Error("Failed to start the server: %v", err)
 	}
 }
+
+func proxyHeader() string {
+	if header := os.Getenv("PROXY_HEADER"); header != "" {
+		return header
+	}
+	return fiber.HeaderXForwardedFor
+}
+
+func trustedProxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
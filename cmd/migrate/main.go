@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	mlog "log/slog"
+
+	config "github.com/ghulammuzz/misterblast/config/postgres"
+	"github.com/ghulammuzz/misterblast/migrations"
+	"github.com/ghulammuzz/misterblast/pkg/log"
+	"github.com/ghulammuzz/misterblast/pkg/migrate"
+	"github.com/joho/godotenv"
+)
+
+const usage = `usage: migrate [--env=stg|prod] <command>
+
+commands:
+  up [n]          apply all or the next n pending migrations
+  down [n]        revert the last n applied migrations (default 1)
+  status          show the current version and pending migrations
+  force <version> record version as applied and clear the dirty flag`
+
+func main() {
+	env := flag.String("env", "prod", "Environment for (stg/prod)")
+	flag.Usage = func() { fmt.Fprintln(os.Stderr, usage) }
+	flag.Parse()
+
+	if *env == "stg" {
+		if err := godotenv.Load("./stg.env"); err != nil {
+			mlog.Error("Error loading stg.env file ")
+		}
+	}
+	log.InitLogger("dev", false, "")
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	db, err := config.InitPostgres()
+	if err != nil {
+		log.Error("Failed to initialize database: %v", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	migrator, err := migrate.New(db, migrations.FS)
+	if err != nil {
+		log.Error("Failed to load migrations: %v", err)
+		os.Exit(1)
+	}
+
+	if err := run(context.Background(), migrator, flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, migrator *migrate.Migrator, args []string) error {
+	switch args[0] {
+	case "up":
+		n, err := optionalInt(args)
+		if err != nil {
+			return err
+		}
+		return migrator.Up(ctx, n)
+	case "down":
+		n, err := optionalInt(args)
+		if err != nil {
+			return err
+		}
+		return migrator.Down(ctx, n)
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("version: %d (dirty: %t)\n", status.Version, status.Dirty)
+		for _, m := range status.Applied {
+			fmt.Printf("  applied  %06d_%s\n", m.Version, m.Name)
+		}
+		for _, m := range status.Pending {
+			fmt.Printf("  pending  %06d_%s\n", m.Version, m.Name)
+		}
+		return nil
+	case "force":
+		if len(args) != 2 {
+			return fmt.Errorf("force needs a version\n%s", usage)
+		}
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		return migrator.Force(ctx, version)
+	default:
+		return fmt.Errorf("unknown command %q\n%s", args[0], usage)
+	}
+}
+
+func optionalInt(args []string) (int, error) {
+	if len(args) < 2 {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(args[1])
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid step count %q", args[1])
+	}
+	return n, nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	mlog "log/slog"
+
+	config "github.com/ghulammuzz/misterblast/config/postgres"
+	"github.com/ghulammuzz/misterblast/internal/seed"
+	"github.com/ghulammuzz/misterblast/pkg/log"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	env := flag.String("env", "prod", "Environment for (stg/prod)")
+	dir := flag.String("dir", "./fixtures", "Directory of fixture files, applied in file name order")
+	file := flag.String("file", "", "Apply a single fixture file instead of -dir")
+	synthetic := flag.Int("synthetic", 0, "Also generate this many synthetic quiz sets")
+	questions := flag.Int("questions", 10, "Questions per synthetic set")
+	randSeed := flag.Int64("seed", 1, "Random seed for synthetic sets")
+	flag.Parse()
+
+	if *env == "stg" {
+		if err := godotenv.Load("./stg.env"); err != nil {
+			mlog.Error("Error loading stg.env file ")
+		}
+	}
+	log.InitLogger("dev", false, "")
+
+	var fixtures []seed.Fixture
+	var err error
+	if *file != "" {
+		var fixture seed.Fixture
+		fixture, err = seed.LoadFile(*file)
+		fixtures = append(fixtures, fixture)
+	} else {
+		fixtures, err = seed.LoadDir(*dir)
+	}
+	if err != nil {
+		log.Error("Failed to load fixtures: %v", err)
+		os.Exit(1)
+	}
+	if *synthetic > 0 {
+		fixtures = append(fixtures, seed.Synthetic(*synthetic, *questions, *randSeed))
+	}
+
+	db, err := config.InitPostgres()
+	if err != nil {
+		log.Error("Failed to initialize database: %v", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	seeder := seed.NewSeeder(db)
+	for _, fixture := range fixtures {
+		stats, err := seeder.Apply(fixture)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "seed failed:", err)
+			os.Exit(1)
+		}
+		fmt.Println("created", stats)
+	}
+}
@@ -0,0 +1,42 @@
+package mailer
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ghulammuzz/misterblast/pkg/mailer"
+)
+
+// InitMailer builds the mail transport from the environment. MAIL_DRIVER=file
+// writes messages to MAIL_OUTBOX_DIR instead of sending them; anything else
+// uses SMTP, defaulting to Gmail with STARTTLS as before.
+func InitMailer() (mailer.Mailer, error) {
+	from := getenv("MAIL_FROM", os.Getenv("EMAIL_HOST_USER"))
+
+	switch driver := getenv("MAIL_DRIVER", "smtp"); driver {
+	case "file":
+		return mailer.NewFileMailer(getenv("MAIL_OUTBOX_DIR", "./tmp/mail"), from)
+	case "smtp":
+		tls := getenv("MAIL_TLS", mailer.TLSStartTLS)
+		if tls != mailer.TLSNone && tls != mailer.TLSStartTLS && tls != mailer.TLSImplicit {
+			return nil, fmt.Errorf("invalid MAIL_TLS %q", tls)
+		}
+		return mailer.NewSMTPMailer(mailer.SMTPConfig{
+			Host:     getenv("MAIL_HOST", "smtp.gmail.com"),
+			Port:     getenv("MAIL_PORT", "587"),
+			Username: getenv("MAIL_USERNAME", os.Getenv("EMAIL_HOST_USER")),
+			Password: getenv("MAIL_PASSWORD", os.Getenv("EMAIL_HOST_PASSWORD")),
+			From:     from,
+			TLS:      tls,
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown MAIL_DRIVER %q", driver)
+	}
+}
+
+func getenv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -1 +1,68 @@
 package redis
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ghulammuzz/misterblast/pkg/log"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+var (
+	client    *goredis.Client
+	onceRedis sync.Once
+	initErr   error
+)
+
+// InitRedis connects to the Redis server at REDIS_HOST:REDIS_PORT. It returns
+// a nil client without error when REDIS_HOST is unset so callers can fall back
+// to in-process implementations.
+func InitRedis() (*goredis.Client, error) {
+	onceRedis.Do(func() {
+		host := os.Getenv("REDIS_HOST")
+		if host == "" {
+			return
+		}
+
+		port := os.Getenv("REDIS_PORT")
+		if port == "" {
+			port = "6379"
+		}
+
+		db := 0
+		if v := os.Getenv("REDIS_DB"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				initErr = fmt.Errorf("invalid REDIS_DB %q", v)
+				return
+			}
+			db = n
+		}
+
+		rdb := goredis.NewClient(&goredis.Options{
+			Addr:         fmt.Sprintf("%s:%s", host, port),
+			Password:     os.Getenv("REDIS_PASS"),
+			DB:           db,
+			DialTimeout:  2 * time.Second,
+			ReadTimeout:  time.Second,
+			WriteTimeout: time.Second,
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		if err := rdb.Ping(ctx).Err(); err != nil {
+			rdb.Close()
+			initErr = fmt.Errorf("failed to ping redis: %w", err)
+			return
+		}
+
+		log.Info("Redis connected successfully")
+		client = rdb
+	})
+
+	return client, initErr
+}
@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ghulammuzz/misterblast/pkg/storage"
+)
+
+// InitStorage builds the upload storage from the environment.
+// STORAGE_DRIVER=s3 stores objects in an S3-compatible bucket; the default
+// local driver writes them under STORAGE_DIR, served by the API at
+// STORAGE_BASE_URL.
+func InitStorage() (storage.Storage, error) {
+	var store storage.Storage
+	var err error
+
+	switch driver := getenv("STORAGE_DRIVER", "local"); driver {
+	case "local":
+		store, err = storage.NewLocal(getenv("STORAGE_DIR", "./uploads"), getenv("STORAGE_BASE_URL", "/media"))
+	case "s3":
+		store, err = storage.NewS3(storage.S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			PublicURL: os.Getenv("S3_PUBLIC_URL"),
+		})
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_DRIVER %q", driver)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func getenv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,21 @@
+package di
+
+import (
+	"database/sql"
+
+	assignmentHandler "github.com/ghulammuzz/misterblast/internal/assignment/handler"
+	assignmentRepo "github.com/ghulammuzz/misterblast/internal/assignment/repo"
+	assignmentSvc "github.com/ghulammuzz/misterblast/internal/assignment/svc"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/wire"
+)
+
+func InitializedAssignmentServiceFake(sb *sql.DB, val *validator.Validate) *assignmentHandler.AssignmentHandler {
+	wire.Build(
+		assignmentHandler.NewAssignmentHandler,
+		assignmentSvc.NewAssignmentService,
+		assignmentRepo.NewAssignmentRepository,
+	)
+
+	return &assignmentHandler.AssignmentHandler{}
+}
@@ -0,0 +1,42 @@
+package entity
+
+// Status of an assignment for one student.
+const (
+	StatusPending   = "pending"
+	StatusOverdue   = "overdue"
+	StatusSubmitted = "submitted"
+	StatusLate      = "late"
+)
+
+// Assignment binds the quiz of a set to the students who should take it by
+// DueAt. When made for a classroom, its members at that time are assigned.
+// Assigned and Submitted count its students and those who handed in an
+// attempt started after it was assigned.
+type Assignment struct {
+	ID          int32  `json:"id"`
+	SetID       int32  `json:"set_id"`
+	SetName     string `json:"set_name"`
+	Title       string `json:"title"`
+	DueAt       int64  `json:"due_at"`
+	ClassroomID *int32 `json:"classroom_id"`
+	CreatedBy   int32  `json:"created_by"`
+	CreatedAt   int64  `json:"created_at"`
+	Assigned    int    `json:"assigned"`
+	Submitted   int    `json:"submitted"`
+}
+
+// Status tells where a student stands: an attempt submitted by the due date
+// is submitted, after it late; without one the assignment is pending until
+// it is overdue.
+func Status(dueAt int64, submittedAt *int64, now int64) string {
+	switch {
+	case submittedAt != nil && *submittedAt <= dueAt:
+		return StatusSubmitted
+	case submittedAt != nil:
+		return StatusLate
+	case now > dueAt:
+		return StatusOverdue
+	default:
+		return StatusPending
+	}
+}
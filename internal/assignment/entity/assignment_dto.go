@@ -0,0 +1,33 @@
+package entity
+
+// SetAssignment assigns a set to the members of a classroom, to individual
+// students, or both.
+type SetAssignment struct {
+	SetID       int32   `json:"set_id" validate:"required"`
+	Title       string  `json:"title" validate:"required,min=2,max=100"`
+	DueAt       int64   `json:"due_at" validate:"required"`
+	ClassroomID *int32  `json:"classroom_id" validate:"omitempty,min=1"`
+	StudentIDs  []int32 `json:"student_ids" validate:"required_without=ClassroomID,max=500,dive,required"`
+}
+
+// MyAssignment is an assignment as its student sees it, with the first
+// attempt handed in for it.
+type MyAssignment struct {
+	ID          int32    `json:"id"`
+	SetID       int32    `json:"set_id"`
+	SetName     string   `json:"set_name"`
+	Title       string   `json:"title"`
+	DueAt       int64    `json:"due_at"`
+	Status      string   `json:"status"`
+	AttemptID   *int32   `json:"attempt_id"`
+	Score       *float64 `json:"score"`
+	SubmittedAt *int64   `json:"submitted_at"`
+}
+
+// PendingStudent is a student who has not handed in an assignment yet.
+type PendingStudent struct {
+	UserID     int32  `json:"user_id"`
+	Name       string `json:"name"`
+	Email      string `json:"email"`
+	InProgress bool   `json:"in_progress"`
+}
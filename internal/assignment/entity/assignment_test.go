@@ -0,0 +1,31 @@
+package entity_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ghulammuzz/misterblast/internal/assignment/entity"
+)
+
+func TestStatus(t *testing.T) {
+	onTime, late := int64(900), int64(1100)
+
+	tests := []struct {
+		name        string
+		submittedAt *int64
+		now         int64
+		expected    string
+	}{
+		{"not submitted before due", nil, 500, entity.StatusPending},
+		{"not submitted after due", nil, 1001, entity.StatusOverdue},
+		{"submitted on time", &onTime, 2000, entity.StatusSubmitted},
+		{"submitted late", &late, 2000, entity.StatusLate},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, entity.Status(1000, tc.submittedAt, tc.now))
+		})
+	}
+}
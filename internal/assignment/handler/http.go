@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"github.com/ghulammuzz/misterblast/internal/assignment/entity"
+	"github.com/ghulammuzz/misterblast/internal/assignment/svc"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/log"
+	"github.com/ghulammuzz/misterblast/pkg/middleware"
+	"github.com/ghulammuzz/misterblast/pkg/response"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type AssignmentHandler struct {
+	assignmentService svc.AssignmentService
+	val               *validator.Validate
+}
+
+func NewAssignmentHandler(assignmentService svc.AssignmentService, val *validator.Validate) *AssignmentHandler {
+	return &AssignmentHandler{assignmentService, val}
+}
+
+func (h *AssignmentHandler) Router(r fiber.Router) {
+	auth := middleware.JWTProtected()
+	staff := middleware.RequireRole(middleware.StaffRoles...)
+	student := middleware.RequireRole(middleware.AllRoles...)
+
+	r.Post("/assignment", auth, staff, h.AddAssignmentHandler)
+	r.Get("/assignment", auth, staff, h.ListAssignmentsHandler)
+	r.Delete("/assignment/:id", auth, staff, h.DeleteAssignmentHandler)
+	r.Get("/assignment/:id/pending", auth, staff, h.ListPendingStudentsHandler)
+
+	r.Get("/me/assignments", auth, student, h.MyAssignmentsHandler)
+}
+
+func (h *AssignmentHandler) AddAssignmentHandler(c *fiber.Ctx) error {
+	teacherID, ok := middleware.UserID(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	var assignment entity.SetAssignment
+	if err := c.BodyParser(&assignment); err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid request body", nil)
+	}
+
+	if err := h.val.Struct(assignment); err != nil {
+		validationErrors := app.ValidationErrorResponse(err)
+		log.Error("Validation failed: %v", validationErrors)
+		return response.SendError(c, fiber.StatusBadRequest, "Validation failed", validationErrors)
+	}
+
+	created, err := h.assignmentService.AddAssignment(teacherID, assignment)
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "assignment created successfully", created)
+}
+
+func (h *AssignmentHandler) ListAssignmentsHandler(c *fiber.Ctx) error {
+	teacherID, ok := middleware.UserID(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	filter := map[string]string{}
+	if classroomID := c.Query("classroom_id"); classroomID != "" {
+		filter["classroom_id"] = classroomID
+	}
+
+	assignments, err := h.assignmentService.ListAssignments(teacherID, filter)
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "assignments retrieved successfully", assignments)
+}
+
+func (h *AssignmentHandler) DeleteAssignmentHandler(c *fiber.Ctx) error {
+	teacherID, ok := middleware.UserID(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid assignment ID", nil)
+	}
+
+	if err := h.assignmentService.DeleteAssignment(teacherID, int32(id)); err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "assignment deleted successfully", nil)
+}
+
+// ListPendingStudentsHandler lists who has not handed in an assignment yet.
+func (h *AssignmentHandler) ListPendingStudentsHandler(c *fiber.Ctx) error {
+	teacherID, ok := middleware.UserID(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid assignment ID", nil)
+	}
+
+	students, err := h.assignmentService.ListPendingStudents(teacherID, int32(id))
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "pending students retrieved successfully", students)
+}
+
+// MyAssignmentsHandler lists the assignments of the calling student.
+func (h *AssignmentHandler) MyAssignmentsHandler(c *fiber.Ctx) error {
+	studentID, ok := middleware.UserID(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	assignments, err := h.assignmentService.MyAssignments(studentID)
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "assignments retrieved successfully", assignments)
+}
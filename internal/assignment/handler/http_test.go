@@ -0,0 +1,160 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/ghulammuzz/misterblast/internal/assignment/entity"
+	"github.com/ghulammuzz/misterblast/internal/assignment/handler"
+	"github.com/ghulammuzz/misterblast/pkg/middleware"
+)
+
+type MockAssignmentService struct {
+	mock.Mock
+}
+
+func (m *MockAssignmentService) AddAssignment(teacherID int32, assignment entity.SetAssignment) (entity.Assignment, error) {
+	args := m.Called(teacherID, assignment)
+	return args.Get(0).(entity.Assignment), args.Error(1)
+}
+
+func (m *MockAssignmentService) DeleteAssignment(teacherID, id int32) error {
+	args := m.Called(teacherID, id)
+	return args.Error(0)
+}
+
+func (m *MockAssignmentService) ListAssignments(teacherID int32, filter map[string]string) ([]entity.Assignment, error) {
+	args := m.Called(teacherID, filter)
+	return args.Get(0).([]entity.Assignment), args.Error(1)
+}
+
+func (m *MockAssignmentService) ListPendingStudents(teacherID, id int32) ([]entity.PendingStudent, error) {
+	args := m.Called(teacherID, id)
+	return args.Get(0).([]entity.PendingStudent), args.Error(1)
+}
+
+func (m *MockAssignmentService) MyAssignments(studentID int32) ([]entity.MyAssignment, error) {
+	args := m.Called(studentID)
+	return args.Get(0).([]entity.MyAssignment), args.Error(1)
+}
+
+func signedToken(userID int, role string) string {
+	claims := jwt.MapClaims{
+		"apps":    "misterblast-core",
+		"email":   "john@example.com",
+		"user_id": userID,
+		"role":    role,
+		"jti":     "jti-test",
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, _ := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+	return signed
+}
+
+func TestAddAssignmentHandler(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockAssignmentService)
+	h := handler.NewAssignmentHandler(mockService, validator.New())
+	app.Post("/assignment", middleware.JWTProtected(), h.AddAssignmentHandler)
+
+	assignment := entity.SetAssignment{SetID: 2, Title: "Week 1", DueAt: 1800000000, StudentIDs: []int32{11, 12}}
+	mockService.On("AddAssignment", int32(9), assignment).Return(entity.Assignment{ID: 4}, nil)
+
+	body, _ := json.Marshal(assignment)
+	req := httptest.NewRequest(http.MethodPost, "/assignment", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+signedToken(9, middleware.RoleTeacher))
+
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
+func TestAddAssignmentHandler_NoStudents(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockAssignmentService)
+	h := handler.NewAssignmentHandler(mockService, validator.New())
+	app.Post("/assignment", middleware.JWTProtected(), h.AddAssignmentHandler)
+
+	body := []byte(`{"set_id": 2, "title": "Week 1", "due_at": 1800000000}`)
+	req := httptest.NewRequest(http.MethodPost, "/assignment", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+signedToken(9, middleware.RoleTeacher))
+
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	mockService.AssertNotCalled(t, "AddAssignment", mock.Anything, mock.Anything)
+}
+
+func TestListAssignmentsHandler_ByClassroom(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockAssignmentService)
+	h := handler.NewAssignmentHandler(mockService, validator.New())
+	app.Get("/assignment", middleware.JWTProtected(), h.ListAssignmentsHandler)
+
+	mockService.On("ListAssignments", int32(9), map[string]string{"classroom_id": "3"}).Return([]entity.Assignment{{ID: 4}}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/assignment?classroom_id=3", nil)
+	req.Header.Set("Authorization", "Bearer "+signedToken(9, middleware.RoleTeacher))
+
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
+func TestAssignmentRouter_StudentForbidden(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockAssignmentService)
+	handler.NewAssignmentHandler(mockService, validator.New()).Router(app)
+
+	req := httptest.NewRequest(http.MethodGet, "/assignment/4/pending", nil)
+	req.Header.Set("Authorization", "Bearer "+signedToken(11, middleware.RoleStudent))
+
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	mockService.AssertNotCalled(t, "ListPendingStudents", mock.Anything, mock.Anything)
+}
+
+func TestListPendingStudentsHandler(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockAssignmentService)
+	h := handler.NewAssignmentHandler(mockService, validator.New())
+	app.Get("/assignment/:id/pending", middleware.JWTProtected(), h.ListPendingStudentsHandler)
+
+	mockService.On("ListPendingStudents", int32(9), int32(4)).Return([]entity.PendingStudent{{UserID: 11, Name: "Ani"}}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/assignment/4/pending", nil)
+	req.Header.Set("Authorization", "Bearer "+signedToken(9, middleware.RoleTeacher))
+
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
+func TestMyAssignmentsHandler(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockAssignmentService)
+	h := handler.NewAssignmentHandler(mockService, validator.New())
+	app.Get("/me/assignments", middleware.JWTProtected(), h.MyAssignmentsHandler)
+
+	mockService.On("MyAssignments", int32(11)).Return([]entity.MyAssignment{{ID: 4, Status: entity.StatusPending}}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/me/assignments", nil)
+	req.Header.Set("Authorization", "Bearer "+signedToken(11, middleware.RoleStudent))
+
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,303 @@
+package repo
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	assignmentEntity "github.com/ghulammuzz/misterblast/internal/assignment/entity"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/log"
+)
+
+type AssignmentRepository interface {
+	Add(createdBy int32, assignment assignmentEntity.SetAssignment) (int32, error)
+	Detail(id int32) (assignmentEntity.Assignment, error)
+	Delete(id int32) error
+	List(createdBy int32, filter map[string]string) ([]assignmentEntity.Assignment, error)
+	ListForStudent(userID int32) ([]assignmentEntity.MyAssignment, error)
+	ListPending(id int32) ([]assignmentEntity.PendingStudent, error)
+}
+
+type assignmentRepository struct {
+	db *sql.DB
+}
+
+func NewAssignmentRepository(db *sql.DB) AssignmentRepository {
+	return &assignmentRepository{db: db}
+}
+
+// submitted matches the attempts that hand in an assignment a for the
+// student st: finished attempts of its set started after it was assigned.
+const submitted = `
+	SELECT 1 FROM quiz_attempts qa
+	WHERE qa.user_id = st.user_id AND qa.set_id = a.set_id
+	  AND qa.status <> 'in_progress' AND qa.started_at >= a.created_at`
+
+// Add creates the assignment and enrolls its students in one transaction:
+// those listed and the members of its classroom, which must belong to the
+// teacher. Unknown users, admins and students of another school are
+// rejected, as are the sets the teacher's school cannot see.
+func (r *assignmentRepository) Add(createdBy int32, assignment assignmentEntity.SetAssignment) (int32, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Error("[Repo][AddAssignment] Error Begin: ", err)
+		return 0, app.NewAppError(500, "failed to create assignment")
+	}
+	defer tx.Rollback()
+
+	if assignment.ClassroomID != nil {
+		var owned bool
+		check := `SELECT EXISTS(SELECT 1 FROM classrooms WHERE id = $1 AND teacher_id = $2)`
+		if err := tx.QueryRow(check, *assignment.ClassroomID, createdBy).Scan(&owned); err != nil {
+			log.Error("[Repo][AddAssignment] Error QueryRow Classroom: ", err)
+			return 0, app.NewAppError(500, "failed to create assignment")
+		}
+		if !owned {
+			return 0, app.NewAppError(404, "classroom not found")
+		}
+	}
+
+	var id int32
+	query := `
+		INSERT INTO assignments (set_id, title, due_at, classroom_id, created_by, created_at)
+		SELECT s.id, $2, $3, $4, $5, $6 FROM sets s
+		WHERE s.id = $1 AND (s.school_id IS NULL OR s.school_id = (SELECT school_id FROM users WHERE id = $5))
+		RETURNING id`
+	err = tx.QueryRow(query, assignment.SetID, assignment.Title, assignment.DueAt, assignment.ClassroomID,
+		createdBy, time.Now().Unix()).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, app.NewAppError(404, "set not found")
+	}
+	if err != nil {
+		log.Error("[Repo][AddAssignment] Error QueryRow: ", err)
+		return 0, app.NewAppError(500, "failed to create assignment")
+	}
+
+	insert := `
+		INSERT INTO assignment_students (assignment_id, user_id)
+		SELECT $1, id FROM users WHERE id = $2 AND role = 'student'
+		  AND school_id IS NOT DISTINCT FROM (SELECT school_id FROM users WHERE id = $3)`
+	for _, studentID := range assignment.StudentIDs {
+		res, err := tx.Exec(insert, id, studentID, createdBy)
+		if err != nil {
+			log.Error("[Repo][AddAssignment] Error Exec Student: ", err)
+			return 0, app.NewAppError(500, "failed to assign students")
+		}
+		if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+			return 0, app.NewAppError(400, fmt.Sprintf("student %d not found", studentID))
+		}
+	}
+	assigned := len(assignment.StudentIDs)
+
+	if assignment.ClassroomID != nil {
+		members := `
+			INSERT INTO assignment_students (assignment_id, user_id)
+			SELECT $1, user_id FROM classroom_members WHERE classroom_id = $2
+			ON CONFLICT (assignment_id, user_id) DO NOTHING`
+		res, err := tx.Exec(members, id, *assignment.ClassroomID)
+		if err != nil {
+			log.Error("[Repo][AddAssignment] Error Exec Classroom: ", err)
+			return 0, app.NewAppError(500, "failed to assign students")
+		}
+		rowsAffected, _ := res.RowsAffected()
+		assigned += int(rowsAffected)
+	}
+	if assigned == 0 {
+		return 0, app.NewAppError(400, "assignment has no students")
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error("[Repo][AddAssignment] Error Commit: ", err)
+		return 0, app.NewAppError(500, "failed to create assignment")
+	}
+
+	return id, nil
+}
+
+func (r *assignmentRepository) Detail(id int32) (assignmentEntity.Assignment, error) {
+	query := `
+		SELECT a.id, a.set_id, s.name, a.title, a.due_at, a.classroom_id, a.created_by, a.created_at,
+			   (SELECT COUNT(*) FROM assignment_students st WHERE st.assignment_id = a.id)
+		FROM assignments a
+		JOIN sets s ON s.id = a.set_id
+		WHERE a.id = $1`
+	var assignment assignmentEntity.Assignment
+	var classroomID sql.NullInt32
+	err := r.db.QueryRow(query, id).Scan(&assignment.ID, &assignment.SetID, &assignment.SetName, &assignment.Title,
+		&assignment.DueAt, &classroomID, &assignment.CreatedBy, &assignment.CreatedAt, &assignment.Assigned)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return assignment, app.NewAppError(404, "assignment not found")
+		}
+		log.Error("[Repo][DetailAssignment] Error QueryRow: ", err)
+		return assignment, app.NewAppError(500, "failed to fetch assignment")
+	}
+	if classroomID.Valid {
+		assignment.ClassroomID = &classroomID.Int32
+	}
+
+	return assignment, nil
+}
+
+func (r *assignmentRepository) Delete(id int32) error {
+	res, err := r.db.Exec(`DELETE FROM assignments WHERE id = $1`, id)
+	if err != nil {
+		log.Error("[Repo][DeleteAssignment] Error Exec: ", err)
+		return app.NewAppError(500, "failed to delete assignment")
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		log.Error("[Repo][DeleteAssignment] Error RowsAffected: ", err)
+		return app.NewAppError(500, "failed to check rows affected")
+	}
+	if rowsAffected == 0 {
+		return app.NewAppError(404, "assignment not found")
+	}
+
+	return nil
+}
+
+// List returns the assignments a teacher created, latest due date first,
+// with how many of their students handed them in.
+func (r *assignmentRepository) List(createdBy int32, filter map[string]string) ([]assignmentEntity.Assignment, error) {
+	query := `
+		SELECT a.id, a.set_id, s.name, a.title, a.due_at, a.classroom_id, a.created_by, a.created_at,
+			   COUNT(st.user_id), COUNT(st.user_id) FILTER (WHERE EXISTS (` + submitted + `))
+		FROM assignments a
+		JOIN sets s ON s.id = a.set_id
+		LEFT JOIN assignment_students st ON st.assignment_id = a.id
+		WHERE a.created_by = $1`
+	args := []interface{}{createdBy}
+	argCounter := 2
+
+	if classroomID, ok := filter["classroom_id"]; ok {
+		query += fmt.Sprintf(" AND a.classroom_id = $%d", argCounter)
+		args = append(args, classroomID)
+		argCounter++
+	}
+
+	query += " GROUP BY a.id, s.name ORDER BY a.due_at DESC"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		log.Error("[Repo][ListAssignments] Error Query: ", err)
+		return nil, app.NewAppError(500, "failed to fetch assignments")
+	}
+	defer rows.Close()
+
+	assignments := []assignmentEntity.Assignment{}
+	for rows.Next() {
+		var assignment assignmentEntity.Assignment
+		var classroomID sql.NullInt32
+		if err := rows.Scan(&assignment.ID, &assignment.SetID, &assignment.SetName, &assignment.Title, &assignment.DueAt,
+			&classroomID, &assignment.CreatedBy, &assignment.CreatedAt, &assignment.Assigned, &assignment.Submitted); err != nil {
+			log.Error("[Repo][ListAssignments] Error Scan: ", err)
+			return nil, app.NewAppError(500, "failed to scan assignment")
+		}
+		if classroomID.Valid {
+			assignment.ClassroomID = &classroomID.Int32
+		}
+		assignments = append(assignments, assignment)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Error("[Repo][ListAssignments] Error Iterating Rows: ", err)
+		return nil, app.NewAppError(500, "error iterating rows")
+	}
+
+	return assignments, nil
+}
+
+// ListForStudent returns a student's assignments, soonest due first, each
+// with the first attempt handed in for it. Status is left to the caller.
+func (r *assignmentRepository) ListForStudent(userID int32) ([]assignmentEntity.MyAssignment, error) {
+	query := `
+		SELECT a.id, a.set_id, s.name, a.title, a.due_at, qa.id, qa.score, qa.submitted_at
+		FROM assignment_students st
+		JOIN assignments a ON a.id = st.assignment_id
+		JOIN sets s ON s.id = a.set_id
+		LEFT JOIN LATERAL (
+			SELECT qa.id, qa.score, qa.submitted_at FROM quiz_attempts qa
+			WHERE qa.user_id = st.user_id AND qa.set_id = a.set_id
+			  AND qa.status <> 'in_progress' AND qa.started_at >= a.created_at
+			ORDER BY qa.submitted_at LIMIT 1
+		) qa ON true
+		WHERE st.user_id = $1
+		ORDER BY a.due_at`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		log.Error("[Repo][ListStudentAssignments] Error Query: ", err)
+		return nil, app.NewAppError(500, "failed to fetch assignments")
+	}
+	defer rows.Close()
+
+	assignments := []assignmentEntity.MyAssignment{}
+	for rows.Next() {
+		var assignment assignmentEntity.MyAssignment
+		var attemptID sql.NullInt32
+		var score sql.NullFloat64
+		var submittedAt sql.NullInt64
+		if err := rows.Scan(&assignment.ID, &assignment.SetID, &assignment.SetName, &assignment.Title, &assignment.DueAt,
+			&attemptID, &score, &submittedAt); err != nil {
+			log.Error("[Repo][ListStudentAssignments] Error Scan: ", err)
+			return nil, app.NewAppError(500, "failed to scan assignment")
+		}
+		if attemptID.Valid {
+			assignment.AttemptID = &attemptID.Int32
+		}
+		if score.Valid {
+			assignment.Score = &score.Float64
+		}
+		if submittedAt.Valid {
+			assignment.SubmittedAt = &submittedAt.Int64
+		}
+		assignments = append(assignments, assignment)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Error("[Repo][ListStudentAssignments] Error Iterating Rows: ", err)
+		return nil, app.NewAppError(500, "error iterating rows")
+	}
+
+	return assignments, nil
+}
+
+// ListPending returns the students of an assignment who have not handed it
+// in, noting who has an attempt under way.
+func (r *assignmentRepository) ListPending(id int32) ([]assignmentEntity.PendingStudent, error) {
+	query := `
+		SELECT u.id, u.name, u.email, EXISTS (
+			SELECT 1 FROM quiz_attempts qa
+			WHERE qa.user_id = st.user_id AND qa.set_id = a.set_id
+			  AND qa.status = 'in_progress' AND qa.started_at >= a.created_at)
+		FROM assignment_students st
+		JOIN assignments a ON a.id = st.assignment_id
+		JOIN users u ON u.id = st.user_id
+		WHERE st.assignment_id = $1 AND NOT EXISTS (` + submitted + `)
+		ORDER BY u.name`
+	rows, err := r.db.Query(query, id)
+	if err != nil {
+		log.Error("[Repo][ListPendingStudents] Error Query: ", err)
+		return nil, app.NewAppError(500, "failed to fetch pending students")
+	}
+	defer rows.Close()
+
+	students := []assignmentEntity.PendingStudent{}
+	for rows.Next() {
+		var student assignmentEntity.PendingStudent
+		if err := rows.Scan(&student.UserID, &student.Name, &student.Email, &student.InProgress); err != nil {
+			log.Error("[Repo][ListPendingStudents] Error Scan: ", err)
+			return nil, app.NewAppError(500, "failed to scan student")
+		}
+		students = append(students, student)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Error("[Repo][ListPendingStudents] Error Iterating Rows: ", err)
+		return nil, app.NewAppError(500, "error iterating rows")
+	}
+
+	return students, nil
+}
@@ -0,0 +1,247 @@
+package repo_test
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ghulammuzz/misterblast/internal/assignment/entity"
+	"github.com/ghulammuzz/misterblast/internal/assignment/repo"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+)
+
+func TestAddAssignment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewAssignmentRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO assignments \(set_id, title, due_at, classroom_id, created_by, created_at\)(.|\n)+s.school_id IS NULL OR`).
+		WithArgs(2, "Week 1", int64(1800000000), nil, 9, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+	mock.ExpectExec(`INSERT INTO assignment_students \(assignment_id, user_id\)(.|\n)+school_id IS NOT DISTINCT FROM`).
+		WithArgs(4, 11, 9).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO assignment_students \(assignment_id, user_id\)`).
+		WithArgs(4, 12, 9).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	id, err := repository.Add(9, entity.SetAssignment{SetID: 2, Title: "Week 1", DueAt: 1800000000, StudentIDs: []int32{11, 12}})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(4), id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddAssignment_UnknownStudent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewAssignmentRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO assignments`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+	mock.ExpectExec(`INSERT INTO assignment_students`).
+		WithArgs(4, 99, 9).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	_, err = repository.Add(9, entity.SetAssignment{SetID: 2, Title: "Week 1", DueAt: 1800000000, StudentIDs: []int32{99}})
+	assert.Error(t, err)
+	assert.Equal(t, 400, err.(*app.AppError).Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddAssignment_SetNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewAssignmentRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO assignments`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+
+	_, err = repository.Add(9, entity.SetAssignment{SetID: 2, Title: "Week 1", DueAt: 1800000000, StudentIDs: []int32{11}})
+	assert.Error(t, err)
+	assert.Equal(t, 404, err.(*app.AppError).Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddAssignment_Classroom(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewAssignmentRepository(db)
+	classroomID := int32(3)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM classrooms WHERE id = \$1 AND teacher_id = \$2\)`).
+		WithArgs(3, 9).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(`INSERT INTO assignments`).
+		WithArgs(2, "Week 1", int64(1800000000), 3, 9, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+	mock.ExpectExec(`SELECT \$1, user_id FROM classroom_members WHERE classroom_id = \$2`).
+		WithArgs(4, 3).
+		WillReturnResult(sqlmock.NewResult(0, 25))
+	mock.ExpectCommit()
+
+	id, err := repository.Add(9, entity.SetAssignment{SetID: 2, Title: "Week 1", DueAt: 1800000000, ClassroomID: &classroomID})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(4), id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddAssignment_EmptyClassroom(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewAssignmentRepository(db)
+	classroomID := int32(3)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT EXISTS`).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(`INSERT INTO assignments`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+	mock.ExpectExec(`FROM classroom_members`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	_, err = repository.Add(9, entity.SetAssignment{SetID: 2, Title: "Week 1", DueAt: 1800000000, ClassroomID: &classroomID})
+	assert.Error(t, err)
+	assert.Equal(t, 400, err.(*app.AppError).Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddAssignment_ClassroomNotOwned(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewAssignmentRepository(db)
+	classroomID := int32(3)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT EXISTS`).
+		WithArgs(3, 9).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectRollback()
+
+	_, err = repository.Add(9, entity.SetAssignment{SetID: 2, Title: "Week 1", DueAt: 1800000000, ClassroomID: &classroomID})
+	assert.Error(t, err)
+	assert.Equal(t, 404, err.(*app.AppError).Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListAssignments(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewAssignmentRepository(db)
+
+	rows := sqlmock.NewRows([]string{"id", "set_id", "name", "title", "due_at", "classroom_id", "created_by", "created_at", "assigned", "submitted"}).
+		AddRow(4, 2, "Set A", "Week 1", 1800000000, nil, 9, 1700000000, 30, 12)
+	mock.ExpectQuery(`SELECT a.id, a.set_id, s.name, a.title, a.due_at, a.classroom_id, a.created_by, a.created_at`).
+		WithArgs(9).
+		WillReturnRows(rows)
+
+	assignments, err := repository.List(9, map[string]string{})
+	assert.NoError(t, err)
+	assert.Len(t, assignments, 1)
+	assert.Nil(t, assignments[0].ClassroomID)
+	assert.Equal(t, 30, assignments[0].Assigned)
+	assert.Equal(t, 12, assignments[0].Submitted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListAssignments_ByClassroom(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewAssignmentRepository(db)
+
+	rows := sqlmock.NewRows([]string{"id", "set_id", "name", "title", "due_at", "classroom_id", "created_by", "created_at", "assigned", "submitted"}).
+		AddRow(4, 2, "Set A", "Week 1", 1800000000, 3, 9, 1700000000, 30, 12)
+	mock.ExpectQuery(`WHERE a.created_by = \$1 AND a.classroom_id = \$2`).
+		WithArgs(9, "3").
+		WillReturnRows(rows)
+
+	assignments, err := repository.List(9, map[string]string{"classroom_id": "3"})
+	assert.NoError(t, err)
+	assert.Len(t, assignments, 1)
+	assert.Equal(t, int32(3), *assignments[0].ClassroomID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListStudentAssignments(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewAssignmentRepository(db)
+
+	rows := sqlmock.NewRows([]string{"id", "set_id", "name", "title", "due_at", "attempt_id", "score", "submitted_at"}).
+		AddRow(4, 2, "Set A", "Week 1", 1800000000, 7, 75.5, 1750000000).
+		AddRow(5, 3, "Set B", "Week 2", 1900000000, nil, nil, nil)
+	mock.ExpectQuery(`FROM assignment_students st`).
+		WithArgs(11).
+		WillReturnRows(rows)
+
+	assignments, err := repository.ListForStudent(11)
+	assert.NoError(t, err)
+	assert.Len(t, assignments, 2)
+	assert.Equal(t, int32(7), *assignments[0].AttemptID)
+	assert.Equal(t, 75.5, *assignments[0].Score)
+	assert.Nil(t, assignments[1].SubmittedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListPendingStudents(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewAssignmentRepository(db)
+
+	rows := sqlmock.NewRows([]string{"id", "name", "email", "in_progress"}).
+		AddRow(11, "Ani", "ani@example.com", true).
+		AddRow(12, "Budi", "budi@example.com", false)
+	mock.ExpectQuery(`SELECT u.id, u.name, u.email`).
+		WithArgs(4).
+		WillReturnRows(rows)
+
+	students, err := repository.ListPending(4)
+	assert.NoError(t, err)
+	assert.Len(t, students, 2)
+	assert.True(t, students[0].InProgress)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteAssignment_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewAssignmentRepository(db)
+
+	mock.ExpectExec(`DELETE FROM assignments WHERE id = \$1`).
+		WithArgs(4).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repository.Delete(4)
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
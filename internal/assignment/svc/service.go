@@ -0,0 +1,96 @@
+package svc
+
+import (
+	"time"
+
+	assignmentEntity "github.com/ghulammuzz/misterblast/internal/assignment/entity"
+	assignmentRepo "github.com/ghulammuzz/misterblast/internal/assignment/repo"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+)
+
+type AssignmentService interface {
+	AddAssignment(teacherID int32, assignment assignmentEntity.SetAssignment) (assignmentEntity.Assignment, error)
+	DeleteAssignment(teacherID, id int32) error
+	ListAssignments(teacherID int32, filter map[string]string) ([]assignmentEntity.Assignment, error)
+	ListPendingStudents(teacherID, id int32) ([]assignmentEntity.PendingStudent, error)
+	MyAssignments(studentID int32) ([]assignmentEntity.MyAssignment, error)
+}
+
+type assignmentService struct {
+	repo assignmentRepo.AssignmentRepository
+}
+
+func NewAssignmentService(repo assignmentRepo.AssignmentRepository) AssignmentService {
+	return &assignmentService{repo: repo}
+}
+
+func (s *assignmentService) AddAssignment(teacherID int32, assignment assignmentEntity.SetAssignment) (assignmentEntity.Assignment, error) {
+	if assignment.DueAt <= time.Now().Unix() {
+		return assignmentEntity.Assignment{}, app.NewAppError(400, "due_at must be in the future")
+	}
+
+	// A student listed twice is assigned once.
+	seen := make(map[int32]bool, len(assignment.StudentIDs))
+	studentIDs := make([]int32, 0, len(assignment.StudentIDs))
+	for _, id := range assignment.StudentIDs {
+		if !seen[id] {
+			seen[id] = true
+			studentIDs = append(studentIDs, id)
+		}
+	}
+	assignment.StudentIDs = studentIDs
+	if assignment.ClassroomID == nil && len(studentIDs) == 0 {
+		return assignmentEntity.Assignment{}, app.NewAppError(400, "student_ids or classroom_id is required")
+	}
+
+	id, err := s.repo.Add(teacherID, assignment)
+	if err != nil {
+		return assignmentEntity.Assignment{}, err
+	}
+
+	return s.repo.Detail(id)
+}
+
+func (s *assignmentService) DeleteAssignment(teacherID, id int32) error {
+	if _, err := s.ownedAssignment(teacherID, id); err != nil {
+		return err
+	}
+	return s.repo.Delete(id)
+}
+
+func (s *assignmentService) ListAssignments(teacherID int32, filter map[string]string) ([]assignmentEntity.Assignment, error) {
+	return s.repo.List(teacherID, filter)
+}
+
+func (s *assignmentService) ListPendingStudents(teacherID, id int32) ([]assignmentEntity.PendingStudent, error) {
+	if _, err := s.ownedAssignment(teacherID, id); err != nil {
+		return nil, err
+	}
+	return s.repo.ListPending(id)
+}
+
+func (s *assignmentService) MyAssignments(studentID int32) ([]assignmentEntity.MyAssignment, error) {
+	assignments, err := s.repo.ListForStudent(studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	for i := range assignments {
+		assignments[i].Status = assignmentEntity.Status(assignments[i].DueAt, assignments[i].SubmittedAt, now)
+	}
+	return assignments, nil
+}
+
+// ownedAssignment only returns assignments the teacher created; others are
+// reported as not found.
+func (s *assignmentService) ownedAssignment(teacherID, id int32) (assignmentEntity.Assignment, error) {
+	assignment, err := s.repo.Detail(id)
+	if err != nil {
+		return assignment, err
+	}
+	if assignment.CreatedBy != teacherID {
+		return assignmentEntity.Assignment{}, app.NewAppError(404, "assignment not found")
+	}
+	return assignment, nil
+}
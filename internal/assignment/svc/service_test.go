@@ -0,0 +1,151 @@
+package svc_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/ghulammuzz/misterblast/internal/assignment/entity"
+	"github.com/ghulammuzz/misterblast/internal/assignment/svc"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+)
+
+type MockAssignmentRepo struct {
+	mock.Mock
+}
+
+func (m *MockAssignmentRepo) Add(createdBy int32, assignment entity.SetAssignment) (int32, error) {
+	args := m.Called(createdBy, assignment)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (m *MockAssignmentRepo) Detail(id int32) (entity.Assignment, error) {
+	args := m.Called(id)
+	return args.Get(0).(entity.Assignment), args.Error(1)
+}
+
+func (m *MockAssignmentRepo) Delete(id int32) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockAssignmentRepo) List(createdBy int32, filter map[string]string) ([]entity.Assignment, error) {
+	args := m.Called(createdBy, filter)
+	return args.Get(0).([]entity.Assignment), args.Error(1)
+}
+
+func (m *MockAssignmentRepo) ListForStudent(userID int32) ([]entity.MyAssignment, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]entity.MyAssignment), args.Error(1)
+}
+
+func (m *MockAssignmentRepo) ListPending(id int32) ([]entity.PendingStudent, error) {
+	args := m.Called(id)
+	return args.Get(0).([]entity.PendingStudent), args.Error(1)
+}
+
+func TestAddAssignment(t *testing.T) {
+	mockRepo := new(MockAssignmentRepo)
+	service := svc.NewAssignmentService(mockRepo)
+
+	dueAt := time.Now().Add(24 * time.Hour).Unix()
+	input := entity.SetAssignment{SetID: 2, Title: "Week 1", DueAt: dueAt, StudentIDs: []int32{11, 12, 11}}
+	expected := entity.SetAssignment{SetID: 2, Title: "Week 1", DueAt: dueAt, StudentIDs: []int32{11, 12}}
+
+	mockRepo.On("Add", int32(9), expected).Return(int32(4), nil)
+	mockRepo.On("Detail", int32(4)).Return(entity.Assignment{ID: 4, SetID: 2, Title: "Week 1", DueAt: dueAt, CreatedBy: 9, Assigned: 2}, nil)
+
+	created, err := service.AddAssignment(9, input)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(4), created.ID)
+	assert.Equal(t, 2, created.Assigned)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAddAssignment_DueInPast(t *testing.T) {
+	mockRepo := new(MockAssignmentRepo)
+	service := svc.NewAssignmentService(mockRepo)
+
+	input := entity.SetAssignment{SetID: 2, Title: "Week 1", DueAt: time.Now().Add(-time.Hour).Unix(), StudentIDs: []int32{11}}
+
+	_, err := service.AddAssignment(9, input)
+	assert.Error(t, err)
+	assert.Equal(t, 400, err.(*app.AppError).Code)
+	mockRepo.AssertNotCalled(t, "Add", mock.Anything, mock.Anything)
+}
+
+func TestAddAssignment_NoStudents(t *testing.T) {
+	mockRepo := new(MockAssignmentRepo)
+	service := svc.NewAssignmentService(mockRepo)
+
+	input := entity.SetAssignment{SetID: 2, Title: "Week 1", DueAt: time.Now().Add(time.Hour).Unix(), StudentIDs: []int32{}}
+
+	_, err := service.AddAssignment(9, input)
+	assert.Error(t, err)
+	assert.Equal(t, 400, err.(*app.AppError).Code)
+	mockRepo.AssertNotCalled(t, "Add", mock.Anything, mock.Anything)
+}
+
+func TestAddAssignment_Classroom(t *testing.T) {
+	mockRepo := new(MockAssignmentRepo)
+	service := svc.NewAssignmentService(mockRepo)
+
+	classroomID := int32(3)
+	dueAt := time.Now().Add(24 * time.Hour).Unix()
+	input := entity.SetAssignment{SetID: 2, Title: "Week 1", DueAt: dueAt, ClassroomID: &classroomID}
+
+	mockRepo.On("Add", int32(9), mock.MatchedBy(func(a entity.SetAssignment) bool {
+		return *a.ClassroomID == 3 && len(a.StudentIDs) == 0
+	})).Return(int32(4), nil)
+	mockRepo.On("Detail", int32(4)).Return(entity.Assignment{ID: 4, ClassroomID: &classroomID, CreatedBy: 9, Assigned: 25}, nil)
+
+	created, err := service.AddAssignment(9, input)
+	assert.NoError(t, err)
+	assert.Equal(t, 25, created.Assigned)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestListPendingStudents_NotOwner(t *testing.T) {
+	mockRepo := new(MockAssignmentRepo)
+	service := svc.NewAssignmentService(mockRepo)
+
+	mockRepo.On("Detail", int32(4)).Return(entity.Assignment{ID: 4, CreatedBy: 8}, nil)
+
+	_, err := service.ListPendingStudents(9, 4)
+	assert.Error(t, err)
+	assert.Equal(t, 404, err.(*app.AppError).Code)
+	mockRepo.AssertNotCalled(t, "ListPending", mock.Anything)
+}
+
+func TestDeleteAssignment(t *testing.T) {
+	mockRepo := new(MockAssignmentRepo)
+	service := svc.NewAssignmentService(mockRepo)
+
+	mockRepo.On("Detail", int32(4)).Return(entity.Assignment{ID: 4, CreatedBy: 9}, nil)
+	mockRepo.On("Delete", int32(4)).Return(nil)
+
+	err := service.DeleteAssignment(9, 4)
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestMyAssignments(t *testing.T) {
+	mockRepo := new(MockAssignmentRepo)
+	service := svc.NewAssignmentService(mockRepo)
+
+	now := time.Now().Unix()
+	submittedAt := now - 7200
+	mockRepo.On("ListForStudent", int32(11)).Return([]entity.MyAssignment{
+		{ID: 1, DueAt: now - 3600, SubmittedAt: &submittedAt},
+		{ID: 2, DueAt: now - 3600},
+		{ID: 3, DueAt: now + 3600},
+	}, nil)
+
+	assignments, err := service.MyAssignments(11)
+	assert.NoError(t, err)
+	assert.Equal(t, entity.StatusSubmitted, assignments[0].Status)
+	assert.Equal(t, entity.StatusOverdue, assignments[1].Status)
+	assert.Equal(t, entity.StatusPending, assignments[2].Status)
+}
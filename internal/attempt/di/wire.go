@@ -0,0 +1,24 @@
+package di
+
+import (
+	"database/sql"
+
+	attemptHandler "github.com/ghulammuzz/misterblast/internal/attempt/handler"
+	attemptRepo "github.com/ghulammuzz/misterblast/internal/attempt/repo"
+	attemptSvc "github.com/ghulammuzz/misterblast/internal/attempt/svc"
+	questionRepo "github.com/ghulammuzz/misterblast/internal/question/repo"
+	"github.com/ghulammuzz/misterblast/pkg/cache"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/wire"
+)
+
+func InitializedAttemptServiceFake(sb *sql.DB, val *validator.Validate, c cache.Cache) *attemptHandler.AttemptHandler {
+	wire.Build(
+		attemptHandler.NewAttemptHandler,
+		attemptSvc.NewAttemptService,
+		attemptRepo.NewAttemptRepository,
+		questionRepo.NewCachedQuestionRepository,
+	)
+
+	return &attemptHandler.AttemptHandler{}
+}
@@ -0,0 +1,118 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	StatusInProgress = "in_progress"
+	StatusGrading    = "grading"
+	StatusSubmitted  = "submitted"
+)
+
+// DeadlineGrace is how many seconds past its deadline an attempt still
+// accepts answers, covering the time a submit spends in transit.
+const DeadlineGrace = 30
+
+// MaxPoints is what a single question is worth. Automatically scored
+// questions earn all or nothing, essays are graded anywhere in between.
+const MaxPoints = 100
+
+// Attempt is one student's run through the quiz of a set. The shuffle
+// settings are copied from the set when the attempt starts and, with Seed,
+// fix the order its questions and options are delivered in. Deadline is fixed
+// at the start too; RemainingSeconds is measured by the server's clock when
+// the attempt is returned, so a client can count down without trusting its own.
+type Attempt struct {
+	ID               int32   `json:"id"`
+	UserID           int32   `json:"user_id"`
+	SetID            int32   `json:"set_id"`
+	Status           string  `json:"status"`
+	Correct          int     `json:"correct"`
+	Total            int     `json:"total"`
+	Score            float64 `json:"score"`
+	StartedAt        int64   `json:"started_at"`
+	SubmittedAt      *int64  `json:"submitted_at"`
+	ShuffleQuestions bool    `json:"shuffle_questions"`
+	ShuffleOptions   bool    `json:"shuffle_options"`
+	Seed             int64   `json:"-"`
+	Deadline         *int64  `json:"deadline"`
+	RemainingSeconds *int64  `json:"remaining_seconds,omitempty"`
+}
+
+// Expired tells whether answers to the attempt arrive too late at now.
+func (a Attempt) Expired(now int64) bool {
+	return a.Deadline != nil && now > *a.Deadline+DeadlineGrace
+}
+
+// QuizWindow is when and for how long the quiz of a set may be taken.
+// TimeLimit is in minutes, OpensAt and ClosesAt are unix seconds.
+type QuizWindow struct {
+	TimeLimit *int
+	OpensAt   *int64
+	ClosesAt  *int64
+}
+
+// Deadline is when an attempt started at now must be submitted: after the
+// time limit or when the quiz closes, whichever comes first.
+func (w QuizWindow) Deadline(now int64) *int64 {
+	var deadline *int64
+	if w.TimeLimit != nil {
+		d := now + int64(*w.TimeLimit)*60
+		deadline = &d
+	}
+	if w.ClosesAt != nil && (deadline == nil || *w.ClosesAt < *deadline) {
+		d := *w.ClosesAt
+		deadline = &d
+	}
+	return deadline
+}
+
+type AttemptAnswer struct {
+	QuestionID int32     `json:"question_id"`
+	AnswerID   int32     `json:"answer_id,omitempty"`
+	IsCorrect  bool      `json:"is_correct"`
+	EssayText  *string   `json:"essay_text,omitempty"`
+	Response   *Response `json:"response,omitempty"`
+	Points     *float64  `json:"points,omitempty"`
+	Feedback   *string   `json:"feedback,omitempty"`
+}
+
+// Response is the answer to a multiple, matching, ordering or fill_blank
+// question, stored as JSONB. AnswerIDs are the selected options, Matches maps
+// each matching option to the text it was paired with, Order lists the
+// ordering options first to last and Blanks fills the blanks in order.
+type Response struct {
+	AnswerIDs []int32          `json:"answer_ids,omitempty"`
+	Matches   map[int32]string `json:"matches,omitempty"`
+	Order     []int32          `json:"order,omitempty"`
+	Blanks    []string         `json:"blanks,omitempty"`
+}
+
+func (r Response) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
+
+func (r *Response) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, r)
+	case string:
+		return json.Unmarshal([]byte(v), r)
+	default:
+		return fmt.Errorf("cannot scan %T into Response", src)
+	}
+}
+
+// QuestionKey is what grading needs of a question: whether each option is
+// correct, and for matching, ordering and fill_blank questions the text each
+// option pairs with, its position and the accepted answers of each blank.
+type QuestionKey struct {
+	Kind      string
+	Options   map[int32]bool
+	Matches   map[int32]string
+	Positions map[int32]int
+	Blanks    map[int][]string
+}
@@ -0,0 +1,98 @@
+package entity
+
+import questionEntity "github.com/ghulammuzz/misterblast/internal/question/entity"
+
+type StartAttempt struct {
+	SetID int32 `json:"set_id" validate:"required"`
+}
+
+// SubmitAnswer answers one question: single and true_false questions with
+// AnswerID, essays with EssayText, multiple questions with AnswerIDs,
+// matching with Matches, ordering with Order and fill_blank with Blanks.
+// Which one a question needs is checked while grading.
+type SubmitAnswer struct {
+	QuestionID int32            `json:"question_id" validate:"required"`
+	AnswerID   int32            `json:"answer_id"`
+	EssayText  string           `json:"essay_text" validate:"max=5000"`
+	AnswerIDs  []int32          `json:"answer_ids" validate:"max=10"`
+	Matches    map[int32]string `json:"matches" validate:"max=50,dive,max=500"`
+	Order      []int32          `json:"order" validate:"max=50"`
+	Blanks     []string         `json:"blanks" validate:"max=50,dive,max=200"`
+}
+
+type SubmitAttempt struct {
+	Answers []SubmitAnswer `json:"answers" validate:"required,min=1,dive"`
+}
+
+type GradedAttempt struct {
+	Answers []AttemptAnswer
+	Status  string
+	Correct int
+	Total   int
+	Score   float64
+}
+
+type GradeEssay struct {
+	Points   *float64 `json:"points" validate:"required,min=0,max=100"`
+	Feedback string   `json:"feedback" validate:"max=2000"`
+}
+
+type PendingEssay struct {
+	AttemptID   int32  `json:"attempt_id"`
+	QuestionID  int32  `json:"question_id"`
+	Number      int    `json:"number"`
+	Question    string `json:"question"`
+	SetID       int32  `json:"set_id"`
+	SetName     string `json:"set_name"`
+	UserID      int32  `json:"user_id"`
+	UserName    string `json:"user_name"`
+	EssayText   string `json:"essay_text"`
+	SubmittedAt int64  `json:"submitted_at"`
+}
+
+type ListAttempt struct {
+	ID          int32   `json:"id"`
+	SetID       int32   `json:"set_id"`
+	SetName     string  `json:"set_name"`
+	Status      string  `json:"status"`
+	Correct     int     `json:"correct"`
+	Total       int     `json:"total"`
+	Score       float64 `json:"score"`
+	StartedAt   int64   `json:"started_at"`
+	SubmittedAt *int64  `json:"submitted_at"`
+}
+
+type DetailAttempt struct {
+	Attempt
+	Answers []AttemptAnswer `json:"answers"`
+}
+
+// AttemptQuiz is the quiz of an attempt as delivered to the student.
+type AttemptQuiz struct {
+	AttemptID        int32                             `json:"attempt_id"`
+	Deadline         *int64                            `json:"deadline"`
+	RemainingSeconds *int64                            `json:"remaining_seconds,omitempty"`
+	ServerTime       int64                             `json:"server_time"`
+	Questions        []questionEntity.ListQuestionQuiz `json:"questions"`
+}
+
+type ReviewQuestion struct {
+	ID               int32                       `json:"id"`
+	Number           int                         `json:"number"`
+	Type             string                      `json:"type"`
+	Kind             string                      `json:"kind"`
+	Content          string                      `json:"content"`
+	Answers          []questionEntity.ListAnswer `json:"answers"`
+	SelectedAnswerID *int32                      `json:"selected_answer_id"`
+	Response         *Response                   `json:"response,omitempty"`
+	IsCorrect        *bool                       `json:"is_correct"`
+	CorrectAnswers   []questionEntity.Answer     `json:"correct_answers,omitempty"`
+	EssayText        *string                     `json:"essay_text,omitempty"`
+	Points           *float64                    `json:"points,omitempty"`
+	Feedback         *string                     `json:"feedback,omitempty"`
+}
+
+type ReviewAttempt struct {
+	Attempt
+	Questions []ReviewQuestion `json:"questions"`
+}
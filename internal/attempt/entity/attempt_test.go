@@ -0,0 +1,28 @@
+package entity_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ghulammuzz/misterblast/internal/attempt/entity"
+)
+
+func TestQuizWindowDeadline(t *testing.T) {
+	limit := 30
+	closesAt := int64(10_000)
+
+	assert.Nil(t, entity.QuizWindow{}.Deadline(1000))
+	assert.Equal(t, int64(2800), *entity.QuizWindow{TimeLimit: &limit}.Deadline(1000))
+	assert.Equal(t, closesAt, *entity.QuizWindow{ClosesAt: &closesAt}.Deadline(1000))
+	assert.Equal(t, int64(2800), *entity.QuizWindow{TimeLimit: &limit, ClosesAt: &closesAt}.Deadline(1000))
+	assert.Equal(t, closesAt, *entity.QuizWindow{TimeLimit: &limit, ClosesAt: &closesAt}.Deadline(9000), "closing cuts the time limit short")
+}
+
+func TestAttemptExpired(t *testing.T) {
+	deadline := int64(1000)
+
+	assert.False(t, entity.Attempt{}.Expired(5000))
+	assert.False(t, entity.Attempt{Deadline: &deadline}.Expired(1000+entity.DeadlineGrace))
+	assert.True(t, entity.Attempt{Deadline: &deadline}.Expired(1001+entity.DeadlineGrace))
+}
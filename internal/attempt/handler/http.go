@@ -0,0 +1,257 @@
+package handler
+
+import (
+	"github.com/ghulammuzz/misterblast/internal/attempt/entity"
+	"github.com/ghulammuzz/misterblast/internal/attempt/svc"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/log"
+	"github.com/ghulammuzz/misterblast/pkg/middleware"
+	"github.com/ghulammuzz/misterblast/pkg/response"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type AttemptHandler struct {
+	attemptService svc.AttemptService
+	val            *validator.Validate
+}
+
+func NewAttemptHandler(attemptService svc.AttemptService, val *validator.Validate) *AttemptHandler {
+	return &AttemptHandler{attemptService, val}
+}
+
+func (h *AttemptHandler) Router(r fiber.Router) {
+	auth := middleware.JWTProtected()
+	staff := middleware.RequireRole(middleware.StaffRoles...)
+	student := middleware.RequireRole(middleware.AllRoles...)
+
+	r.Post("/attempt", auth, student, h.StartAttemptHandler)
+	r.Get("/attempt", auth, student, h.ListAttemptsHandler)
+	r.Get("/attempt/:id", auth, student, h.DetailAttemptHandler)
+	r.Post("/attempt/:id/submit", auth, student, h.SubmitAttemptHandler)
+	r.Get("/attempt/:id/questions", auth, student, h.AttemptQuestionsHandler)
+	r.Get("/attempt/:id/review", auth, student, h.ReviewAttemptHandler)
+
+	// essay grading
+	r.Get("/essay-grading", auth, staff, h.ListPendingEssaysHandler)
+	r.Put("/essay-grading/:attempt_id/:question_id", auth, staff, h.GradeEssayHandler)
+}
+
+func (h *AttemptHandler) StartAttemptHandler(c *fiber.Ctx) error {
+	userID, ok := middleware.UserID(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	var start entity.StartAttempt
+	if err := c.BodyParser(&start); err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid request body", nil)
+	}
+
+	if err := h.val.Struct(start); err != nil {
+		validationErrors := app.ValidationErrorResponse(err)
+		log.Error("Validation failed: %v", validationErrors)
+		return response.SendError(c, fiber.StatusBadRequest, "Validation failed", validationErrors)
+	}
+
+	attempt, err := h.attemptService.StartAttempt(userID, start)
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "attempt started successfully", attempt)
+}
+
+func (h *AttemptHandler) SubmitAttemptHandler(c *fiber.Ctx) error {
+	userID, ok := middleware.UserID(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid attempt ID", nil)
+	}
+
+	var submit entity.SubmitAttempt
+	if err := c.BodyParser(&submit); err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid request body", nil)
+	}
+
+	if err := h.val.Struct(submit); err != nil {
+		validationErrors := app.ValidationErrorResponse(err)
+		log.Error("Validation failed: %v", validationErrors)
+		return response.SendError(c, fiber.StatusBadRequest, "Validation failed", validationErrors)
+	}
+
+	result, err := h.attemptService.SubmitAttempt(userID, int32(id), submit)
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "attempt submitted successfully", result)
+}
+
+func (h *AttemptHandler) DetailAttemptHandler(c *fiber.Ctx) error {
+	userID, ok := middleware.UserID(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid attempt ID", nil)
+	}
+
+	attempt, err := h.attemptService.DetailAttempt(userID, int32(id))
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "attempt retrieved successfully", attempt)
+}
+
+// AttemptQuestionsHandler delivers the quiz questions of an attempt, shuffled
+// when the set asks for it, with the time left. Reloading returns the same
+// order.
+func (h *AttemptHandler) AttemptQuestionsHandler(c *fiber.Ctx) error {
+	userID, ok := middleware.UserID(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid attempt ID", nil)
+	}
+
+	questions, err := h.attemptService.AttemptQuestions(userID, int32(id))
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "attempt questions retrieved successfully", questions)
+}
+
+func (h *AttemptHandler) ReviewAttemptHandler(c *fiber.Ctx) error {
+	userID, ok := middleware.UserID(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid attempt ID", nil)
+	}
+
+	review, err := h.attemptService.ReviewAttempt(userID, int32(id))
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "attempt review retrieved successfully", review)
+}
+
+func (h *AttemptHandler) ListAttemptsHandler(c *fiber.Ctx) error {
+	userID, ok := middleware.UserID(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	filter := map[string]string{}
+	if setID := c.Query("set_id"); setID != "" {
+		filter["set_id"] = setID
+	}
+	if status := c.Query("status"); status != "" {
+		filter["status"] = status
+	}
+
+	attempts, err := h.attemptService.ListAttempts(userID, filter)
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "attempts retrieved successfully", attempts)
+}
+
+// Essay grading
+
+func (h *AttemptHandler) ListPendingEssaysHandler(c *fiber.Ctx) error {
+	filter := map[string]string{}
+	if setID := c.Query("set_id"); setID != "" {
+		filter["set_id"] = setID
+	}
+
+	essays, err := h.attemptService.ListPendingEssays(middleware.SchoolID(c), filter)
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "pending essays retrieved successfully", essays)
+}
+
+func (h *AttemptHandler) GradeEssayHandler(c *fiber.Ctx) error {
+	graderID, ok := middleware.UserID(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	attemptID, err := c.ParamsInt("attempt_id")
+	if err != nil || attemptID <= 0 {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid attempt ID", nil)
+	}
+
+	questionID, err := c.ParamsInt("question_id")
+	if err != nil || questionID <= 0 {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid question ID", nil)
+	}
+
+	var grade entity.GradeEssay
+	if err := c.BodyParser(&grade); err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid request body", nil)
+	}
+
+	if err := h.val.Struct(grade); err != nil {
+		validationErrors := app.ValidationErrorResponse(err)
+		log.Error("Validation failed: %v", validationErrors)
+		return response.SendError(c, fiber.StatusBadRequest, "Validation failed", validationErrors)
+	}
+
+	if err := h.attemptService.GradeEssay(graderID, middleware.SchoolID(c), int32(attemptID), int32(questionID), grade); err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "essay graded successfully", nil)
+}
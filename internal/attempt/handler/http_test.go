@@ -0,0 +1,236 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	attemptEntity "github.com/ghulammuzz/misterblast/internal/attempt/entity"
+	"github.com/ghulammuzz/misterblast/internal/attempt/handler"
+	questionEntity "github.com/ghulammuzz/misterblast/internal/question/entity"
+	"github.com/ghulammuzz/misterblast/pkg/middleware"
+)
+
+type MockAttemptService struct {
+	mock.Mock
+}
+
+func (m *MockAttemptService) StartAttempt(userID int32, start attemptEntity.StartAttempt) (attemptEntity.Attempt, error) {
+	args := m.Called(userID, start)
+	return args.Get(0).(attemptEntity.Attempt), args.Error(1)
+}
+
+func (m *MockAttemptService) SubmitAttempt(userID, attemptID int32, submit attemptEntity.SubmitAttempt) (attemptEntity.DetailAttempt, error) {
+	args := m.Called(userID, attemptID, submit)
+	return args.Get(0).(attemptEntity.DetailAttempt), args.Error(1)
+}
+
+func (m *MockAttemptService) DetailAttempt(userID, attemptID int32) (attemptEntity.DetailAttempt, error) {
+	args := m.Called(userID, attemptID)
+	return args.Get(0).(attemptEntity.DetailAttempt), args.Error(1)
+}
+
+func (m *MockAttemptService) ListAttempts(userID int32, filter map[string]string) ([]attemptEntity.ListAttempt, error) {
+	args := m.Called(userID, filter)
+	return args.Get(0).([]attemptEntity.ListAttempt), args.Error(1)
+}
+
+func (m *MockAttemptService) AttemptQuestions(userID, attemptID int32) (attemptEntity.AttemptQuiz, error) {
+	args := m.Called(userID, attemptID)
+	return args.Get(0).(attemptEntity.AttemptQuiz), args.Error(1)
+}
+
+func (m *MockAttemptService) ReviewAttempt(userID, attemptID int32) (attemptEntity.ReviewAttempt, error) {
+	args := m.Called(userID, attemptID)
+	return args.Get(0).(attemptEntity.ReviewAttempt), args.Error(1)
+}
+
+func (m *MockAttemptService) ListPendingEssays(schoolID *int32, filter map[string]string) ([]attemptEntity.PendingEssay, error) {
+	args := m.Called(schoolID, filter)
+	return args.Get(0).([]attemptEntity.PendingEssay), args.Error(1)
+}
+
+func (m *MockAttemptService) GradeEssay(graderID int32, schoolID *int32, attemptID, questionID int32, grade attemptEntity.GradeEssay) error {
+	args := m.Called(graderID, schoolID, attemptID, questionID, grade)
+	return args.Error(0)
+}
+
+func school(id int32) *int32 {
+	return &id
+}
+
+func signedToken(userID int) string {
+	claims := jwt.MapClaims{
+		"apps":    "misterblast-core",
+		"email":   "john@example.com",
+		"user_id": userID,
+		"role":    middleware.RoleStudent,
+		"jti":     "jti-test",
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, _ := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+	return signed
+}
+
+func TestStartAttemptHandler(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockAttemptService)
+	h := handler.NewAttemptHandler(mockService, validator.New())
+	app.Post("/attempt", middleware.JWTProtected(), h.StartAttemptHandler)
+
+	start := attemptEntity.StartAttempt{SetID: 2}
+	mockService.On("StartAttempt", int32(1), start).Return(attemptEntity.Attempt{ID: 9, UserID: 1, SetID: 2}, nil)
+
+	body, _ := json.Marshal(start)
+	req := httptest.NewRequest(http.MethodPost, "/attempt", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+signedToken(1))
+
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
+func TestStartAttemptHandler_NoToken(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockAttemptService)
+	h := handler.NewAttemptHandler(mockService, validator.New())
+	app.Post("/attempt", middleware.JWTProtected(), h.StartAttemptHandler)
+
+	req := httptest.NewRequest(http.MethodPost, "/attempt", bytes.NewReader([]byte(`{"set_id":2}`)))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	mockService.AssertNotCalled(t, "StartAttempt", mock.Anything, mock.Anything)
+}
+
+func TestSubmitAttemptHandler(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockAttemptService)
+	h := handler.NewAttemptHandler(mockService, validator.New())
+	app.Post("/attempt/:id/submit", middleware.JWTProtected(), h.SubmitAttemptHandler)
+
+	submit := attemptEntity.SubmitAttempt{Answers: []attemptEntity.SubmitAnswer{{QuestionID: 1, AnswerID: 11}}}
+	mockService.On("SubmitAttempt", int32(1), int32(5), submit).Return(attemptEntity.DetailAttempt{}, nil)
+
+	body, _ := json.Marshal(submit)
+	req := httptest.NewRequest(http.MethodPost, "/attempt/5/submit", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+signedToken(1))
+
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
+func TestSubmitAttemptHandler_EmptyAnswers(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockAttemptService)
+	h := handler.NewAttemptHandler(mockService, validator.New())
+	app.Post("/attempt/:id/submit", middleware.JWTProtected(), h.SubmitAttemptHandler)
+
+	req := httptest.NewRequest(http.MethodPost, "/attempt/5/submit", bytes.NewReader([]byte(`{"answers":[]}`)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+signedToken(1))
+
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestListAttemptsHandler(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockAttemptService)
+	h := handler.NewAttemptHandler(mockService, validator.New())
+	app.Get("/attempt", middleware.JWTProtected(), h.ListAttemptsHandler)
+
+	mockService.On("ListAttempts", int32(1), map[string]string{"set_id": "2"}).Return([]attemptEntity.ListAttempt{}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/attempt?set_id=2", nil)
+	req.Header.Set("Authorization", "Bearer "+signedToken(1))
+
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
+func TestReviewAttemptHandler(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockAttemptService)
+	h := handler.NewAttemptHandler(mockService, validator.New())
+	app.Get("/attempt/:id/review", middleware.JWTProtected(), h.ReviewAttemptHandler)
+
+	mockService.On("ReviewAttempt", int32(1), int32(5)).Return(attemptEntity.ReviewAttempt{}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/attempt/5/review", nil)
+	req.Header.Set("Authorization", "Bearer "+signedToken(1))
+
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
+func TestAttemptQuestionsHandler(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockAttemptService)
+	h := handler.NewAttemptHandler(mockService, validator.New())
+	app.Get("/attempt/:id/questions", middleware.JWTProtected(), h.AttemptQuestionsHandler)
+
+	mockService.On("AttemptQuestions", int32(1), int32(5)).Return(attemptEntity.AttemptQuiz{
+		AttemptID: 5,
+		Questions: []questionEntity.ListQuestionQuiz{{ID: 3, Number: 1}},
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/attempt/5/questions", nil)
+	req.Header.Set("Authorization", "Bearer "+signedToken(1))
+
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
+func TestGradeEssayHandler(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockAttemptService)
+	h := handler.NewAttemptHandler(mockService, validator.New())
+	app.Put("/essay-grading/:attempt_id/:question_id", middleware.JWTProtected(), h.GradeEssayHandler)
+
+	points := 75.0
+	grade := attemptEntity.GradeEssay{Points: &points, Feedback: "needs more detail"}
+	mockService.On("GradeEssay", int32(1), school(0), int32(5), int32(2), grade).Return(nil)
+
+	body, _ := json.Marshal(grade)
+	req := httptest.NewRequest(http.MethodPut, "/essay-grading/5/2", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+signedToken(1))
+
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
+func TestGradeEssayHandler_PointsOutOfRange(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockAttemptService)
+	h := handler.NewAttemptHandler(mockService, validator.New())
+	app.Put("/essay-grading/:attempt_id/:question_id", middleware.JWTProtected(), h.GradeEssayHandler)
+
+	req := httptest.NewRequest(http.MethodPut, "/essay-grading/5/2", bytes.NewReader([]byte(`{"points":150}`)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+signedToken(1))
+
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	mockService.AssertNotCalled(t, "GradeEssay", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
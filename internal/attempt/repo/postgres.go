@@ -0,0 +1,361 @@
+package repo
+
+import (
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"time"
+
+	attemptEntity "github.com/ghulammuzz/misterblast/internal/attempt/entity"
+	questionEntity "github.com/ghulammuzz/misterblast/internal/question/entity"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/log"
+)
+
+type AttemptRepository interface {
+	Start(userID, setID int32, deadline *int64) (attemptEntity.Attempt, error)
+	Detail(id int32) (attemptEntity.Attempt, error)
+	List(userID int32, filter map[string]string) ([]attemptEntity.ListAttempt, error)
+	ListAnswers(attemptID int32) ([]attemptEntity.AttemptAnswer, error)
+	QuizWindow(setID int32) (attemptEntity.QuizWindow, error)
+	AnswerKey(setID int32) (map[int32]attemptEntity.QuestionKey, error)
+	Submit(id int32, graded attemptEntity.GradedAttempt) error
+	ListExpired(before int64, limit int) ([]attemptEntity.Attempt, error)
+
+	// Essay grading
+	InSchool(schoolID *int32, id int32) error
+	ListPendingEssays(schoolID *int32, filter map[string]string) ([]attemptEntity.PendingEssay, error)
+	GradeEssay(attemptID, questionID, graderID int32, grade attemptEntity.GradeEssay) error
+	UpdateScore(id int32, status string, score float64) error
+}
+
+type attemptRepository struct {
+	db *sql.DB
+}
+
+func NewAttemptRepository(db *sql.DB) AttemptRepository {
+	return &attemptRepository{db: db}
+}
+
+func (r *attemptRepository) QuizWindow(setID int32) (attemptEntity.QuizWindow, error) {
+	var window attemptEntity.QuizWindow
+	var timeLimit sql.NullInt32
+	var opensAt, closesAt sql.NullInt64
+	query := `SELECT time_limit, opens_at, closes_at FROM sets WHERE id = $1`
+	err := r.db.QueryRow(query, setID).Scan(&timeLimit, &opensAt, &closesAt)
+	if err == sql.ErrNoRows {
+		return window, app.NewAppError(404, "set not found")
+	}
+	if err != nil {
+		log.Error("[Repo][QuizWindow] Error QueryRow: ", err)
+		return window, app.NewAppError(500, "failed to fetch set")
+	}
+
+	if timeLimit.Valid {
+		minutes := int(timeLimit.Int32)
+		window.TimeLimit = &minutes
+	}
+	if opensAt.Valid {
+		window.OpensAt = &opensAt.Int64
+	}
+	if closesAt.Valid {
+		window.ClosesAt = &closesAt.Int64
+	}
+	return window, nil
+}
+
+func (r *attemptRepository) Start(userID, setID int32, deadline *int64) (attemptEntity.Attempt, error) {
+	attempt := attemptEntity.Attempt{
+		UserID:    userID,
+		SetID:     setID,
+		Status:    attemptEntity.StatusInProgress,
+		StartedAt: time.Now().Unix(),
+		Seed:      rand.Int63(),
+		Deadline:  deadline,
+	}
+
+	// A student only starts the sets of their own school or the shared ones.
+	query := `
+		INSERT INTO quiz_attempts (user_id, set_id, status, started_at, seed, deadline, shuffle_questions, shuffle_options)
+		SELECT $1, $2, $3, $4, $5, $6, s.shuffle_questions, s.shuffle_options FROM sets s
+		WHERE s.id = $2 AND (s.school_id IS NULL OR s.school_id = (SELECT school_id FROM users WHERE id = $1))
+		RETURNING id, shuffle_questions, shuffle_options`
+	err := r.db.QueryRow(query, attempt.UserID, attempt.SetID, attempt.Status, attempt.StartedAt, attempt.Seed, attempt.Deadline).
+		Scan(&attempt.ID, &attempt.ShuffleQuestions, &attempt.ShuffleOptions)
+	if err == sql.ErrNoRows {
+		return attempt, app.NewAppError(404, "set not found")
+	}
+	if err != nil {
+		log.Error("[Repo][StartAttempt] Error QueryRow: ", err)
+		return attempt, app.NewAppError(500, "failed to start attempt")
+	}
+
+	return attempt, nil
+}
+
+func (r *attemptRepository) Detail(id int32) (attemptEntity.Attempt, error) {
+	query := `
+		SELECT id, user_id, set_id, status, correct, total, score, started_at, submitted_at,
+			   seed, shuffle_questions, shuffle_options, deadline
+		FROM quiz_attempts WHERE id = $1`
+	var attempt attemptEntity.Attempt
+	var submittedAt, deadline sql.NullInt64
+	err := r.db.QueryRow(query, id).Scan(&attempt.ID, &attempt.UserID, &attempt.SetID, &attempt.Status,
+		&attempt.Correct, &attempt.Total, &attempt.Score, &attempt.StartedAt, &submittedAt,
+		&attempt.Seed, &attempt.ShuffleQuestions, &attempt.ShuffleOptions, &deadline)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return attempt, app.NewAppError(404, "attempt not found")
+		}
+		log.Error("[Repo][DetailAttempt] Error QueryRow: ", err)
+		return attempt, app.NewAppError(500, "failed to fetch attempt")
+	}
+	if submittedAt.Valid {
+		attempt.SubmittedAt = &submittedAt.Int64
+	}
+	if deadline.Valid {
+		attempt.Deadline = &deadline.Int64
+	}
+
+	return attempt, nil
+}
+
+func (r *attemptRepository) List(userID int32, filter map[string]string) ([]attemptEntity.ListAttempt, error) {
+	query := `SELECT a.id, a.set_id, s.name, a.status, a.correct, a.total, a.score, a.started_at, a.submitted_at
+		FROM quiz_attempts a
+		JOIN sets s ON a.set_id = s.id
+		WHERE a.user_id = $1`
+	args := []interface{}{userID}
+	argCounter := 2
+
+	if setID, ok := filter["set_id"]; ok {
+		query += fmt.Sprintf(" AND a.set_id = $%d", argCounter)
+		args = append(args, setID)
+		argCounter++
+	}
+	if status, ok := filter["status"]; ok {
+		query += fmt.Sprintf(" AND a.status = $%d", argCounter)
+		args = append(args, status)
+		argCounter++
+	}
+
+	query += " ORDER BY a.started_at DESC"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		log.Error("[Repo][ListAttempts] Error Query: ", err)
+		return nil, app.NewAppError(500, "failed to fetch attempts")
+	}
+	defer rows.Close()
+
+	var attempts []attemptEntity.ListAttempt
+	for rows.Next() {
+		var attempt attemptEntity.ListAttempt
+		var submittedAt sql.NullInt64
+		if err := rows.Scan(&attempt.ID, &attempt.SetID, &attempt.SetName, &attempt.Status, &attempt.Correct,
+			&attempt.Total, &attempt.Score, &attempt.StartedAt, &submittedAt); err != nil {
+			log.Error("[Repo][ListAttempts] Error Scan: ", err)
+			return nil, app.NewAppError(500, "failed to scan attempt")
+		}
+		if submittedAt.Valid {
+			attempt.SubmittedAt = &submittedAt.Int64
+		}
+		attempts = append(attempts, attempt)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Error("[Repo][ListAttempts] Error Iterating Rows: ", err)
+		return nil, app.NewAppError(500, "error iterating rows")
+	}
+
+	return attempts, nil
+}
+
+func (r *attemptRepository) ListAnswers(attemptID int32) ([]attemptEntity.AttemptAnswer, error) {
+	query := `
+		SELECT question_id, COALESCE(answer_id, 0), is_correct, essay_text, response, points, feedback
+		FROM attempt_answers WHERE attempt_id = $1 ORDER BY question_id`
+	rows, err := r.db.Query(query, attemptID)
+	if err != nil {
+		log.Error("[Repo][ListAttemptAnswers] Error Query: ", err)
+		return nil, app.NewAppError(500, "failed to fetch attempt answers")
+	}
+	defer rows.Close()
+
+	answers := []attemptEntity.AttemptAnswer{}
+	for rows.Next() {
+		var answer attemptEntity.AttemptAnswer
+		var essayText, feedback sql.NullString
+		var response []byte
+		var points sql.NullFloat64
+		if err := rows.Scan(&answer.QuestionID, &answer.AnswerID, &answer.IsCorrect, &essayText, &response, &points, &feedback); err != nil {
+			log.Error("[Repo][ListAttemptAnswers] Error Scan: ", err)
+			return nil, app.NewAppError(500, "failed to scan attempt answer")
+		}
+		if essayText.Valid {
+			answer.EssayText = &essayText.String
+		}
+		if response != nil {
+			answer.Response = &attemptEntity.Response{}
+			if err := answer.Response.Scan(response); err != nil {
+				log.Error("[Repo][ListAttemptAnswers] Error Scan Response: ", err)
+				return nil, app.NewAppError(500, "failed to scan attempt answer")
+			}
+		}
+		if points.Valid {
+			answer.Points = &points.Float64
+		}
+		if feedback.Valid {
+			answer.Feedback = &feedback.String
+		}
+		answers = append(answers, answer)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Error("[Repo][ListAttemptAnswers] Error Iterating Rows: ", err)
+		return nil, app.NewAppError(500, "error iterating rows")
+	}
+
+	return answers, nil
+}
+
+// AnswerKey maps every quiz question of a set to its kind and its answer
+// options: whether each is correct, and the match texts, positions and blank
+// answers of the kinds that use them. Questions without options are still
+// returned so they count towards the total.
+func (r *attemptRepository) AnswerKey(setID int32) (map[int32]attemptEntity.QuestionKey, error) {
+	query := `
+		SELECT q.id, q.kind, COALESCE(a.id, 0), COALESCE(a.is_answer, false),
+			   COALESCE(a.content, ''), a.match_text, a.position
+		FROM questions q
+		LEFT JOIN answers a ON q.id = a.question_id
+		WHERE q.set_id = $1 AND q.is_quiz = true
+	`
+	rows, err := r.db.Query(query, setID)
+	if err != nil {
+		log.Error("[Repo][AnswerKey] Error Query: ", err)
+		return nil, app.NewAppError(500, "failed to fetch answer key")
+	}
+	defer rows.Close()
+
+	key := make(map[int32]attemptEntity.QuestionKey)
+	for rows.Next() {
+		var questionID, answerID int32
+		var kind, content string
+		var isAnswer bool
+		var matchText sql.NullString
+		var position sql.NullInt32
+		if err := rows.Scan(&questionID, &kind, &answerID, &isAnswer, &content, &matchText, &position); err != nil {
+			log.Error("[Repo][AnswerKey] Error Scan: ", err)
+			return nil, app.NewAppError(500, "failed to scan answer key")
+		}
+		if _, ok := key[questionID]; !ok {
+			key[questionID] = attemptEntity.QuestionKey{
+				Kind:      kind,
+				Options:   make(map[int32]bool),
+				Matches:   make(map[int32]string),
+				Positions: make(map[int32]int),
+				Blanks:    make(map[int][]string),
+			}
+		}
+		if answerID == 0 {
+			continue
+		}
+
+		question := key[questionID]
+		question.Options[answerID] = isAnswer
+		switch kind {
+		case questionEntity.KindMatching:
+			question.Matches[answerID] = matchText.String
+		case questionEntity.KindOrdering:
+			question.Positions[answerID] = int(position.Int32)
+		case questionEntity.KindFillBlank:
+			question.Blanks[int(position.Int32)] = append(question.Blanks[int(position.Int32)], content)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Error("[Repo][AnswerKey] Error Iterating Rows: ", err)
+		return nil, app.NewAppError(500, "error iterating rows")
+	}
+
+	return key, nil
+}
+
+func (r *attemptRepository) Submit(id int32, graded attemptEntity.GradedAttempt) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Error("[Repo][SubmitAttempt] Error Begin: ", err)
+		return app.NewAppError(500, "failed to submit attempt")
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE quiz_attempts
+		SET status = $1, correct = $2, total = $3, score = $4, submitted_at = $5
+		WHERE id = $6 AND status = $7`
+	res, err := tx.Exec(query, graded.Status, graded.Correct, graded.Total, graded.Score,
+		time.Now().Unix(), id, attemptEntity.StatusInProgress)
+	if err != nil {
+		log.Error("[Repo][SubmitAttempt] Error Exec: ", err)
+		return app.NewAppError(500, "failed to submit attempt")
+	}
+
+	rowsAffected, _ := res.RowsAffected()
+	if rowsAffected == 0 {
+		return app.NewAppError(409, "attempt already submitted")
+	}
+
+	insert := `
+		INSERT INTO attempt_answers (attempt_id, question_id, answer_id, is_correct, essay_text, response)
+		VALUES ($1, $2, $3, $4, $5, $6)`
+	for _, answer := range graded.Answers {
+		answerID := sql.NullInt32{Int32: answer.AnswerID, Valid: answer.AnswerID != 0}
+		if _, err := tx.Exec(insert, id, answer.QuestionID, answerID, answer.IsCorrect, answer.EssayText, answer.Response); err != nil {
+			log.Error("[Repo][SubmitAttempt] Error Exec Answer: ", err)
+			return app.NewAppError(500, "failed to save attempt answers")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error("[Repo][SubmitAttempt] Error Commit: ", err)
+		return app.NewAppError(500, "failed to submit attempt")
+	}
+
+	return nil
+}
+
+// ListExpired returns attempts still in progress whose deadline passed before
+// the given time, oldest deadline first.
+func (r *attemptRepository) ListExpired(before int64, limit int) ([]attemptEntity.Attempt, error) {
+	query := `
+		SELECT id, user_id, set_id, deadline FROM quiz_attempts
+		WHERE status = $1 AND deadline IS NOT NULL AND deadline < $2
+		ORDER BY deadline LIMIT $3`
+	rows, err := r.db.Query(query, attemptEntity.StatusInProgress, before, limit)
+	if err != nil {
+		log.Error("[Repo][ListExpiredAttempts] Error Query: ", err)
+		return nil, app.NewAppError(500, "failed to fetch expired attempts")
+	}
+	defer rows.Close()
+
+	var attempts []attemptEntity.Attempt
+	for rows.Next() {
+		var attempt attemptEntity.Attempt
+		var deadline int64
+		if err := rows.Scan(&attempt.ID, &attempt.UserID, &attempt.SetID, &deadline); err != nil {
+			log.Error("[Repo][ListExpiredAttempts] Error Scan: ", err)
+			return nil, app.NewAppError(500, "failed to scan attempt")
+		}
+		attempt.Status = attemptEntity.StatusInProgress
+		attempt.Deadline = &deadline
+		attempts = append(attempts, attempt)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Error("[Repo][ListExpiredAttempts] Error Iterating Rows: ", err)
+		return nil, app.NewAppError(500, "error iterating rows")
+	}
+
+	return attempts, nil
+}
@@ -0,0 +1,116 @@
+package repo
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	attemptEntity "github.com/ghulammuzz/misterblast/internal/attempt/entity"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/log"
+)
+
+// InSchool reports an attempt of a student outside schoolID as not found; nil,
+// for super admins, reaches every school.
+func (r *attemptRepository) InSchool(schoolID *int32, id int32) error {
+	query := `SELECT 1 FROM quiz_attempts a JOIN users u ON a.user_id = u.id WHERE a.id = $1`
+	args := []interface{}{id}
+	if schoolID != nil {
+		query += ` AND u.school_id = $2`
+		args = append(args, *schoolID)
+	}
+
+	var found int
+	if err := r.db.QueryRow(query, args...).Scan(&found); err != nil {
+		if err == sql.ErrNoRows {
+			return app.NewAppError(404, "attempt not found")
+		}
+		log.Error("[Repo][AttemptInSchool] Error QueryRow: ", err)
+		return app.NewAppError(500, "failed to fetch attempt")
+	}
+	return nil
+}
+
+// ListPendingEssays lists the ungraded essays of the students of schoolID.
+func (r *attemptRepository) ListPendingEssays(schoolID *int32, filter map[string]string) ([]attemptEntity.PendingEssay, error) {
+	query := `
+		SELECT aa.attempt_id, aa.question_id, q.number, q.content, a.set_id, s.name,
+			   a.user_id, u.name, aa.essay_text, COALESCE(a.submitted_at, 0)
+		FROM attempt_answers aa
+		JOIN quiz_attempts a ON aa.attempt_id = a.id
+		JOIN questions q ON aa.question_id = q.id
+		JOIN sets s ON a.set_id = s.id
+		JOIN users u ON a.user_id = u.id
+		WHERE aa.essay_text IS NOT NULL AND aa.points IS NULL
+	`
+	args := []interface{}{}
+	argCounter := 1
+
+	if schoolID != nil {
+		query += fmt.Sprintf(" AND u.school_id = $%d", argCounter)
+		args = append(args, *schoolID)
+		argCounter++
+	}
+
+	if setID, ok := filter["set_id"]; ok {
+		query += fmt.Sprintf(" AND a.set_id = $%d", argCounter)
+		args = append(args, setID)
+		argCounter++
+	}
+
+	query += " ORDER BY a.submitted_at, aa.attempt_id, q.number"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		log.Error("[Repo][ListPendingEssays] Error Query: ", err)
+		return nil, app.NewAppError(500, "failed to fetch pending essays")
+	}
+	defer rows.Close()
+
+	essays := []attemptEntity.PendingEssay{}
+	for rows.Next() {
+		var e attemptEntity.PendingEssay
+		if err := rows.Scan(&e.AttemptID, &e.QuestionID, &e.Number, &e.Question, &e.SetID, &e.SetName,
+			&e.UserID, &e.UserName, &e.EssayText, &e.SubmittedAt); err != nil {
+			log.Error("[Repo][ListPendingEssays] Error Scan: ", err)
+			return nil, app.NewAppError(500, "failed to scan pending essay")
+		}
+		essays = append(essays, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Error("[Repo][ListPendingEssays] Error Iterating Rows: ", err)
+		return nil, app.NewAppError(500, "error iterating rows")
+	}
+
+	return essays, nil
+}
+
+func (r *attemptRepository) GradeEssay(attemptID, questionID, graderID int32, grade attemptEntity.GradeEssay) error {
+	query := `
+		UPDATE attempt_answers
+		SET points = $1, feedback = $2, graded_by = $3, graded_at = $4
+		WHERE attempt_id = $5 AND question_id = $6 AND essay_text IS NOT NULL`
+
+	res, err := r.db.Exec(query, *grade.Points, grade.Feedback, graderID, time.Now().Unix(), attemptID, questionID)
+	if err != nil {
+		log.Error("[Repo][GradeEssay] Error Exec: ", err)
+		return app.NewAppError(500, "failed to grade essay")
+	}
+
+	rowsAffected, _ := res.RowsAffected()
+	if rowsAffected == 0 {
+		return app.NewAppError(404, "essay answer not found")
+	}
+
+	return nil
+}
+
+func (r *attemptRepository) UpdateScore(id int32, status string, score float64) error {
+	query := `UPDATE quiz_attempts SET status = $1, score = $2 WHERE id = $3`
+	if _, err := r.db.Exec(query, status, score, id); err != nil {
+		log.Error("[Repo][UpdateScore] Error Exec: ", err)
+		return app.NewAppError(500, "failed to update attempt score")
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package repo_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	attemptEntity "github.com/ghulammuzz/misterblast/internal/attempt/entity"
+	"github.com/ghulammuzz/misterblast/internal/attempt/repo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartAttempt(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewAttemptRepository(db)
+
+	deadline := int64(1700001800)
+	mock.ExpectQuery(`INSERT INTO quiz_attempts \(user_id, set_id, status, started_at, seed, deadline, shuffle_questions, shuffle_options\)(.|\n)+s.school_id = \(SELECT school_id FROM users WHERE id = \$1\)`).
+		WithArgs(1, 2, attemptEntity.StatusInProgress, sqlmock.AnyArg(), sqlmock.AnyArg(), &deadline).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "shuffle_questions", "shuffle_options"}).AddRow(7, true, false))
+
+	attempt, err := repository.Start(1, 2, &deadline)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(7), attempt.ID)
+	assert.Equal(t, attemptEntity.StatusInProgress, attempt.Status)
+	assert.True(t, attempt.ShuffleQuestions)
+	assert.False(t, attempt.ShuffleOptions)
+	assert.Equal(t, &deadline, attempt.Deadline)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestQuizWindow(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewAttemptRepository(db)
+
+	mock.ExpectQuery(`SELECT time_limit, opens_at, closes_at FROM sets WHERE id = \$1`).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"time_limit", "opens_at", "closes_at"}).AddRow(45, nil, 1700086400))
+
+	window, err := repository.QuizWindow(2)
+	assert.NoError(t, err)
+	assert.Equal(t, 45, *window.TimeLimit)
+	assert.Nil(t, window.OpensAt)
+	assert.Equal(t, int64(1700086400), *window.ClosesAt)
+
+	mock.ExpectQuery(`SELECT time_limit, opens_at, closes_at FROM sets WHERE id = \$1`).
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"time_limit", "opens_at", "closes_at"}))
+
+	_, err = repository.QuizWindow(3)
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListExpiredAttempts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewAttemptRepository(db)
+
+	mock.ExpectQuery(`SELECT id, user_id, set_id, deadline FROM quiz_attempts`).
+		WithArgs(attemptEntity.StatusInProgress, int64(1700000000), 50).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "set_id", "deadline"}).AddRow(7, 1, 2, 1699999000))
+
+	attempts, err := repository.ListExpired(1700000000, 50)
+	assert.NoError(t, err)
+	assert.Len(t, attempts, 1)
+	assert.Equal(t, int32(2), attempts[0].SetID)
+	assert.Equal(t, int64(1699999000), *attempts[0].Deadline)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAnswerKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewAttemptRepository(db)
+
+	rows := sqlmock.NewRows([]string{"question_id", "kind", "answer_id", "is_answer", "content", "match_text", "position"}).
+		AddRow(1, "single", 10, false, "3", nil, nil).
+		AddRow(1, "single", 11, true, "4", nil, nil).
+		AddRow(2, "essay", 0, false, "", nil, nil)
+
+	mock.ExpectQuery(`SELECT q.id, q.kind, COALESCE\(a.id, 0\), COALESCE\(a.is_answer, false\)`).
+		WithArgs(3).
+		WillReturnRows(rows)
+
+	key, err := repository.AnswerKey(3)
+	assert.NoError(t, err)
+	assert.Len(t, key, 2)
+	assert.True(t, key[1].Options[11])
+	assert.False(t, key[1].Options[10])
+	assert.Equal(t, "essay", key[2].Kind)
+	assert.Empty(t, key[2].Options)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSubmitAttempt(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewAttemptRepository(db)
+
+	essay := "Because the moon orbits the earth."
+	graded := attemptEntity.GradedAttempt{
+		Answers: []attemptEntity.AttemptAnswer{
+			{QuestionID: 1, AnswerID: 11, IsCorrect: true},
+			{QuestionID: 2, EssayText: &essay},
+		},
+		Status:  attemptEntity.StatusGrading,
+		Correct: 1,
+		Total:   2,
+		Score:   50,
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE quiz_attempts SET status = \$1`).
+		WithArgs(attemptEntity.StatusGrading, 1, 2, 50.0, sqlmock.AnyArg(), 5, attemptEntity.StatusInProgress).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO attempt_answers`).
+		WithArgs(5, 1, 11, true, nil, nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`INSERT INTO attempt_answers`).
+		WithArgs(5, 2, nil, false, essay, nil).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectCommit()
+
+	err = repository.Submit(5, graded)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSubmitAttempt_AlreadySubmitted(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewAttemptRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE quiz_attempts SET status = \$1`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err = repository.Submit(5, attemptEntity.GradedAttempt{})
+	assert.Error(t, err)
+	assert.Equal(t, "attempt already submitted", err.Error())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListPendingEssays_SchoolScope(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewAttemptRepository(db)
+
+	columns := []string{"attempt_id", "question_id", "number", "content", "set_id", "name", "user_id", "name", "essay_text", "submitted_at"}
+	mock.ExpectQuery(`WHERE aa.essay_text IS NOT NULL AND aa.points IS NULL AND u.school_id = \$1 AND a.set_id = \$2`).
+		WithArgs(int32(1), "3").
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(5, 2, 1, "Jelaskan", 3, "Set", 11, "Ani", "jawaban", 100))
+
+	schoolID := int32(1)
+	essays, err := repository.ListPendingEssays(&schoolID, map[string]string{"set_id": "3"})
+	assert.NoError(t, err)
+	assert.Len(t, essays, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAttemptInSchool(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewAttemptRepository(db)
+
+	mock.ExpectQuery(`SELECT 1 FROM quiz_attempts a JOIN users u ON a.user_id = u.id WHERE a.id = \$1 AND u.school_id = \$2`).
+		WithArgs(5, int32(2)).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(`SELECT 1 FROM quiz_attempts a JOIN users u ON a.user_id = u.id WHERE a.id = \$1$`).
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"?column?"}).AddRow(1))
+
+	other := int32(2)
+	err = repository.InSchool(&other, 5)
+	assert.Equal(t, "attempt not found", err.Error())
+	assert.NoError(t, repository.InSchool(nil, 5))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGradeEssay(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewAttemptRepository(db)
+
+	points := 80.0
+	mock.ExpectExec(`UPDATE attempt_answers SET points = \$1`).
+		WithArgs(80.0, "good", 9, sqlmock.AnyArg(), 5, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repository.GradeEssay(5, 2, 9, attemptEntity.GradeEssay{Points: &points, Feedback: "good"})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGradeEssay_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewAttemptRepository(db)
+
+	points := 80.0
+	mock.ExpectExec(`UPDATE attempt_answers SET points = \$1`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repository.GradeEssay(5, 2, 9, attemptEntity.GradeEssay{Points: &points})
+	assert.Error(t, err)
+	assert.Equal(t, "essay answer not found", err.Error())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,74 @@
+package svc
+
+import (
+	"context"
+	"time"
+
+	attemptEntity "github.com/ghulammuzz/misterblast/internal/attempt/entity"
+	attemptRepo "github.com/ghulammuzz/misterblast/internal/attempt/repo"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/log"
+)
+
+const (
+	sweepInterval  = 15 * time.Second
+	sweepBatchSize = 50
+)
+
+// DeadlineSweeper submits attempts left in progress past their deadline, so
+// an abandoned timed quiz still ends up scored.
+type DeadlineSweeper struct {
+	repo attemptRepo.AttemptRepository
+}
+
+func NewDeadlineSweeper(repo attemptRepo.AttemptRepository) *DeadlineSweeper {
+	return &DeadlineSweeper{repo: repo}
+}
+
+// Run sweeps expired attempts until ctx is cancelled.
+func (w *DeadlineSweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+
+	for {
+		// Sweep again right away while attempts keep getting submitted;
+		// attempts that fail every time wait for the next tick.
+		if w.ProcessBatch() > 0 && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessBatch submits one batch of expired attempts and returns how many
+// left the in-progress state, including those submitted meanwhile. Attempts
+// are only swept once the grace period has passed, so a submit still in
+// transit wins.
+func (w *DeadlineSweeper) ProcessBatch() int {
+	attempts, err := w.repo.ListExpired(time.Now().Unix()-attemptEntity.DeadlineGrace, sweepBatchSize)
+	if err != nil {
+		log.Error("[Worker][DeadlineSweeper] Error ListExpired: ", err)
+		return 0
+	}
+
+	done := 0
+	for _, attempt := range attempts {
+		err := autoSubmit(w.repo, attempt)
+		if appErr, ok := err.(*app.AppError); ok && appErr.Code == 409 {
+			done++ // submitted meanwhile
+			continue
+		}
+		if err != nil {
+			log.Error("[Worker][DeadlineSweeper] Error autoSubmit: ", attempt.ID, " error: ", err)
+			continue
+		}
+		done++
+	}
+
+	return done
+}
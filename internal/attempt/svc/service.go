@@ -0,0 +1,455 @@
+package svc
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	attemptEntity "github.com/ghulammuzz/misterblast/internal/attempt/entity"
+	attemptRepo "github.com/ghulammuzz/misterblast/internal/attempt/repo"
+	questionEntity "github.com/ghulammuzz/misterblast/internal/question/entity"
+	questionRepo "github.com/ghulammuzz/misterblast/internal/question/repo"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/log"
+)
+
+type AttemptService interface {
+	StartAttempt(userID int32, start attemptEntity.StartAttempt) (attemptEntity.Attempt, error)
+	SubmitAttempt(userID, attemptID int32, submit attemptEntity.SubmitAttempt) (attemptEntity.DetailAttempt, error)
+	DetailAttempt(userID, attemptID int32) (attemptEntity.DetailAttempt, error)
+	ListAttempts(userID int32, filter map[string]string) ([]attemptEntity.ListAttempt, error)
+	ReviewAttempt(userID, attemptID int32) (attemptEntity.ReviewAttempt, error)
+	AttemptQuestions(userID, attemptID int32) (attemptEntity.AttemptQuiz, error)
+
+	// Essay grading
+	ListPendingEssays(schoolID *int32, filter map[string]string) ([]attemptEntity.PendingEssay, error)
+	GradeEssay(graderID int32, schoolID *int32, attemptID, questionID int32, grade attemptEntity.GradeEssay) error
+}
+
+type attemptService struct {
+	repo         attemptRepo.AttemptRepository
+	questionRepo questionRepo.QuestionRepository
+}
+
+func NewAttemptService(repo attemptRepo.AttemptRepository, questionRepo questionRepo.QuestionRepository) AttemptService {
+	return &attemptService{repo: repo, questionRepo: questionRepo}
+}
+
+// StartAttempt opens an attempt while the set's quiz window is open and fixes
+// its deadline.
+func (s *attemptService) StartAttempt(userID int32, start attemptEntity.StartAttempt) (attemptEntity.Attempt, error) {
+	window, err := s.repo.QuizWindow(start.SetID)
+	if err != nil {
+		return attemptEntity.Attempt{}, err
+	}
+
+	now := time.Now().Unix()
+	if window.OpensAt != nil && now < *window.OpensAt {
+		return attemptEntity.Attempt{}, app.NewAppError(403, "quiz is not open yet")
+	}
+	if window.ClosesAt != nil && now >= *window.ClosesAt {
+		return attemptEntity.Attempt{}, app.NewAppError(403, "quiz is closed")
+	}
+
+	attempt, err := s.repo.Start(userID, start.SetID, window.Deadline(now))
+	if err != nil {
+		return attempt, err
+	}
+
+	return withRemaining(attempt, now), nil
+}
+
+func (s *attemptService) SubmitAttempt(userID, attemptID int32, submit attemptEntity.SubmitAttempt) (attemptEntity.DetailAttempt, error) {
+	attempt, err := s.ownedAttempt(userID, attemptID)
+	if err != nil {
+		return attemptEntity.DetailAttempt{}, err
+	}
+	if attempt.Status != attemptEntity.StatusInProgress {
+		return attemptEntity.DetailAttempt{}, app.NewAppError(409, "attempt already submitted")
+	}
+	if attempt.Expired(time.Now().Unix()) {
+		// Late answers are discarded; the attempt is scored as left at the deadline.
+		if err := autoSubmit(s.repo, attempt); err != nil {
+			return attemptEntity.DetailAttempt{}, err
+		}
+		return attemptEntity.DetailAttempt{}, app.NewAppError(409, "time limit exceeded")
+	}
+
+	key, err := s.repo.AnswerKey(attempt.SetID)
+	if err != nil {
+		return attemptEntity.DetailAttempt{}, err
+	}
+
+	graded, err := grade(key, submit.Answers)
+	if err != nil {
+		return attemptEntity.DetailAttempt{}, err
+	}
+
+	if err := s.repo.Submit(attemptID, graded); err != nil {
+		log.Error("[Svc][SubmitAttempt] Error: ", err)
+		return attemptEntity.DetailAttempt{}, err
+	}
+
+	return s.DetailAttempt(userID, attemptID)
+}
+
+func (s *attemptService) DetailAttempt(userID, attemptID int32) (attemptEntity.DetailAttempt, error) {
+	attempt, err := s.ownedAttempt(userID, attemptID)
+	if err != nil {
+		return attemptEntity.DetailAttempt{}, err
+	}
+
+	answers, err := s.repo.ListAnswers(attemptID)
+	if err != nil {
+		return attemptEntity.DetailAttempt{}, err
+	}
+
+	return attemptEntity.DetailAttempt{Attempt: withRemaining(attempt, time.Now().Unix()), Answers: answers}, nil
+}
+
+func (s *attemptService) ListAttempts(userID int32, filter map[string]string) ([]attemptEntity.ListAttempt, error) {
+	return s.repo.List(userID, filter)
+}
+
+// AttemptQuestions lists the quiz questions of an attempt in the order drawn
+// for it. The order only depends on the attempt, so a reload shows the same
+// layout, while students of the same set each get their own. The time left
+// comes along, measured by the server's clock.
+func (s *attemptService) AttemptQuestions(userID, attemptID int32) (attemptEntity.AttemptQuiz, error) {
+	attempt, err := s.ownedAttempt(userID, attemptID)
+	if err != nil {
+		return attemptEntity.AttemptQuiz{}, err
+	}
+
+	now := time.Now().Unix()
+	if attempt.Status == attemptEntity.StatusInProgress && attempt.Expired(now) {
+		return attemptEntity.AttemptQuiz{}, app.NewAppError(409, "time limit exceeded")
+	}
+
+	// Starting the attempt already checked the set is visible to the student.
+	questions, err := s.questionRepo.ListQuizQuestions(nil, map[string]string{"set_id": strconv.Itoa(int(attempt.SetID))})
+	if err != nil {
+		return attemptEntity.AttemptQuiz{}, err
+	}
+
+	questionEntity.ShuffleQuiz(questions, attempt.Seed, attempt.ShuffleQuestions, attempt.ShuffleOptions)
+
+	attempt = withRemaining(attempt, now)
+	return attemptEntity.AttemptQuiz{
+		AttemptID:        attempt.ID,
+		Deadline:         attempt.Deadline,
+		RemainingSeconds: attempt.RemainingSeconds,
+		ServerTime:       now,
+		Questions:        questions,
+	}, nil
+}
+
+// ReviewAttempt lists the set's questions for a finished attempt. The correct
+// options are only revealed for questions the student actually answered.
+func (s *attemptService) ReviewAttempt(userID, attemptID int32) (attemptEntity.ReviewAttempt, error) {
+	attempt, err := s.ownedAttempt(userID, attemptID)
+	if err != nil {
+		return attemptEntity.ReviewAttempt{}, err
+	}
+	if attempt.Status == attemptEntity.StatusInProgress {
+		return attemptEntity.ReviewAttempt{}, app.NewAppError(409, "attempt not yet submitted")
+	}
+
+	answers, err := s.repo.ListAnswers(attemptID)
+	if err != nil {
+		return attemptEntity.ReviewAttempt{}, err
+	}
+
+	questions, err := s.questionRepo.ListAnswerKey(nil, attempt.SetID)
+	if err != nil {
+		return attemptEntity.ReviewAttempt{}, err
+	}
+
+	selected := make(map[int32]attemptEntity.AttemptAnswer, len(answers))
+	for _, answer := range answers {
+		selected[answer.QuestionID] = answer
+	}
+
+	review := attemptEntity.ReviewAttempt{Attempt: attempt, Questions: []attemptEntity.ReviewQuestion{}}
+	for _, q := range questions {
+		item := attemptEntity.ReviewQuestion{
+			ID:      q.ID,
+			Number:  q.Number,
+			Type:    q.Type,
+			Kind:    q.Kind,
+			Content: q.Content,
+			Answers: []questionEntity.ListAnswer{},
+		}
+		// The options of a blank are its accepted answers and that of an essay
+		// its model answer, so neither is listed. Ordering options are listed
+		// by content as on the quiz, as their authored order is the solution.
+		switch q.Kind {
+		case questionEntity.KindFillBlank, questionEntity.KindEssay:
+		default:
+			for _, a := range q.Answers {
+				item.Answers = append(item.Answers, questionEntity.ListAnswer{ID: a.ID, Code: a.Code, Content: a.Content, ImgURL: a.ImgURL})
+			}
+			if q.Kind == questionEntity.KindOrdering {
+				sort.SliceStable(item.Answers, func(i, j int) bool { return item.Answers[i].Content < item.Answers[j].Content })
+			}
+		}
+
+		if answer, ok := selected[q.ID]; ok && answer.EssayText != nil {
+			item.EssayText = answer.EssayText
+			item.Points = answer.Points
+			item.Feedback = answer.Feedback
+		} else if ok {
+			if answer.Response == nil {
+				item.SelectedAnswerID = &answer.AnswerID
+			}
+			item.Response = answer.Response
+			item.IsCorrect = &answer.IsCorrect
+			for _, a := range q.Answers {
+				// Matching, ordering and fill_blank options all make up the key.
+				if a.IsAnswer || !questionEntity.IsChoiceKind(q.Kind) {
+					item.CorrectAnswers = append(item.CorrectAnswers, a)
+				}
+			}
+		}
+
+		review.Questions = append(review.Questions, item)
+	}
+
+	return review, nil
+}
+
+// ListPendingEssays and GradeEssay only reach the attempts of students of
+// schoolID; nil, for super admins, reaches every school.
+func (s *attemptService) ListPendingEssays(schoolID *int32, filter map[string]string) ([]attemptEntity.PendingEssay, error) {
+	return s.repo.ListPendingEssays(schoolID, filter)
+}
+
+// GradeEssay stores the teacher's points for one essay. Once no essay of the
+// attempt is left ungraded the final score is recalculated.
+func (s *attemptService) GradeEssay(graderID int32, schoolID *int32, attemptID, questionID int32, grade attemptEntity.GradeEssay) error {
+	if err := s.repo.InSchool(schoolID, attemptID); err != nil {
+		return err
+	}
+
+	attempt, err := s.repo.Detail(attemptID)
+	if err != nil {
+		return err
+	}
+	if attempt.Status == attemptEntity.StatusInProgress {
+		return app.NewAppError(409, "attempt not yet submitted")
+	}
+
+	if err := s.repo.GradeEssay(attemptID, questionID, graderID, grade); err != nil {
+		return err
+	}
+
+	answers, err := s.repo.ListAnswers(attemptID)
+	if err != nil {
+		return err
+	}
+
+	var essayPoints float64
+	for _, answer := range answers {
+		if answer.EssayText == nil {
+			continue
+		}
+		if answer.Points == nil {
+			return nil
+		}
+		essayPoints += *answer.Points
+	}
+
+	if err := s.repo.UpdateScore(attemptID, attemptEntity.StatusSubmitted, score(attempt.Correct, essayPoints, attempt.Total)); err != nil {
+		log.Error("[Svc][GradeEssay] Error: ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *attemptService) ownedAttempt(userID, attemptID int32) (attemptEntity.Attempt, error) {
+	attempt, err := s.repo.Detail(attemptID)
+	if err != nil {
+		return attempt, err
+	}
+	if attempt.UserID != userID {
+		return attemptEntity.Attempt{}, app.NewAppError(404, "attempt not found")
+	}
+	return attempt, nil
+}
+
+// withRemaining fills in the seconds left until the deadline of an attempt
+// still in progress.
+func withRemaining(attempt attemptEntity.Attempt, now int64) attemptEntity.Attempt {
+	if attempt.Deadline == nil || attempt.Status != attemptEntity.StatusInProgress {
+		return attempt
+	}
+	remaining := max(*attempt.Deadline-now, 0)
+	attempt.RemainingSeconds = &remaining
+	return attempt
+}
+
+// autoSubmit closes an expired attempt as if it were submitted without
+// answers: every quiz question of the set counts towards the total.
+func autoSubmit(repo attemptRepo.AttemptRepository, attempt attemptEntity.Attempt) error {
+	key, err := repo.AnswerKey(attempt.SetID)
+	if err != nil {
+		return err
+	}
+
+	graded, err := grade(key, nil)
+	if err != nil {
+		return err
+	}
+
+	return repo.Submit(attempt.ID, graded)
+}
+
+// grade checks each submitted answer against the set's answer key. Every quiz
+// question in the set counts towards the total, answered or not. Essays are
+// stored ungraded and keep the attempt in grading until a teacher scores them.
+func grade(key map[int32]attemptEntity.QuestionKey, answers []attemptEntity.SubmitAnswer) (attemptEntity.GradedAttempt, error) {
+	graded := attemptEntity.GradedAttempt{Status: attemptEntity.StatusSubmitted, Total: len(key)}
+	seen := make(map[int32]bool, len(answers))
+
+	for _, answer := range answers {
+		question, ok := key[answer.QuestionID]
+		if !ok {
+			return graded, app.NewAppError(400, "question does not belong to this set")
+		}
+		if seen[answer.QuestionID] {
+			return graded, app.NewAppError(400, "question answered more than once")
+		}
+		seen[answer.QuestionID] = true
+
+		if question.Kind == questionEntity.KindEssay {
+			if answer.EssayText == "" || answer.AnswerID != 0 {
+				return graded, app.NewAppError(400, "essay question requires essay text")
+			}
+			essayText := answer.EssayText
+			graded.Status = attemptEntity.StatusGrading
+			graded.Answers = append(graded.Answers, attemptEntity.AttemptAnswer{
+				QuestionID: answer.QuestionID,
+				EssayText:  &essayText,
+			})
+			continue
+		}
+
+		result, err := check(question, answer)
+		if err != nil {
+			return graded, err
+		}
+		if result.IsCorrect {
+			graded.Correct++
+		}
+		graded.Answers = append(graded.Answers, result)
+	}
+
+	graded.Score = score(graded.Correct, 0, graded.Total)
+
+	return graded, nil
+}
+
+// check scores one automatically graded answer, all or nothing: a multiple
+// question needs exactly its correct options, a matching question every pair,
+// an ordering question the whole sequence and a fill_blank question an
+// accepted answer in every blank.
+func check(question attemptEntity.QuestionKey, answer attemptEntity.SubmitAnswer) (attemptEntity.AttemptAnswer, error) {
+	result := attemptEntity.AttemptAnswer{QuestionID: answer.QuestionID}
+
+	switch question.Kind {
+	case questionEntity.KindMultiple:
+		if len(answer.AnswerIDs) == 0 {
+			return result, app.NewAppError(400, "multiple question requires answer_ids")
+		}
+		selected := map[int32]bool{}
+		for _, id := range answer.AnswerIDs {
+			if _, ok := question.Options[id]; !ok {
+				return result, app.NewAppError(400, "answer does not belong to question")
+			}
+			selected[id] = true
+		}
+		result.IsCorrect = true
+		for id, isAnswer := range question.Options {
+			result.IsCorrect = result.IsCorrect && selected[id] == isAnswer
+		}
+		result.Response = &attemptEntity.Response{AnswerIDs: answer.AnswerIDs}
+
+	case questionEntity.KindMatching:
+		if len(answer.Matches) == 0 {
+			return result, app.NewAppError(400, "matching question requires matches")
+		}
+		for id := range answer.Matches {
+			if _, ok := question.Matches[id]; !ok {
+				return result, app.NewAppError(400, "answer does not belong to question")
+			}
+		}
+		result.IsCorrect = len(question.Matches) > 0
+		for id, matchText := range question.Matches {
+			result.IsCorrect = result.IsCorrect && normalize(answer.Matches[id]) == normalize(matchText)
+		}
+		result.Response = &attemptEntity.Response{Matches: answer.Matches}
+
+	case questionEntity.KindOrdering:
+		if len(answer.Order) == 0 {
+			return result, app.NewAppError(400, "ordering question requires order")
+		}
+		placed := map[int32]bool{}
+		for _, id := range answer.Order {
+			if _, ok := question.Positions[id]; !ok || placed[id] {
+				return result, app.NewAppError(400, "order must list each answer of the question once")
+			}
+			placed[id] = true
+		}
+		result.IsCorrect = len(answer.Order) == len(question.Positions)
+		for i := 1; result.IsCorrect && i < len(answer.Order); i++ {
+			result.IsCorrect = question.Positions[answer.Order[i-1]] < question.Positions[answer.Order[i]]
+		}
+		result.Response = &attemptEntity.Response{Order: answer.Order}
+
+	case questionEntity.KindFillBlank:
+		if len(answer.Blanks) == 0 {
+			return result, app.NewAppError(400, "fill_blank question requires blanks")
+		}
+		result.IsCorrect = len(question.Blanks) > 0
+		for position, accepted := range question.Blanks {
+			filled := ""
+			if position <= len(answer.Blanks) {
+				filled = normalize(answer.Blanks[position-1])
+			}
+			ok := false
+			for _, a := range accepted {
+				ok = ok || filled == normalize(a)
+			}
+			result.IsCorrect = result.IsCorrect && ok
+		}
+		result.Response = &attemptEntity.Response{Blanks: answer.Blanks}
+
+	default:
+		isCorrect, ok := question.Options[answer.AnswerID]
+		if !ok {
+			return result, app.NewAppError(400, "answer does not belong to question")
+		}
+		result.AnswerID = answer.AnswerID
+		result.IsCorrect = isCorrect
+	}
+
+	return result, nil
+}
+
+// normalize makes typed answers compare equal regardless of case and of
+// leading, trailing or repeated spaces.
+func normalize(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+// score turns earned points into a 0-100 percentage rounded to two decimals.
+func score(correct int, essayPoints float64, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	earned := float64(correct*attemptEntity.MaxPoints) + essayPoints
+	return math.Round(earned/float64(total*attemptEntity.MaxPoints)*10000) / 100
+}
@@ -0,0 +1,678 @@
+package svc_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	attemptEntity "github.com/ghulammuzz/misterblast/internal/attempt/entity"
+	"github.com/ghulammuzz/misterblast/internal/attempt/svc"
+	questionEntity "github.com/ghulammuzz/misterblast/internal/question/entity"
+	questionRepo "github.com/ghulammuzz/misterblast/internal/question/repo"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+)
+
+func school(id int32) *int32 {
+	return &id
+}
+
+type MockAttemptRepo struct {
+	mock.Mock
+}
+
+func (m *MockAttemptRepo) Start(userID, setID int32, deadline *int64) (attemptEntity.Attempt, error) {
+	args := m.Called(userID, setID, deadline)
+	return args.Get(0).(attemptEntity.Attempt), args.Error(1)
+}
+
+func (m *MockAttemptRepo) Detail(id int32) (attemptEntity.Attempt, error) {
+	args := m.Called(id)
+	return args.Get(0).(attemptEntity.Attempt), args.Error(1)
+}
+
+func (m *MockAttemptRepo) List(userID int32, filter map[string]string) ([]attemptEntity.ListAttempt, error) {
+	args := m.Called(userID, filter)
+	return args.Get(0).([]attemptEntity.ListAttempt), args.Error(1)
+}
+
+func (m *MockAttemptRepo) ListAnswers(attemptID int32) ([]attemptEntity.AttemptAnswer, error) {
+	args := m.Called(attemptID)
+	return args.Get(0).([]attemptEntity.AttemptAnswer), args.Error(1)
+}
+
+func (m *MockAttemptRepo) QuizWindow(setID int32) (attemptEntity.QuizWindow, error) {
+	args := m.Called(setID)
+	return args.Get(0).(attemptEntity.QuizWindow), args.Error(1)
+}
+
+func (m *MockAttemptRepo) AnswerKey(setID int32) (map[int32]attemptEntity.QuestionKey, error) {
+	args := m.Called(setID)
+	return args.Get(0).(map[int32]attemptEntity.QuestionKey), args.Error(1)
+}
+
+func (m *MockAttemptRepo) Submit(id int32, graded attemptEntity.GradedAttempt) error {
+	args := m.Called(id, graded)
+	return args.Error(0)
+}
+
+func (m *MockAttemptRepo) ListExpired(before int64, limit int) ([]attemptEntity.Attempt, error) {
+	args := m.Called(before, limit)
+	return args.Get(0).([]attemptEntity.Attempt), args.Error(1)
+}
+
+func (m *MockAttemptRepo) InSchool(schoolID *int32, id int32) error {
+	args := m.Called(schoolID, id)
+	return args.Error(0)
+}
+
+func (m *MockAttemptRepo) ListPendingEssays(schoolID *int32, filter map[string]string) ([]attemptEntity.PendingEssay, error) {
+	args := m.Called(schoolID, filter)
+	return args.Get(0).([]attemptEntity.PendingEssay), args.Error(1)
+}
+
+func (m *MockAttemptRepo) GradeEssay(attemptID, questionID, graderID int32, grade attemptEntity.GradeEssay) error {
+	args := m.Called(attemptID, questionID, graderID, grade)
+	return args.Error(0)
+}
+
+func (m *MockAttemptRepo) UpdateScore(id int32, status string, score float64) error {
+	args := m.Called(id, status, score)
+	return args.Error(0)
+}
+
+// MockQuestionRepo only stubs what the attempt service uses; calling any other
+// method panics on the nil embedded interface.
+type MockQuestionRepo struct {
+	mock.Mock
+	questionRepo.QuestionRepository
+}
+
+func (m *MockQuestionRepo) ListAnswerKey(schoolID *int32, setID int32) ([]questionEntity.ListQuestionKey, error) {
+	args := m.Called(schoolID, setID)
+	return args.Get(0).([]questionEntity.ListQuestionKey), args.Error(1)
+}
+
+func (m *MockQuestionRepo) ListQuizQuestions(schoolID *int32, filter map[string]string) ([]questionEntity.ListQuestionQuiz, error) {
+	args := m.Called(schoolID, filter)
+	return args.Get(0).([]questionEntity.ListQuestionQuiz), args.Error(1)
+}
+
+func TestStartAttemptService(t *testing.T) {
+	mockRepo := new(MockAttemptRepo)
+	service := svc.NewAttemptService(mockRepo, new(MockQuestionRepo))
+
+	mockRepo.On("QuizWindow", int32(2)).Return(attemptEntity.QuizWindow{}, nil)
+	mockRepo.On("Start", int32(1), int32(2), (*int64)(nil)).Return(attemptEntity.Attempt{ID: 9, UserID: 1, SetID: 2}, nil)
+
+	attempt, err := service.StartAttempt(1, attemptEntity.StartAttempt{SetID: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(9), attempt.ID)
+	assert.Nil(t, attempt.RemainingSeconds)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestStartAttemptService_TimeLimit(t *testing.T) {
+	mockRepo := new(MockAttemptRepo)
+	service := svc.NewAttemptService(mockRepo, new(MockQuestionRepo))
+
+	limit := 20
+	var deadline *int64
+	mockRepo.On("QuizWindow", int32(2)).Return(attemptEntity.QuizWindow{TimeLimit: &limit}, nil)
+	mockRepo.On("Start", int32(1), int32(2), mock.Anything).
+		Run(func(args mock.Arguments) { deadline = args.Get(2).(*int64) }).
+		Return(attemptEntity.Attempt{ID: 9, Status: attemptEntity.StatusInProgress}, nil)
+
+	before := time.Now().Unix()
+	_, err := service.StartAttempt(1, attemptEntity.StartAttempt{SetID: 2})
+	assert.NoError(t, err)
+	assert.NotNil(t, deadline)
+	assert.InDelta(t, before+20*60, *deadline, 1)
+}
+
+func TestStartAttemptService_Window(t *testing.T) {
+	now := time.Now().Unix()
+	later, earlier := now+3600, now-3600
+
+	for name, window := range map[string]attemptEntity.QuizWindow{
+		"not open yet": {OpensAt: &later},
+		"closed":       {OpensAt: &earlier, ClosesAt: &earlier},
+	} {
+		mockRepo := new(MockAttemptRepo)
+		service := svc.NewAttemptService(mockRepo, new(MockQuestionRepo))
+		mockRepo.On("QuizWindow", int32(2)).Return(window, nil)
+
+		_, err := service.StartAttempt(1, attemptEntity.StartAttempt{SetID: 2})
+		assert.Error(t, err, name)
+		assert.Equal(t, 403, err.(*app.AppError).Code, name)
+		mockRepo.AssertNotCalled(t, "Start", mock.Anything, mock.Anything, mock.Anything)
+	}
+}
+
+func TestStartAttemptService_SetNotFound(t *testing.T) {
+	mockRepo := new(MockAttemptRepo)
+	service := svc.NewAttemptService(mockRepo, new(MockQuestionRepo))
+
+	mockRepo.On("QuizWindow", int32(2)).Return(attemptEntity.QuizWindow{}, app.NewAppError(404, "set not found"))
+
+	_, err := service.StartAttempt(1, attemptEntity.StartAttempt{SetID: 2})
+	assert.Error(t, err)
+	mockRepo.AssertNotCalled(t, "Start", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSubmitAttemptService(t *testing.T) {
+	mockRepo := new(MockAttemptRepo)
+	service := svc.NewAttemptService(mockRepo, new(MockQuestionRepo))
+
+	attempt := attemptEntity.Attempt{ID: 5, UserID: 1, SetID: 2, Status: attemptEntity.StatusInProgress}
+	key := map[int32]attemptEntity.QuestionKey{
+		1: {Kind: questionEntity.KindSingle, Options: map[int32]bool{10: false, 11: true}},
+		2: {Kind: questionEntity.KindSingle, Options: map[int32]bool{20: true, 21: false}},
+		3: {Kind: questionEntity.KindSingle, Options: map[int32]bool{30: true}},
+		4: {Kind: questionEntity.KindSingle, Options: map[int32]bool{40: true}},
+	}
+	expected := attemptEntity.GradedAttempt{
+		Status: attemptEntity.StatusSubmitted,
+		Answers: []attemptEntity.AttemptAnswer{
+			{QuestionID: 1, AnswerID: 11, IsCorrect: true},
+			{QuestionID: 2, AnswerID: 21, IsCorrect: false},
+			{QuestionID: 3, AnswerID: 30, IsCorrect: true},
+		},
+		Correct: 2,
+		Total:   4,
+		Score:   50,
+	}
+
+	mockRepo.On("Detail", int32(5)).Return(attempt, nil)
+	mockRepo.On("AnswerKey", int32(2)).Return(key, nil)
+	mockRepo.On("Submit", int32(5), expected).Return(nil)
+	mockRepo.On("ListAnswers", int32(5)).Return(expected.Answers, nil)
+
+	result, err := service.SubmitAttempt(1, 5, attemptEntity.SubmitAttempt{Answers: []attemptEntity.SubmitAnswer{
+		{QuestionID: 1, AnswerID: 11},
+		{QuestionID: 2, AnswerID: 21},
+		{QuestionID: 3, AnswerID: 30},
+	}})
+	assert.NoError(t, err)
+	assert.Len(t, result.Answers, 3)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSubmitAttemptService_ForeignAnswer(t *testing.T) {
+	mockRepo := new(MockAttemptRepo)
+	service := svc.NewAttemptService(mockRepo, new(MockQuestionRepo))
+
+	attempt := attemptEntity.Attempt{ID: 5, UserID: 1, SetID: 2, Status: attemptEntity.StatusInProgress}
+	key := map[int32]attemptEntity.QuestionKey{
+		1: {Kind: questionEntity.KindSingle, Options: map[int32]bool{10: false, 11: true}},
+	}
+
+	mockRepo.On("Detail", int32(5)).Return(attempt, nil)
+	mockRepo.On("AnswerKey", int32(2)).Return(key, nil)
+
+	_, err := service.SubmitAttempt(1, 5, attemptEntity.SubmitAttempt{Answers: []attemptEntity.SubmitAnswer{
+		{QuestionID: 1, AnswerID: 99},
+	}})
+	assert.Error(t, err)
+	assert.Equal(t, "answer does not belong to question", err.Error())
+	mockRepo.AssertNotCalled(t, "Submit", mock.Anything, mock.Anything)
+}
+
+func TestSubmitAttemptService_QuestionKinds(t *testing.T) {
+	mockRepo := new(MockAttemptRepo)
+	service := svc.NewAttemptService(mockRepo, new(MockQuestionRepo))
+
+	attempt := attemptEntity.Attempt{ID: 5, UserID: 1, SetID: 2, Status: attemptEntity.StatusInProgress}
+	key := map[int32]attemptEntity.QuestionKey{
+		1: {Kind: questionEntity.KindMultiple, Options: map[int32]bool{10: true, 11: false, 12: true}},
+		2: {Kind: questionEntity.KindMatching, Options: map[int32]bool{20: false, 21: false},
+			Matches: map[int32]string{20: "Jakarta", 21: "Tokyo"}},
+		3: {Kind: questionEntity.KindOrdering, Options: map[int32]bool{30: false, 31: false, 32: false},
+			Positions: map[int32]int{30: 2, 31: 1, 32: 3}},
+		4: {Kind: questionEntity.KindFillBlank, Options: map[int32]bool{40: true, 41: true, 42: true},
+			Blanks: map[int][]string{1: {"ibu kota", "ibukota"}, 2: {"Jawa"}}},
+		5: {Kind: questionEntity.KindTrueFalse, Options: map[int32]bool{50: true, 51: false}},
+	}
+
+	var graded attemptEntity.GradedAttempt
+	mockRepo.On("Detail", int32(5)).Return(attempt, nil)
+	mockRepo.On("AnswerKey", int32(2)).Return(key, nil)
+	mockRepo.On("Submit", int32(5), mock.Anything).Run(func(args mock.Arguments) {
+		graded = args.Get(1).(attemptEntity.GradedAttempt)
+	}).Return(nil)
+	mockRepo.On("ListAnswers", int32(5)).Return([]attemptEntity.AttemptAnswer{}, nil)
+
+	_, err := service.SubmitAttempt(1, 5, attemptEntity.SubmitAttempt{Answers: []attemptEntity.SubmitAnswer{
+		{QuestionID: 1, AnswerIDs: []int32{12, 10}},
+		{QuestionID: 2, Matches: map[int32]string{20: "jakarta ", 21: "Tokyo"}},
+		{QuestionID: 3, Order: []int32{30, 31, 32}},
+		{QuestionID: 4, Blanks: []string{"Ibu  Kota", "jawa"}},
+		{QuestionID: 5, AnswerID: 51},
+	}})
+	assert.NoError(t, err)
+
+	correct := map[int32]bool{}
+	for _, answer := range graded.Answers {
+		correct[answer.QuestionID] = answer.IsCorrect
+	}
+	assert.Equal(t, map[int32]bool{1: true, 2: true, 3: false, 4: true, 5: false}, correct)
+	assert.Equal(t, 3, graded.Correct)
+	assert.Equal(t, float64(60), graded.Score)
+	assert.Equal(t, []int32{30, 31, 32}, graded.Answers[2].Response.Order)
+	assert.Nil(t, graded.Answers[4].Response)
+}
+
+func TestSubmitAttemptService_PartialMultiple(t *testing.T) {
+	mockRepo := new(MockAttemptRepo)
+	service := svc.NewAttemptService(mockRepo, new(MockQuestionRepo))
+
+	attempt := attemptEntity.Attempt{ID: 5, UserID: 1, SetID: 2, Status: attemptEntity.StatusInProgress}
+	key := map[int32]attemptEntity.QuestionKey{
+		1: {Kind: questionEntity.KindMultiple, Options: map[int32]bool{10: true, 11: false, 12: true}},
+	}
+
+	mockRepo.On("Detail", int32(5)).Return(attempt, nil)
+	mockRepo.On("AnswerKey", int32(2)).Return(key, nil)
+	mockRepo.On("Submit", int32(5), mock.MatchedBy(func(graded attemptEntity.GradedAttempt) bool {
+		return graded.Correct == 0 && !graded.Answers[0].IsCorrect
+	})).Return(nil)
+	mockRepo.On("ListAnswers", int32(5)).Return([]attemptEntity.AttemptAnswer{}, nil)
+
+	_, err := service.SubmitAttempt(1, 5, attemptEntity.SubmitAttempt{Answers: []attemptEntity.SubmitAnswer{
+		{QuestionID: 1, AnswerIDs: []int32{10}},
+	}})
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSubmitAttemptService_MissingResponse(t *testing.T) {
+	mockRepo := new(MockAttemptRepo)
+	service := svc.NewAttemptService(mockRepo, new(MockQuestionRepo))
+
+	attempt := attemptEntity.Attempt{ID: 5, UserID: 1, SetID: 2, Status: attemptEntity.StatusInProgress}
+	key := map[int32]attemptEntity.QuestionKey{
+		3: {Kind: questionEntity.KindOrdering, Options: map[int32]bool{30: false}, Positions: map[int32]int{30: 1}},
+	}
+
+	mockRepo.On("Detail", int32(5)).Return(attempt, nil)
+	mockRepo.On("AnswerKey", int32(2)).Return(key, nil)
+
+	_, err := service.SubmitAttempt(1, 5, attemptEntity.SubmitAttempt{Answers: []attemptEntity.SubmitAnswer{
+		{QuestionID: 3, AnswerID: 30},
+	}})
+	assert.Error(t, err)
+	assert.Equal(t, "ordering question requires order", err.Error())
+	mockRepo.AssertNotCalled(t, "Submit", mock.Anything, mock.Anything)
+}
+
+func TestSubmitAttemptService_Essay(t *testing.T) {
+	mockRepo := new(MockAttemptRepo)
+	service := svc.NewAttemptService(mockRepo, new(MockQuestionRepo))
+
+	attempt := attemptEntity.Attempt{ID: 5, UserID: 1, SetID: 2, Status: attemptEntity.StatusInProgress}
+	key := map[int32]attemptEntity.QuestionKey{
+		1: {Kind: questionEntity.KindSingle, Options: map[int32]bool{10: false, 11: true}},
+		2: {Kind: questionEntity.KindEssay, Options: map[int32]bool{}},
+	}
+	essay := "Photosynthesis turns light into chemical energy."
+
+	mockRepo.On("Detail", int32(5)).Return(attempt, nil)
+	mockRepo.On("AnswerKey", int32(2)).Return(key, nil)
+	mockRepo.On("Submit", int32(5), mock.MatchedBy(func(graded attemptEntity.GradedAttempt) bool {
+		return graded.Status == attemptEntity.StatusGrading && graded.Correct == 1 && graded.Score == 50 &&
+			len(graded.Answers) == 2 && *graded.Answers[1].EssayText == essay
+	})).Return(nil)
+	mockRepo.On("ListAnswers", int32(5)).Return([]attemptEntity.AttemptAnswer{}, nil)
+
+	_, err := service.SubmitAttempt(1, 5, attemptEntity.SubmitAttempt{Answers: []attemptEntity.SubmitAnswer{
+		{QuestionID: 1, AnswerID: 11},
+		{QuestionID: 2, EssayText: essay},
+	}})
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSubmitAttemptService_EssayWithoutText(t *testing.T) {
+	mockRepo := new(MockAttemptRepo)
+	service := svc.NewAttemptService(mockRepo, new(MockQuestionRepo))
+
+	attempt := attemptEntity.Attempt{ID: 5, UserID: 1, SetID: 2, Status: attemptEntity.StatusInProgress}
+	key := map[int32]attemptEntity.QuestionKey{
+		2: {Kind: questionEntity.KindEssay, Options: map[int32]bool{}},
+	}
+
+	mockRepo.On("Detail", int32(5)).Return(attempt, nil)
+	mockRepo.On("AnswerKey", int32(2)).Return(key, nil)
+
+	_, err := service.SubmitAttempt(1, 5, attemptEntity.SubmitAttempt{Answers: []attemptEntity.SubmitAnswer{
+		{QuestionID: 2, AnswerID: 7},
+	}})
+	assert.Error(t, err)
+	assert.Equal(t, "essay question requires essay text", err.Error())
+	mockRepo.AssertNotCalled(t, "Submit", mock.Anything, mock.Anything)
+}
+
+func TestSubmitAttemptService_NotOwner(t *testing.T) {
+	mockRepo := new(MockAttemptRepo)
+	service := svc.NewAttemptService(mockRepo, new(MockQuestionRepo))
+
+	mockRepo.On("Detail", int32(5)).Return(attemptEntity.Attempt{ID: 5, UserID: 3, SetID: 2, Status: attemptEntity.StatusInProgress}, nil)
+
+	_, err := service.SubmitAttempt(1, 5, attemptEntity.SubmitAttempt{})
+	assert.Error(t, err)
+	assert.Equal(t, "attempt not found", err.Error())
+}
+
+func TestSubmitAttemptService_AlreadySubmitted(t *testing.T) {
+	mockRepo := new(MockAttemptRepo)
+	service := svc.NewAttemptService(mockRepo, new(MockQuestionRepo))
+
+	mockRepo.On("Detail", int32(5)).Return(attemptEntity.Attempt{ID: 5, UserID: 1, SetID: 2, Status: attemptEntity.StatusSubmitted}, nil)
+
+	_, err := service.SubmitAttempt(1, 5, attemptEntity.SubmitAttempt{})
+	assert.Error(t, err)
+	assert.Equal(t, "attempt already submitted", err.Error())
+}
+
+func TestReviewAttemptService(t *testing.T) {
+	mockRepo := new(MockAttemptRepo)
+	mockQuestionRepo := new(MockQuestionRepo)
+	service := svc.NewAttemptService(mockRepo, mockQuestionRepo)
+
+	mockRepo.On("Detail", int32(5)).Return(attemptEntity.Attempt{ID: 5, UserID: 1, SetID: 2, Status: attemptEntity.StatusSubmitted}, nil)
+	mockRepo.On("ListAnswers", int32(5)).Return([]attemptEntity.AttemptAnswer{
+		{QuestionID: 1, AnswerID: 10, IsCorrect: false},
+	}, nil)
+	mockQuestionRepo.On("ListAnswerKey", (*int32)(nil), int32(2)).Return([]questionEntity.ListQuestionKey{
+		{ID: 1, Number: 1, Answers: []questionEntity.Answer{
+			{ID: 10, QuestionID: 1, Code: "a", IsAnswer: false},
+			{ID: 11, QuestionID: 1, Code: "b", IsAnswer: true},
+		}},
+		{ID: 2, Number: 2, Answers: []questionEntity.Answer{
+			{ID: 20, QuestionID: 2, Code: "a", IsAnswer: true},
+		}},
+	}, nil)
+
+	review, err := service.ReviewAttempt(1, 5)
+	assert.NoError(t, err)
+	assert.Len(t, review.Questions, 2)
+
+	answered := review.Questions[0]
+	assert.Equal(t, int32(10), *answered.SelectedAnswerID)
+	assert.False(t, *answered.IsCorrect)
+	assert.Len(t, answered.CorrectAnswers, 1)
+	assert.Equal(t, int32(11), answered.CorrectAnswers[0].ID)
+
+	unanswered := review.Questions[1]
+	assert.Nil(t, unanswered.SelectedAnswerID)
+	assert.Empty(t, unanswered.CorrectAnswers)
+	assert.Len(t, unanswered.Answers, 1)
+}
+
+func TestReviewAttemptService_UnansweredKeysHidden(t *testing.T) {
+	mockRepo := new(MockAttemptRepo)
+	mockQuestionRepo := new(MockQuestionRepo)
+	service := svc.NewAttemptService(mockRepo, mockQuestionRepo)
+
+	one, two, three := 1, 2, 3
+	mockRepo.On("Detail", int32(5)).Return(attemptEntity.Attempt{ID: 5, UserID: 1, SetID: 2, Status: attemptEntity.StatusSubmitted}, nil)
+	mockRepo.On("ListAnswers", int32(5)).Return([]attemptEntity.AttemptAnswer{}, nil)
+	mockQuestionRepo.On("ListAnswerKey", (*int32)(nil), int32(2)).Return([]questionEntity.ListQuestionKey{
+		{ID: 1, Number: 1, Kind: questionEntity.KindEssay, Answers: []questionEntity.Answer{
+			{ID: 10, QuestionID: 1, Code: "a", Content: "Model answer"},
+		}},
+		{ID: 2, Number: 2, Kind: questionEntity.KindOrdering, Answers: []questionEntity.Answer{
+			{ID: 20, QuestionID: 2, Code: "a", Content: "Pagi", Position: &one},
+			{ID: 21, QuestionID: 2, Code: "b", Content: "Siang", Position: &two},
+			{ID: 22, QuestionID: 2, Code: "c", Content: "Malam", Position: &three},
+		}},
+	}, nil)
+
+	review, err := service.ReviewAttempt(1, 5)
+	assert.NoError(t, err)
+
+	essay := review.Questions[0]
+	assert.Empty(t, essay.Answers)
+	assert.Empty(t, essay.CorrectAnswers)
+
+	ordering := review.Questions[1]
+	assert.Empty(t, ordering.CorrectAnswers)
+	var contents []string
+	for _, a := range ordering.Answers {
+		contents = append(contents, a.Content)
+	}
+	assert.Equal(t, []string{"Malam", "Pagi", "Siang"}, contents)
+}
+
+func TestAttemptQuestionsService(t *testing.T) {
+	quiz := func() []questionEntity.ListQuestionQuiz {
+		var questions []questionEntity.ListQuestionQuiz
+		for i := int32(1); i <= 6; i++ {
+			questions = append(questions, questionEntity.ListQuestionQuiz{ID: i, Number: int(i), Kind: questionEntity.KindSingle,
+				Answers: []questionEntity.ListAnswer{{ID: i * 10, Code: "a"}, {ID: i*10 + 1, Code: "b"}, {ID: i*10 + 2, Code: "c"}, {ID: i*10 + 3, Code: "d"}}})
+		}
+		return questions
+	}
+
+	deliver := func(attempt attemptEntity.Attempt) []questionEntity.ListQuestionQuiz {
+		mockRepo := new(MockAttemptRepo)
+		mockQuestionRepo := new(MockQuestionRepo)
+		service := svc.NewAttemptService(mockRepo, mockQuestionRepo)
+
+		mockRepo.On("Detail", attempt.ID).Return(attempt, nil)
+		mockQuestionRepo.On("ListQuizQuestions", (*int32)(nil), map[string]string{"set_id": "2"}).Return(quiz(), nil)
+
+		quiz, err := service.AttemptQuestions(1, attempt.ID)
+		assert.NoError(t, err)
+		return quiz.Questions
+	}
+
+	plain := deliver(attemptEntity.Attempt{ID: 5, UserID: 1, SetID: 2, Seed: 42})
+	assert.Equal(t, quiz(), plain)
+
+	shuffled := attemptEntity.Attempt{ID: 5, UserID: 1, SetID: 2, Seed: 42, ShuffleQuestions: true, ShuffleOptions: true}
+	first := deliver(shuffled)
+	assert.Equal(t, first, deliver(shuffled), "the same attempt must always get the same order")
+	assert.NotEqual(t, quiz(), first)
+
+	ids := map[int32]bool{}
+	for _, q := range first {
+		for j, a := range q.Answers {
+			assert.Equal(t, string(rune('a'+j)), a.Code)
+			assert.Equal(t, q.ID, a.ID/10, "options stay with their question")
+			ids[a.ID] = true
+		}
+	}
+	assert.Len(t, ids, 24)
+
+	other := deliver(attemptEntity.Attempt{ID: 5, UserID: 1, SetID: 2, Seed: 7, ShuffleQuestions: true, ShuffleOptions: true})
+	assert.NotEqual(t, first, other)
+}
+
+func TestAttemptQuestionsService_RemainingTime(t *testing.T) {
+	mockRepo := new(MockAttemptRepo)
+	mockQuestionRepo := new(MockQuestionRepo)
+	service := svc.NewAttemptService(mockRepo, mockQuestionRepo)
+
+	deadline := time.Now().Unix() + 600
+	attempt := attemptEntity.Attempt{ID: 5, UserID: 1, SetID: 2, Status: attemptEntity.StatusInProgress, Deadline: &deadline}
+	mockRepo.On("Detail", int32(5)).Return(attempt, nil)
+	mockQuestionRepo.On("ListQuizQuestions", (*int32)(nil), map[string]string{"set_id": "2"}).Return([]questionEntity.ListQuestionQuiz{{ID: 1}}, nil)
+
+	quiz, err := service.AttemptQuestions(1, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, &deadline, quiz.Deadline)
+	assert.InDelta(t, 600, *quiz.RemainingSeconds, 1)
+	assert.Equal(t, deadline-*quiz.RemainingSeconds, quiz.ServerTime)
+}
+
+func TestAttemptQuestionsService_Expired(t *testing.T) {
+	mockRepo := new(MockAttemptRepo)
+	mockQuestionRepo := new(MockQuestionRepo)
+	service := svc.NewAttemptService(mockRepo, mockQuestionRepo)
+
+	deadline := time.Now().Unix() - 600
+	attempt := attemptEntity.Attempt{ID: 5, UserID: 1, SetID: 2, Status: attemptEntity.StatusInProgress, Deadline: &deadline}
+	mockRepo.On("Detail", int32(5)).Return(attempt, nil)
+
+	_, err := service.AttemptQuestions(1, 5)
+	assert.Error(t, err)
+	assert.Equal(t, 409, err.(*app.AppError).Code)
+	mockQuestionRepo.AssertNotCalled(t, "ListQuizQuestions", mock.Anything)
+}
+
+func TestSubmitAttemptService_PastDeadline(t *testing.T) {
+	mockRepo := new(MockAttemptRepo)
+	service := svc.NewAttemptService(mockRepo, new(MockQuestionRepo))
+
+	deadline := time.Now().Unix() - attemptEntity.DeadlineGrace - 5
+	attempt := attemptEntity.Attempt{ID: 5, UserID: 1, SetID: 2, Status: attemptEntity.StatusInProgress, Deadline: &deadline}
+	key := map[int32]attemptEntity.QuestionKey{
+		1: {Kind: questionEntity.KindSingle, Options: map[int32]bool{10: true}},
+		2: {Kind: questionEntity.KindSingle, Options: map[int32]bool{20: true}},
+	}
+	expected := attemptEntity.GradedAttempt{Status: attemptEntity.StatusSubmitted, Total: 2}
+
+	mockRepo.On("Detail", int32(5)).Return(attempt, nil)
+	mockRepo.On("AnswerKey", int32(2)).Return(key, nil)
+	mockRepo.On("Submit", int32(5), expected).Return(nil)
+
+	submit := attemptEntity.SubmitAttempt{Answers: []attemptEntity.SubmitAnswer{{QuestionID: 1, AnswerID: 10}}}
+	_, err := service.SubmitAttempt(1, 5, submit)
+	assert.Error(t, err)
+	assert.Equal(t, 409, err.(*app.AppError).Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSubmitAttemptService_WithinGrace(t *testing.T) {
+	mockRepo := new(MockAttemptRepo)
+	service := svc.NewAttemptService(mockRepo, new(MockQuestionRepo))
+
+	deadline := time.Now().Unix() - 5
+	attempt := attemptEntity.Attempt{ID: 5, UserID: 1, SetID: 2, Status: attemptEntity.StatusInProgress, Deadline: &deadline}
+	key := map[int32]attemptEntity.QuestionKey{1: {Kind: questionEntity.KindSingle, Options: map[int32]bool{10: true}}}
+	expected := attemptEntity.GradedAttempt{
+		Status:  attemptEntity.StatusSubmitted,
+		Answers: []attemptEntity.AttemptAnswer{{QuestionID: 1, AnswerID: 10, IsCorrect: true}},
+		Correct: 1,
+		Total:   1,
+		Score:   100,
+	}
+
+	mockRepo.On("Detail", int32(5)).Return(attempt, nil)
+	mockRepo.On("AnswerKey", int32(2)).Return(key, nil)
+	mockRepo.On("Submit", int32(5), expected).Return(nil)
+	mockRepo.On("ListAnswers", int32(5)).Return([]attemptEntity.AttemptAnswer{}, nil)
+
+	submit := attemptEntity.SubmitAttempt{Answers: []attemptEntity.SubmitAnswer{{QuestionID: 1, AnswerID: 10}}}
+	_, err := service.SubmitAttempt(1, 5, submit)
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDeadlineSweeper(t *testing.T) {
+	mockRepo := new(MockAttemptRepo)
+	sweeper := svc.NewDeadlineSweeper(mockRepo)
+
+	deadline := time.Now().Unix() - 600
+	expired := []attemptEntity.Attempt{
+		{ID: 5, SetID: 2, Status: attemptEntity.StatusInProgress, Deadline: &deadline},
+		{ID: 6, SetID: 2, Status: attemptEntity.StatusInProgress, Deadline: &deadline},
+	}
+	key := map[int32]attemptEntity.QuestionKey{1: {Kind: questionEntity.KindEssay}}
+	expected := attemptEntity.GradedAttempt{Status: attemptEntity.StatusSubmitted, Total: 1}
+
+	mockRepo.On("ListExpired", mock.AnythingOfType("int64"), 50).Return(expired, nil)
+	mockRepo.On("AnswerKey", int32(2)).Return(key, nil)
+	mockRepo.On("Submit", int32(5), expected).Return(nil)
+	mockRepo.On("Submit", int32(6), expected).Return(app.NewAppError(409, "attempt already submitted"))
+
+	assert.Equal(t, 2, sweeper.ProcessBatch())
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDeadlineSweeper_Failures(t *testing.T) {
+	mockRepo := new(MockAttemptRepo)
+	sweeper := svc.NewDeadlineSweeper(mockRepo)
+
+	deadline := time.Now().Unix() - 600
+	expired := make([]attemptEntity.Attempt, 50)
+	for i := range expired {
+		expired[i] = attemptEntity.Attempt{ID: int32(i + 1), SetID: 2, Status: attemptEntity.StatusInProgress, Deadline: &deadline}
+	}
+
+	mockRepo.On("ListExpired", mock.AnythingOfType("int64"), 50).Return(expired, nil)
+	mockRepo.On("AnswerKey", int32(2)).Return(map[int32]attemptEntity.QuestionKey(nil), fmt.Errorf("connection refused"))
+
+	// A full batch that made no progress must not be retried right away.
+	assert.Equal(t, 0, sweeper.ProcessBatch())
+	mockRepo.AssertNotCalled(t, "Submit", mock.Anything, mock.Anything)
+}
+
+func TestReviewAttemptService_InProgress(t *testing.T) {
+	mockRepo := new(MockAttemptRepo)
+	service := svc.NewAttemptService(mockRepo, new(MockQuestionRepo))
+
+	mockRepo.On("Detail", int32(5)).Return(attemptEntity.Attempt{ID: 5, UserID: 1, SetID: 2, Status: attemptEntity.StatusInProgress}, nil)
+
+	_, err := service.ReviewAttempt(1, 5)
+	assert.Error(t, err)
+	assert.Equal(t, "attempt not yet submitted", err.Error())
+}
+
+func TestGradeEssayService(t *testing.T) {
+	mockRepo := new(MockAttemptRepo)
+	service := svc.NewAttemptService(mockRepo, new(MockQuestionRepo))
+
+	points := 80.0
+	essay := "answer"
+	grade := attemptEntity.GradeEssay{Points: &points, Feedback: "good"}
+
+	mockRepo.On("InSchool", school(1), int32(5)).Return(nil)
+	mockRepo.On("Detail", int32(5)).Return(attemptEntity.Attempt{ID: 5, UserID: 1, SetID: 2, Status: attemptEntity.StatusGrading, Correct: 1, Total: 2}, nil)
+	mockRepo.On("GradeEssay", int32(5), int32(2), int32(9), grade).Return(nil)
+	mockRepo.On("ListAnswers", int32(5)).Return([]attemptEntity.AttemptAnswer{
+		{QuestionID: 1, AnswerID: 11, IsCorrect: true},
+		{QuestionID: 2, EssayText: &essay, Points: &points},
+	}, nil)
+	mockRepo.On("UpdateScore", int32(5), attemptEntity.StatusSubmitted, 90.0).Return(nil)
+
+	err := service.GradeEssay(9, school(1), 5, 2, grade)
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGradeEssayService_OtherSchool(t *testing.T) {
+	mockRepo := new(MockAttemptRepo)
+	service := svc.NewAttemptService(mockRepo, new(MockQuestionRepo))
+
+	points := 80.0
+	mockRepo.On("InSchool", school(1), int32(5)).Return(app.NewAppError(404, "attempt not found"))
+
+	err := service.GradeEssay(9, school(1), 5, 2, attemptEntity.GradeEssay{Points: &points})
+	assert.Equal(t, 404, err.(*app.AppError).Code)
+	mockRepo.AssertNotCalled(t, "GradeEssay", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGradeEssayService_PendingEssaysLeft(t *testing.T) {
+	mockRepo := new(MockAttemptRepo)
+	service := svc.NewAttemptService(mockRepo, new(MockQuestionRepo))
+
+	points := 80.0
+	essay := "answer"
+	grade := attemptEntity.GradeEssay{Points: &points}
+
+	mockRepo.On("InSchool", school(1), int32(5)).Return(nil)
+	mockRepo.On("Detail", int32(5)).Return(attemptEntity.Attempt{ID: 5, UserID: 1, SetID: 2, Status: attemptEntity.StatusGrading, Total: 2}, nil)
+	mockRepo.On("GradeEssay", int32(5), int32(1), int32(9), grade).Return(nil)
+	mockRepo.On("ListAnswers", int32(5)).Return([]attemptEntity.AttemptAnswer{
+		{QuestionID: 1, EssayText: &essay, Points: &points},
+		{QuestionID: 2, EssayText: &essay},
+	}, nil)
+
+	err := service.GradeEssay(9, school(1), 5, 1, grade)
+	assert.NoError(t, err)
+	mockRepo.AssertNotCalled(t, "UpdateScore", mock.Anything, mock.Anything, mock.Anything)
+}
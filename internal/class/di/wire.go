@@ -6,14 +6,15 @@ import (
 	classHandler "github.com/ghulammuzz/misterblast/internal/class/handler"
 	classRepo "github.com/ghulammuzz/misterblast/internal/class/repo"
 	classSvc "github.com/ghulammuzz/misterblast/internal/class/svc"
+	"github.com/ghulammuzz/misterblast/pkg/cache"
 	"github.com/google/wire"
 )
 
-func InitializedClassServiceFake(sb *sql.DB) *classHandler.ClassHandler {
+func InitializedClassServiceFake(sb *sql.DB, c cache.Cache) *classHandler.ClassHandler {
 	wire.Build(
 		classHandler.NewClassHandler,
 		classSvc.NewClassService,
-		classRepo.NewClassRepository,
+		classRepo.NewCachedClassRepository,
 	)
 
 	return &classHandler.ClassHandler{}
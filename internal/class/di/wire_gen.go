@@ -11,12 +11,13 @@ import (
 	"github.com/ghulammuzz/misterblast/internal/class/handler"
 	"github.com/ghulammuzz/misterblast/internal/class/repo"
 	"github.com/ghulammuzz/misterblast/internal/class/svc"
+	"github.com/ghulammuzz/misterblast/pkg/cache"
 )
 
 // Injectors from wire.go:
 
-func InitializedClassService(sb *sql.DB) *handler.ClassHandler {
-	classRepository := repo.NewClassRepository(sb)
+func InitializedClassService(sb *sql.DB, c cache.Cache) *handler.ClassHandler {
+	classRepository := repo.NewCachedClassRepository(sb, c)
 	classService := svc.NewClassService(classRepository)
 	classHandler := handler.NewClassHandler(classService)
 	return classHandler
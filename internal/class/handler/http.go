@@ -4,6 +4,7 @@ import (
 	classEntity "github.com/ghulammuzz/misterblast/internal/class/entity"
 	classSvc "github.com/ghulammuzz/misterblast/internal/class/svc"
 	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/middleware"
 	"github.com/ghulammuzz/misterblast/pkg/response"
 	"github.com/gofiber/fiber/v2"
 )
@@ -17,8 +18,11 @@ func NewClassHandler(classService classSvc.ClassService) *ClassHandler {
 }
 
 func (h *ClassHandler) Router(r fiber.Router) {
-	r.Post("/class", h.AddClassHandler)
-	r.Delete("/class/:id", h.DeleteClassHandler)
+	auth := middleware.JWTProtected()
+	admin := middleware.RequireRole(middleware.AdminRoles...)
+
+	r.Post("/class", auth, admin, h.AddClassHandler)
+	r.Delete("/class/:id", auth, admin, h.DeleteClassHandler)
 	r.Get("/class", h.ListClassesHandler)
 }
 
@@ -0,0 +1,53 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+
+	classEntity "github.com/ghulammuzz/misterblast/internal/class/entity"
+	"github.com/ghulammuzz/misterblast/pkg/cache"
+)
+
+type cachedClassRepository struct {
+	repo    ClassRepository
+	classes *cache.Namespace
+	// sets and questions are listed with their class name.
+	dependents []*cache.Namespace
+}
+
+// NewCachedClassRepository caches class reads in c and drops them, along with
+// the set and question reads that embed class data, on every write.
+func NewCachedClassRepository(db *sql.DB, c cache.Cache) ClassRepository {
+	return &cachedClassRepository{
+		repo:    NewClassRepository(db),
+		classes: cache.NewNamespace(c, cache.NamespaceClass, cache.DefaultTTL),
+		dependents: []*cache.Namespace{
+			cache.NewNamespace(c, cache.NamespaceSet, cache.DefaultTTL),
+			cache.NewNamespace(c, cache.NamespaceQuestion, cache.DefaultTTL),
+		},
+	}
+}
+
+func (r *cachedClassRepository) Add(class classEntity.SetClass) error {
+	if err := r.repo.Add(class); err != nil {
+		return err
+	}
+	r.invalidate()
+	return nil
+}
+
+func (r *cachedClassRepository) Delete(id int32) error {
+	if err := r.repo.Delete(id); err != nil {
+		return err
+	}
+	r.invalidate()
+	return nil
+}
+
+func (r *cachedClassRepository) List() ([]classEntity.Class, error) {
+	return cache.Remember(context.Background(), r.classes, "list", r.repo.List)
+}
+
+func (r *cachedClassRepository) invalidate() {
+	cache.Invalidate(context.Background(), append([]*cache.Namespace{r.classes}, r.dependents...)...)
+}
@@ -0,0 +1,42 @@
+package repo_test
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ghulammuzz/misterblast/internal/class/entity"
+	"github.com/ghulammuzz/misterblast/internal/class/repo"
+	"github.com/ghulammuzz/misterblast/pkg/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachedClassRepository(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	repository := repo.NewCachedClassRepository(mockDB, cache.NewLRU(100))
+
+	mock.ExpectQuery("SELECT id, name FROM classes").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "1"))
+
+	// The second list is served from the cache.
+	for i := 0; i < 2; i++ {
+		classes, err := repository.List()
+		assert.NoError(t, err)
+		assert.Len(t, classes, 1)
+	}
+
+	mock.ExpectExec("INSERT INTO classes").
+		WithArgs("2").
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectQuery("SELECT id, name FROM classes").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "1").AddRow(2, "2"))
+
+	assert.NoError(t, repository.Add(entity.SetClass{Name: "2"}))
+
+	classes, err := repository.List()
+	assert.NoError(t, err)
+	assert.Len(t, classes, 2)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
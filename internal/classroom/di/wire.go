@@ -0,0 +1,21 @@
+package di
+
+import (
+	"database/sql"
+
+	classroomHandler "github.com/ghulammuzz/misterblast/internal/classroom/handler"
+	classroomRepo "github.com/ghulammuzz/misterblast/internal/classroom/repo"
+	classroomSvc "github.com/ghulammuzz/misterblast/internal/classroom/svc"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/wire"
+)
+
+func InitializedClassroomServiceFake(sb *sql.DB, val *validator.Validate) *classroomHandler.ClassroomHandler {
+	wire.Build(
+		classroomHandler.NewClassroomHandler,
+		classroomSvc.NewClassroomService,
+		classroomRepo.NewClassroomRepository,
+	)
+
+	return &classroomHandler.ClassroomHandler{}
+}
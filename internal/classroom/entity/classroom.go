@@ -0,0 +1,49 @@
+package entity
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// InviteCodeAlphabet leaves out characters easily mistaken for one another.
+const (
+	InviteCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	InviteCodeLength   = 8
+)
+
+// MaxRosterRows caps how many students one roster upload may enroll.
+const MaxRosterRows = 500
+
+// Classroom is a teacher's group of students at a grade from classes.
+// InviteCode lets students join and is only shown to the teacher.
+type Classroom struct {
+	ID           int32  `json:"id"`
+	Name         string `json:"name"`
+	ClassID      int32  `json:"class_id"`
+	Class        string `json:"class"`
+	School       string `json:"school"`
+	AcademicYear string `json:"academic_year"`
+	TeacherID    int32  `json:"teacher_id"`
+	InviteCode   string `json:"invite_code,omitempty"`
+	Members      int    `json:"members"`
+	CreatedAt    int64  `json:"created_at"`
+}
+
+type Member struct {
+	UserID   int32  `json:"user_id"`
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	JoinedAt int64  `json:"joined_at"`
+}
+
+// CheckAcademicYear accepts two consecutive years such as "2026/2027".
+func CheckAcademicYear(year string) error {
+	if len(year) == 9 && year[4] == '/' {
+		first, err1 := strconv.Atoi(year[:4])
+		second, err2 := strconv.Atoi(year[5:])
+		if err1 == nil && err2 == nil && second == first+1 {
+			return nil
+		}
+	}
+	return fmt.Errorf("academic_year must look like 2026/2027")
+}
@@ -0,0 +1,20 @@
+package entity
+
+type SetClassroom struct {
+	Name         string `json:"name" validate:"required,min=1,max=50"`
+	ClassID      int32  `json:"class_id" validate:"required"`
+	School       string `json:"school" validate:"required,min=2,max=100"`
+	AcademicYear string `json:"academic_year" validate:"required,len=9"`
+}
+
+type JoinClassroom struct {
+	Code string `json:"code" validate:"required,len=8,alphanum"`
+}
+
+// RosterResult reports a roster upload: students newly enrolled, those who
+// already were, and emails that match no student account.
+type RosterResult struct {
+	Enrolled        int      `json:"enrolled"`
+	AlreadyEnrolled int      `json:"already_enrolled"`
+	NotFound        []string `json:"not_found"`
+}
@@ -0,0 +1,30 @@
+package entity_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ghulammuzz/misterblast/internal/classroom/entity"
+)
+
+func TestCheckAcademicYear(t *testing.T) {
+	tests := []struct {
+		year  string
+		valid bool
+	}{
+		{"2026/2027", true},
+		{"2026/2028", false},
+		{"2027/2026", false},
+		{"2026-2027", false},
+		{"26/27", false},
+		{"abcd/efgh", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.year, func(t *testing.T) {
+			err := entity.CheckAcademicYear(tc.year)
+			assert.Equal(t, tc.valid, err == nil)
+		})
+	}
+}
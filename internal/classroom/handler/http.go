@@ -0,0 +1,254 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/ghulammuzz/misterblast/internal/classroom/entity"
+	"github.com/ghulammuzz/misterblast/internal/classroom/svc"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/log"
+	"github.com/ghulammuzz/misterblast/pkg/middleware"
+	"github.com/ghulammuzz/misterblast/pkg/response"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ClassroomHandler struct {
+	classroomService svc.ClassroomService
+	val              *validator.Validate
+}
+
+func NewClassroomHandler(classroomService svc.ClassroomService, val *validator.Validate) *ClassroomHandler {
+	return &ClassroomHandler{classroomService, val}
+}
+
+func (h *ClassroomHandler) Router(r fiber.Router) {
+	auth := middleware.JWTProtected()
+	staff := middleware.RequireRole(middleware.StaffRoles...)
+	student := middleware.RequireRole(middleware.RoleStudent)
+	joinLimit := middleware.RateLimit(middleware.RateLimitConfig{Name: "classroom-join", Limit: 10, Window: time.Minute})
+
+	r.Post("/classroom", auth, staff, h.AddClassroomHandler)
+	r.Get("/classroom", auth, staff, h.ListClassroomsHandler)
+	r.Post("/classroom/join", auth, student, joinLimit, h.JoinClassroomHandler)
+	r.Delete("/classroom/:id", auth, staff, h.DeleteClassroomHandler)
+	r.Post("/classroom/:id/invite-code", auth, staff, h.ResetInviteCodeHandler)
+	r.Get("/classroom/:id/members", auth, staff, h.ListMembersHandler)
+	r.Delete("/classroom/:id/members/:user_id", auth, staff, h.RemoveMemberHandler)
+	r.Post("/classroom/:id/roster", auth, staff, h.UploadRosterHandler)
+
+	r.Get("/me/classrooms", auth, student, h.MyClassroomsHandler)
+	r.Delete("/me/classrooms/:id", auth, student, h.LeaveClassroomHandler)
+}
+
+func (h *ClassroomHandler) AddClassroomHandler(c *fiber.Ctx) error {
+	teacherID, ok := middleware.UserID(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	var classroom entity.SetClassroom
+	if err := c.BodyParser(&classroom); err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid request body", nil)
+	}
+
+	if err := h.val.Struct(classroom); err != nil {
+		validationErrors := app.ValidationErrorResponse(err)
+		log.Error("Validation failed: %v", validationErrors)
+		return response.SendError(c, fiber.StatusBadRequest, "Validation failed", validationErrors)
+	}
+
+	created, err := h.classroomService.AddClassroom(teacherID, classroom)
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "classroom created successfully", created)
+}
+
+func (h *ClassroomHandler) ListClassroomsHandler(c *fiber.Ctx) error {
+	teacherID, ok := middleware.UserID(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	classrooms, err := h.classroomService.ListClassrooms(teacherID)
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "classrooms retrieved successfully", classrooms)
+}
+
+func (h *ClassroomHandler) DeleteClassroomHandler(c *fiber.Ctx) error {
+	teacherID, ok := middleware.UserID(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid classroom ID", nil)
+	}
+
+	if err := h.classroomService.DeleteClassroom(teacherID, int32(id)); err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "classroom deleted successfully", nil)
+}
+
+// ResetInviteCodeHandler replaces the invite code of a classroom.
+func (h *ClassroomHandler) ResetInviteCodeHandler(c *fiber.Ctx) error {
+	teacherID, ok := middleware.UserID(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid classroom ID", nil)
+	}
+
+	classroom, err := h.classroomService.ResetInviteCode(teacherID, int32(id))
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "invite code reset successfully", classroom)
+}
+
+func (h *ClassroomHandler) ListMembersHandler(c *fiber.Ctx) error {
+	teacherID, ok := middleware.UserID(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid classroom ID", nil)
+	}
+
+	members, err := h.classroomService.ListMembers(teacherID, int32(id))
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "members retrieved successfully", members)
+}
+
+func (h *ClassroomHandler) RemoveMemberHandler(c *fiber.Ctx) error {
+	teacherID, ok := middleware.UserID(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid classroom ID", nil)
+	}
+
+	userID, err := c.ParamsInt("user_id")
+	if err != nil || userID <= 0 {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid user ID", nil)
+	}
+
+	if err := h.classroomService.RemoveMember(teacherID, int32(id), int32(userID)); err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "member removed successfully", nil)
+}
+
+// JoinClassroomHandler enrolls the calling student with an invite code.
+func (h *ClassroomHandler) JoinClassroomHandler(c *fiber.Ctx) error {
+	studentID, ok := middleware.UserID(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	var join entity.JoinClassroom
+	if err := c.BodyParser(&join); err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid request body", nil)
+	}
+
+	if err := h.val.Struct(join); err != nil {
+		validationErrors := app.ValidationErrorResponse(err)
+		return response.SendError(c, fiber.StatusBadRequest, "Validation failed", validationErrors)
+	}
+
+	classroom, err := h.classroomService.JoinClassroom(studentID, middleware.SchoolID(c), join)
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "classroom joined successfully", classroom)
+}
+
+func (h *ClassroomHandler) MyClassroomsHandler(c *fiber.Ctx) error {
+	studentID, ok := middleware.UserID(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	classrooms, err := h.classroomService.MyClassrooms(studentID)
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "classrooms retrieved successfully", classrooms)
+}
+
+func (h *ClassroomHandler) LeaveClassroomHandler(c *fiber.Ctx) error {
+	studentID, ok := middleware.UserID(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid classroom ID", nil)
+	}
+
+	if err := h.classroomService.LeaveClassroom(studentID, int32(id)); err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "classroom left successfully", nil)
+}
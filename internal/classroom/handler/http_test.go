@@ -0,0 +1,211 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/ghulammuzz/misterblast/internal/classroom/entity"
+	"github.com/ghulammuzz/misterblast/internal/classroom/handler"
+	"github.com/ghulammuzz/misterblast/pkg/middleware"
+)
+
+type MockClassroomService struct {
+	mock.Mock
+}
+
+func (m *MockClassroomService) AddClassroom(teacherID int32, classroom entity.SetClassroom) (entity.Classroom, error) {
+	args := m.Called(teacherID, classroom)
+	return args.Get(0).(entity.Classroom), args.Error(1)
+}
+
+func (m *MockClassroomService) DeleteClassroom(teacherID, id int32) error {
+	args := m.Called(teacherID, id)
+	return args.Error(0)
+}
+
+func (m *MockClassroomService) ListClassrooms(teacherID int32) ([]entity.Classroom, error) {
+	args := m.Called(teacherID)
+	return args.Get(0).([]entity.Classroom), args.Error(1)
+}
+
+func (m *MockClassroomService) ResetInviteCode(teacherID, id int32) (entity.Classroom, error) {
+	args := m.Called(teacherID, id)
+	return args.Get(0).(entity.Classroom), args.Error(1)
+}
+
+func (m *MockClassroomService) ListMembers(teacherID, id int32) ([]entity.Member, error) {
+	args := m.Called(teacherID, id)
+	return args.Get(0).([]entity.Member), args.Error(1)
+}
+
+func (m *MockClassroomService) RemoveMember(teacherID, id, userID int32) error {
+	args := m.Called(teacherID, id, userID)
+	return args.Error(0)
+}
+
+func (m *MockClassroomService) EnrollRoster(teacherID, id int32, emails []string) (entity.RosterResult, error) {
+	args := m.Called(teacherID, id, emails)
+	return args.Get(0).(entity.RosterResult), args.Error(1)
+}
+
+func (m *MockClassroomService) JoinClassroom(studentID int32, schoolID *int32, join entity.JoinClassroom) (entity.Classroom, error) {
+	args := m.Called(studentID, schoolID, join)
+	return args.Get(0).(entity.Classroom), args.Error(1)
+}
+
+func (m *MockClassroomService) LeaveClassroom(studentID, id int32) error {
+	args := m.Called(studentID, id)
+	return args.Error(0)
+}
+
+func (m *MockClassroomService) MyClassrooms(studentID int32) ([]entity.Classroom, error) {
+	args := m.Called(studentID)
+	return args.Get(0).([]entity.Classroom), args.Error(1)
+}
+
+func signedToken(userID int, role string) string {
+	claims := jwt.MapClaims{
+		"apps":      "misterblast-core",
+		"email":     "john@example.com",
+		"user_id":   userID,
+		"role":      role,
+		"school_id": 2,
+		"jti":       "jti-test",
+		"exp":       time.Now().Add(time.Hour).Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, _ := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+	return signed
+}
+
+func rosterRequest(t *testing.T, csv string) *http.Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", "roster.csv")
+	assert.NoError(t, err)
+	part.Write([]byte(csv))
+	assert.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/classroom/3/roster", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+signedToken(9, middleware.RoleTeacher))
+	return req
+}
+
+func TestAddClassroomHandler(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockClassroomService)
+	h := handler.NewClassroomHandler(mockService, validator.New())
+	app.Post("/classroom", middleware.JWTProtected(), h.AddClassroomHandler)
+
+	classroom := entity.SetClassroom{Name: "4A", ClassID: 4, School: "SD Negeri 1", AcademicYear: "2026/2027"}
+	mockService.On("AddClassroom", int32(9), classroom).Return(entity.Classroom{ID: 3, InviteCode: "ABCD2345"}, nil)
+
+	body, _ := json.Marshal(classroom)
+	req := httptest.NewRequest(http.MethodPost, "/classroom", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+signedToken(9, middleware.RoleTeacher))
+
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
+func TestAddClassroomHandler_InvalidBody(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockClassroomService)
+	h := handler.NewClassroomHandler(mockService, validator.New())
+	app.Post("/classroom", middleware.JWTProtected(), h.AddClassroomHandler)
+
+	body := []byte(`{"name": "4A", "class_id": 4, "school": "SD Negeri 1", "academic_year": "2026"}`)
+	req := httptest.NewRequest(http.MethodPost, "/classroom", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+signedToken(9, middleware.RoleTeacher))
+
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	mockService.AssertNotCalled(t, "AddClassroom", mock.Anything, mock.Anything)
+}
+
+func TestJoinClassroomHandler(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockClassroomService)
+	h := handler.NewClassroomHandler(mockService, validator.New())
+	app.Post("/classroom/join", middleware.JWTProtected(), h.JoinClassroomHandler)
+
+	schoolID := int32(2)
+	mockService.On("JoinClassroom", int32(11), &schoolID, entity.JoinClassroom{Code: "ABCD2345"}).Return(entity.Classroom{ID: 3}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/classroom/join", bytes.NewReader([]byte(`{"code": "ABCD2345"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+signedToken(11, middleware.RoleStudent))
+
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
+func TestJoinClassroomHandler_InvalidCode(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockClassroomService)
+	h := handler.NewClassroomHandler(mockService, validator.New())
+	app.Post("/classroom/join", middleware.JWTProtected(), h.JoinClassroomHandler)
+
+	req := httptest.NewRequest(http.MethodPost, "/classroom/join", bytes.NewReader([]byte(`{"code": "ABC-234"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+signedToken(11, middleware.RoleStudent))
+
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	mockService.AssertNotCalled(t, "JoinClassroom", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestClassroomRouter_StudentForbidden(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockClassroomService)
+	handler.NewClassroomHandler(mockService, validator.New()).Router(app)
+
+	req := httptest.NewRequest(http.MethodGet, "/classroom/3/members", nil)
+	req.Header.Set("Authorization", "Bearer "+signedToken(11, middleware.RoleStudent))
+
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	mockService.AssertNotCalled(t, "ListMembers", mock.Anything, mock.Anything)
+}
+
+func TestUploadRosterHandler(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockClassroomService)
+	h := handler.NewClassroomHandler(mockService, validator.New())
+	app.Post("/classroom/:id/roster", middleware.JWTProtected(), h.UploadRosterHandler)
+
+	mockService.On("EnrollRoster", int32(9), int32(3), []string{"ani@example.com", "budi@example.com"}).
+		Return(entity.RosterResult{Enrolled: 2, NotFound: []string{}}, nil)
+
+	resp, _ := app.Test(rosterRequest(t, "\ufeffName,Email\nAni,ani@example.com\nBudi,budi@example.com\n"))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
+func TestUploadRosterHandler_NoEmailColumn(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockClassroomService)
+	h := handler.NewClassroomHandler(mockService, validator.New())
+	app.Post("/classroom/:id/roster", middleware.JWTProtected(), h.UploadRosterHandler)
+
+	resp, _ := app.Test(rosterRequest(t, "name\nAni\n"))
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	mockService.AssertNotCalled(t, "EnrollRoster", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ghulammuzz/misterblast/internal/classroom/entity"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/log"
+	"github.com/ghulammuzz/misterblast/pkg/middleware"
+	"github.com/ghulammuzz/misterblast/pkg/response"
+)
+
+// maxRosterBytes is plenty for MaxRosterRows lines of name and email.
+const maxRosterBytes = 1 << 20
+
+// UploadRosterHandler enrolls students listed in a CSV roster sent as the
+// multipart field "file". The first row is a header with an "email" column;
+// other columns such as name are ignored. Students need an account already,
+// unknown emails are reported back.
+func (h *ClassroomHandler) UploadRosterHandler(c *fiber.Ctx) error {
+	teacherID, ok := middleware.UserID(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid classroom ID", nil)
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "file is required", nil)
+	}
+	if header.Size > maxRosterBytes {
+		return response.SendError(c, fiber.StatusRequestEntityTooLarge, "file is too large", nil)
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		log.Error("[Handler][UploadRoster] Error Open: ", err)
+		return response.SendError(c, fiber.StatusBadRequest, "failed to read file", nil)
+	}
+	defer file.Close()
+
+	emails, err := readRoster(file)
+	if err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, err.Error(), nil)
+	}
+
+	result, err := h.classroomService.EnrollRoster(teacherID, int32(id), emails)
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "roster enrolled successfully", result)
+}
+
+func readRoster(r io.Reader) ([]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid csv file: %v", err)
+	}
+	if len(rows) < 2 {
+		return nil, fmt.Errorf("file has no student rows")
+	}
+	if len(rows)-1 > entity.MaxRosterRows {
+		return nil, fmt.Errorf("file has more than %d student rows", entity.MaxRosterRows)
+	}
+
+	column := -1
+	for i, name := range rows[0] {
+		if strings.EqualFold(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")), "email") {
+			column = i
+		}
+	}
+	if column < 0 {
+		return nil, fmt.Errorf("file has no email column")
+	}
+
+	emails := make([]string, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		if column < len(row) {
+			emails = append(emails, row[column])
+		}
+	}
+	return emails, nil
+}
@@ -0,0 +1,332 @@
+package repo
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+
+	classroomEntity "github.com/ghulammuzz/misterblast/internal/classroom/entity"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/log"
+)
+
+// ErrInviteCodeTaken is returned when a new invite code collides with an
+// existing one; the caller draws another.
+var ErrInviteCodeTaken = app.NewAppError(409, "invite code already in use")
+
+type ClassroomRepository interface {
+	Add(teacherID int32, classroom classroomEntity.SetClassroom, inviteCode string) (int32, error)
+	Detail(id int32) (classroomEntity.Classroom, error)
+	DetailByCode(schoolID *int32, inviteCode string) (classroomEntity.Classroom, error)
+	Delete(id int32) error
+	List(teacherID int32) ([]classroomEntity.Classroom, error)
+	ListForStudent(userID int32) ([]classroomEntity.Classroom, error)
+	EditInviteCode(id int32, inviteCode string) error
+
+	// Membership
+	AddMember(id, userID int32) (bool, error)
+	RemoveMember(id, userID int32) error
+	ListMembers(id int32) ([]classroomEntity.Member, error)
+	EnrollByEmail(id int32, emails []string) (classroomEntity.RosterResult, error)
+}
+
+type classroomRepository struct {
+	db *sql.DB
+}
+
+func NewClassroomRepository(db *sql.DB) ClassroomRepository {
+	return &classroomRepository{db: db}
+}
+
+const selectClassroom = `
+	SELECT r.id, r.name, r.class_id, c.name, r.school, r.academic_year, r.teacher_id, r.invite_code, r.created_at,
+		   (SELECT COUNT(*) FROM classroom_members m WHERE m.classroom_id = r.id)
+	FROM classrooms r
+	JOIN classes c ON c.id = r.class_id`
+
+func scanClassroom(row interface{ Scan(...interface{}) error }) (classroomEntity.Classroom, error) {
+	var classroom classroomEntity.Classroom
+	err := row.Scan(&classroom.ID, &classroom.Name, &classroom.ClassID, &classroom.Class, &classroom.School,
+		&classroom.AcademicYear, &classroom.TeacherID, &classroom.InviteCode, &classroom.CreatedAt, &classroom.Members)
+	return classroom, err
+}
+
+func (r *classroomRepository) Add(teacherID int32, classroom classroomEntity.SetClassroom, inviteCode string) (int32, error) {
+	var id int32
+	query := `
+		INSERT INTO classrooms (name, class_id, school, academic_year, teacher_id, invite_code, created_at, school_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, (SELECT school_id FROM users WHERE id = $5)) RETURNING id`
+	err := r.db.QueryRow(query, classroom.Name, classroom.ClassID, classroom.School, classroom.AcademicYear,
+		teacherID, inviteCode, time.Now().Unix()).Scan(&id)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+			return 0, app.NewAppError(404, "class not found")
+		}
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return 0, ErrInviteCodeTaken
+		}
+		log.Error("[Repo][AddClassroom] Error QueryRow: ", err)
+		return 0, app.NewAppError(500, "failed to create classroom")
+	}
+
+	return id, nil
+}
+
+func (r *classroomRepository) Detail(id int32) (classroomEntity.Classroom, error) {
+	classroom, err := scanClassroom(r.db.QueryRow(selectClassroom+` WHERE r.id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return classroom, app.NewAppError(404, "classroom not found")
+		}
+		log.Error("[Repo][DetailClassroom] Error QueryRow: ", err)
+		return classroom, app.NewAppError(500, "failed to fetch classroom")
+	}
+
+	return classroom, nil
+}
+
+// DetailByCode finds a classroom by invite code within schoolID, so codes
+// shared outside a school do not enroll students of another one. A nil
+// schoolID searches every school.
+func (r *classroomRepository) DetailByCode(schoolID *int32, inviteCode string) (classroomEntity.Classroom, error) {
+	query := selectClassroom + ` WHERE r.invite_code = $1`
+	args := []interface{}{inviteCode}
+	if schoolID != nil {
+		query += ` AND r.school_id = $2`
+		args = append(args, *schoolID)
+	}
+
+	classroom, err := scanClassroom(r.db.QueryRow(query, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return classroom, app.NewAppError(404, "invalid invite code")
+		}
+		log.Error("[Repo][DetailClassroomByCode] Error QueryRow: ", err)
+		return classroom, app.NewAppError(500, "failed to fetch classroom")
+	}
+
+	return classroom, nil
+}
+
+func (r *classroomRepository) Delete(id int32) error {
+	res, err := r.db.Exec(`DELETE FROM classrooms WHERE id = $1`, id)
+	if err != nil {
+		log.Error("[Repo][DeleteClassroom] Error Exec: ", err)
+		return app.NewAppError(500, "failed to delete classroom")
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		log.Error("[Repo][DeleteClassroom] Error RowsAffected: ", err)
+		return app.NewAppError(500, "failed to check rows affected")
+	}
+	if rowsAffected == 0 {
+		return app.NewAppError(404, "classroom not found")
+	}
+
+	return nil
+}
+
+func (r *classroomRepository) List(teacherID int32) ([]classroomEntity.Classroom, error) {
+	return r.list("[Repo][ListClassrooms]", selectClassroom+` WHERE r.teacher_id = $1 ORDER BY r.academic_year DESC, r.name`, teacherID)
+}
+
+func (r *classroomRepository) ListForStudent(userID int32) ([]classroomEntity.Classroom, error) {
+	query := selectClassroom + `
+	JOIN classroom_members cm ON cm.classroom_id = r.id
+	WHERE cm.user_id = $1
+	ORDER BY r.academic_year DESC, r.name`
+	return r.list("[Repo][ListStudentClassrooms]", query, userID)
+}
+
+func (r *classroomRepository) list(logPrefix, query string, args ...interface{}) ([]classroomEntity.Classroom, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		log.Error(logPrefix+" Error Query: ", err)
+		return nil, app.NewAppError(500, "failed to fetch classrooms")
+	}
+	defer rows.Close()
+
+	classrooms := []classroomEntity.Classroom{}
+	for rows.Next() {
+		classroom, err := scanClassroom(rows)
+		if err != nil {
+			log.Error(logPrefix+" Error Scan: ", err)
+			return nil, app.NewAppError(500, "failed to scan classroom")
+		}
+		classrooms = append(classrooms, classroom)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Error(logPrefix+" Error Iterating Rows: ", err)
+		return nil, app.NewAppError(500, "error iterating rows")
+	}
+
+	return classrooms, nil
+}
+
+func (r *classroomRepository) EditInviteCode(id int32, inviteCode string) error {
+	res, err := r.db.Exec(`UPDATE classrooms SET invite_code = $1 WHERE id = $2`, inviteCode, id)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return ErrInviteCodeTaken
+		}
+		log.Error("[Repo][EditInviteCode] Error Exec: ", err)
+		return app.NewAppError(500, "failed to update invite code")
+	}
+
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return app.NewAppError(404, "classroom not found")
+	}
+
+	return nil
+}
+
+// placeStudent moves a student without a school into the school of the
+// classroom they are enrolled in. Students of a school are left as they are.
+const placeStudent = `
+	UPDATE users SET school_id = (SELECT school_id FROM classrooms WHERE id = $2)
+	WHERE id = $1 AND school_id IS NULL`
+
+// AddMember enrolls a student and tells whether they were not enrolled yet.
+// A student without a school joins the classroom's school in the same
+// transaction.
+func (r *classroomRepository) AddMember(id, userID int32) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Error("[Repo][AddClassroomMember] Error Begin: ", err)
+		return false, app.NewAppError(500, "failed to join classroom")
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(placeStudent, userID, id); err != nil {
+		log.Error("[Repo][AddClassroomMember] Error Exec: ", err)
+		return false, app.NewAppError(500, "failed to join classroom")
+	}
+
+	query := `
+		INSERT INTO classroom_members (classroom_id, user_id, joined_at) VALUES ($1, $2, $3)
+		ON CONFLICT (classroom_id, user_id) DO NOTHING`
+	res, err := tx.Exec(query, id, userID, time.Now().Unix())
+	if err != nil {
+		log.Error("[Repo][AddClassroomMember] Error Exec: ", err)
+		return false, app.NewAppError(500, "failed to join classroom")
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error("[Repo][AddClassroomMember] Error Commit: ", err)
+		return false, app.NewAppError(500, "failed to join classroom")
+	}
+
+	rowsAffected, _ := res.RowsAffected()
+	return rowsAffected > 0, nil
+}
+
+func (r *classroomRepository) RemoveMember(id, userID int32) error {
+	res, err := r.db.Exec(`DELETE FROM classroom_members WHERE classroom_id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		log.Error("[Repo][RemoveClassroomMember] Error Exec: ", err)
+		return app.NewAppError(500, "failed to remove member")
+	}
+
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return app.NewAppError(404, "member not found")
+	}
+
+	return nil
+}
+
+func (r *classroomRepository) ListMembers(id int32) ([]classroomEntity.Member, error) {
+	query := `
+		SELECT u.id, u.name, u.email, m.joined_at
+		FROM classroom_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.classroom_id = $1
+		ORDER BY u.name`
+	rows, err := r.db.Query(query, id)
+	if err != nil {
+		log.Error("[Repo][ListClassroomMembers] Error Query: ", err)
+		return nil, app.NewAppError(500, "failed to fetch members")
+	}
+	defer rows.Close()
+
+	members := []classroomEntity.Member{}
+	for rows.Next() {
+		var member classroomEntity.Member
+		if err := rows.Scan(&member.UserID, &member.Name, &member.Email, &member.JoinedAt); err != nil {
+			log.Error("[Repo][ListClassroomMembers] Error Scan: ", err)
+			return nil, app.NewAppError(500, "failed to scan member")
+		}
+		members = append(members, member)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Error("[Repo][ListClassroomMembers] Error Iterating Rows: ", err)
+		return nil, app.NewAppError(500, "error iterating rows")
+	}
+
+	return members, nil
+}
+
+// EnrollByEmail enrolls the student accounts of a roster in one transaction.
+// Emails are matched case-insensitively; admins and students of other schools
+// are never enrolled. Students without a school join the classroom's school.
+func (r *classroomRepository) EnrollByEmail(id int32, emails []string) (classroomEntity.RosterResult, error) {
+	result := classroomEntity.RosterResult{NotFound: []string{}}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Error("[Repo][EnrollRoster] Error Begin: ", err)
+		return result, app.NewAppError(500, "failed to enroll roster")
+	}
+	defer tx.Rollback()
+
+	lookup := `
+		SELECT id FROM users
+		WHERE LOWER(email) = LOWER($1) AND role = 'student'
+		  AND (school_id IS NULL OR school_id = (SELECT school_id FROM classrooms WHERE id = $2))`
+	insert := `
+		INSERT INTO classroom_members (classroom_id, user_id, joined_at) VALUES ($1, $2, $3)
+		ON CONFLICT (classroom_id, user_id) DO NOTHING`
+	now := time.Now().Unix()
+
+	for _, email := range emails {
+		var userID int32
+		err := tx.QueryRow(lookup, email, id).Scan(&userID)
+		if err == sql.ErrNoRows {
+			result.NotFound = append(result.NotFound, email)
+			continue
+		}
+		if err != nil {
+			log.Error("[Repo][EnrollRoster] Error QueryRow: ", err)
+			return result, app.NewAppError(500, "failed to enroll roster")
+		}
+
+		if _, err := tx.Exec(placeStudent, userID, id); err != nil {
+			log.Error("[Repo][EnrollRoster] Error Exec: ", err)
+			return result, app.NewAppError(500, "failed to enroll roster")
+		}
+
+		res, err := tx.Exec(insert, id, userID, now)
+		if err != nil {
+			log.Error("[Repo][EnrollRoster] Error Exec: ", err)
+			return result, app.NewAppError(500, "failed to enroll roster")
+		}
+		if rowsAffected, _ := res.RowsAffected(); rowsAffected > 0 {
+			result.Enrolled++
+		} else {
+			result.AlreadyEnrolled++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error("[Repo][EnrollRoster] Error Commit: ", err)
+		return result, app.NewAppError(500, "failed to enroll roster")
+	}
+
+	return result, nil
+}
@@ -0,0 +1,195 @@
+package repo_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ghulammuzz/misterblast/internal/classroom/entity"
+	"github.com/ghulammuzz/misterblast/internal/classroom/repo"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+)
+
+var classroomColumns = []string{"id", "name", "class_id", "class", "school", "academic_year", "teacher_id", "invite_code", "created_at", "members"}
+
+func TestAddClassroom(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewClassroomRepository(db)
+
+	mock.ExpectQuery(`INSERT INTO classrooms \(name, class_id, school, academic_year, teacher_id, invite_code, created_at, school_id\)
+		VALUES \(\$1, \$2, \$3, \$4, \$5, \$6, \$7, \(SELECT school_id FROM users WHERE id = \$5\)\)`).
+		WithArgs("4A", 4, "SD Negeri 1", "2026/2027", 9, "ABCD2345", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+
+	id, err := repository.Add(9, entity.SetClassroom{Name: "4A", ClassID: 4, School: "SD Negeri 1", AcademicYear: "2026/2027"}, "ABCD2345")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddClassroom_CodeTaken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewClassroomRepository(db)
+
+	mock.ExpectQuery(`INSERT INTO classrooms`).
+		WillReturnError(&pq.Error{Code: "23505"})
+
+	_, err = repository.Add(9, entity.SetClassroom{Name: "4A", ClassID: 4, School: "SD Negeri 1", AcademicYear: "2026/2027"}, "ABCD2345")
+	assert.Equal(t, repo.ErrInviteCodeTaken, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddClassroom_ClassNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewClassroomRepository(db)
+
+	mock.ExpectQuery(`INSERT INTO classrooms`).
+		WillReturnError(&pq.Error{Code: "23503"})
+
+	_, err = repository.Add(9, entity.SetClassroom{Name: "4A", ClassID: 99, School: "SD Negeri 1", AcademicYear: "2026/2027"}, "ABCD2345")
+	assert.Error(t, err)
+	assert.Equal(t, 404, err.(*app.AppError).Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDetailClassroomByCode(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewClassroomRepository(db)
+
+	rows := sqlmock.NewRows(classroomColumns).
+		AddRow(3, "4A", 4, "Kelas 4", "SD Negeri 1", "2026/2027", 9, "ABCD2345", 1700000000, 25)
+	mock.ExpectQuery(`WHERE r.invite_code = \$1`).
+		WithArgs("ABCD2345").
+		WillReturnRows(rows)
+
+	classroom, err := repository.DetailByCode(nil, "ABCD2345")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), classroom.ID)
+	assert.Equal(t, "Kelas 4", classroom.Class)
+	assert.Equal(t, 25, classroom.Members)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDetailClassroomByCode_Invalid(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewClassroomRepository(db)
+
+	schoolID := int32(2)
+	mock.ExpectQuery(`WHERE r.invite_code = \$1 AND r.school_id = \$2`).
+		WithArgs("ZZZZZZZZ", schoolID).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err = repository.DetailByCode(&schoolID, "ZZZZZZZZ")
+	assert.Error(t, err)
+	assert.Equal(t, 404, err.(*app.AppError).Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListClassrooms(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewClassroomRepository(db)
+
+	rows := sqlmock.NewRows(classroomColumns).
+		AddRow(3, "4A", 4, "Kelas 4", "SD Negeri 1", "2026/2027", 9, "ABCD2345", 1700000000, 25).
+		AddRow(4, "4B", 4, "Kelas 4", "SD Negeri 1", "2026/2027", 9, "EFGH6789", 1700000000, 0)
+	mock.ExpectQuery(`WHERE r.teacher_id = \$1`).
+		WithArgs(9).
+		WillReturnRows(rows)
+
+	classrooms, err := repository.List(9)
+	assert.NoError(t, err)
+	assert.Len(t, classrooms, 2)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddMember_AlreadyEnrolled(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewClassroomRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE users SET school_id = \(SELECT school_id FROM classrooms WHERE id = \$2\) WHERE id = \$1 AND school_id IS NULL`).
+		WithArgs(11, 3).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO classroom_members`).
+		WithArgs(3, 11, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	added, err := repository.AddMember(3, 11)
+	assert.NoError(t, err)
+	assert.False(t, added)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRemoveMember_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewClassroomRepository(db)
+
+	mock.ExpectExec(`DELETE FROM classroom_members WHERE classroom_id = \$1 AND user_id = \$2`).
+		WithArgs(3, 11).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repository.RemoveMember(3, 11)
+	assert.Error(t, err)
+	assert.Equal(t, 404, err.(*app.AppError).Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEnrollByEmail(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewClassroomRepository(db)
+
+	lookup := `SELECT id FROM users WHERE LOWER\(email\) = LOWER\(\$1\) AND role = 'student' AND \(school_id IS NULL OR school_id = \(SELECT school_id FROM classrooms WHERE id = \$2\)\)`
+	place := `UPDATE users SET school_id = \(SELECT school_id FROM classrooms WHERE id = \$2\) WHERE id = \$1 AND school_id IS NULL`
+	mock.ExpectBegin()
+	mock.ExpectQuery(lookup).WithArgs("ani@example.com", 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11))
+	mock.ExpectExec(place).WithArgs(11, 3).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO classroom_members`).WithArgs(3, 11, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(lookup).WithArgs("budi@example.com", 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(12))
+	mock.ExpectExec(place).WithArgs(12, 3).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO classroom_members`).WithArgs(3, 12, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(lookup).WithArgs("nobody@example.com", 3).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectCommit()
+
+	result, err := repository.EnrollByEmail(3, []string{"ani@example.com", "budi@example.com", "nobody@example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Enrolled)
+	assert.Equal(t, 1, result.AlreadyEnrolled)
+	assert.Equal(t, []string{"nobody@example.com"}, result.NotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,207 @@
+package svc
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+
+	classroomEntity "github.com/ghulammuzz/misterblast/internal/classroom/entity"
+	classroomRepo "github.com/ghulammuzz/misterblast/internal/classroom/repo"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/log"
+)
+
+// inviteCodeTries bounds how often a colliding invite code is redrawn.
+const inviteCodeTries = 5
+
+type ClassroomService interface {
+	AddClassroom(teacherID int32, classroom classroomEntity.SetClassroom) (classroomEntity.Classroom, error)
+	DeleteClassroom(teacherID, id int32) error
+	ListClassrooms(teacherID int32) ([]classroomEntity.Classroom, error)
+	ResetInviteCode(teacherID, id int32) (classroomEntity.Classroom, error)
+
+	// Membership
+	ListMembers(teacherID, id int32) ([]classroomEntity.Member, error)
+	RemoveMember(teacherID, id, userID int32) error
+	EnrollRoster(teacherID, id int32, emails []string) (classroomEntity.RosterResult, error)
+	JoinClassroom(studentID int32, schoolID *int32, join classroomEntity.JoinClassroom) (classroomEntity.Classroom, error)
+	LeaveClassroom(studentID, id int32) error
+	MyClassrooms(studentID int32) ([]classroomEntity.Classroom, error)
+}
+
+type classroomService struct {
+	repo classroomRepo.ClassroomRepository
+}
+
+func NewClassroomService(repo classroomRepo.ClassroomRepository) ClassroomService {
+	return &classroomService{repo: repo}
+}
+
+func (s *classroomService) AddClassroom(teacherID int32, classroom classroomEntity.SetClassroom) (classroomEntity.Classroom, error) {
+	if err := classroomEntity.CheckAcademicYear(classroom.AcademicYear); err != nil {
+		return classroomEntity.Classroom{}, app.NewAppError(400, err.Error())
+	}
+
+	var id int32
+	err := withInviteCode(func(code string) error {
+		var err error
+		id, err = s.repo.Add(teacherID, classroom, code)
+		return err
+	})
+	if err != nil {
+		return classroomEntity.Classroom{}, err
+	}
+
+	return s.repo.Detail(id)
+}
+
+func (s *classroomService) DeleteClassroom(teacherID, id int32) error {
+	if _, err := s.ownedClassroom(teacherID, id); err != nil {
+		return err
+	}
+	return s.repo.Delete(id)
+}
+
+func (s *classroomService) ListClassrooms(teacherID int32) ([]classroomEntity.Classroom, error) {
+	return s.repo.List(teacherID)
+}
+
+// ResetInviteCode replaces the invite code, e.g. after it leaked. Students
+// already enrolled stay.
+func (s *classroomService) ResetInviteCode(teacherID, id int32) (classroomEntity.Classroom, error) {
+	if _, err := s.ownedClassroom(teacherID, id); err != nil {
+		return classroomEntity.Classroom{}, err
+	}
+
+	err := withInviteCode(func(code string) error {
+		return s.repo.EditInviteCode(id, code)
+	})
+	if err != nil {
+		return classroomEntity.Classroom{}, err
+	}
+
+	return s.repo.Detail(id)
+}
+
+func (s *classroomService) ListMembers(teacherID, id int32) ([]classroomEntity.Member, error) {
+	if _, err := s.ownedClassroom(teacherID, id); err != nil {
+		return nil, err
+	}
+	return s.repo.ListMembers(id)
+}
+
+func (s *classroomService) RemoveMember(teacherID, id, userID int32) error {
+	if _, err := s.ownedClassroom(teacherID, id); err != nil {
+		return err
+	}
+	return s.repo.RemoveMember(id, userID)
+}
+
+// EnrollRoster enrolls the students of a roster by email. Blank and repeated
+// emails are skipped.
+func (s *classroomService) EnrollRoster(teacherID, id int32, emails []string) (classroomEntity.RosterResult, error) {
+	if _, err := s.ownedClassroom(teacherID, id); err != nil {
+		return classroomEntity.RosterResult{}, err
+	}
+
+	seen := make(map[string]bool, len(emails))
+	unique := make([]string, 0, len(emails))
+	for _, email := range emails {
+		email = strings.TrimSpace(email)
+		key := strings.ToLower(email)
+		if email == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, email)
+	}
+	if len(unique) == 0 {
+		return classroomEntity.RosterResult{}, app.NewAppError(400, "roster has no emails")
+	}
+	if len(unique) > classroomEntity.MaxRosterRows {
+		return classroomEntity.RosterResult{}, app.NewAppError(400, "roster has too many students")
+	}
+
+	return s.repo.EnrollByEmail(id, unique)
+}
+
+// JoinClassroom enrolls a student with an invite code of a classroom in their
+// school, schoolID. Students not placed in a school yet, with a schoolID of 0,
+// may use the code of any school's classroom and join that school with it.
+func (s *classroomService) JoinClassroom(studentID int32, schoolID *int32, join classroomEntity.JoinClassroom) (classroomEntity.Classroom, error) {
+	if schoolID != nil && *schoolID == 0 {
+		schoolID = nil
+	}
+
+	classroom, err := s.repo.DetailByCode(schoolID, strings.ToUpper(join.Code))
+	if err != nil {
+		return classroomEntity.Classroom{}, err
+	}
+
+	added, err := s.repo.AddMember(classroom.ID, studentID)
+	if err != nil {
+		return classroomEntity.Classroom{}, err
+	}
+	if added {
+		classroom.Members++
+	}
+
+	classroom.InviteCode = ""
+	return classroom, nil
+}
+
+func (s *classroomService) LeaveClassroom(studentID, id int32) error {
+	return s.repo.RemoveMember(id, studentID)
+}
+
+func (s *classroomService) MyClassrooms(studentID int32) ([]classroomEntity.Classroom, error) {
+	classrooms, err := s.repo.ListForStudent(studentID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range classrooms {
+		classrooms[i].InviteCode = ""
+	}
+	return classrooms, nil
+}
+
+// ownedClassroom only returns classrooms of the teacher; others are reported
+// as not found.
+func (s *classroomService) ownedClassroom(teacherID, id int32) (classroomEntity.Classroom, error) {
+	classroom, err := s.repo.Detail(id)
+	if err != nil {
+		return classroom, err
+	}
+	if classroom.TeacherID != teacherID {
+		return classroomEntity.Classroom{}, app.NewAppError(404, "classroom not found")
+	}
+	return classroom, nil
+}
+
+// withInviteCode calls save with fresh invite codes until one is not taken.
+func withInviteCode(save func(code string) error) error {
+	for i := 0; i < inviteCodeTries; i++ {
+		code, err := inviteCode()
+		if err != nil {
+			log.Error("[Svc][InviteCode] Error Random: ", err)
+			return app.ErrInternal
+		}
+		if err := save(code); err != classroomRepo.ErrInviteCodeTaken {
+			return err
+		}
+	}
+	return app.NewAppError(500, "failed to generate invite code")
+}
+
+func inviteCode() (string, error) {
+	alphabet := classroomEntity.InviteCodeAlphabet
+	code := make([]byte, classroomEntity.InviteCodeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(alphabet))))
+		if err != nil {
+			return "", err
+		}
+		code[i] = alphabet[n.Int64()]
+	}
+	return string(code), nil
+}
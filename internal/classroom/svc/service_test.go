@@ -0,0 +1,199 @@
+package svc_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/ghulammuzz/misterblast/internal/classroom/entity"
+	"github.com/ghulammuzz/misterblast/internal/classroom/repo"
+	"github.com/ghulammuzz/misterblast/internal/classroom/svc"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+)
+
+type MockClassroomRepo struct {
+	mock.Mock
+}
+
+func (m *MockClassroomRepo) Add(teacherID int32, classroom entity.SetClassroom, inviteCode string) (int32, error) {
+	args := m.Called(teacherID, classroom, inviteCode)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (m *MockClassroomRepo) Detail(id int32) (entity.Classroom, error) {
+	args := m.Called(id)
+	return args.Get(0).(entity.Classroom), args.Error(1)
+}
+
+func (m *MockClassroomRepo) DetailByCode(schoolID *int32, inviteCode string) (entity.Classroom, error) {
+	args := m.Called(schoolID, inviteCode)
+	return args.Get(0).(entity.Classroom), args.Error(1)
+}
+
+func (m *MockClassroomRepo) Delete(id int32) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockClassroomRepo) List(teacherID int32) ([]entity.Classroom, error) {
+	args := m.Called(teacherID)
+	return args.Get(0).([]entity.Classroom), args.Error(1)
+}
+
+func (m *MockClassroomRepo) ListForStudent(userID int32) ([]entity.Classroom, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]entity.Classroom), args.Error(1)
+}
+
+func (m *MockClassroomRepo) EditInviteCode(id int32, inviteCode string) error {
+	args := m.Called(id, inviteCode)
+	return args.Error(0)
+}
+
+func (m *MockClassroomRepo) AddMember(id, userID int32) (bool, error) {
+	args := m.Called(id, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockClassroomRepo) RemoveMember(id, userID int32) error {
+	args := m.Called(id, userID)
+	return args.Error(0)
+}
+
+func (m *MockClassroomRepo) ListMembers(id int32) ([]entity.Member, error) {
+	args := m.Called(id)
+	return args.Get(0).([]entity.Member), args.Error(1)
+}
+
+func (m *MockClassroomRepo) EnrollByEmail(id int32, emails []string) (entity.RosterResult, error) {
+	args := m.Called(id, emails)
+	return args.Get(0).(entity.RosterResult), args.Error(1)
+}
+
+func TestAddClassroom(t *testing.T) {
+	mockRepo := new(MockClassroomRepo)
+	service := svc.NewClassroomService(mockRepo)
+
+	input := entity.SetClassroom{Name: "4A", ClassID: 4, School: "SD Negeri 1", AcademicYear: "2026/2027"}
+
+	var code string
+	mockRepo.On("Add", int32(9), input, mock.Anything).Run(func(args mock.Arguments) {
+		code = args.String(2)
+	}).Return(int32(3), nil)
+	mockRepo.On("Detail", int32(3)).Return(entity.Classroom{ID: 3, TeacherID: 9}, nil)
+
+	created, err := service.AddClassroom(9, input)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), created.ID)
+	assert.Len(t, code, entity.InviteCodeLength)
+	for _, r := range code {
+		assert.Contains(t, entity.InviteCodeAlphabet, string(r))
+	}
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAddClassroom_RetriesTakenCode(t *testing.T) {
+	mockRepo := new(MockClassroomRepo)
+	service := svc.NewClassroomService(mockRepo)
+
+	input := entity.SetClassroom{Name: "4A", ClassID: 4, School: "SD Negeri 1", AcademicYear: "2026/2027"}
+
+	mockRepo.On("Add", int32(9), input, mock.Anything).Return(int32(0), repo.ErrInviteCodeTaken).Once()
+	mockRepo.On("Add", int32(9), input, mock.Anything).Return(int32(3), nil).Once()
+	mockRepo.On("Detail", int32(3)).Return(entity.Classroom{ID: 3, TeacherID: 9}, nil)
+
+	_, err := service.AddClassroom(9, input)
+	assert.NoError(t, err)
+	mockRepo.AssertNumberOfCalls(t, "Add", 2)
+}
+
+func TestAddClassroom_InvalidAcademicYear(t *testing.T) {
+	mockRepo := new(MockClassroomRepo)
+	service := svc.NewClassroomService(mockRepo)
+
+	_, err := service.AddClassroom(9, entity.SetClassroom{Name: "4A", ClassID: 4, School: "SD Negeri 1", AcademicYear: "2026/2028"})
+	assert.Error(t, err)
+	assert.Equal(t, 400, err.(*app.AppError).Code)
+	mockRepo.AssertNotCalled(t, "Add", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDeleteClassroom_NotOwner(t *testing.T) {
+	mockRepo := new(MockClassroomRepo)
+	service := svc.NewClassroomService(mockRepo)
+
+	mockRepo.On("Detail", int32(3)).Return(entity.Classroom{ID: 3, TeacherID: 8}, nil)
+
+	err := service.DeleteClassroom(9, 3)
+	assert.Error(t, err)
+	assert.Equal(t, 404, err.(*app.AppError).Code)
+	mockRepo.AssertNotCalled(t, "Delete", mock.Anything)
+}
+
+func TestEnrollRoster_Dedup(t *testing.T) {
+	mockRepo := new(MockClassroomRepo)
+	service := svc.NewClassroomService(mockRepo)
+
+	mockRepo.On("Detail", int32(3)).Return(entity.Classroom{ID: 3, TeacherID: 9}, nil)
+	mockRepo.On("EnrollByEmail", int32(3), []string{"ani@example.com", "budi@example.com"}).
+		Return(entity.RosterResult{Enrolled: 2, NotFound: []string{}}, nil)
+
+	result, err := service.EnrollRoster(9, 3, []string{" ani@example.com", "", "ANI@example.com", "budi@example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Enrolled)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestEnrollRoster_Empty(t *testing.T) {
+	mockRepo := new(MockClassroomRepo)
+	service := svc.NewClassroomService(mockRepo)
+
+	mockRepo.On("Detail", int32(3)).Return(entity.Classroom{ID: 3, TeacherID: 9}, nil)
+
+	_, err := service.EnrollRoster(9, 3, []string{" ", ""})
+	assert.Error(t, err)
+	assert.Equal(t, 400, err.(*app.AppError).Code)
+	mockRepo.AssertNotCalled(t, "EnrollByEmail", mock.Anything, mock.Anything)
+}
+
+func TestJoinClassroom(t *testing.T) {
+	mockRepo := new(MockClassroomRepo)
+	service := svc.NewClassroomService(mockRepo)
+
+	schoolID := int32(2)
+	mockRepo.On("DetailByCode", &schoolID, "ABCD2345").Return(entity.Classroom{ID: 3, TeacherID: 9, InviteCode: "ABCD2345", Members: 24}, nil)
+	mockRepo.On("AddMember", int32(3), int32(11)).Return(true, nil)
+
+	classroom, err := service.JoinClassroom(11, &schoolID, entity.JoinClassroom{Code: "abcd2345"})
+	assert.NoError(t, err)
+	assert.Equal(t, 25, classroom.Members)
+	assert.Empty(t, classroom.InviteCode)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestJoinClassroom_StudentWithoutSchool(t *testing.T) {
+	mockRepo := new(MockClassroomRepo)
+	service := svc.NewClassroomService(mockRepo)
+
+	noSchool := int32(0)
+	mockRepo.On("DetailByCode", (*int32)(nil), "ABCD2345").Return(entity.Classroom{ID: 3, TeacherID: 9, InviteCode: "ABCD2345", Members: 24}, nil)
+	mockRepo.On("AddMember", int32(3), int32(11)).Return(true, nil)
+
+	classroom, err := service.JoinClassroom(11, &noSchool, entity.JoinClassroom{Code: "abcd2345"})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), classroom.ID)
+	assert.Equal(t, 25, classroom.Members)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestMyClassrooms_HidesInviteCode(t *testing.T) {
+	mockRepo := new(MockClassroomRepo)
+	service := svc.NewClassroomService(mockRepo)
+
+	mockRepo.On("ListForStudent", int32(11)).Return([]entity.Classroom{{ID: 3, InviteCode: "ABCD2345"}}, nil)
+
+	classrooms, err := service.MyClassrooms(11)
+	assert.NoError(t, err)
+	assert.Len(t, classrooms, 1)
+	assert.Empty(t, classrooms[0].InviteCode)
+}
@@ -19,6 +19,7 @@ func InitializedEmailServiceFake(sb *sql.DB, val *validator.Validate) *emailHand
 		emailRepo.NewEmailRepository,
 		emailRepo.NewOTPService,
 		userRepo.NewUserRepository,
+		userRepo.NewTokenRepository,
 	)
 
 	return &emailHandler.EmailHandler{}
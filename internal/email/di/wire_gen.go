@@ -20,8 +20,9 @@ import (
 func InitializedEmailService(sb *sql.DB, val *validator.Validate) *handler.EmailHandler {
 	emailRepository := repo.NewEmailRepository(sb)
 	userRepository := repo2.NewUserRepository(sb)
+	tokenRepository := repo2.NewTokenRepository(sb)
 	otp := repo.NewOTPService()
-	emailService := svc.NewEmailService(emailRepository, userRepository, otp)
+	emailService := svc.NewEmailService(emailRepository, userRepository, tokenRepository, otp)
 	emailHandler := handler.NewEmailHandler(emailService, val)
 	return emailHandler
 }
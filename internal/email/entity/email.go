@@ -1,5 +1,21 @@
 package entity
 
+const (
+	PurposeActivation    = "activation"
+	PurposePasswordReset = "password_reset"
+)
+
+const (
+	IPEventSend   = "send"
+	IPEventVerify = "verify"
+)
+
+const (
+	OutboxPending = "pending"
+	OutboxSent    = "sent"
+	OutboxDead    = "dead"
+)
+
 type SendOTP struct {
 	Email string `json:"email" validate:"required,email"`
 }
@@ -8,3 +24,36 @@ type CheckOTP struct {
 	OTP string `json:"otp" validate:"required"`
 	ID  int32  `json:"id" validate:"required"`
 }
+
+type ForgotPassword struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPassword struct {
+	Email    string `json:"email" validate:"required,email"`
+	OTP      string `json:"otp" validate:"required,len=6,numeric"`
+	Password string `json:"password" validate:"required,min=6,max=20"`
+}
+
+type UserOTP struct {
+	CodeHash    string
+	ExpiresAt   int64
+	SentAt      int64
+	Attempts    int
+	UsedAt      *int64
+	LockedUntil *int64
+}
+
+type OutboxEmail struct {
+	ID            int32   `json:"id"`
+	Recipient     string  `json:"recipient"`
+	Subject       string  `json:"subject"`
+	TextBody      string  `json:"-"`
+	HTMLBody      string  `json:"-"`
+	Status        string  `json:"status"`
+	Attempts      int     `json:"attempts"`
+	LastError     *string `json:"last_error,omitempty"`
+	NextAttemptAt int64   `json:"next_attempt_at"`
+	CreatedAt     int64   `json:"created_at"`
+	SentAt        *int64  `json:"sent_at,omitempty"`
+}
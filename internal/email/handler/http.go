@@ -1,10 +1,14 @@
 package handler
 
 import (
+	"time"
+
 	"github.com/ghulammuzz/misterblast/internal/email/entity"
 	"github.com/ghulammuzz/misterblast/internal/email/svc"
 	"github.com/ghulammuzz/misterblast/pkg/app"
 	"github.com/ghulammuzz/misterblast/pkg/log"
+	"github.com/ghulammuzz/misterblast/pkg/mailer"
+	"github.com/ghulammuzz/misterblast/pkg/middleware"
 	"github.com/ghulammuzz/misterblast/pkg/response"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
@@ -20,8 +24,19 @@ func NewEmailHandler(emailService svc.EmailService, val *validator.Validate) *Em
 }
 
 func (h *EmailHandler) Router(r fiber.Router) {
-	r.Post("/activation/send-otp", h.SendOTPActivation)
-	r.Post("/activation/check-otp", h.CheckOTPHandler)
+	sendLimit := middleware.RateLimit(middleware.RateLimitConfig{Name: "otp-send", Limit: 5, Window: 10 * time.Minute})
+	checkLimit := middleware.RateLimit(middleware.RateLimitConfig{Name: "otp-check", Limit: 20, Window: 10 * time.Minute})
+
+	r.Post("/activation/send-otp", sendLimit, h.SendOTPActivation)
+	r.Post("/activation/check-otp", checkLimit, h.CheckOTPHandler)
+	r.Post("/password/forgot", sendLimit, h.ForgotPasswordHandler)
+	r.Post("/password/reset", checkLimit, h.ResetPasswordHandler)
+
+	auth := middleware.JWTProtected()
+	admin := middleware.RequireRole(middleware.AdminRoles...)
+
+	r.Get("/email-outbox", auth, admin, h.ListOutboxHandler)
+	r.Post("/email-outbox/:id/retry", auth, admin, h.RetryOutboxHandler)
 }
 
 func (h *EmailHandler) SendOTPActivation(c *fiber.Ctx) error {
@@ -38,7 +53,7 @@ func (h *EmailHandler) SendOTPActivation(c *fiber.Ctx) error {
 		return response.SendError(c, fiber.StatusBadRequest, "Validation failed", validationErrors)
 	}
 
-	if err := h.emailService.SendOTP(SendOTP.Email); err != nil {
+	if err := h.emailService.SendOTP(SendOTP.Email, mailer.LangFromHeader(c.Get("Accept-Language")), c.IP()); err != nil {
 		appErr, ok := err.(*app.AppError)
 		if !ok {
 			appErr = app.ErrInternal
@@ -63,7 +78,7 @@ func (h *EmailHandler) CheckOTPHandler(c *fiber.Ctx) error {
 		return response.SendError(c, fiber.StatusBadRequest, "Validation failed", validationErrors)
 	}
 
-	if err := h.emailService.Validate(checkOTP.ID, checkOTP.OTP); err != nil {
+	if err := h.emailService.Validate(checkOTP.ID, checkOTP.OTP, c.IP()); err != nil {
 		appErr, ok := err.(*app.AppError)
 		if !ok {
 			appErr = app.ErrInternal
@@ -73,3 +88,90 @@ func (h *EmailHandler) CheckOTPHandler(c *fiber.Ctx) error {
 
 	return response.SendSuccess(c, "Valid", nil)
 }
+
+func (h *EmailHandler) ForgotPasswordHandler(c *fiber.Ctx) error {
+
+	var forgot entity.ForgotPassword
+
+	if err := c.BodyParser(&forgot); err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "Invalid request body", nil)
+	}
+
+	if err := h.val.Struct(forgot); err != nil {
+		validationErrors := app.ValidationErrorResponse(err)
+		log.Error("Validation failed: %v", validationErrors)
+		return response.SendError(c, fiber.StatusBadRequest, "Validation failed", validationErrors)
+	}
+
+	if err := h.emailService.ForgotPassword(forgot.Email, mailer.LangFromHeader(c.Get("Accept-Language")), c.IP()); err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "If the email is registered, a reset OTP has been sent", nil)
+}
+
+func (h *EmailHandler) ResetPasswordHandler(c *fiber.Ctx) error {
+
+	var reset entity.ResetPassword
+
+	if err := c.BodyParser(&reset); err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "Invalid request body", nil)
+	}
+
+	if err := h.val.Struct(reset); err != nil {
+		validationErrors := app.ValidationErrorResponse(err)
+		log.Error("Validation failed: %v", validationErrors)
+		return response.SendError(c, fiber.StatusBadRequest, "Validation failed", validationErrors)
+	}
+
+	if err := h.emailService.ResetPassword(reset, c.IP()); err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "Password reset successfully", nil)
+}
+
+func (h *EmailHandler) ListOutboxHandler(c *fiber.Ctx) error {
+
+	filter := map[string]string{}
+	if status := c.Query("status"); status != "" {
+		filter["status"] = status
+	}
+
+	emails, err := h.emailService.ListOutbox(filter)
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "Outbox retrieved successfully", emails)
+}
+
+func (h *EmailHandler) RetryOutboxHandler(c *fiber.Ctx) error {
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return response.SendError(c, fiber.StatusBadRequest, "Invalid email ID", nil)
+	}
+
+	if err := h.emailService.RetryOutbox(int32(id)); err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "Email queued for retry", nil)
+}
@@ -4,12 +4,29 @@ import (
 	"database/sql"
 	"time"
 
+	"github.com/ghulammuzz/misterblast/internal/email/entity"
 	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/log"
+	"github.com/ghulammuzz/misterblast/pkg/mailer"
 )
 
 type EmailRepository interface {
-	SetOTP(adminID int32, otp string, expiresAt int64) error
-	GetOTP(adminID int32) (string, int64, error)
+	SetOTP(adminID int32, purpose, otpHash string, expiresAt int64, msg mailer.Message) error
+	GetOTP(adminID int32, purpose string) (entity.UserOTP, error)
+	ConsumeOTP(adminID int32, purpose string) error
+	UseAttempt(adminID int32, purpose string, maxAttempts int, now int64) (entity.UserOTP, error)
+	LockOTP(adminID int32, purpose string, lockedUntil int64) error
+
+	// Per-IP counters
+	HitIPEvent(ip, kind string, since int64) (int, error)
+
+	// Outbox
+	ClaimOutbox(limit int, lease time.Duration) ([]entity.OutboxEmail, error)
+	MarkOutboxSent(id int32) error
+	MarkOutboxFailed(id int32, attempts int, lastError string, nextAttemptAt int64, dead bool) error
+	ListOutbox(filter map[string]string) ([]entity.OutboxEmail, error)
+	RetryOutbox(id int32) error
+	PurgeOutbox(before int64) (int64, error)
 }
 
 type emailRepository struct {
@@ -20,30 +37,155 @@ func NewEmailRepository(db *sql.DB) EmailRepository {
 	return &emailRepository{db}
 }
 
-func (r *emailRepository) SetOTP(adminID int32, otp string, expiresAt int64) error {
+// SetOTP stores the hashed OTP for the given purpose, replacing any previous
+// code of the same purpose so only the latest one is valid, and resets its
+// failed attempts. A running lockout is kept. The mail carrying the code is
+// queued in the outbox within the same transaction, as a sensitive mail.
+func (r *emailRepository) SetOTP(adminID int32, purpose, otpHash string, expiresAt int64, msg mailer.Message) error {
 	if expiresAt <= time.Now().Unix() {
 		return app.NewAppError(400, "Waktu kedaluwarsa tidak valid")
 	}
+
+	tx, err := r.DB.Begin()
+	if err != nil {
+		log.Error("[Repo][SetOTP] Error Begin: ", err)
+		return app.NewAppError(500, "Gagal menyimpan OTP")
+	}
+	defer tx.Rollback()
+
 	query := `
-        INSERT INTO user_otps (admin_id, otp_code, expires_at) 
-        VALUES ($1, $2, $3)
-        ON CONFLICT (admin_id) 
-        DO UPDATE SET otp_code = EXCLUDED.otp_code, expires_at = EXCLUDED.expires_at;
+        INSERT INTO user_otps (admin_id, purpose, otp_code, expires_at, sent_at, attempts) 
+        VALUES ($1, $2, $3, $4, $5, 0)
+        ON CONFLICT (admin_id, purpose) 
+        DO UPDATE SET otp_code = EXCLUDED.otp_code, expires_at = EXCLUDED.expires_at, sent_at = EXCLUDED.sent_at,
+            attempts = 0, used_at = NULL;
     `
-	_, err := r.DB.Exec(query, adminID, otp, expiresAt)
-	if err != nil {
+	if _, err := tx.Exec(query, adminID, purpose, otpHash, expiresAt, time.Now().Unix()); err != nil {
+		log.Error("[Repo][SetOTP] Error Exec: ", err)
+		return app.NewAppError(500, "Gagal menyimpan OTP")
+	}
+
+	if err := Enqueue(tx, msg, true); err != nil {
+		log.Error("[Repo][SetOTP] Error Enqueue: ", err)
+		return app.NewAppError(500, "Gagal menyimpan email")
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error("[Repo][SetOTP] Error Commit: ", err)
 		return app.NewAppError(500, "Gagal menyimpan OTP")
 	}
 	return nil
 }
 
-func (r *emailRepository) GetOTP(adminID int32) (string, int64, error) {
-	var dbOtp string
-	var expiresAt int64
-	query := `SELECT otp_code, expires_at FROM user_otps WHERE admin_id=$1 LIMIT 1`
-	err := r.DB.QueryRow(query, adminID).Scan(&dbOtp, &expiresAt)
+func (r *emailRepository) GetOTP(adminID int32, purpose string) (entity.UserOTP, error) {
+	var otp entity.UserOTP
+	var usedAt, lockedUntil sql.NullInt64
+	query := `
+		SELECT otp_code, expires_at, sent_at, attempts, used_at, locked_until
+		FROM user_otps WHERE admin_id=$1 AND purpose=$2 LIMIT 1`
+	err := r.DB.QueryRow(query, adminID, purpose).Scan(&otp.CodeHash, &otp.ExpiresAt, &otp.SentAt, &otp.Attempts, &usedAt, &lockedUntil)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Error("[Repo][GetOTP] Error QueryRow: ", err)
+		}
+		return otp, app.NewAppError(404, "OTP tidak ditemukan atau sudah kadaluarsa")
+	}
+	if usedAt.Valid {
+		otp.UsedAt = &usedAt.Int64
+	}
+	if lockedUntil.Valid {
+		otp.LockedUntil = &lockedUntil.Int64
+	}
+	return otp, nil
+}
+
+// ConsumeOTP marks the OTP as used. It fails when the code was already used,
+// so two concurrent requests cannot both redeem it.
+func (r *emailRepository) ConsumeOTP(adminID int32, purpose string) error {
+	query := `UPDATE user_otps SET used_at = $1 WHERE admin_id = $2 AND purpose = $3 AND used_at IS NULL`
+	res, err := r.DB.Exec(query, time.Now().Unix(), adminID, purpose)
 	if err != nil {
-		return "", 0, app.NewAppError(404, "OTP tidak ditemukan atau sudah kadaluarsa")
+		log.Error("[Repo][ConsumeOTP] Error Exec: ", err)
+		return app.NewAppError(500, "Gagal memperbarui OTP")
+	}
+
+	rowsAffected, _ := res.RowsAffected()
+	if rowsAffected == 0 {
+		return app.NewAppError(400, "OTP sudah digunakan")
+	}
+	return nil
+}
+
+// UseAttempt counts one try of the current code before it is compared, so
+// concurrent guesses cannot all get past maxAttempts. It returns the code
+// with the updated count, or fails once the code is used up, locked or
+// already redeemed.
+func (r *emailRepository) UseAttempt(adminID int32, purpose string, maxAttempts int, now int64) (entity.UserOTP, error) {
+	query := `
+		UPDATE user_otps SET attempts = attempts + 1
+		WHERE admin_id = $1 AND purpose = $2 AND attempts < $3 AND used_at IS NULL
+		  AND (locked_until IS NULL OR locked_until <= $4)
+		RETURNING otp_code, expires_at, sent_at, attempts`
+	var otp entity.UserOTP
+	err := r.DB.QueryRow(query, adminID, purpose, maxAttempts, now).Scan(&otp.CodeHash, &otp.ExpiresAt, &otp.SentAt, &otp.Attempts)
+	if err == sql.ErrNoRows {
+		return otp, app.NewAppError(400, "OTP tidak berlaku, silakan minta OTP baru")
+	}
+	if err != nil {
+		log.Error("[Repo][UseAttempt] Error QueryRow: ", err)
+		return otp, app.NewAppError(500, "Gagal memperbarui OTP")
+	}
+	return otp, nil
+}
+
+// LockOTP locks the account for this purpose until lockedUntil.
+func (r *emailRepository) LockOTP(adminID int32, purpose string, lockedUntil int64) error {
+	query := `UPDATE user_otps SET locked_until = $1 WHERE admin_id = $2 AND purpose = $3`
+	if _, err := r.DB.Exec(query, lockedUntil, adminID, purpose); err != nil {
+		log.Error("[Repo][LockOTP] Error Exec: ", err)
+		return app.NewAppError(500, "Gagal memperbarui OTP")
+	}
+	return nil
+}
+
+// HitIPEvent records one event for the IP and returns how many it has had
+// since the given time, this one included. Hits of the same IP and kind are
+// serialized, so concurrent requests cannot all see a count under the limit.
+// Events older than a day, longer than any window they are counted in, are
+// pruned on the way.
+func (r *emailRepository) HitIPEvent(ip, kind string, since int64) (int, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		log.Error("[Repo][HitIPEvent] Error Begin: ", err)
+		return 0, app.NewAppError(500, "failed to record attempt")
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext($1 || ':' || $2))`, ip, kind); err != nil {
+		log.Error("[Repo][HitIPEvent] Error Exec Lock: ", err)
+		return 0, app.NewAppError(500, "failed to record attempt")
+	}
+
+	now := time.Now().Unix()
+	if _, err := tx.Exec(`INSERT INTO otp_ip_events (ip, kind, created_at) VALUES ($1, $2, $3)`, ip, kind, now); err != nil {
+		log.Error("[Repo][HitIPEvent] Error Exec: ", err)
+		return 0, app.NewAppError(500, "failed to record attempt")
+	}
+
+	var count int
+	query := `SELECT COUNT(*) FROM otp_ip_events WHERE ip = $1 AND kind = $2 AND created_at >= $3`
+	if err := tx.QueryRow(query, ip, kind, since).Scan(&count); err != nil {
+		log.Error("[Repo][HitIPEvent] Error QueryRow: ", err)
+		return 0, app.NewAppError(500, "failed to check attempt limit")
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error("[Repo][HitIPEvent] Error Commit: ", err)
+		return 0, app.NewAppError(500, "failed to record attempt")
+	}
+
+	if _, err := r.DB.Exec(`DELETE FROM otp_ip_events WHERE created_at < $1`, now-24*60*60); err != nil {
+		log.Warn("[Repo][HitIPEvent] Error Exec Prune: ", err)
 	}
-	return dbOtp, expiresAt, nil
+	return count, nil
 }
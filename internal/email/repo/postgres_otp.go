@@ -1,11 +1,12 @@
 package repo
 
 import (
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
-	"log"
 	"math/big"
-	"net/smtp"
 	"os"
 
 	"github.com/ghulammuzz/misterblast/pkg/app"
@@ -13,7 +14,8 @@ import (
 
 type OTP interface {
 	GenerateOTP() (string, error)
-	SendEmailSMTP(to string, otp string) error
+	HashOTP(otp string) string
+	VerifyOTP(hash, otp string) bool
 }
 
 type otpService struct{}
@@ -31,23 +33,21 @@ func (o *otpService) GenerateOTP() (string, error) {
 	return fmt.Sprintf("%06d", n.Int64()), nil
 }
 
-func (o *otpService) SendEmailSMTP(to string, otp string) error {
-	smtpHost := "smtp.gmail.com"
-	smtpPort := "587"
-	smtpUser := os.Getenv("EMAIL_HOST_USER")
-	smtpPassword := os.Getenv("EMAIL_HOST_PASSWORD")
-
-	auth := smtp.PlainAuth("", smtpUser, smtpPassword, smtpHost)
+// HashOTP keys the hash with a server secret; a plain hash of a 6-digit code
+// would be reversed by trying all million candidates.
+func (o *otpService) HashOTP(otp string) string {
+	mac := hmac.New(sha256.New, otpSecret())
+	mac.Write([]byte(otp))
+	return hex.EncodeToString(mac.Sum(nil))
+}
 
-	msg := []byte("To: " + to + "\r\n" +
-		"Subject: OTP Verification\r\n" +
-		"\r\n" +
-		"Your OTP code is: " + otp + "\r\n")
+func (o *otpService) VerifyOTP(hash, otp string) bool {
+	return hmac.Equal([]byte(hash), []byte(o.HashOTP(otp)))
+}
 
-	err := smtp.SendMail(smtpHost+":"+smtpPort, auth, smtpUser, []string{to}, msg)
-	if err != nil {
-		log.Println("Error sending email:", err)
-		return app.NewAppError(500, err.Error())
+func otpSecret() []byte {
+	if secret := os.Getenv("OTP_SECRET"); secret != "" {
+		return []byte(secret)
 	}
-	return nil
+	return []byte(os.Getenv("JWT_SECRET"))
 }
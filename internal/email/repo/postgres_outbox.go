@@ -0,0 +1,179 @@
+package repo
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ghulammuzz/misterblast/internal/email/entity"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/log"
+	"github.com/ghulammuzz/misterblast/pkg/mailer"
+)
+
+// Enqueue queues msg for the outbox worker within tx, so the mail only goes
+// out if whatever it announces is committed with it. The bodies of a
+// sensitive mail are dropped once it is sent or dead-lettered.
+func Enqueue(tx *sql.Tx, msg mailer.Message, sensitive bool) error {
+	now := time.Now().Unix()
+	query := `
+		INSERT INTO email_outbox (recipient, subject, text_body, html_body, status, attempts, next_attempt_at, created_at, sensitive)
+		VALUES ($1, $2, $3, $4, $5, 0, $6, $6, $7)`
+	_, err := tx.Exec(query, msg.To, msg.Subject, msg.Text, msg.HTML, entity.OutboxPending, now, sensitive)
+	return err
+}
+
+// ClaimOutbox picks due pending mails and pushes their next attempt past the
+// lease, so another worker does not pick the same rows while they are being
+// delivered. A worker that dies mid-send simply lets the lease run out.
+func (r *emailRepository) ClaimOutbox(limit int, lease time.Duration) ([]entity.OutboxEmail, error) {
+	now := time.Now()
+	query := `
+		UPDATE email_outbox SET next_attempt_at = $1
+		WHERE id IN (
+			SELECT id FROM email_outbox
+			WHERE status = $2 AND next_attempt_at <= $3
+			ORDER BY next_attempt_at
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, recipient, subject, text_body, html_body, status, attempts, next_attempt_at, created_at`
+
+	rows, err := r.DB.Query(query, now.Add(lease).Unix(), entity.OutboxPending, now.Unix(), limit)
+	if err != nil {
+		log.Error("[Repo][ClaimOutbox] Error Query: ", err)
+		return nil, app.NewAppError(500, "failed to claim outbox")
+	}
+	defer rows.Close()
+
+	var emails []entity.OutboxEmail
+	for rows.Next() {
+		var e entity.OutboxEmail
+		if err := rows.Scan(&e.ID, &e.Recipient, &e.Subject, &e.TextBody, &e.HTMLBody, &e.Status,
+			&e.Attempts, &e.NextAttemptAt, &e.CreatedAt); err != nil {
+			log.Error("[Repo][ClaimOutbox] Error Scan: ", err)
+			return nil, app.NewAppError(500, "failed to scan outbox")
+		}
+		emails = append(emails, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Error("[Repo][ClaimOutbox] Error Iterating Rows: ", err)
+		return nil, app.NewAppError(500, "error iterating rows")
+	}
+
+	return emails, nil
+}
+
+// MarkOutboxSent also drops the bodies, which are not needed once delivered.
+func (r *emailRepository) MarkOutboxSent(id int32) error {
+	query := `
+		UPDATE email_outbox SET status = $1, attempts = attempts + 1, sent_at = $2, last_error = NULL,
+			text_body = '', html_body = ''
+		WHERE id = $3`
+	if _, err := r.DB.Exec(query, entity.OutboxSent, time.Now().Unix(), id); err != nil {
+		log.Error("[Repo][MarkOutboxSent] Error Exec: ", err)
+		return app.NewAppError(500, "failed to update outbox")
+	}
+	return nil
+}
+
+func (r *emailRepository) MarkOutboxFailed(id int32, attempts int, lastError string, nextAttemptAt int64, dead bool) error {
+	status := entity.OutboxPending
+	if dead {
+		status = entity.OutboxDead
+	}
+
+	// A sensitive mail that is given up on cannot be retried: its bodies are
+	// dropped and a fresh one has to be requested instead.
+	query := `
+		UPDATE email_outbox SET status = $1, attempts = $2, last_error = $3, next_attempt_at = $4,
+			text_body = CASE WHEN $6 AND sensitive THEN '' ELSE text_body END,
+			html_body = CASE WHEN $6 AND sensitive THEN '' ELSE html_body END
+		WHERE id = $5`
+	if _, err := r.DB.Exec(query, status, attempts, lastError, nextAttemptAt, id, dead); err != nil {
+		log.Error("[Repo][MarkOutboxFailed] Error Exec: ", err)
+		return app.NewAppError(500, "failed to update outbox")
+	}
+	return nil
+}
+
+func (r *emailRepository) ListOutbox(filter map[string]string) ([]entity.OutboxEmail, error) {
+	query := `
+		SELECT id, recipient, subject, status, attempts, last_error, next_attempt_at, created_at, sent_at
+		FROM email_outbox WHERE 1=1`
+	args := []interface{}{}
+	argCounter := 1
+
+	if status, ok := filter["status"]; ok {
+		query += fmt.Sprintf(" AND status = $%d", argCounter)
+		args = append(args, status)
+		argCounter++
+	}
+
+	query += " ORDER BY created_at DESC LIMIT 100"
+
+	rows, err := r.DB.Query(query, args...)
+	if err != nil {
+		log.Error("[Repo][ListOutbox] Error Query: ", err)
+		return nil, app.NewAppError(500, "failed to fetch outbox")
+	}
+	defer rows.Close()
+
+	emails := []entity.OutboxEmail{}
+	for rows.Next() {
+		var e entity.OutboxEmail
+		var lastError sql.NullString
+		var sentAt sql.NullInt64
+		if err := rows.Scan(&e.ID, &e.Recipient, &e.Subject, &e.Status, &e.Attempts, &lastError,
+			&e.NextAttemptAt, &e.CreatedAt, &sentAt); err != nil {
+			log.Error("[Repo][ListOutbox] Error Scan: ", err)
+			return nil, app.NewAppError(500, "failed to scan outbox")
+		}
+		if lastError.Valid {
+			e.LastError = &lastError.String
+		}
+		if sentAt.Valid {
+			e.SentAt = &sentAt.Int64
+		}
+		emails = append(emails, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Error("[Repo][ListOutbox] Error Iterating Rows: ", err)
+		return nil, app.NewAppError(500, "error iterating rows")
+	}
+
+	return emails, nil
+}
+
+// RetryOutbox puts a dead-lettered mail back in the queue with a fresh
+// attempt budget. Sensitive mails are not retried.
+func (r *emailRepository) RetryOutbox(id int32) error {
+	query := `UPDATE email_outbox SET status = $1, attempts = 0, next_attempt_at = $2 WHERE id = $3 AND status = $4 AND NOT sensitive`
+	res, err := r.DB.Exec(query, entity.OutboxPending, time.Now().Unix(), id, entity.OutboxDead)
+	if err != nil {
+		log.Error("[Repo][RetryOutbox] Error Exec: ", err)
+		return app.NewAppError(500, "failed to retry outbox email")
+	}
+
+	rowsAffected, _ := res.RowsAffected()
+	if rowsAffected == 0 {
+		return app.NewAppError(404, "failed email not found")
+	}
+	return nil
+}
+
+// PurgeOutbox deletes the sent and dead-lettered mails created before the
+// given time and returns how many were deleted.
+func (r *emailRepository) PurgeOutbox(before int64) (int64, error) {
+	query := `DELETE FROM email_outbox WHERE status <> $1 AND created_at < $2`
+	res, err := r.DB.Exec(query, entity.OutboxPending, before)
+	if err != nil {
+		log.Error("[Repo][PurgeOutbox] Error Exec: ", err)
+		return 0, app.NewAppError(500, "failed to purge outbox")
+	}
+
+	rowsAffected, _ := res.RowsAffected()
+	return rowsAffected, nil
+}
@@ -1 +1,198 @@
 package repo_test
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ghulammuzz/misterblast/internal/email/entity"
+	emailRepo "github.com/ghulammuzz/misterblast/internal/email/repo"
+	"github.com/ghulammuzz/misterblast/pkg/mailer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsumeOTP(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := emailRepo.NewEmailRepository(db)
+
+	mock.ExpectExec(`UPDATE user_otps SET used_at = \$1 WHERE admin_id = \$2 AND purpose = \$3 AND used_at IS NULL`).
+		WithArgs(sqlmock.AnyArg(), 4, entity.PurposePasswordReset).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.ConsumeOTP(4, entity.PurposePasswordReset))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestConsumeOTP_AlreadyUsed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := emailRepo.NewEmailRepository(db)
+
+	mock.ExpectExec(`UPDATE user_otps SET used_at`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repo.ConsumeOTP(4, entity.PurposePasswordReset)
+	assert.Error(t, err)
+	assert.Equal(t, "OTP sudah digunakan", err.Error())
+}
+
+func TestSetOTP_QueuesMailInSameTransaction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := emailRepo.NewEmailRepository(db)
+	msg := mailer.Message{To: "john@example.com", Subject: "Kode", Text: "123456", HTML: "<p>123456</p>"}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO user_otps`).
+		WithArgs(4, entity.PurposeActivation, "otp-hash", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`INSERT INTO email_outbox`).
+		WithArgs(msg.To, msg.Subject, msg.Text, msg.HTML, entity.OutboxPending, sqlmock.AnyArg(), true).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err = repo.SetOTP(4, entity.PurposeActivation, "otp-hash", time.Now().Add(time.Minute).Unix(), msg)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetOTP_OutboxFailureRollsBack(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := emailRepo.NewEmailRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO user_otps`).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`INSERT INTO email_outbox`).WillReturnError(errors.New("disk full"))
+	mock.ExpectRollback()
+
+	err = repo.SetOTP(4, entity.PurposeActivation, "123456", time.Now().Add(time.Minute).Unix(), mailer.Message{To: "john@example.com"})
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRetryOutbox_NotDead(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := emailRepo.NewEmailRepository(db)
+
+	mock.ExpectExec(`UPDATE email_outbox SET status = \$1, attempts = 0`).
+		WithArgs(entity.OutboxPending, sqlmock.AnyArg(), 7, entity.OutboxDead).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repo.RetryOutbox(7)
+	assert.Error(t, err)
+	assert.Equal(t, "failed email not found", err.Error())
+}
+
+func TestMarkOutboxSent_DropsBodies(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := emailRepo.NewEmailRepository(db)
+
+	mock.ExpectExec(`UPDATE email_outbox SET status = \$1(.|\n)+text_body = '', html_body = ''`).
+		WithArgs(entity.OutboxSent, sqlmock.AnyArg(), 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repo.MarkOutboxSent(7)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPurgeOutbox(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := emailRepo.NewEmailRepository(db)
+
+	mock.ExpectExec(`DELETE FROM email_outbox WHERE status <> \$1 AND created_at < \$2`).
+		WithArgs(entity.OutboxPending, int64(1700000000)).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	purged, err := repo.PurgeOutbox(1700000000)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), purged)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUseAttempt(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := emailRepo.NewEmailRepository(db)
+
+	mock.ExpectQuery(`UPDATE user_otps SET attempts = attempts \+ 1(.|\n)+attempts < \$3 AND used_at IS NULL`).
+		WithArgs(4, entity.PurposeActivation, 5, int64(1700000000)).
+		WillReturnRows(sqlmock.NewRows([]string{"otp_code", "expires_at", "sent_at", "attempts"}).AddRow("otp-hash", 1700000120, 1700000000, 5))
+
+	otp, err := repo.UseAttempt(4, entity.PurposeActivation, 5, 1700000000)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, otp.Attempts)
+	assert.Equal(t, "otp-hash", otp.CodeHash)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUseAttempt_Exhausted(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := emailRepo.NewEmailRepository(db)
+
+	mock.ExpectQuery(`UPDATE user_otps SET attempts = attempts \+ 1`).
+		WithArgs(4, entity.PurposeActivation, 5, int64(1700000000)).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err = repo.UseAttempt(4, entity.PurposeActivation, 5, 1700000000)
+	assert.EqualError(t, err, "OTP tidak berlaku, silakan minta OTP baru")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestHitIPEvent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := emailRepo.NewEmailRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT pg_advisory_xact_lock`).WithArgs("10.0.0.1", entity.IPEventVerify).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO otp_ip_events`).WithArgs("10.0.0.1", entity.IPEventVerify, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM otp_ip_events`).WithArgs("10.0.0.1", entity.IPEventVerify, int64(1700000000)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectCommit()
+	mock.ExpectExec(`DELETE FROM otp_ip_events`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	count, err := repo.HitIPEvent("10.0.0.1", entity.IPEventVerify, 1700000000)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOTPHash(t *testing.T) {
+	otp := emailRepo.NewOTPService()
+
+	hash := otp.HashOTP("123456")
+	assert.NotContains(t, hash, "123456")
+	assert.True(t, otp.VerifyOTP(hash, "123456"))
+	assert.False(t, otp.VerifyOTP(hash, "123457"))
+}
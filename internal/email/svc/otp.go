@@ -0,0 +1,142 @@
+package svc
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ghulammuzz/misterblast/internal/email/entity"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/log"
+	"github.com/ghulammuzz/misterblast/pkg/mailer"
+)
+
+const (
+	activationOTPTTL    = 120 * time.Second
+	passwordResetOTPTTL = 10 * time.Minute
+
+	// per account and purpose
+	otpMaxAttempts    = 5
+	otpLockout        = 15 * time.Minute
+	otpResendCooldown = 60 * time.Second
+
+	// per client IP, counting every request whether it succeeds or not
+	ipVerifyWindow = 15 * time.Minute
+	ipMaxVerifies  = 20
+	ipSendWindow   = time.Hour
+	ipMaxSends     = 10
+)
+
+func (s *emailService) sendOTP(userID int32, email, lang, purpose string, ttl time.Duration) error {
+	now := time.Now()
+
+	stored, err := s.emailRepo.GetOTP(userID, purpose)
+	if err == nil {
+		if stored.LockedUntil != nil && *stored.LockedUntil > now.Unix() {
+			return lockedError(*stored.LockedUntil - now.Unix())
+		}
+		if wait := stored.SentAt + int64(otpResendCooldown.Seconds()) - now.Unix(); wait > 0 {
+			return app.NewAppError(429, fmt.Sprintf("Tunggu %d detik sebelum meminta OTP baru", wait))
+		}
+	} else if appErr, ok := err.(*app.AppError); !ok || appErr.Code != 404 {
+		return err
+	}
+
+	otpString, err := s.otp.GenerateOTP()
+	if err != nil {
+		return err
+	}
+
+	template := mailer.TemplateOTP
+	if purpose == entity.PurposePasswordReset {
+		template = mailer.TemplatePasswordReset
+	}
+
+	msg, err := mailer.Render(template, lang, mailer.OTPData{Code: otpString, Minutes: int(ttl.Minutes())})
+	if err != nil {
+		log.Error("[Svc][mailer.Render] Error: ", err)
+		return app.ErrInternal
+	}
+	msg.To = email
+
+	// Only the hash is stored; the mail is delivered by the outbox worker,
+	// not within this request.
+	expAt := now.Add(ttl).Unix()
+	return s.emailRepo.SetOTP(userID, purpose, s.otp.HashOTP(otpString), expAt, msg)
+}
+
+// checkOTP verifies the code for the purpose and marks it used. Each try is
+// counted before the code is compared; after otpMaxAttempts wrong codes the
+// code is burned and the account locked out. Callers count the try against
+// the client IP first.
+func (s *emailService) checkOTP(userID int32, purpose, otp string) error {
+	stored, err := s.emailRepo.GetOTP(userID, purpose)
+	if err != nil {
+		log.Error("[Svc][s.emailRepo.GetOTP] Error Exec: ", err)
+		return err
+	}
+
+	now := time.Now().Unix()
+	if stored.LockedUntil != nil && *stored.LockedUntil > now {
+		return lockedError(*stored.LockedUntil - now)
+	}
+
+	if stored.UsedAt != nil {
+		return app.NewAppError(400, "OTP sudah digunakan")
+	}
+
+	if stored.Attempts >= otpMaxAttempts {
+		return app.NewAppError(400, "OTP tidak berlaku, silakan minta OTP baru")
+	}
+
+	// The lookup above only picks the message; the attempt is claimed here,
+	// atomically, and the code compared is the one it was claimed on.
+	stored, err = s.emailRepo.UseAttempt(userID, purpose, otpMaxAttempts, now)
+	if err != nil {
+		return err
+	}
+
+	if !s.otp.VerifyOTP(stored.CodeHash, otp) {
+		if stored.Attempts >= otpMaxAttempts {
+			lockedUntil := time.Now().Add(otpLockout).Unix()
+			if err := s.emailRepo.LockOTP(userID, purpose, lockedUntil); err != nil {
+				return err
+			}
+			log.Warn("[Svc][checkOTP] OTP locked after failed attempts for user: ", userID)
+			return lockedError(lockedUntil - now)
+		}
+		return app.NewAppError(400, "OTP tidak sesuai")
+	}
+
+	if now > stored.ExpiresAt {
+		return app.NewAppError(400, "OTP sudah kedaluwarsa")
+	}
+
+	return s.emailRepo.ConsumeOTP(userID, purpose)
+}
+
+func (s *emailService) checkIPSends(ip string) error {
+	return s.checkIPLimit(ip, entity.IPEventSend, ipSendWindow, ipMaxSends)
+}
+
+func (s *emailService) checkIPVerifies(ip string) error {
+	return s.checkIPLimit(ip, entity.IPEventVerify, ipVerifyWindow, ipMaxVerifies)
+}
+
+// checkIPLimit counts the request against the IP before checking the limit,
+// so concurrent requests cannot all slip under it.
+func (s *emailService) checkIPLimit(ip, kind string, window time.Duration, max int) error {
+	count, err := s.emailRepo.HitIPEvent(ip, kind, time.Now().Add(-window).Unix())
+	if err != nil {
+		return err
+	}
+	if count > max {
+		log.Warn("[Svc][checkIPLimit] Limit reached for ip: ", ip, " kind: ", kind)
+		return app.NewAppError(429, "Terlalu banyak percobaan, coba lagi nanti")
+	}
+	return nil
+}
+
+func lockedError(seconds int64) error {
+	minutes := (seconds + 59) / 60
+	return app.NewAppError(429, fmt.Sprintf("Terlalu banyak percobaan OTP, coba lagi dalam %d menit", minutes))
+}
@@ -0,0 +1,128 @@
+package svc
+
+import (
+	"context"
+	"time"
+
+	"github.com/ghulammuzz/misterblast/internal/email/entity"
+	emailRepo "github.com/ghulammuzz/misterblast/internal/email/repo"
+	"github.com/ghulammuzz/misterblast/pkg/log"
+	"github.com/ghulammuzz/misterblast/pkg/mailer"
+)
+
+const (
+	outboxInterval    = 5 * time.Second
+	outboxBatchSize   = 20
+	outboxLease       = 2 * time.Minute
+	outboxMaxAttempts = 8
+	outboxBaseDelay   = 30 * time.Second
+	outboxMaxDelay    = time.Hour
+
+	// sent and dead-lettered mails are kept this long, then deleted
+	outboxRetention     = 30 * 24 * time.Hour
+	outboxPurgeInterval = time.Hour
+)
+
+// OutboxWorker delivers queued mails from email_outbox. Failed sends are
+// retried with exponential backoff and dead-lettered after outboxMaxAttempts.
+// Mails past outboxRetention are purged.
+type OutboxWorker struct {
+	repo     emailRepo.EmailRepository
+	mail     mailer.Mailer
+	purgedAt time.Time
+}
+
+func NewOutboxWorker(repo emailRepo.EmailRepository, mail mailer.Mailer) *OutboxWorker {
+	return &OutboxWorker{repo: repo, mail: mail}
+}
+
+// Run polls the outbox until ctx is cancelled.
+func (w *OutboxWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(outboxInterval)
+	defer ticker.Stop()
+
+	for {
+		if now := time.Now(); now.Sub(w.purgedAt) >= outboxPurgeInterval {
+			w.Purge(now)
+		}
+
+		if w.ProcessBatch() == outboxBatchSize && ctx.Err() == nil {
+			continue // a full batch means more mails are likely due
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessBatch claims and delivers one batch of due mails and returns how
+// many were claimed.
+func (w *OutboxWorker) ProcessBatch() int {
+	emails, err := w.repo.ClaimOutbox(outboxBatchSize, outboxLease)
+	if err != nil {
+		log.Error("[Worker][Outbox] Error ClaimOutbox: ", err)
+		return 0
+	}
+
+	for _, email := range emails {
+		w.deliver(email)
+	}
+
+	return len(emails)
+}
+
+// Purge deletes the sent and dead-lettered mails older than outboxRetention.
+func (w *OutboxWorker) Purge(now time.Time) {
+	w.purgedAt = now
+	purged, err := w.repo.PurgeOutbox(now.Add(-outboxRetention).Unix())
+	if err != nil {
+		log.Error("[Worker][Outbox] Error PurgeOutbox: ", err)
+		return
+	}
+	if purged > 0 {
+		log.Info("[Worker][Outbox] Purged emails: ", purged)
+	}
+}
+
+func (w *OutboxWorker) deliver(email entity.OutboxEmail) {
+	err := w.mail.Send(mailer.Message{
+		To:      email.Recipient,
+		Subject: email.Subject,
+		Text:    email.TextBody,
+		HTML:    email.HTMLBody,
+	})
+	if err == nil {
+		if err := w.repo.MarkOutboxSent(email.ID); err != nil {
+			log.Error("[Worker][Outbox] Error MarkOutboxSent: ", err)
+		}
+		return
+	}
+
+	attempts := email.Attempts + 1
+	dead := attempts >= outboxMaxAttempts
+	if dead {
+		log.Error("[Worker][Outbox] Dead-lettering email: ", email.ID, " error: ", err)
+	} else {
+		log.Warn("[Worker][Outbox] Error Send: ", email.ID, " error: ", err)
+	}
+
+	nextAttemptAt := time.Now().Add(backoff(attempts)).Unix()
+	if err := w.repo.MarkOutboxFailed(email.ID, attempts, err.Error(), nextAttemptAt, dead); err != nil {
+		log.Error("[Worker][Outbox] Error MarkOutboxFailed: ", err)
+	}
+}
+
+// backoff doubles the delay with every failed attempt, capped at outboxMaxDelay.
+func backoff(attempts int) time.Duration {
+	delay := outboxBaseDelay
+	for i := 1; i < attempts && delay < outboxMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > outboxMaxDelay {
+		delay = outboxMaxDelay
+	}
+	return delay
+}
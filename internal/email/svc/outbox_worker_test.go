@@ -0,0 +1,50 @@
+package svc
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/ghulammuzz/misterblast/internal/email/entity"
+	"github.com/ghulammuzz/misterblast/pkg/mailer"
+)
+
+func TestOutboxWorker_ProcessBatch(t *testing.T) {
+	repo, mail := new(mockEmailRepo), new(mockMailer)
+	worker := NewOutboxWorker(repo, mail)
+
+	repo.On("ClaimOutbox", outboxBatchSize, outboxLease).Return([]entity.OutboxEmail{
+		{ID: 1, Recipient: "a@example.com", Subject: "one", Attempts: 0},
+		{ID: 2, Recipient: "b@example.com", Subject: "two", Attempts: 2},
+		{ID: 3, Recipient: "c@example.com", Subject: "three", Attempts: outboxMaxAttempts - 1},
+	}, nil)
+	mail.On("Send", mock.MatchedBy(func(msg mailer.Message) bool { return msg.To == "a@example.com" })).Return(nil)
+	mail.On("Send", mock.Anything).Return(errors.New("connection refused"))
+	repo.On("MarkOutboxSent", int32(1)).Return(nil)
+	repo.On("MarkOutboxFailed", int32(2), 3, "connection refused", mock.Anything, false).Return(nil)
+	repo.On("MarkOutboxFailed", int32(3), outboxMaxAttempts, "connection refused", mock.Anything, true).Return(nil)
+
+	assert.Equal(t, 3, worker.ProcessBatch())
+	repo.AssertExpectations(t)
+}
+
+func TestOutboxWorker_Purge(t *testing.T) {
+	repo := new(mockEmailRepo)
+	worker := NewOutboxWorker(repo, new(mockMailer))
+
+	now := time.Unix(1800000000, 0)
+	repo.On("PurgeOutbox", now.Add(-outboxRetention).Unix()).Return(int64(4), nil)
+
+	worker.Purge(now)
+	repo.AssertExpectations(t)
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, backoff(1))
+	assert.Equal(t, 60*time.Second, backoff(2))
+	assert.Equal(t, 4*time.Minute, backoff(4))
+	assert.Equal(t, time.Hour, backoff(20))
+}
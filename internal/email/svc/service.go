@@ -1,8 +1,7 @@
 package svc
 
 import (
-	"time"
-
+	"github.com/ghulammuzz/misterblast/internal/email/entity"
 	emailRepo "github.com/ghulammuzz/misterblast/internal/email/repo"
 	userRepo "github.com/ghulammuzz/misterblast/internal/user/repo"
 	"github.com/ghulammuzz/misterblast/pkg/app"
@@ -10,14 +9,21 @@ import (
 )
 
 type EmailService interface {
-	SendOTP(email string) error
-	Validate(adminID int32, otp string) error
+	SendOTP(email, lang, ip string) error
+	Validate(adminID int32, otp, ip string) error
+	ForgotPassword(email, lang, ip string) error
+	ResetPassword(reset entity.ResetPassword, ip string) error
+
+	// Outbox
+	ListOutbox(filter map[string]string) ([]entity.OutboxEmail, error)
+	RetryOutbox(id int32) error
 }
 
-func NewEmailService(emailRepo emailRepo.EmailRepository, userRepo userRepo.UserRepository, otp emailRepo.OTP) EmailService {
+func NewEmailService(emailRepo emailRepo.EmailRepository, userRepo userRepo.UserRepository, tokenRepo userRepo.TokenRepository, otp emailRepo.OTP) EmailService {
 	return &emailService{
 		emailRepo: emailRepo,
 		userRepo:  userRepo,
+		tokenRepo: tokenRepo,
 		otp:       otp,
 	}
 }
@@ -25,35 +31,29 @@ func NewEmailService(emailRepo emailRepo.EmailRepository, userRepo userRepo.User
 type emailService struct {
 	emailRepo emailRepo.EmailRepository
 	userRepo  userRepo.UserRepository
+	tokenRepo userRepo.TokenRepository
 	otp       emailRepo.OTP
 }
 
-func (s *emailService) SendOTP(email string) error {
+func (s *emailService) SendOTP(email, lang, ip string) error {
 
-	adminID, err := s.userRepo.GetIDByEmail(email)
-	if err != nil {
+	if err := s.checkIPSends(ip); err != nil {
 		return err
 	}
-	otpString, err := s.otp.GenerateOTP()
+
+	adminID, err := s.userRepo.GetIDByEmail(email)
 	if err != nil {
 		return err
 	}
 
-	expAt := time.Now().Add(120 * time.Second).Unix()
-
-	if err := s.emailRepo.SetOTP(adminID, otpString, expAt); err != nil {
-		return err
-	}
+	return s.sendOTP(adminID, email, lang, entity.PurposeActivation, activationOTPTTL)
+}
 
-	if err := s.otp.SendEmailSMTP(email, otpString); err != nil {
+func (s *emailService) Validate(adminID int32, otp, ip string) error {
+	if err := s.checkIPVerifies(ip); err != nil {
 		return err
 	}
 
-	return nil
-}
-
-func (s *emailService) Validate(adminID int32, otp string) error {
-
 	exists, err := s.userRepo.Exists(adminID)
 	if !exists {
 		if err != nil {
@@ -62,27 +62,74 @@ func (s *emailService) Validate(adminID int32, otp string) error {
 		}
 	}
 
-	dbOtp, expiresAt, err := s.emailRepo.GetOTP(adminID)
+	if err := s.checkOTP(adminID, entity.PurposeActivation, otp); err != nil {
+		return err
+	}
+
+	err = s.userRepo.AdminActivation(adminID)
 	if err != nil {
-		log.Error("[Svc][s.emailRepo.GetOTP] Error Exec: ", err)
+		log.Error("[Svc][s.userRepo.AdminActivation] Error Exec: ", err)
 		return app.NewAppError(500, err.Error())
 	}
 
-	if dbOtp != otp {
-		log.Error("[Svc][otp] Error Exec: ", err)
-		return app.NewAppError(400, "OTP tidak sesuai")
+	return nil
+}
+
+// ForgotPassword mails a password reset OTP. Unknown emails are accepted
+// silently so the endpoint cannot be used to probe for accounts.
+func (s *emailService) ForgotPassword(email, lang, ip string) error {
+	if err := s.checkIPSends(ip); err != nil {
+		return err
+	}
+
+	userID, err := s.userRepo.GetIDByEmail(email)
+	if err != nil {
+		if appErr, ok := err.(*app.AppError); ok && appErr.Code == 404 {
+			log.Info("[Svc][ForgotPassword] Unknown email requested password reset")
+			return nil
+		}
+		return err
 	}
 
-	if time.Now().Unix() > expiresAt {
-		log.Error("[Svc][expiresAt] Error Exec: ", err)
-		return app.NewAppError(400, "OTP sudah kedaluwarsa")
+	return s.sendOTP(userID, email, lang, entity.PurposePasswordReset, passwordResetOTPTTL)
+}
+
+// ResetPassword redeems a password reset OTP, stores the new password and
+// signs the user out of every existing session.
+func (s *emailService) ResetPassword(reset entity.ResetPassword, ip string) error {
+	if err := s.checkIPVerifies(ip); err != nil {
+		return err
 	}
 
-	err = s.userRepo.AdminActivation(adminID)
+	userID, err := s.userRepo.GetIDByEmail(reset.Email)
 	if err != nil {
-		log.Error("[Svc][s.userRepo.AdminActivation] Error Exec: ", err)
-		return app.NewAppError(500, err.Error())
+		if appErr, ok := err.(*app.AppError); ok && appErr.Code == 404 {
+			return app.NewAppError(400, "OTP tidak sesuai")
+		}
+		return err
+	}
+
+	if err := s.checkOTP(userID, entity.PurposePasswordReset, reset.OTP); err != nil {
+		return err
+	}
+
+	if err := s.userRepo.UpdatePassword(userID, reset.Password); err != nil {
+		log.Error("[Svc][s.userRepo.UpdatePassword] Error Exec: ", err)
+		return err
+	}
+
+	if err := s.tokenRepo.RevokeUserRefreshTokens(userID); err != nil {
+		log.Error("[Svc][s.tokenRepo.RevokeUserRefreshTokens] Error Exec: ", err)
+		return err
 	}
 
 	return nil
 }
+
+func (s *emailService) ListOutbox(filter map[string]string) ([]entity.OutboxEmail, error) {
+	return s.emailRepo.ListOutbox(filter)
+}
+
+func (s *emailService) RetryOutbox(id int32) error {
+	return s.emailRepo.RetryOutbox(id)
+}
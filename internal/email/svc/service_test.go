@@ -1 +1,323 @@
 package svc
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/ghulammuzz/misterblast/internal/email/entity"
+	userRepo "github.com/ghulammuzz/misterblast/internal/user/repo"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/mailer"
+)
+
+type mockEmailRepo struct {
+	mock.Mock
+}
+
+func (m *mockEmailRepo) SetOTP(adminID int32, purpose, otp string, expiresAt int64, msg mailer.Message) error {
+	args := m.Called(adminID, purpose, otp, expiresAt, msg)
+	return args.Error(0)
+}
+
+func (m *mockEmailRepo) GetOTP(adminID int32, purpose string) (entity.UserOTP, error) {
+	args := m.Called(adminID, purpose)
+	return args.Get(0).(entity.UserOTP), args.Error(1)
+}
+
+func (m *mockEmailRepo) ConsumeOTP(adminID int32, purpose string) error {
+	args := m.Called(adminID, purpose)
+	return args.Error(0)
+}
+
+func (m *mockEmailRepo) UseAttempt(adminID int32, purpose string, maxAttempts int, now int64) (entity.UserOTP, error) {
+	args := m.Called(adminID, purpose, maxAttempts, now)
+	return args.Get(0).(entity.UserOTP), args.Error(1)
+}
+
+func (m *mockEmailRepo) LockOTP(adminID int32, purpose string, lockedUntil int64) error {
+	args := m.Called(adminID, purpose, lockedUntil)
+	return args.Error(0)
+}
+
+func (m *mockEmailRepo) HitIPEvent(ip, kind string, since int64) (int, error) {
+	args := m.Called(ip, kind, since)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockEmailRepo) ClaimOutbox(limit int, lease time.Duration) ([]entity.OutboxEmail, error) {
+	args := m.Called(limit, lease)
+	return args.Get(0).([]entity.OutboxEmail), args.Error(1)
+}
+
+func (m *mockEmailRepo) MarkOutboxSent(id int32) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *mockEmailRepo) MarkOutboxFailed(id int32, attempts int, lastError string, nextAttemptAt int64, dead bool) error {
+	args := m.Called(id, attempts, lastError, nextAttemptAt, dead)
+	return args.Error(0)
+}
+
+func (m *mockEmailRepo) ListOutbox(filter map[string]string) ([]entity.OutboxEmail, error) {
+	args := m.Called(filter)
+	return args.Get(0).([]entity.OutboxEmail), args.Error(1)
+}
+
+func (m *mockEmailRepo) RetryOutbox(id int32) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *mockEmailRepo) PurgeOutbox(before int64) (int64, error) {
+	args := m.Called(before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// mockUserRepo and mockTokenRepo only stub what the email service uses.
+type mockUserRepo struct {
+	mock.Mock
+	userRepo.UserRepository
+}
+
+func (m *mockUserRepo) GetIDByEmail(email string) (int32, error) {
+	args := m.Called(email)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (m *mockUserRepo) UpdatePassword(id int32, password string) error {
+	args := m.Called(id, password)
+	return args.Error(0)
+}
+
+type mockTokenRepo struct {
+	mock.Mock
+	userRepo.TokenRepository
+}
+
+func (m *mockTokenRepo) RevokeUserRefreshTokens(userID int32) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+type mockOTP struct {
+	mock.Mock
+}
+
+func (m *mockOTP) GenerateOTP() (string, error) {
+	args := m.Called()
+	return args.String(0), args.Error(1)
+}
+
+func (m *mockOTP) HashOTP(otp string) string {
+	return "hash:" + otp
+}
+
+func (m *mockOTP) VerifyOTP(hash, otp string) bool {
+	return hash == "hash:"+otp
+}
+
+type mockMailer struct {
+	mock.Mock
+}
+
+func (m *mockMailer) Send(msg mailer.Message) error {
+	args := m.Called(msg)
+	return args.Error(0)
+}
+
+// allowIP lets every per-IP limit pass.
+func allowIP(repo *mockEmailRepo) {
+	repo.On("HitIPEvent", mock.Anything, mock.Anything, mock.Anything).Return(1, nil).Maybe()
+}
+
+func TestForgotPassword(t *testing.T) {
+	emailRepo, users, otp := new(mockEmailRepo), new(mockUserRepo), new(mockOTP)
+	service := NewEmailService(emailRepo, users, new(mockTokenRepo), otp)
+	allowIP(emailRepo)
+
+	users.On("GetIDByEmail", "john@example.com").Return(int32(4), nil)
+	emailRepo.On("GetOTP", int32(4), entity.PurposePasswordReset).Return(entity.UserOTP{}, app.NewAppError(404, "OTP tidak ditemukan"))
+	otp.On("GenerateOTP").Return("123456", nil)
+	emailRepo.On("SetOTP", int32(4), entity.PurposePasswordReset, "hash:123456", mock.Anything, mock.MatchedBy(func(msg mailer.Message) bool {
+		return msg.To == "john@example.com" && msg.Subject == "Reset your Misterblast password" &&
+			strings.Contains(msg.Text, "123456") && strings.Contains(msg.HTML, "123456")
+	})).Return(nil)
+
+	err := service.ForgotPassword("john@example.com", mailer.LangEN, "10.0.0.1")
+	assert.NoError(t, err)
+	emailRepo.AssertExpectations(t)
+	emailRepo.AssertCalled(t, "HitIPEvent", "10.0.0.1", entity.IPEventSend, mock.Anything)
+}
+
+func TestForgotPassword_UnknownEmail(t *testing.T) {
+	emailRepo, users := new(mockEmailRepo), new(mockUserRepo)
+	service := NewEmailService(emailRepo, users, new(mockTokenRepo), new(mockOTP))
+	allowIP(emailRepo)
+
+	users.On("GetIDByEmail", "ghost@example.com").Return(int32(0), app.NewAppError(404, "user not found"))
+
+	err := service.ForgotPassword("ghost@example.com", mailer.LangID, "10.0.0.1")
+	assert.NoError(t, err)
+	emailRepo.AssertNotCalled(t, "SetOTP", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSendOTP_ResendCooldown(t *testing.T) {
+	emailRepo, users, otp := new(mockEmailRepo), new(mockUserRepo), new(mockOTP)
+	service := NewEmailService(emailRepo, users, new(mockTokenRepo), otp)
+	allowIP(emailRepo)
+
+	users.On("GetIDByEmail", "admin@example.com").Return(int32(4), nil)
+	emailRepo.On("GetOTP", int32(4), entity.PurposeActivation).Return(entity.UserOTP{
+		CodeHash: "hash:111111", SentAt: time.Now().Add(-10 * time.Second).Unix(), ExpiresAt: time.Now().Add(time.Minute).Unix(),
+	}, nil)
+
+	err := service.SendOTP("admin@example.com", mailer.LangID, "10.0.0.1")
+	assert.Error(t, err)
+	assert.Equal(t, 429, err.(*app.AppError).Code)
+	otp.AssertNotCalled(t, "GenerateOTP")
+}
+
+func TestSendOTP_IPLimit(t *testing.T) {
+	emailRepo, users := new(mockEmailRepo), new(mockUserRepo)
+	service := NewEmailService(emailRepo, users, new(mockTokenRepo), new(mockOTP))
+
+	emailRepo.On("HitIPEvent", "10.0.0.1", entity.IPEventSend, mock.Anything).Return(ipMaxSends+1, nil)
+
+	err := service.SendOTP("admin@example.com", mailer.LangID, "10.0.0.1")
+	assert.Error(t, err)
+	assert.Equal(t, "Terlalu banyak percobaan, coba lagi nanti", err.Error())
+	users.AssertNotCalled(t, "GetIDByEmail", mock.Anything)
+}
+
+func TestResetPassword(t *testing.T) {
+	emailRepo, users, tokens := new(mockEmailRepo), new(mockUserRepo), new(mockTokenRepo)
+	service := NewEmailService(emailRepo, users, tokens, new(mockOTP))
+	allowIP(emailRepo)
+
+	reset := entity.ResetPassword{Email: "john@example.com", OTP: "123456", Password: "newpassword"}
+	users.On("GetIDByEmail", reset.Email).Return(int32(4), nil)
+	emailRepo.On("GetOTP", int32(4), entity.PurposePasswordReset).
+		Return(entity.UserOTP{CodeHash: "hash:123456", ExpiresAt: time.Now().Add(time.Minute).Unix()}, nil)
+	emailRepo.On("UseAttempt", int32(4), entity.PurposePasswordReset, otpMaxAttempts, mock.Anything).
+		Return(entity.UserOTP{CodeHash: "hash:123456", ExpiresAt: time.Now().Add(time.Minute).Unix(), Attempts: 1}, nil)
+	emailRepo.On("ConsumeOTP", int32(4), entity.PurposePasswordReset).Return(nil)
+	users.On("UpdatePassword", int32(4), "newpassword").Return(nil)
+	tokens.On("RevokeUserRefreshTokens", int32(4)).Return(nil)
+
+	err := service.ResetPassword(reset, "10.0.0.1")
+	assert.NoError(t, err)
+	emailRepo.AssertExpectations(t)
+	users.AssertExpectations(t)
+	tokens.AssertExpectations(t)
+}
+
+func TestResetPassword_RejectedOTP(t *testing.T) {
+	usedAt := time.Now().Unix()
+	lockedUntil := time.Now().Add(time.Minute).Unix()
+	valid := time.Now().Add(time.Minute).Unix()
+	tests := []struct {
+		name   string
+		stored entity.UserOTP
+		errMsg string
+	}{
+		{"expired", entity.UserOTP{CodeHash: "hash:123456", ExpiresAt: time.Now().Add(-time.Minute).Unix()}, "OTP sudah kedaluwarsa"},
+		{"already used", entity.UserOTP{CodeHash: "hash:123456", ExpiresAt: valid, UsedAt: &usedAt}, "OTP sudah digunakan"},
+		{"burned", entity.UserOTP{CodeHash: "hash:123456", ExpiresAt: valid, Attempts: otpMaxAttempts}, "OTP tidak berlaku, silakan minta OTP baru"},
+		{"locked", entity.UserOTP{CodeHash: "hash:123456", ExpiresAt: valid, LockedUntil: &lockedUntil}, "Terlalu banyak percobaan OTP, coba lagi dalam 1 menit"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			emailRepo, users := new(mockEmailRepo), new(mockUserRepo)
+			service := NewEmailService(emailRepo, users, new(mockTokenRepo), new(mockOTP))
+			allowIP(emailRepo)
+
+			reset := entity.ResetPassword{Email: "john@example.com", OTP: "123456", Password: "newpassword"}
+			users.On("GetIDByEmail", reset.Email).Return(int32(4), nil)
+			emailRepo.On("GetOTP", int32(4), entity.PurposePasswordReset).Return(tc.stored, nil)
+			emailRepo.On("UseAttempt", int32(4), entity.PurposePasswordReset, otpMaxAttempts, mock.Anything).Return(tc.stored, nil).Maybe()
+
+			err := service.ResetPassword(reset, "10.0.0.1")
+			assert.Error(t, err)
+			assert.Equal(t, tc.errMsg, err.Error())
+			users.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestResetPassword_WrongCodeLocksAfterMaxAttempts(t *testing.T) {
+	tests := []struct {
+		name     string
+		attempts int
+		code     int
+	}{
+		{"counts failure", 2, 400},
+		{"locks account", otpMaxAttempts, 429},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			emailRepo, users := new(mockEmailRepo), new(mockUserRepo)
+			service := NewEmailService(emailRepo, users, new(mockTokenRepo), new(mockOTP))
+			allowIP(emailRepo)
+
+			reset := entity.ResetPassword{Email: "john@example.com", OTP: "000000", Password: "newpassword"}
+			users.On("GetIDByEmail", reset.Email).Return(int32(4), nil)
+			emailRepo.On("GetOTP", int32(4), entity.PurposePasswordReset).
+				Return(entity.UserOTP{CodeHash: "hash:123456", ExpiresAt: time.Now().Add(time.Minute).Unix()}, nil)
+			emailRepo.On("UseAttempt", int32(4), entity.PurposePasswordReset, otpMaxAttempts, mock.Anything).
+				Return(entity.UserOTP{CodeHash: "hash:123456", ExpiresAt: time.Now().Add(time.Minute).Unix(), Attempts: tc.attempts}, nil)
+			emailRepo.On("LockOTP", int32(4), entity.PurposePasswordReset, mock.Anything).Return(nil).Maybe()
+
+			err := service.ResetPassword(reset, "10.0.0.1")
+			assert.Error(t, err)
+			assert.Equal(t, tc.code, err.(*app.AppError).Code)
+			emailRepo.AssertCalled(t, "HitIPEvent", "10.0.0.1", entity.IPEventVerify, mock.Anything)
+			emailRepo.AssertNotCalled(t, "ConsumeOTP", mock.Anything, mock.Anything)
+			if tc.code == 429 {
+				emailRepo.AssertCalled(t, "LockOTP", int32(4), entity.PurposePasswordReset, mock.Anything)
+			} else {
+				emailRepo.AssertNotCalled(t, "LockOTP", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestResetPassword_ConcurrentGuessPastLimit(t *testing.T) {
+	emailRepo, users := new(mockEmailRepo), new(mockUserRepo)
+	service := NewEmailService(emailRepo, users, new(mockTokenRepo), new(mockOTP))
+	allowIP(emailRepo)
+
+	// The lookup still shows attempts left, but concurrent guesses used them
+	// up before this one could claim its try.
+	reset := entity.ResetPassword{Email: "john@example.com", OTP: "123456", Password: "newpassword"}
+	users.On("GetIDByEmail", reset.Email).Return(int32(4), nil)
+	emailRepo.On("GetOTP", int32(4), entity.PurposePasswordReset).
+		Return(entity.UserOTP{CodeHash: "hash:123456", ExpiresAt: time.Now().Add(time.Minute).Unix(), Attempts: otpMaxAttempts - 1}, nil)
+	emailRepo.On("UseAttempt", int32(4), entity.PurposePasswordReset, otpMaxAttempts, mock.Anything).
+		Return(entity.UserOTP{}, app.NewAppError(400, "OTP tidak berlaku, silakan minta OTP baru"))
+
+	err := service.ResetPassword(reset, "10.0.0.1")
+	assert.EqualError(t, err, "OTP tidak berlaku, silakan minta OTP baru")
+	emailRepo.AssertNotCalled(t, "ConsumeOTP", mock.Anything, mock.Anything)
+	users.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything)
+}
+
+func TestSendOTP_IPLimitCountsFirst(t *testing.T) {
+	emailRepo, users := new(mockEmailRepo), new(mockUserRepo)
+	service := NewEmailService(emailRepo, users, new(mockTokenRepo), new(mockOTP))
+
+	// The request that reaches the limit still passes; the next one does not.
+	emailRepo.On("HitIPEvent", "10.0.0.1", entity.IPEventSend, mock.Anything).Return(ipMaxSends, nil).Once()
+	users.On("GetIDByEmail", "ghost@example.com").Return(int32(0), app.NewAppError(404, "user not found"))
+	assert.NoError(t, service.ForgotPassword("ghost@example.com", mailer.LangID, "10.0.0.1"))
+
+	emailRepo.On("HitIPEvent", "10.0.0.1", entity.IPEventSend, mock.Anything).Return(ipMaxSends+1, nil).Once()
+	err := service.ForgotPassword("ghost@example.com", mailer.LangID, "10.0.0.1")
+	assert.Equal(t, 429, err.(*app.AppError).Code)
+}
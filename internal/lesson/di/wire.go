@@ -6,15 +6,16 @@ import (
 	lessonHandler "github.com/ghulammuzz/misterblast/internal/lesson/handler"
 	lessonRepo "github.com/ghulammuzz/misterblast/internal/lesson/repo"
 	lessonSvc "github.com/ghulammuzz/misterblast/internal/lesson/svc"
+	"github.com/ghulammuzz/misterblast/pkg/cache"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/wire"
 )
 
-func InitializedLessonServiceFake(sb *sql.DB, val *validator.Validate) *lessonHandler.LessonHandler {
+func InitializedLessonServiceFake(sb *sql.DB, val *validator.Validate, c cache.Cache) *lessonHandler.LessonHandler {
 	wire.Build(
 		lessonHandler.NewLessonHandler,
 		lessonSvc.NewLessonService,
-		lessonRepo.NewLessonRepository,
+		lessonRepo.NewCachedLessonRepository,
 	)
 
 	return &lessonHandler.LessonHandler{}
@@ -11,13 +11,14 @@ import (
 	"github.com/ghulammuzz/misterblast/internal/lesson/handler"
 	"github.com/ghulammuzz/misterblast/internal/lesson/repo"
 	"github.com/ghulammuzz/misterblast/internal/lesson/svc"
+	"github.com/ghulammuzz/misterblast/pkg/cache"
 	"github.com/go-playground/validator/v10"
 )
 
 // Injectors from wire.go:
 
-func InitializedLessonService(sb *sql.DB, val *validator.Validate) *handler.LessonHandler {
-	lessonRepository := repo.NewLessonRepository(sb)
+func InitializedLessonService(sb *sql.DB, val *validator.Validate, c cache.Cache) *handler.LessonHandler {
+	lessonRepository := repo.NewCachedLessonRepository(sb, c)
 	lessonService := svc.NewLessonService(lessonRepository)
 	lessonHandler := handler.NewLessonHandler(lessonService, val)
 	return lessonHandler
@@ -5,6 +5,7 @@ import (
 	"github.com/ghulammuzz/misterblast/internal/lesson/svc"
 	"github.com/ghulammuzz/misterblast/pkg/app"
 	"github.com/ghulammuzz/misterblast/pkg/log"
+	"github.com/ghulammuzz/misterblast/pkg/middleware"
 	"github.com/ghulammuzz/misterblast/pkg/response"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
@@ -20,8 +21,11 @@ func NewLessonHandler(lessonService svc.LessonService, val *validator.Validate)
 }
 
 func (h *LessonHandler) Router(r fiber.Router) {
-	r.Post("/lesson", h.AddLessonHandler)
-	r.Delete("/lesson/:id", h.DeleteLessonHandler)
+	auth := middleware.JWTProtected()
+	admin := middleware.RequireRole(middleware.AdminRoles...)
+
+	r.Post("/lesson", auth, admin, h.AddLessonHandler)
+	r.Delete("/lesson/:id", auth, admin, h.DeleteLessonHandler)
 	r.Get("/lesson", h.ListLessonsHandler)
 }
 
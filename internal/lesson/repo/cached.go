@@ -0,0 +1,53 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/ghulammuzz/misterblast/internal/lesson/entity"
+	"github.com/ghulammuzz/misterblast/pkg/cache"
+)
+
+type cachedLessonRepository struct {
+	repo    LessonRepository
+	lessons *cache.Namespace
+	// sets and questions are listed with their lesson name.
+	dependents []*cache.Namespace
+}
+
+// NewCachedLessonRepository caches lesson reads in c and drops them, along with
+// the set and question reads that embed lesson data, on every write.
+func NewCachedLessonRepository(db *sql.DB, c cache.Cache) LessonRepository {
+	return &cachedLessonRepository{
+		repo:    NewLessonRepository(db),
+		lessons: cache.NewNamespace(c, cache.NamespaceLesson, cache.DefaultTTL),
+		dependents: []*cache.Namespace{
+			cache.NewNamespace(c, cache.NamespaceSet, cache.DefaultTTL),
+			cache.NewNamespace(c, cache.NamespaceQuestion, cache.DefaultTTL),
+		},
+	}
+}
+
+func (r *cachedLessonRepository) Add(lesson entity.Lesson) error {
+	if err := r.repo.Add(lesson); err != nil {
+		return err
+	}
+	r.invalidate()
+	return nil
+}
+
+func (r *cachedLessonRepository) Delete(id int32) error {
+	if err := r.repo.Delete(id); err != nil {
+		return err
+	}
+	r.invalidate()
+	return nil
+}
+
+func (r *cachedLessonRepository) List() ([]entity.Lesson, error) {
+	return cache.Remember(context.Background(), r.lessons, "list", r.repo.List)
+}
+
+func (r *cachedLessonRepository) invalidate() {
+	cache.Invalidate(context.Background(), append([]*cache.Namespace{r.lessons}, r.dependents...)...)
+}
@@ -0,0 +1,22 @@
+package di
+
+import (
+	"database/sql"
+
+	mediaHandler "github.com/ghulammuzz/misterblast/internal/media/handler"
+	mediaRepo "github.com/ghulammuzz/misterblast/internal/media/repo"
+	mediaSvc "github.com/ghulammuzz/misterblast/internal/media/svc"
+	"github.com/ghulammuzz/misterblast/pkg/storage"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/wire"
+)
+
+func InitializedMediaServiceFake(sb *sql.DB, val *validator.Validate, store storage.Storage) *mediaHandler.MediaHandler {
+	wire.Build(
+		mediaHandler.NewMediaHandler,
+		mediaSvc.NewMediaService,
+		mediaRepo.NewMediaRepository,
+	)
+
+	return &mediaHandler.MediaHandler{}
+}
@@ -0,0 +1,37 @@
+package entity
+
+// Purposes of an upload, deciding who may upload it and how it is sized.
+const (
+	PurposeAvatar   = "avatar"
+	PurposeQuestion = "question"
+	PurposeAnswer   = "answer"
+)
+
+const (
+	MaxAvatarBytes = 2 << 20
+	MaxImageBytes  = 5 << 20
+
+	// Images wider or taller than these are scaled down before storing.
+	MaxAvatarDimension = 512
+	MaxImageDimension  = 1600
+	ThumbnailDimension = 256
+)
+
+type Media struct {
+	ID          int32  `json:"id"`
+	UserID      int32  `json:"user_id"`
+	Purpose     string `json:"purpose"`
+	URL         string `json:"url"`
+	ThumbURL    string `json:"thumb_url"`
+	Key         string `json:"-"`
+	ThumbKey    string `json:"-"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	CreatedAt   int64  `json:"created_at"`
+}
+
+type UploadMedia struct {
+	Purpose string `json:"purpose" form:"purpose" validate:"required,oneof=avatar question answer"`
+}
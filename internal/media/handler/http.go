@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"io"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ghulammuzz/misterblast/internal/media/entity"
+	"github.com/ghulammuzz/misterblast/internal/media/svc"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/log"
+	"github.com/ghulammuzz/misterblast/pkg/middleware"
+	"github.com/ghulammuzz/misterblast/pkg/response"
+)
+
+type MediaHandler struct {
+	mediaService svc.MediaService
+	val          *validator.Validate
+}
+
+func NewMediaHandler(mediaService svc.MediaService, val *validator.Validate) *MediaHandler {
+	return &MediaHandler{mediaService, val}
+}
+
+func (h *MediaHandler) Router(r fiber.Router) {
+	auth := middleware.JWTProtected()
+	student := middleware.RequireRole(middleware.AllRoles...)
+	uploadLimit := middleware.RateLimit(middleware.RateLimitConfig{Name: "media-upload", Limit: 30, Window: 10 * time.Minute})
+
+	r.Post("/media", auth, student, uploadLimit, h.UploadMediaHandler)
+	r.Delete("/media/:id", auth, student, h.DeleteMediaHandler)
+}
+
+// UploadMediaHandler stores an image sent as the multipart field "file" and
+// returns its URL and thumbnail URL, ready to be set as the img_url of a user,
+// question or answer. Question and answer images are for staff only.
+func (h *MediaHandler) UploadMediaHandler(c *fiber.Ctx) error {
+	userID, ok := middleware.UserID(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	upload := entity.UploadMedia{Purpose: c.FormValue("purpose")}
+	if err := h.val.Struct(upload); err != nil {
+		validationErrors := app.ValidationErrorResponse(err)
+		return response.SendError(c, fiber.StatusBadRequest, "Validation failed", validationErrors)
+	}
+	if upload.Purpose != entity.PurposeAvatar && !middleware.IsStaff(c) {
+		return response.SendError(c, fiber.StatusForbidden, "Forbidden", "insufficient role")
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "file is required", nil)
+	}
+	maxBytes, _ := svc.Limits(upload.Purpose)
+	if header.Size > int64(maxBytes) {
+		return response.SendError(c, fiber.StatusRequestEntityTooLarge, "file is too large", nil)
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		log.Error("[Handler][UploadMedia] Error Open: ", err)
+		return response.SendError(c, fiber.StatusBadRequest, "failed to read file", nil)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, int64(maxBytes)+1))
+	if err != nil {
+		log.Error("[Handler][UploadMedia] Error Read: ", err)
+		return response.SendError(c, fiber.StatusBadRequest, "failed to read file", nil)
+	}
+
+	media, err := h.mediaService.Upload(c.UserContext(), userID, upload.Purpose, data)
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "media uploaded successfully", media)
+}
+
+func (h *MediaHandler) DeleteMediaHandler(c *fiber.Ctx) error {
+	userID, ok := middleware.UserID(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid media ID", nil)
+	}
+
+	if err := h.mediaService.Delete(c.UserContext(), userID, middleware.IsAdmin(c), int32(id)); err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "media deleted successfully", nil)
+}
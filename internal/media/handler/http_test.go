@@ -0,0 +1,119 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	mediaEntity "github.com/ghulammuzz/misterblast/internal/media/entity"
+	"github.com/ghulammuzz/misterblast/internal/media/handler"
+	"github.com/ghulammuzz/misterblast/pkg/middleware"
+)
+
+type MockMediaService struct {
+	mock.Mock
+}
+
+func (m *MockMediaService) Upload(ctx context.Context, userID int32, purpose string, data []byte) (mediaEntity.Media, error) {
+	args := m.Called(userID, purpose, data)
+	return args.Get(0).(mediaEntity.Media), args.Error(1)
+}
+
+func (m *MockMediaService) Delete(ctx context.Context, userID int32, isAdmin bool, id int32) error {
+	args := m.Called(userID, isAdmin, id)
+	return args.Error(0)
+}
+
+func signedToken(userID int, role string) string {
+	claims := jwt.MapClaims{
+		"apps":    "misterblast-core",
+		"email":   "john@example.com",
+		"user_id": userID,
+		"role":    role,
+		"jti":     "jti-test",
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, _ := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+	return signed
+}
+
+func uploadRequest(t *testing.T, purpose string, data []byte, token string) *http.Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	assert.NoError(t, writer.WriteField("purpose", purpose))
+	part, err := writer.CreateFormFile("file", "image.png")
+	assert.NoError(t, err)
+	part.Write(data)
+	assert.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/media", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req
+}
+
+func newApp(service *MockMediaService) *fiber.App {
+	app := fiber.New()
+	h := handler.NewMediaHandler(service, validator.New())
+	app.Post("/media", middleware.JWTProtected(), h.UploadMediaHandler)
+	app.Delete("/media/:id", middleware.JWTProtected(), h.DeleteMediaHandler)
+	return app
+}
+
+func TestUploadMediaHandler(t *testing.T) {
+	mockService := new(MockMediaService)
+	app := newApp(mockService)
+
+	mockService.On("Upload", int32(1), "avatar", []byte("png")).
+		Return(mediaEntity.Media{ID: 3, URL: "/media/avatar/a.png", ThumbURL: "/media/avatar/a_thumb.png"}, nil)
+
+	resp, _ := app.Test(uploadRequest(t, "avatar", []byte("png"), signedToken(1, middleware.RoleStudent)))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
+func TestUploadMediaHandler_QuestionImageNeedsAdmin(t *testing.T) {
+	mockService := new(MockMediaService)
+	app := newApp(mockService)
+
+	resp, _ := app.Test(uploadRequest(t, "question", []byte("png"), signedToken(1, middleware.RoleStudent)))
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp, _ = app.Test(uploadRequest(t, "banner", []byte("png"), signedToken(1, middleware.RoleTeacher)))
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	mockService.AssertNotCalled(t, "Upload", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUploadMediaHandler_TooLarge(t *testing.T) {
+	mockService := new(MockMediaService)
+	app := newApp(mockService)
+
+	resp, _ := app.Test(uploadRequest(t, "avatar", make([]byte, mediaEntity.MaxAvatarBytes+1), signedToken(1, middleware.RoleStudent)))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	mockService.AssertNotCalled(t, "Upload", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDeleteMediaHandler(t *testing.T) {
+	mockService := new(MockMediaService)
+	app := newApp(mockService)
+
+	mockService.On("Delete", int32(1), true, int32(3)).Return(nil)
+
+	req := httptest.NewRequest(http.MethodDelete, "/media/3", nil)
+	req.Header.Set("Authorization", "Bearer "+signedToken(1, middleware.RoleSchoolAdmin))
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,71 @@
+package repo
+
+import (
+	"database/sql"
+	"time"
+
+	mediaEntity "github.com/ghulammuzz/misterblast/internal/media/entity"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/log"
+)
+
+type MediaRepository interface {
+	Add(media mediaEntity.Media) (mediaEntity.Media, error)
+	Detail(id int32) (mediaEntity.Media, error)
+	Delete(id int32) error
+}
+
+type mediaRepository struct {
+	db *sql.DB
+}
+
+func NewMediaRepository(db *sql.DB) MediaRepository {
+	return &mediaRepository{db: db}
+}
+
+func (r *mediaRepository) Add(media mediaEntity.Media) (mediaEntity.Media, error) {
+	media.CreatedAt = time.Now().Unix()
+
+	query := `INSERT INTO media (user_id, purpose, storage_key, thumb_key, url, thumb_url, content_type, size, width, height, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id`
+	err := r.db.QueryRow(query, media.UserID, media.Purpose, media.Key, media.ThumbKey, media.URL, media.ThumbURL,
+		media.ContentType, media.Size, media.Width, media.Height, media.CreatedAt).Scan(&media.ID)
+	if err != nil {
+		log.Error("[Repo][AddMedia] Error QueryRow: ", err)
+		return media, app.NewAppError(500, "failed to save media")
+	}
+
+	return media, nil
+}
+
+func (r *mediaRepository) Detail(id int32) (mediaEntity.Media, error) {
+	query := `SELECT id, COALESCE(user_id, 0), purpose, storage_key, thumb_key, url, thumb_url, content_type, size, width, height, created_at
+		FROM media WHERE id = $1`
+	var media mediaEntity.Media
+	err := r.db.QueryRow(query, id).Scan(&media.ID, &media.UserID, &media.Purpose, &media.Key, &media.ThumbKey, &media.URL,
+		&media.ThumbURL, &media.ContentType, &media.Size, &media.Width, &media.Height, &media.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return media, app.NewAppError(404, "media not found")
+		}
+		log.Error("[Repo][DetailMedia] Error QueryRow: ", err)
+		return media, app.NewAppError(500, "failed to fetch media")
+	}
+
+	return media, nil
+}
+
+func (r *mediaRepository) Delete(id int32) error {
+	res, err := r.db.Exec(`DELETE FROM media WHERE id = $1`, id)
+	if err != nil {
+		log.Error("[Repo][DeleteMedia] Error Exec: ", err)
+		return app.NewAppError(500, "failed to delete media")
+	}
+
+	rowsAffected, _ := res.RowsAffected()
+	if rowsAffected == 0 {
+		return app.NewAppError(404, "media not found")
+	}
+
+	return nil
+}
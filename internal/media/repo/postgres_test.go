@@ -0,0 +1,46 @@
+package repo_test
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	mediaEntity "github.com/ghulammuzz/misterblast/internal/media/entity"
+	"github.com/ghulammuzz/misterblast/internal/media/repo"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+)
+
+func TestAddMedia(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewMediaRepository(db)
+	media := mediaEntity.Media{UserID: 1, Purpose: "avatar", Key: "avatar/a.png", ThumbKey: "avatar/a_thumb.png",
+		URL: "/media/avatar/a.png", ThumbURL: "/media/avatar/a_thumb.png", ContentType: "image/png", Size: 10, Width: 4, Height: 2}
+
+	mock.ExpectQuery(`INSERT INTO media`).
+		WithArgs(1, "avatar", "avatar/a.png", "avatar/a_thumb.png", "/media/avatar/a.png", "/media/avatar/a_thumb.png",
+			"image/png", 10, 4, 2, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+
+	saved, err := repository.Add(media)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(5), saved.ID)
+	assert.NotZero(t, saved.CreatedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteMedia_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewMediaRepository(db)
+	mock.ExpectExec(`DELETE FROM media WHERE id = \$1`).WithArgs(9).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repository.Delete(9)
+	assert.Equal(t, 404, err.(*app.AppError).Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,140 @@
+package svc
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/webp"
+
+	mediaEntity "github.com/ghulammuzz/misterblast/internal/media/entity"
+)
+
+// maxPixels rejects images whose header promises more pixels than we are
+// willing to decode, so a tiny file cannot exhaust memory.
+const maxPixels = 40_000_000
+
+type encodedImage struct {
+	data        []byte
+	contentType string
+	ext         string
+	width       int
+	height      int
+}
+
+var errUnsupportedType = fmt.Errorf("unsupported image type")
+
+// processImage sniffs data, scales it down to fit maxDimension and renders a
+// thumbnail. Images already within bounds are stored as uploaded, which keeps
+// animated GIFs intact.
+func processImage(data []byte, maxDimension int) (encodedImage, encodedImage, error) {
+	contentType := http.DetectContentType(data)
+
+	var decode func([]byte) (image.Image, error)
+	var decodeConfig func([]byte) (image.Config, error)
+	switch contentType {
+	case "image/jpeg":
+		decode = func(b []byte) (image.Image, error) { return jpeg.Decode(bytes.NewReader(b)) }
+		decodeConfig = func(b []byte) (image.Config, error) { return jpeg.DecodeConfig(bytes.NewReader(b)) }
+	case "image/png":
+		decode = func(b []byte) (image.Image, error) { return png.Decode(bytes.NewReader(b)) }
+		decodeConfig = func(b []byte) (image.Config, error) { return png.DecodeConfig(bytes.NewReader(b)) }
+	case "image/gif":
+		decode = func(b []byte) (image.Image, error) { return gif.Decode(bytes.NewReader(b)) }
+		decodeConfig = func(b []byte) (image.Config, error) { return gif.DecodeConfig(bytes.NewReader(b)) }
+	case "image/webp":
+		decode = func(b []byte) (image.Image, error) { return webp.Decode(bytes.NewReader(b)) }
+		decodeConfig = func(b []byte) (image.Config, error) { return webp.DecodeConfig(bytes.NewReader(b)) }
+	default:
+		return encodedImage{}, encodedImage{}, errUnsupportedType
+	}
+
+	cfg, err := decodeConfig(data)
+	if err != nil {
+		return encodedImage{}, encodedImage{}, err
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxPixels {
+		return encodedImage{}, encodedImage{}, fmt.Errorf("image is %dx%d pixels", cfg.Width, cfg.Height)
+	}
+
+	img, err := decode(data)
+	if err != nil {
+		return encodedImage{}, encodedImage{}, err
+	}
+
+	// Lossy sources stay JPEG; anything that may carry transparency is PNG.
+	lossy := contentType == "image/jpeg" || contentType == "image/webp"
+
+	original := encodedImage{data: data, contentType: contentType, ext: extension(contentType),
+		width: cfg.Width, height: cfg.Height}
+	if cfg.Width > maxDimension || cfg.Height > maxDimension {
+		if original, err = encode(scale(img, maxDimension), lossy); err != nil {
+			return encodedImage{}, encodedImage{}, err
+		}
+	}
+
+	thumb, err := encode(scale(img, mediaEntity.ThumbnailDimension), lossy)
+	if err != nil {
+		return encodedImage{}, encodedImage{}, err
+	}
+
+	return original, thumb, nil
+}
+
+// scale fits img into a square of size pixels, keeping its aspect ratio. It
+// never enlarges.
+func scale(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w <= size && h <= size {
+		return img
+	}
+
+	if w >= h {
+		h, w = max(1, h*size/w), size
+	} else {
+		w, h = max(1, w*size/h), size
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Over, nil)
+	return dst
+}
+
+func encode(img image.Image, lossy bool) (encodedImage, error) {
+	var buf bytes.Buffer
+	contentType := "image/png"
+	var err error
+	if lossy {
+		contentType = "image/jpeg"
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85})
+	} else {
+		err = png.Encode(&buf, img)
+	}
+	if err != nil {
+		return encodedImage{}, err
+	}
+
+	bounds := img.Bounds()
+	return encodedImage{data: buf.Bytes(), contentType: contentType, ext: extension(contentType),
+		width: bounds.Dx(), height: bounds.Dy()}, nil
+}
+
+func extension(contentType string) string {
+	switch contentType {
+	case "image/jpeg":
+		return "jpg"
+	case "image/png":
+		return "png"
+	case "image/gif":
+		return "gif"
+	case "image/webp":
+		return "webp"
+	}
+	return "bin"
+}
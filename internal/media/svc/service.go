@@ -0,0 +1,125 @@
+package svc
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	mediaEntity "github.com/ghulammuzz/misterblast/internal/media/entity"
+	mediaRepo "github.com/ghulammuzz/misterblast/internal/media/repo"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/log"
+	"github.com/ghulammuzz/misterblast/pkg/storage"
+)
+
+type MediaService interface {
+	Upload(ctx context.Context, userID int32, purpose string, data []byte) (mediaEntity.Media, error)
+	Delete(ctx context.Context, userID int32, isAdmin bool, id int32) error
+}
+
+type mediaService struct {
+	repo  mediaRepo.MediaRepository
+	store storage.Storage
+}
+
+func NewMediaService(repo mediaRepo.MediaRepository, store storage.Storage) MediaService {
+	return &mediaService{repo: repo, store: store}
+}
+
+// Limits returns the size limit and the largest stored dimension of a purpose.
+func Limits(purpose string) (int, int) {
+	if purpose == mediaEntity.PurposeAvatar {
+		return mediaEntity.MaxAvatarBytes, mediaEntity.MaxAvatarDimension
+	}
+	return mediaEntity.MaxImageBytes, mediaEntity.MaxImageDimension
+}
+
+func (s *mediaService) Upload(ctx context.Context, userID int32, purpose string, data []byte) (mediaEntity.Media, error) {
+	maxBytes, maxDimension := Limits(purpose)
+	if len(data) > maxBytes {
+		return mediaEntity.Media{}, app.NewAppError(413, fmt.Sprintf("file is larger than %d MB", maxBytes>>20))
+	}
+
+	original, thumb, err := processImage(data, maxDimension)
+	if err == errUnsupportedType {
+		return mediaEntity.Media{}, app.NewAppError(415, "only JPEG, PNG, GIF and WebP images are supported")
+	}
+	if err != nil {
+		log.Error("[Svc][UploadMedia] Error Process Image: ", err)
+		return mediaEntity.Media{}, app.NewAppError(400, "invalid image")
+	}
+
+	name, err := randomName()
+	if err != nil {
+		log.Error("[Svc][UploadMedia] Error Random Name: ", err)
+		return mediaEntity.Media{}, app.ErrInternal
+	}
+	prefix := purpose + "/" + time.Now().Format("2006/01") + "/" + name
+
+	media := mediaEntity.Media{
+		UserID:      userID,
+		Purpose:     purpose,
+		Key:         prefix + "." + original.ext,
+		ThumbKey:    prefix + "_thumb." + thumb.ext,
+		ContentType: original.contentType,
+		Size:        int64(len(original.data)),
+		Width:       original.width,
+		Height:      original.height,
+	}
+
+	if media.URL, err = s.store.Put(ctx, media.Key, bytes.NewReader(original.data), original.contentType); err != nil {
+		log.Error("[Svc][UploadMedia] Error Put: ", err)
+		return mediaEntity.Media{}, app.NewAppError(502, "failed to store file")
+	}
+	if media.ThumbURL, err = s.store.Put(ctx, media.ThumbKey, bytes.NewReader(thumb.data), thumb.contentType); err != nil {
+		log.Error("[Svc][UploadMedia] Error Put Thumbnail: ", err)
+		s.remove(ctx, media.Key)
+		return mediaEntity.Media{}, app.NewAppError(502, "failed to store file")
+	}
+
+	saved, err := s.repo.Add(media)
+	if err != nil {
+		s.remove(ctx, media.Key, media.ThumbKey)
+		return mediaEntity.Media{}, err
+	}
+
+	return saved, nil
+}
+
+// Delete removes an upload. Only its uploader or an admin may delete it.
+func (s *mediaService) Delete(ctx context.Context, userID int32, isAdmin bool, id int32) error {
+	media, err := s.repo.Detail(id)
+	if err != nil {
+		return err
+	}
+	if !isAdmin && media.UserID != userID {
+		return app.NewAppError(403, "access restricted to own uploads")
+	}
+
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	s.remove(ctx, media.Key, media.ThumbKey)
+	return nil
+}
+
+// remove deletes stored objects on a best effort basis; an orphaned object is
+// only wasted space.
+func (s *mediaService) remove(ctx context.Context, keys ...string) {
+	for _, key := range keys {
+		if err := s.store.Delete(ctx, key); err != nil {
+			log.Error("[Svc][Media] Error Delete "+key+": ", err)
+		}
+	}
+}
+
+func randomName() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
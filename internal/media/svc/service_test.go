@@ -0,0 +1,108 @@
+package svc_test
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	mediaEntity "github.com/ghulammuzz/misterblast/internal/media/entity"
+	"github.com/ghulammuzz/misterblast/internal/media/svc"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/storage"
+)
+
+type MockMediaRepo struct {
+	mock.Mock
+}
+
+func (m *MockMediaRepo) Add(media mediaEntity.Media) (mediaEntity.Media, error) {
+	args := m.Called(media)
+	return args.Get(0).(mediaEntity.Media), args.Error(1)
+}
+
+func (m *MockMediaRepo) Detail(id int32) (mediaEntity.Media, error) {
+	args := m.Called(id)
+	return args.Get(0).(mediaEntity.Media), args.Error(1)
+}
+
+func (m *MockMediaRepo) Delete(id int32) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func pngImage(t *testing.T, w, h int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for x := 0; x < w; x++ {
+		img.Set(x, x%h, color.RGBA{R: 200, A: 255})
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestUploadMedia(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := storage.NewLocal(dir, "/media")
+	mockRepo := new(MockMediaRepo)
+	service := svc.NewMediaService(mockRepo, store)
+
+	var media mediaEntity.Media
+	mockRepo.On("Add", mock.MatchedBy(func(m mediaEntity.Media) bool {
+		return m.UserID == 1 && m.Purpose == "avatar" && m.Width == 512 && m.Height == 256 && m.ContentType == "image/png"
+	})).Run(func(args mock.Arguments) { media = args.Get(0).(mediaEntity.Media) }).Return(mediaEntity.Media{ID: 7}, nil)
+
+	saved, err := service.Upload(context.Background(), 1, mediaEntity.PurposeAvatar, pngImage(t, 1024, 512))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(7), saved.ID)
+	assert.Regexp(t, `^/media/avatar/\d{4}/\d{2}/[0-9a-f]{32}\.png$`, media.URL)
+
+	thumb, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(media.ThumbKey)))
+	assert.NoError(t, err)
+	cfg, err := png.DecodeConfig(bytes.NewReader(thumb))
+	assert.NoError(t, err)
+	assert.Equal(t, mediaEntity.ThumbnailDimension, cfg.Width)
+	assert.Equal(t, mediaEntity.ThumbnailDimension/2, cfg.Height)
+}
+
+func TestUploadMedia_Rejected(t *testing.T) {
+	store, _ := storage.NewLocal(t.TempDir(), "/media")
+	mockRepo := new(MockMediaRepo)
+	service := svc.NewMediaService(mockRepo, store)
+
+	_, err := service.Upload(context.Background(), 1, mediaEntity.PurposeQuestion, []byte("%PDF-1.4 not an image"))
+	assert.Equal(t, 415, err.(*app.AppError).Code)
+
+	_, err = service.Upload(context.Background(), 1, mediaEntity.PurposeAvatar, make([]byte, mediaEntity.MaxAvatarBytes+1))
+	assert.Equal(t, 413, err.(*app.AppError).Code)
+
+	_, err = service.Upload(context.Background(), 1, mediaEntity.PurposeQuestion, pngImage(t, 4, 4)[:40])
+	assert.Equal(t, 400, err.(*app.AppError).Code)
+	mockRepo.AssertNotCalled(t, "Add", mock.Anything)
+}
+
+func TestDeleteMedia(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := storage.NewLocal(dir, "/media")
+	mockRepo := new(MockMediaRepo)
+	service := svc.NewMediaService(mockRepo, store)
+
+	store.Put(context.Background(), "avatar/a.png", bytes.NewReader([]byte("png")), "image/png")
+	mockRepo.On("Detail", int32(3)).Return(mediaEntity.Media{ID: 3, UserID: 1, Key: "avatar/a.png", ThumbKey: "avatar/a_thumb.png"}, nil)
+	mockRepo.On("Delete", int32(3)).Return(nil)
+
+	err := service.Delete(context.Background(), 2, false, 3)
+	assert.Equal(t, 403, err.(*app.AppError).Code)
+
+	assert.NoError(t, service.Delete(context.Background(), 1, false, 3))
+	_, err = os.Stat(filepath.Join(dir, "avatar", "a.png"))
+	assert.True(t, os.IsNotExist(err))
+	mockRepo.AssertNumberOfCalls(t, "Delete", 1)
+}
@@ -6,15 +6,17 @@ import (
 	questionHandler "github.com/ghulammuzz/misterblast/internal/question/handler"
 	questionRepo "github.com/ghulammuzz/misterblast/internal/question/repo"
 	questionSvc "github.com/ghulammuzz/misterblast/internal/question/svc"
+	"github.com/ghulammuzz/misterblast/pkg/cache"
+	"github.com/ghulammuzz/misterblast/pkg/storage"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/wire"
 )
 
-func InitializedQuestionServiceFake(sb *sql.DB, val *validator.Validate) *questionHandler.QuestionHandler {
+func InitializedQuestionServiceFake(sb *sql.DB, val *validator.Validate, c cache.Cache, store storage.Storage) *questionHandler.QuestionHandler {
 	wire.Build(
 		questionHandler.NewQuestionHandler,
 		questionSvc.NewQuestionService,
-		questionRepo.NewQuestionRepository,
+		questionRepo.NewCachedQuestionRepository,
 	)
 
 	return &questionHandler.QuestionHandler{}
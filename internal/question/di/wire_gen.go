@@ -11,14 +11,16 @@ import (
 	"github.com/ghulammuzz/misterblast/internal/question/handler"
 	"github.com/ghulammuzz/misterblast/internal/question/repo"
 	"github.com/ghulammuzz/misterblast/internal/question/svc"
+	"github.com/ghulammuzz/misterblast/pkg/cache"
+	"github.com/ghulammuzz/misterblast/pkg/storage"
 	"github.com/go-playground/validator/v10"
 )
 
 // Injectors from wire.go:
 
-func InitializedQuestionService(sb *sql.DB, val *validator.Validate) *handler.QuestionHandler {
-	questionRepository := repo.NewQuestionRepository(sb)
-	questionService := svc.NewQuestionService(questionRepository)
-	questionHandler := handler.NewQuestionHandler(questionService, val)
+func InitializedQuestionService(sb *sql.DB, val *validator.Validate, c cache.Cache, store storage.Storage) *handler.QuestionHandler {
+	questionRepository := repo.NewCachedQuestionRepository(sb, c)
+	questionService := svc.NewQuestionService(questionRepository, store)
+	questionHandler := handler.NewQuestionHandler(questionService, val, store)
 	return questionHandler
 }
@@ -1,10 +1,15 @@
 package entity
 
+// Answer is an option of a question. Matching options pair their content with
+// MatchText; ordering options carry their place in the sequence and fill_blank
+// options the number of the blank they fill as Position.
 type Answer struct {
 	ID         int32   `json:"id"`
 	QuestionID int32   `json:"question_id" validate:"required"`
-	Code       string  `json:"code" validate:"required,oneof=a b c d esay"`
+	Code       string  `json:"code" validate:"required,alphanum,max=10"`
 	Content    string  `json:"content" validate:"required"`
 	ImgURL     *string `json:"img_url,omitempty"`
 	IsAnswer   bool    `json:"is_answer"`
+	MatchText  *string `json:"match_text,omitempty" validate:"omitempty,max=500"`
+	Position   *int    `json:"position,omitempty" validate:"omitempty,min=1,max=50"`
 }
@@ -3,17 +3,21 @@ package entity
 type SetAnswer struct {
 	ID         int32   `json:"id"`
 	QuestionID int32   `json:"question_id" validate:"required"`
-	Code       string  `json:"code" validate:"required,oneof=a b c d esay"`
+	Code       string  `json:"code" validate:"required,alphanum,max=10"`
 	Content    string  `json:"content" validate:"required"`
 	ImgURL     *string `json:"img_url,omitempty"`
 	IsAnswer   bool    `json:"is_answer"`
+	MatchText  *string `json:"match_text,omitempty" validate:"omitempty,max=500"`
+	Position   *int    `json:"position,omitempty" validate:"omitempty,min=1,max=50"`
 }
 type EditAnswer struct {
 	QuestionID int32   `json:"question_id" validate:"required"`
-	Code       string  `json:"code" validate:"required,oneof=a b c d esay"`
+	Code       string  `json:"code" validate:"required,alphanum,max=10"`
 	Content    string  `json:"content" validate:"required"`
 	ImgURL     *string `json:"img_url,omitempty"`
 	IsAnswer   bool    `json:"is_answer"`
+	MatchText  *string `json:"match_text,omitempty" validate:"omitempty,max=500"`
+	Position   *int    `json:"position,omitempty" validate:"omitempty,min=1,max=50"`
 }
 
 type ListAnswer struct {
@@ -22,3 +26,15 @@ type ListAnswer struct {
 	Content string  `json:"content"`
 	ImgURL  *string `json:"img_url"`
 }
+
+// Answer returns the option as an Answer, for checking it against the rules
+// of its question kind.
+func (a SetAnswer) Answer() Answer {
+	return Answer{ID: a.ID, QuestionID: a.QuestionID, Code: a.Code, Content: a.Content, ImgURL: a.ImgURL,
+		IsAnswer: a.IsAnswer, MatchText: a.MatchText, Position: a.Position}
+}
+
+func (a EditAnswer) Answer(id int32) Answer {
+	return Answer{ID: id, QuestionID: a.QuestionID, Code: a.Code, Content: a.Content, ImgURL: a.ImgURL,
+		IsAnswer: a.IsAnswer, MatchText: a.MatchText, Position: a.Position}
+}
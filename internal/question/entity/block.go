@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	BlockText  = "text"
+	BlockImage = "image"
+	BlockLatex = "latex"
+	BlockAudio = "audio"
+)
+
+const MaxBlocks = 20
+
+// Block is one piece of a question stem. Text holds the plain text of text
+// blocks and the source of latex blocks; URL points at the file of image and
+// audio blocks, with Alt describing images.
+type Block struct {
+	Type string `json:"type" validate:"required,oneof=text image latex audio"`
+	Text string `json:"text,omitempty" validate:"max=5000"`
+	URL  string `json:"url,omitempty" validate:"max=2048"`
+	Alt  string `json:"alt,omitempty" validate:"max=300"`
+}
+
+// Blocks is the rich stem of a question, stored as JSONB. When present it is
+// what clients render, and the question content is its plain text summary.
+type Blocks []Block
+
+func (b Blocks) Value() (driver.Value, error) {
+	if b == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(b)
+}
+
+func (b *Blocks) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*b = Blocks{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, b)
+	case string:
+		return json.Unmarshal([]byte(v), b)
+	default:
+		return fmt.Errorf("cannot scan %T into Blocks", src)
+	}
+}
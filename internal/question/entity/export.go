@@ -0,0 +1,24 @@
+package entity
+
+const (
+	ExportCSV  = "csv"
+	ExportXLSX = "xlsx"
+	ExportJSON = "json"
+	ExportQTI  = "qti"
+)
+
+// ExportQuestion is a question with its set context and all answer options,
+// correct ones included.
+type ExportQuestion struct {
+	ID      int32    `json:"id"`
+	SetID   int32    `json:"set_id"`
+	SetName string   `json:"set"`
+	Lesson  string   `json:"lesson"`
+	Class   string   `json:"class"`
+	Number  int      `json:"number"`
+	Type    string   `json:"type"`
+	Kind    string   `json:"kind"`
+	Content string   `json:"content"`
+	IsQuiz  bool     `json:"is_quiz"`
+	Answers []Answer `json:"answers"`
+}
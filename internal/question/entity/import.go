@@ -0,0 +1,23 @@
+package entity
+
+// MaxImportRows caps a single spreadsheet import.
+const MaxImportRows = 1000
+
+// ImportQuestion is one spreadsheet row mapped to the question it creates and
+// its answer options. Row is the 1-based sheet row, header included.
+type ImportQuestion struct {
+	Row      int
+	Question SetQuestion
+	Answers  []SetAnswer
+}
+
+type ImportRowError struct {
+	Row    int               `json:"row"`
+	Errors map[string]string `json:"errors"`
+}
+
+type ImportResult struct {
+	Questions int              `json:"questions"`
+	Answers   int              `json:"answers"`
+	Errors    []ImportRowError `json:"errors,omitempty"`
+}
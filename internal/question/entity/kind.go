@@ -0,0 +1,84 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+)
+
+// choiceCodes are the option codes of each choice kind.
+var choiceCodes = map[string][]string{
+	KindSingle:    {"a", "b", "c", "d"},
+	KindMultiple:  {"a", "b", "c", "d"},
+	KindTrueFalse: {"a", "b"},
+}
+
+// CheckAnswers reports the first way the answer options of a question break
+// the rules of its kind. It only rejects what adding further options cannot
+// fix, so options can still be authored one at a time.
+func CheckAnswers(kind string, answers []Answer) error {
+	if kind == "" {
+		kind = KindSingle
+	}
+
+	codes := map[string]bool{}
+	positions := map[int]bool{}
+	correct := 0
+	for _, a := range answers {
+		if codes[a.Code] {
+			return fmt.Errorf("answer code %q is used twice", a.Code)
+		}
+		codes[a.Code] = true
+		if a.IsAnswer {
+			correct++
+		}
+
+		switch kind {
+		case KindSingle, KindMultiple, KindTrueFalse:
+			if !contains(choiceCodes[kind], a.Code) {
+				return fmt.Errorf("%s answers use the codes %s", kind, strings.Join(choiceCodes[kind], ", "))
+			}
+			if a.MatchText != nil || a.Position != nil {
+				return fmt.Errorf("%s answers take no match_text or position", kind)
+			}
+		case KindMatching:
+			if a.MatchText == nil || strings.TrimSpace(*a.MatchText) == "" {
+				return fmt.Errorf("matching answers require match_text")
+			}
+		case KindOrdering:
+			if a.Position == nil {
+				return fmt.Errorf("ordering answers require a position")
+			}
+			if positions[*a.Position] {
+				return fmt.Errorf("position %d is used twice", *a.Position)
+			}
+			positions[*a.Position] = true
+		case KindFillBlank:
+			if a.Position == nil {
+				return fmt.Errorf("fill_blank answers require the position of their blank")
+			}
+		case KindEssay:
+			if a.Code != "esay" {
+				return fmt.Errorf("essay answers use the code esay")
+			}
+		default:
+			return fmt.Errorf("unknown question kind %q", kind)
+		}
+	}
+
+	switch {
+	case (kind == KindSingle || kind == KindTrueFalse) && correct > 1:
+		return fmt.Errorf("%s questions have only one correct answer", kind)
+	case kind == KindEssay && len(answers) > 1:
+		return fmt.Errorf("essay questions have a single model answer")
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -1,10 +1,52 @@
 package entity
 
+// Question kinds decide how a question is answered and scored. They are
+// separate from Type, the cognitive level (C1-C6) a question targets.
+const (
+	// KindSingle has options a-d with exactly one correct.
+	KindSingle = "single"
+	// KindMultiple has options a-d, any number of them correct; the student
+	// must select exactly the correct ones.
+	KindMultiple = "multiple"
+	// KindTrueFalse has the two options a and b, one of them correct.
+	KindTrueFalse = "true_false"
+	// KindMatching pairs each option's content with its MatchText.
+	KindMatching = "matching"
+	// KindOrdering puts its options in the order of their Position.
+	KindOrdering = "ordering"
+	// KindFillBlank accepts, for each blank Position, any of the option
+	// contents given for it, ignoring case and surrounding spaces.
+	KindFillBlank = "fill_blank"
+	// KindEssay is graded by a teacher; its single option is a model answer.
+	KindEssay = "essay"
+)
+
+// IsChoiceKind reports whether kind is answered by picking options a-d. An
+// empty kind is single.
+func IsChoiceKind(kind string) bool {
+	return kind == "" || kind == KindSingle || kind == KindMultiple || kind == KindTrueFalse
+}
+
 type Question struct {
 	ID      int32  `json:"id"`
 	Number  int    `json:"number"`
 	Type    string `json:"type"`
+	Kind    string `json:"kind"`
 	Content string `json:"content"`
 	IsQuiz  bool   `json:"is_quiz"`
 	SetID   int32  `json:"set_id"`
 }
+
+// Ownership tells who may change a question, or add one to a set when
+// OwnerID is nil: admins, the question's owner, the owner of its set and,
+// once the set is shared, every teacher.
+type Ownership struct {
+	OwnerID    *int32
+	SetOwnerID *int32
+	SetShared  bool
+}
+
+func (o Ownership) CanEdit(userID int32, isAdmin bool) bool {
+	owns := func(ownerID *int32) bool { return ownerID != nil && *ownerID == userID }
+	return isAdmin || o.SetShared || owns(o.OwnerID) || owns(o.SetOwnerID)
+}
@@ -3,17 +3,21 @@ package entity
 type SetQuestion struct {
 	Number  int    `json:"number" validate:"required,min=1"`
 	Type    string `json:"type" validate:"required,oneof=C1 C2 C3 C4 C5 C6"`
-	Content string `json:"content" validate:"required"`
+	Kind    string `json:"kind" validate:"omitempty,oneof=single multiple true_false matching ordering fill_blank essay"`
+	Content string `json:"content" validate:"required_without=Blocks"`
 	IsQuiz  bool   `json:"is_quiz"`
 	SetID   int32  `json:"set_id" validate:"required"`
+	Blocks  Blocks `json:"blocks" validate:"omitempty,max=20,dive"`
 }
 
 type EditQuestion struct {
 	Number  int    `json:"number" validate:"required,min=1"`
 	Type    string `json:"type" validate:"required,oneof=C1 C2 C3 C4 C5 C6"`
-	Content string `json:"content" validate:"required"`
+	Kind    string `json:"kind" validate:"omitempty,oneof=single multiple true_false matching ordering fill_blank essay"`
+	Content string `json:"content" validate:"required_without=Blocks"`
 	IsQuiz  bool   `json:"is_quiz"`
 	SetID   int32  `json:"set_id" validate:"required"`
+	Blocks  Blocks `json:"blocks" validate:"omitempty,max=20,dive"`
 }
 
 type ListQuestionExample struct {
@@ -30,15 +34,35 @@ type DetailQuestionExample struct {
 	Type    string `json:"type"`
 	Content string `json:"content"`
 	SetID   int32  `json:"set_id"`
+	Blocks  Blocks `json:"blocks"`
 }
 
+// ListQuestionQuiz is a question as shown to a student. Matching questions
+// list the texts to pair their answers with in MatchOptions, sorted so they
+// reveal nothing; fill_blank questions hide their accepted answers and only
+// give the number of Blanks. Students only get it through their attempt,
+// laid out by ShuffleQuiz, never in the authored order.
 type ListQuestionQuiz struct {
-	ID      int32        `json:"id"`
-	Number  int          `json:"number"`
-	Type    string       `json:"type"`
-	Content string       `json:"content"`
-	SetID   int32        `json:"set_id"`
-	Answers []ListAnswer `json:"answers"`
+	ID           int32        `json:"id"`
+	Number       int          `json:"number"`
+	Type         string       `json:"type"`
+	Kind         string       `json:"kind"`
+	Content      string       `json:"content"`
+	SetID        int32        `json:"set_id"`
+	Blocks       Blocks       `json:"blocks"`
+	Answers      []ListAnswer `json:"answers"`
+	MatchOptions []string     `json:"match_options,omitempty"`
+	Blanks       int          `json:"blanks,omitempty"`
+}
+
+type ListQuestionKey struct {
+	ID      int32    `json:"id"`
+	Number  int      `json:"number"`
+	Type    string   `json:"type"`
+	Kind    string   `json:"kind"`
+	Content string   `json:"content"`
+	SetID   int32    `json:"set_id"`
+	Answers []Answer `json:"answers"`
 }
 
 type ListQuestionAdmin struct {
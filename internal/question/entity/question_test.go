@@ -1 +1,50 @@
 package entity_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ghulammuzz/misterblast/internal/question/entity"
+)
+
+func TestCheckAnswers(t *testing.T) {
+	text := func(s string) *string { return &s }
+	position := func(p int) *int { return &p }
+
+	tests := []struct {
+		name    string
+		kind    string
+		answers []entity.Answer
+		err     string
+	}{
+		{"single", entity.KindSingle, []entity.Answer{{Code: "a", IsAnswer: true}, {Code: "b"}}, ""},
+		{"default kind is single", "", []entity.Answer{{Code: "e"}}, "single answers use the codes a, b, c, d"},
+		{"single with two correct", entity.KindSingle, []entity.Answer{{Code: "a", IsAnswer: true}, {Code: "b", IsAnswer: true}},
+			"single questions have only one correct answer"},
+		{"multiple with two correct", entity.KindMultiple, []entity.Answer{{Code: "a", IsAnswer: true}, {Code: "c", IsAnswer: true}}, ""},
+		{"true_false third option", entity.KindTrueFalse, []entity.Answer{{Code: "a"}, {Code: "b"}, {Code: "c"}},
+			"true_false answers use the codes a, b"},
+		{"duplicate code", entity.KindMultiple, []entity.Answer{{Code: "a"}, {Code: "a"}}, `answer code "a" is used twice`},
+		{"matching", entity.KindMatching, []entity.Answer{{Code: "1", MatchText: text("Jakarta")}}, ""},
+		{"matching without pair", entity.KindMatching, []entity.Answer{{Code: "1", MatchText: text(" ")}}, "matching answers require match_text"},
+		{"ordering", entity.KindOrdering, []entity.Answer{{Code: "x", Position: position(2)}, {Code: "y", Position: position(1)}}, ""},
+		{"ordering duplicate position", entity.KindOrdering, []entity.Answer{{Code: "x", Position: position(1)}, {Code: "y", Position: position(1)}},
+			"position 1 is used twice"},
+		{"fill_blank alternatives", entity.KindFillBlank, []entity.Answer{{Code: "1a", Position: position(1)}, {Code: "1b", Position: position(1)}}, ""},
+		{"fill_blank without blank", entity.KindFillBlank, []entity.Answer{{Code: "1a"}}, "fill_blank answers require the position of their blank"},
+		{"essay", entity.KindEssay, []entity.Answer{{Code: "esay"}}, ""},
+		{"choice with position", entity.KindSingle, []entity.Answer{{Code: "a", Position: position(1)}}, "single answers take no match_text or position"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := entity.CheckAnswers(tt.kind, tt.answers)
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
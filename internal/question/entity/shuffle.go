@@ -0,0 +1,37 @@
+package entity
+
+import "math/rand"
+
+// ShuffleQuiz reorders quiz questions and their options with a generator
+// seeded by seed, so the same seed always gives the same layout. Option IDs
+// are kept, so answers are still graded against the original options; choice
+// options are only relabelled a, b, c... in their new order. Essay and
+// fill_blank questions have no options to shuffle.
+func ShuffleQuiz(questions []ListQuestionQuiz, seed int64, shuffleQuestions, shuffleOptions bool) {
+	rng := rand.New(rand.NewSource(seed))
+
+	if shuffleQuestions {
+		rng.Shuffle(len(questions), func(i, j int) { questions[i], questions[j] = questions[j], questions[i] })
+	}
+	if !shuffleOptions {
+		return
+	}
+
+	for i := range questions {
+		q := &questions[i]
+		switch q.Kind {
+		case KindEssay, KindFillBlank:
+			continue
+		}
+
+		rng.Shuffle(len(q.Answers), func(i, j int) { q.Answers[i], q.Answers[j] = q.Answers[j], q.Answers[i] })
+		rng.Shuffle(len(q.MatchOptions), func(i, j int) {
+			q.MatchOptions[i], q.MatchOptions[j] = q.MatchOptions[j], q.MatchOptions[i]
+		})
+		if IsChoiceKind(q.Kind) {
+			for j := range q.Answers {
+				q.Answers[j].Code = string(rune('a' + j))
+			}
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package entity
+
+// MaxWorksheetVariants bounds the shuffled variants of a printed set, one
+// letter each.
+const MaxWorksheetVariants = 26
+
+// Worksheet is a set laid out for printing. Variant 0 keeps the online order;
+// every other variant is a stable shuffle of questions and options, so the
+// worksheet and the answer key of one variant always agree.
+type Worksheet struct {
+	SetID     int32               `json:"set_id"`
+	Variant   int                 `json:"variant"`
+	Questions []WorksheetQuestion `json:"questions"`
+}
+
+// WorksheetQuestion is a question as printed. Matching questions print their
+// MatchOptions numbered next to the lettered options and fill_blank questions
+// a line for each of their Blanks.
+type WorksheetQuestion struct {
+	ID           int32             `json:"id"`
+	Number       int               `json:"number"`
+	Kind         string            `json:"kind"`
+	Content      string            `json:"content"`
+	Blocks       Blocks            `json:"blocks"`
+	Options      []WorksheetOption `json:"options"`
+	MatchOptions []string          `json:"match_options,omitempty"`
+	Blanks       int               `json:"blanks,omitempty"`
+}
+
+// WorksheetOption is an answer relabelled by its printed position. IsAnswer,
+// MatchText and Position are only filled for answer keys, which also list the
+// accepted answers of fill_blank questions labelled by their blank.
+type WorksheetOption struct {
+	Label     string  `json:"label"`
+	Content   string  `json:"content"`
+	ImgURL    *string `json:"img_url,omitempty"`
+	IsAnswer  bool    `json:"is_answer"`
+	MatchText *string `json:"match_text,omitempty"`
+	Position  *int    `json:"position,omitempty"`
+}
+
+// VariantLabel names a variant for print: "" for the original order, then A,
+// B, C...
+func VariantLabel(variant int) string {
+	if variant <= 0 || variant > MaxWorksheetVariants {
+		return ""
+	}
+	return string(rune('A' + variant - 1))
+}
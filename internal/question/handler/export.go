@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/xuri/excelize/v2"
+
+	"github.com/ghulammuzz/misterblast/internal/question/entity"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/log"
+	"github.com/ghulammuzz/misterblast/pkg/middleware"
+	"github.com/ghulammuzz/misterblast/pkg/response"
+)
+
+type exportFormat struct {
+	contentType string
+	extension   string
+	write       func(w io.Writer, questions []entity.ExportQuestion) error
+}
+
+var exportFormats = map[string]exportFormat{
+	entity.ExportCSV:  {"text/csv; charset=utf-8", "csv", writeExportCSV},
+	entity.ExportXLSX: {"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", "xlsx", writeExportXLSX},
+	entity.ExportJSON: {"application/json", "json", writeExportJSON},
+	entity.ExportQTI:  {"application/zip", "zip", writeExportQTI},
+}
+
+// ExportQuestionsHandler streams the questions of a set, lesson or class as a
+// file download. CSV and XLSX use the import column layout, so an export can
+// be edited and imported again.
+func (h *QuestionHandler) ExportQuestionsHandler(c *fiber.Ctx) error {
+	format, ok := exportFormats[strings.ToLower(c.Query("format", entity.ExportJSON))]
+	if !ok {
+		return response.SendError(c, fiber.StatusBadRequest, "format must be one of csv, xlsx, json, qti", nil)
+	}
+
+	filter := map[string]string{}
+	scope := []string{}
+	for _, key := range []string{"set_id", "lesson_id", "class_id"} {
+		value := c.Query(key)
+		if value == "" {
+			continue
+		}
+		if id, err := strconv.Atoi(value); err != nil || id <= 0 {
+			return response.SendError(c, fiber.StatusBadRequest, "invalid "+key, nil)
+		}
+		filter[key] = value
+		scope = append(scope, strings.TrimSuffix(key, "_id")+"-"+value)
+	}
+
+	questions, err := h.questionService.ExportQuestions(middleware.SchoolID(c), filter)
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	// Question text is stored escaped for the web; files carry it as typed.
+	for i := range questions {
+		questions[i].Content = html.UnescapeString(questions[i].Content)
+	}
+
+	filename := fmt.Sprintf("questions-%s.%s", strings.Join(scope, "-"), format.extension)
+	c.Set(fiber.HeaderContentType, format.contentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := format.write(w, questions); err != nil {
+			log.Error("[Handler][ExportQuestions] Error Write: ", err)
+			return
+		}
+		if err := w.Flush(); err != nil {
+			log.Error("[Handler][ExportQuestions] Error Flush: ", err)
+		}
+	})
+
+	return nil
+}
+
+// exportHeader mirrors the columns read by ImportQuestionsHandler, led by the
+// set context which the import ignores. Match texts and positions have no
+// column, so matching, ordering and fill_blank questions only round-trip
+// through the JSON and QTI formats.
+func exportHeader() []string {
+	header := []string{"set_id", "set", "lesson", "class", "number", "type", "kind", "content", "is_quiz"}
+	for _, code := range answerCodes {
+		header = append(header, "answer_"+code)
+	}
+	for _, code := range answerCodes {
+		header = append(header, "img_"+code)
+	}
+	return append(header, "correct")
+}
+
+func exportRow(q entity.ExportQuestion) []string {
+	answers := map[string]entity.Answer{}
+	var correct []string
+	for _, a := range q.Answers {
+		answers[a.Code] = a
+		if a.IsAnswer {
+			correct = append(correct, a.Code)
+		}
+	}
+
+	row := []string{strconv.Itoa(int(q.SetID)), q.SetName, q.Lesson, q.Class, strconv.Itoa(q.Number),
+		q.Type, q.Kind, q.Content, strconv.FormatBool(q.IsQuiz)}
+	for _, code := range answerCodes {
+		row = append(row, answers[code].Content)
+	}
+	for _, code := range answerCodes {
+		img := ""
+		if a, ok := answers[code]; ok && a.ImgURL != nil {
+			img = *a.ImgURL
+		}
+		row = append(row, img)
+	}
+	return append(row, strings.Join(correct, ","))
+}
+
+func writeExportCSV(w io.Writer, questions []entity.ExportQuestion) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(exportHeader()); err != nil {
+		return err
+	}
+	for _, q := range questions {
+		if err := writer.Write(exportRow(q)); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func writeExportXLSX(w io.Writer, questions []entity.ExportQuestion) error {
+	book := excelize.NewFile()
+	defer book.Close()
+
+	sheet := book.GetSheetName(0)
+	stream, err := book.NewStreamWriter(sheet)
+	if err != nil {
+		return err
+	}
+
+	for i, cells := range append([][]string{exportHeader()}, rowsOf(questions)...) {
+		values := make([]interface{}, len(cells))
+		for j, cell := range cells {
+			values[j] = cell
+		}
+		axis, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := stream.SetRow(axis, values); err != nil {
+			return err
+		}
+	}
+	if err := stream.Flush(); err != nil {
+		return err
+	}
+
+	return book.Write(w)
+}
+
+func rowsOf(questions []entity.ExportQuestion) [][]string {
+	rows := make([][]string, 0, len(questions))
+	for _, q := range questions {
+		rows = append(rows, exportRow(q))
+	}
+	return rows
+}
+
+func writeExportJSON(w io.Writer, questions []entity.ExportQuestion) error {
+	return json.NewEncoder(w).Encode(struct {
+		ExportedAt int64                   `json:"exported_at"`
+		Questions  []entity.ExportQuestion `json:"questions"`
+	}{time.Now().Unix(), questions})
+}
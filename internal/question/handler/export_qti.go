@@ -0,0 +1,278 @@
+package handler
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/ghulammuzz/misterblast/internal/question/entity"
+)
+
+// IMS QTI 2.1 content package: one assessmentItem file per question listed in
+// an imsmanifest.xml, which is what LMS importers expect.
+
+const (
+	qtiNamespace      = "http://www.imsglobal.org/xsd/imsqti_v2p1"
+	imscpNamespace    = "http://www.imsglobal.org/xsd/imscp_v1p1"
+	qtiMatchCorrect   = "http://www.imsglobal.org/question/qti_v2p1/rptemplates/match_correct"
+	qtiItemType       = "imsqti_item_xmlv2p1"
+	qtiResponseID     = "RESPONSE"
+	qtiScoreOutcomeID = "SCORE"
+)
+
+type qtiItem struct {
+	XMLName             xml.Name               `xml:"assessmentItem"`
+	Xmlns               string                 `xml:"xmlns,attr"`
+	Identifier          string                 `xml:"identifier,attr"`
+	Title               string                 `xml:"title,attr"`
+	Adaptive            bool                   `xml:"adaptive,attr"`
+	TimeDependent       bool                   `xml:"timeDependent,attr"`
+	ResponseDeclaration qtiResponseDeclaration `xml:"responseDeclaration"`
+	OutcomeDeclaration  qtiOutcomeDeclaration  `xml:"outcomeDeclaration"`
+	ItemBody            qtiItemBody            `xml:"itemBody"`
+	ResponseProcessing  *qtiResponseProcessing `xml:"responseProcessing,omitempty"`
+}
+
+type qtiResponseDeclaration struct {
+	Identifier      string              `xml:"identifier,attr"`
+	Cardinality     string              `xml:"cardinality,attr"`
+	BaseType        string              `xml:"baseType,attr"`
+	CorrectResponse *qtiCorrectResponse `xml:"correctResponse,omitempty"`
+}
+
+type qtiCorrectResponse struct {
+	Values []string `xml:"value"`
+}
+
+type qtiOutcomeDeclaration struct {
+	Identifier  string `xml:"identifier,attr"`
+	Cardinality string `xml:"cardinality,attr"`
+	BaseType    string `xml:"baseType,attr"`
+}
+
+type qtiItemBody struct {
+	Choice       *qtiChoiceInteraction       `xml:"choiceInteraction,omitempty"`
+	Order        *qtiOrderInteraction        `xml:"orderInteraction,omitempty"`
+	Match        *qtiMatchInteraction        `xml:"matchInteraction,omitempty"`
+	ExtendedText *qtiExtendedTextInteraction `xml:"extendedTextInteraction,omitempty"`
+}
+
+type qtiChoiceInteraction struct {
+	ResponseIdentifier string            `xml:"responseIdentifier,attr"`
+	Shuffle            bool              `xml:"shuffle,attr"`
+	MaxChoices         int               `xml:"maxChoices,attr"`
+	Prompt             string            `xml:"prompt"`
+	Choices            []qtiSimpleChoice `xml:"simpleChoice"`
+}
+
+type qtiOrderInteraction struct {
+	ResponseIdentifier string            `xml:"responseIdentifier,attr"`
+	Shuffle            bool              `xml:"shuffle,attr"`
+	Prompt             string            `xml:"prompt"`
+	Choices            []qtiSimpleChoice `xml:"simpleChoice"`
+}
+
+type qtiMatchInteraction struct {
+	ResponseIdentifier string               `xml:"responseIdentifier,attr"`
+	Shuffle            bool                 `xml:"shuffle,attr"`
+	MaxAssociations    int                  `xml:"maxAssociations,attr"`
+	Prompt             string               `xml:"prompt"`
+	Sets               [2]qtiSimpleMatchSet `xml:"simpleMatchSet"`
+}
+
+type qtiSimpleMatchSet struct {
+	Choices []qtiSimpleAssociableChoice `xml:"simpleAssociableChoice"`
+}
+
+type qtiSimpleAssociableChoice struct {
+	Identifier string `xml:"identifier,attr"`
+	MatchMax   int    `xml:"matchMax,attr"`
+	Text       string `xml:",chardata"`
+}
+
+type qtiSimpleChoice struct {
+	Identifier string  `xml:"identifier,attr"`
+	Text       string  `xml:",chardata"`
+	Img        *qtiImg `xml:"img,omitempty"`
+}
+
+type qtiImg struct {
+	Src string `xml:"src,attr"`
+	Alt string `xml:"alt,attr"`
+}
+
+type qtiExtendedTextInteraction struct {
+	ResponseIdentifier string `xml:"responseIdentifier,attr"`
+	Prompt             string `xml:"prompt"`
+}
+
+type qtiResponseProcessing struct {
+	Template string `xml:"template,attr"`
+}
+
+type qtiManifest struct {
+	XMLName       xml.Name      `xml:"manifest"`
+	Xmlns         string        `xml:"xmlns,attr"`
+	Identifier    string        `xml:"identifier,attr"`
+	Metadata      qtiMetadata   `xml:"metadata"`
+	Organizations struct{}      `xml:"organizations"`
+	Resources     []qtiResource `xml:"resources>resource"`
+}
+
+type qtiMetadata struct {
+	Schema        string `xml:"schema"`
+	SchemaVersion string `xml:"schemaversion"`
+}
+
+type qtiResource struct {
+	Identifier string `xml:"identifier,attr"`
+	Type       string `xml:"type,attr"`
+	Href       string `xml:"href,attr"`
+	File       struct {
+		Href string `xml:"href,attr"`
+	} `xml:"file"`
+}
+
+func writeExportQTI(w io.Writer, questions []entity.ExportQuestion) error {
+	archive := zip.NewWriter(w)
+	manifest := qtiManifest{
+		Xmlns:      imscpNamespace,
+		Identifier: "MANIFEST-misterblast",
+		Metadata:   qtiMetadata{Schema: "IMS Content", SchemaVersion: "1.1"},
+	}
+
+	for _, q := range questions {
+		item := qtiItemFor(q)
+		href := "items/" + item.Identifier + ".xml"
+		if err := writeXML(archive, href, item); err != nil {
+			return err
+		}
+
+		resource := qtiResource{Identifier: "RES-" + item.Identifier, Type: qtiItemType, Href: href}
+		resource.File.Href = href
+		manifest.Resources = append(manifest.Resources, resource)
+	}
+
+	if err := writeXML(archive, "imsmanifest.xml", manifest); err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+// qtiItemFor maps ordering and matching questions to an orderInteraction and
+// a matchInteraction, choice kinds to a choiceInteraction, all scored by
+// match_correct. A choiceInteraction allows several choices for multiple
+// questions or when more than one answer is correct. Essays and fill_blank
+// questions, whose accepted spellings QTI templates cannot express, become an
+// extendedTextInteraction left for manual scoring.
+func qtiItemFor(q entity.ExportQuestion) qtiItem {
+	item := qtiItem{
+		Xmlns:              qtiNamespace,
+		Identifier:         fmt.Sprintf("Q%d", q.ID),
+		Title:              fmt.Sprintf("%s - %d", q.SetName, q.Number),
+		OutcomeDeclaration: qtiOutcomeDeclaration{Identifier: qtiScoreOutcomeID, Cardinality: "single", BaseType: "float"},
+	}
+
+	switch q.Kind {
+	case entity.KindEssay, entity.KindFillBlank:
+		item.ResponseDeclaration = qtiResponseDeclaration{Identifier: qtiResponseID, Cardinality: "single", BaseType: "string"}
+		item.ItemBody.ExtendedText = &qtiExtendedTextInteraction{ResponseIdentifier: qtiResponseID, Prompt: q.Content}
+		return item
+	case entity.KindOrdering:
+		return qtiOrderItem(item, q)
+	case entity.KindMatching:
+		return qtiMatchItem(item, q)
+	}
+
+	choice := &qtiChoiceInteraction{ResponseIdentifier: qtiResponseID, Prompt: q.Content}
+	correct := &qtiCorrectResponse{}
+	for _, a := range q.Answers {
+		id := "CHOICE_" + strings.ToUpper(a.Code)
+		simple := qtiSimpleChoice{Identifier: id, Text: a.Content}
+		if a.ImgURL != nil {
+			simple.Img = &qtiImg{Src: *a.ImgURL, Alt: a.Content}
+		}
+		choice.Choices = append(choice.Choices, simple)
+		if a.IsAnswer {
+			correct.Values = append(correct.Values, id)
+		}
+	}
+
+	cardinality := "single"
+	choice.MaxChoices = 1
+	if q.Kind == entity.KindMultiple || len(correct.Values) > 1 {
+		cardinality = "multiple"
+		choice.MaxChoices = 0
+	}
+
+	item.ResponseDeclaration = qtiResponseDeclaration{Identifier: qtiResponseID, Cardinality: cardinality, BaseType: "identifier"}
+	if len(correct.Values) > 0 {
+		item.ResponseDeclaration.CorrectResponse = correct
+	}
+	item.ItemBody.Choice = choice
+	item.ResponseProcessing = &qtiResponseProcessing{Template: qtiMatchCorrect}
+	return item
+}
+
+func qtiOrderItem(item qtiItem, q entity.ExportQuestion) qtiItem {
+	order := &qtiOrderInteraction{ResponseIdentifier: qtiResponseID, Shuffle: true, Prompt: q.Content}
+	answers := append([]entity.Answer(nil), q.Answers...)
+	sort.SliceStable(answers, func(i, j int) bool { return position(answers[i]) < position(answers[j]) })
+
+	correct := &qtiCorrectResponse{}
+	for _, a := range answers {
+		id := "CHOICE_" + strings.ToUpper(a.Code)
+		order.Choices = append(order.Choices, qtiSimpleChoice{Identifier: id, Text: a.Content})
+		correct.Values = append(correct.Values, id)
+	}
+
+	item.ResponseDeclaration = qtiResponseDeclaration{Identifier: qtiResponseID, Cardinality: "ordered", BaseType: "identifier",
+		CorrectResponse: correct}
+	item.ItemBody.Order = order
+	item.ResponseProcessing = &qtiResponseProcessing{Template: qtiMatchCorrect}
+	return item
+}
+
+func qtiMatchItem(item qtiItem, q entity.ExportQuestion) qtiItem {
+	match := &qtiMatchInteraction{ResponseIdentifier: qtiResponseID, Shuffle: true, MaxAssociations: len(q.Answers), Prompt: q.Content}
+	correct := &qtiCorrectResponse{}
+	for _, a := range q.Answers {
+		code := strings.ToUpper(a.Code)
+		matchText := ""
+		if a.MatchText != nil {
+			matchText = *a.MatchText
+		}
+		match.Sets[0].Choices = append(match.Sets[0].Choices, qtiSimpleAssociableChoice{Identifier: "SOURCE_" + code, MatchMax: 1, Text: a.Content})
+		match.Sets[1].Choices = append(match.Sets[1].Choices, qtiSimpleAssociableChoice{Identifier: "TARGET_" + code, MatchMax: 1, Text: matchText})
+		correct.Values = append(correct.Values, "SOURCE_"+code+" TARGET_"+code)
+	}
+
+	item.ResponseDeclaration = qtiResponseDeclaration{Identifier: qtiResponseID, Cardinality: "multiple", BaseType: "directedPair",
+		CorrectResponse: correct}
+	item.ItemBody.Match = match
+	item.ResponseProcessing = &qtiResponseProcessing{Template: qtiMatchCorrect}
+	return item
+}
+
+func position(a entity.Answer) int {
+	if a.Position == nil {
+		return 0
+	}
+	return *a.Position
+}
+
+func writeXML(archive *zip.Writer, name string, v interface{}) error {
+	f, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(f, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(f)
+	encoder.Indent("", "  ")
+	return encoder.Encode(v)
+}
@@ -7,39 +7,55 @@ import (
 	"github.com/ghulammuzz/misterblast/internal/question/entity"
 	"github.com/ghulammuzz/misterblast/internal/question/svc"
 	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/middleware"
 	"github.com/ghulammuzz/misterblast/pkg/response"
+	"github.com/ghulammuzz/misterblast/pkg/storage"
 )
 
 type QuestionHandler struct {
 	questionService svc.QuestionService
 	val             *validator.Validate
+	store           storage.Storage
 }
 
-func NewQuestionHandler(questionService svc.QuestionService, val *validator.Validate) *QuestionHandler {
-	return &QuestionHandler{questionService, val}
+func NewQuestionHandler(questionService svc.QuestionService, val *validator.Validate, store storage.Storage) *QuestionHandler {
+	return &QuestionHandler{questionService, val, store}
 }
 
 func (h *QuestionHandler) Router(r fiber.Router) {
+	auth := middleware.JWTProtected()
+	staff := middleware.RequireRole(middleware.StaffRoles...)
+
 	// question
-	r.Post("/question", h.AddQuestionHandler)
-	r.Put("/question/:id", h.EditQuestionHandler)
-	r.Get("/question/:id", h.DetailQuestionsHandler)
-	r.Get("/question", h.ListQuestionsHandler)
-	r.Delete("/question/:id", h.DeleteQuestionHandler)
+	r.Post("/question", auth, staff, h.AddQuestionHandler)
+	r.Post("/question/import", auth, staff, h.ImportQuestionsHandler)
+	r.Get("/question/export", auth, staff, h.ExportQuestionsHandler)
+	r.Put("/question/:id", auth, staff, h.EditQuestionHandler)
+	r.Get("/question/:id", middleware.JWTOptional(), h.DetailQuestionsHandler)
+	r.Get("/question", middleware.JWTOptional(), h.ListQuestionsHandler)
+	r.Delete("/question/:id", auth, staff, h.DeleteQuestionHandler)
 
 	// answer
-	r.Delete("/answer/:id", h.DeleteAnswerHandler)
-	r.Put("/answer/:id", h.EditAnswerHandler)
-	r.Post("/quiz-answer", h.AddQuizAnswerHandler)
+	r.Delete("/answer/:id", auth, staff, h.DeleteAnswerHandler)
+	r.Put("/answer/:id", auth, staff, h.EditAnswerHandler)
+	r.Post("/quiz-answer", auth, staff, h.AddQuizAnswerHandler)
 
 	// quiz
-	r.Get("/quiz", h.ListQuizHandler)
+	r.Get("/quiz", auth, staff, h.ListQuizHandler)
+	r.Get("/quiz/worksheet", auth, staff, h.WorksheetPDFHandler)
+	r.Get("/quiz/worksheet/key", auth, staff, h.AnswerKeyPDFHandler)
 
 	// admin
-	r.Get("/admin-question", h.ListQuestionAdminHandler)
+	r.Get("/admin-question", auth, staff, h.ListQuestionAdminHandler)
+	r.Get("/answer-key", auth, staff, h.ListAnswerKeyHandler)
 }
 
 func (h *QuestionHandler) AddQuestionHandler(c *fiber.Ctx) error {
+	userID, ok := middleware.UserID(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
 	var question entity.SetQuestion
 
 	if err := c.BodyParser(&question); err != nil {
@@ -49,7 +65,7 @@ func (h *QuestionHandler) AddQuestionHandler(c *fiber.Ctx) error {
 	if err := h.val.Struct(question); err != nil {
 		return response.SendError(c, fiber.StatusBadRequest, "Validation failed", err.Error())
 	}
-	if err := h.questionService.AddQuestion(question); err != nil {
+	if err := h.questionService.AddQuestion(userID, middleware.IsAdmin(c), middleware.SchoolID(c), question); err != nil {
 		appErr, ok := err.(*app.AppError)
 		if !ok {
 			appErr = app.ErrInternal
@@ -66,7 +82,7 @@ func (h *QuestionHandler) ListQuestionsHandler(c *fiber.Ctx) error {
 		filter["set_id"] = setID
 	}
 
-	questions, err := h.questionService.ListQuestions(filter)
+	questions, err := h.questionService.ListQuestions(middleware.SchoolID(c), filter)
 	if err != nil {
 		appErr, ok := err.(*app.AppError)
 		if !ok {
@@ -84,7 +100,7 @@ func (h *QuestionHandler) DetailQuestionsHandler(c *fiber.Ctx) error {
 		return response.SendError(c, fiber.StatusBadRequest, "invalid question ID", nil)
 	}
 
-	question, err := h.questionService.DetailQuestion(int32(id))
+	question, err := h.questionService.DetailQuestion(middleware.SchoolID(c), int32(id))
 	if err != nil {
 		appErr, ok := err.(*app.AppError)
 		if !ok {
@@ -97,12 +113,17 @@ func (h *QuestionHandler) DetailQuestionsHandler(c *fiber.Ctx) error {
 }
 
 func (h *QuestionHandler) DeleteQuestionHandler(c *fiber.Ctx) error {
+	userID, ok := middleware.UserID(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
 	id, err := c.ParamsInt("id")
 	if err != nil || id <= 0 {
 		return response.SendError(c, fiber.StatusBadRequest, "invalid question ID", nil)
 	}
 
-	if err := h.questionService.DeleteQuestion(int32(id)); err != nil {
+	if err := h.questionService.DeleteQuestion(userID, middleware.IsAdmin(c), middleware.SchoolID(c), int32(id)); err != nil {
 		appErr, ok := err.(*app.AppError)
 		if !ok {
 			appErr = app.ErrInternal
@@ -116,6 +137,11 @@ func (h *QuestionHandler) DeleteQuestionHandler(c *fiber.Ctx) error {
 // Quiz Answer
 
 func (h *QuestionHandler) AddQuizAnswerHandler(c *fiber.Ctx) error {
+	userID, ok := middleware.UserID(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
 	var answer entity.SetAnswer
 
 	if err := c.BodyParser(&answer); err != nil {
@@ -125,7 +151,7 @@ func (h *QuestionHandler) AddQuizAnswerHandler(c *fiber.Ctx) error {
 	if err := h.val.Struct(answer); err != nil {
 		return response.SendError(c, fiber.StatusBadRequest, "Validation failed", err.Error())
 	}
-	if err := h.questionService.AddQuizAnswer(answer); err != nil {
+	if err := h.questionService.AddQuizAnswer(userID, middleware.IsAdmin(c), middleware.SchoolID(c), answer); err != nil {
 		appErr, ok := err.(*app.AppError)
 		if !ok {
 			appErr = app.ErrInternal
@@ -137,12 +163,17 @@ func (h *QuestionHandler) AddQuizAnswerHandler(c *fiber.Ctx) error {
 }
 
 func (h *QuestionHandler) DeleteAnswerHandler(c *fiber.Ctx) error {
+	userID, ok := middleware.UserID(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
 	id, err := c.ParamsInt("id")
 	if err != nil || id <= 0 {
 		return response.SendError(c, fiber.StatusBadRequest, "invalid answer ID", nil)
 	}
 
-	if err := h.questionService.DeleteAnswer(int32(id)); err != nil {
+	if err := h.questionService.DeleteAnswer(userID, middleware.IsAdmin(c), middleware.SchoolID(c), int32(id)); err != nil {
 		appErr, ok := err.(*app.AppError)
 		if !ok {
 			appErr = app.ErrInternal
@@ -155,6 +186,9 @@ func (h *QuestionHandler) DeleteAnswerHandler(c *fiber.Ctx) error {
 
 // Quiz
 
+// ListQuizHandler lets staff preview the quiz questions of a set. It ignores
+// the set's window and time limit, so students get questions through their
+// attempt instead.
 func (h *QuestionHandler) ListQuizHandler(c *fiber.Ctx) error {
 	filter := map[string]string{}
 	if c.Query("set_id") != "" {
@@ -167,7 +201,7 @@ func (h *QuestionHandler) ListQuizHandler(c *fiber.Ctx) error {
 		filter["number"] = c.Query("number")
 	}
 
-	questions, err := h.questionService.ListQuizQuestions(filter)
+	questions, err := h.questionService.ListQuizQuestions(middleware.SchoolID(c), filter)
 	if err != nil {
 		appErr, ok := err.(*app.AppError)
 		if !ok {
@@ -198,7 +232,7 @@ func (h *QuestionHandler) ListQuestionAdminHandler(c *fiber.Ctx) error {
 	page := c.QueryInt("page", 1)
 	limit := c.QueryInt("limit", 10)
 
-	questions, err := h.questionService.ListAdmin(filter, page, limit)
+	questions, err := h.questionService.ListAdmin(middleware.SchoolID(c), filter, page, limit)
 	if err != nil {
 		appErr, ok := err.(*app.AppError)
 		if !ok {
@@ -210,7 +244,30 @@ func (h *QuestionHandler) ListQuestionAdminHandler(c *fiber.Ctx) error {
 	return response.SendSuccess(c, "questions admin retrieved successfully", questions)
 }
 
+func (h *QuestionHandler) ListAnswerKeyHandler(c *fiber.Ctx) error {
+	setID := c.QueryInt("set_id")
+	if setID <= 0 {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid set ID", nil)
+	}
+
+	questions, err := h.questionService.ListAnswerKey(middleware.SchoolID(c), int32(setID))
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "answer key retrieved successfully", questions)
+}
+
 func (h *QuestionHandler) EditQuestionHandler(c *fiber.Ctx) error {
+	userID, ok := middleware.UserID(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
 	id, err := c.ParamsInt("id")
 	if err != nil || id <= 0 {
 		return response.SendError(c, fiber.StatusBadRequest, "invalid question ID", nil)
@@ -225,7 +282,7 @@ func (h *QuestionHandler) EditQuestionHandler(c *fiber.Ctx) error {
 		return response.SendError(c, fiber.StatusBadRequest, "Validation failed", err.Error())
 	}
 
-	if err := h.questionService.EditQuestion(int32(id), question); err != nil {
+	if err := h.questionService.EditQuestion(userID, middleware.IsAdmin(c), middleware.SchoolID(c), int32(id), question); err != nil {
 		appErr, ok := err.(*app.AppError)
 		if !ok {
 			appErr = app.ErrInternal
@@ -237,6 +294,11 @@ func (h *QuestionHandler) EditQuestionHandler(c *fiber.Ctx) error {
 }
 
 func (h *QuestionHandler) EditAnswerHandler(c *fiber.Ctx) error {
+	userID, ok := middleware.UserID(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
 	id, err := c.ParamsInt("id")
 	if err != nil || id <= 0 {
 		return response.SendError(c, fiber.StatusBadRequest, "invalid answer ID", nil)
@@ -251,7 +313,7 @@ func (h *QuestionHandler) EditAnswerHandler(c *fiber.Ctx) error {
 		return response.SendError(c, fiber.StatusBadRequest, "Validation failed", err.Error())
 	}
 
-	if err := h.questionService.EditQuizAnswer(int32(id), answer); err != nil {
+	if err := h.questionService.EditQuizAnswer(userID, middleware.IsAdmin(c), middleware.SchoolID(c), int32(id), answer); err != nil {
 		appErr, ok := err.(*app.AppError)
 		if !ok {
 			appErr = app.ErrInternal
@@ -1,18 +1,31 @@
 package handler_test
 
 import (
+	"archive/zip"
 	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/csv"
 	"encoding/json"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"testing"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/xuri/excelize/v2"
 
 	questionEntity "github.com/ghulammuzz/misterblast/internal/question/entity"
 	"github.com/ghulammuzz/misterblast/internal/question/handler"
+	apperr "github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/middleware"
+	"github.com/ghulammuzz/misterblast/pkg/storage"
 	"github.com/go-playground/validator/v10"
 )
 
@@ -20,72 +33,116 @@ type MockQuestionService struct {
 	mock.Mock
 }
 
-func (m *MockQuestionService) AddQuestion(question questionEntity.SetQuestion) error {
-	args := m.Called(question)
+func (m *MockQuestionService) AddQuestion(userID int32, isAdmin bool, schoolID *int32, question questionEntity.SetQuestion) error {
+	args := m.Called(userID, isAdmin, schoolID, question)
 	return args.Error(0)
 }
 
-func (m *MockQuestionService) EditQuestion(id int32, question questionEntity.EditQuestion) error {
-	args := m.Called(id, question)
+func (m *MockQuestionService) EditQuestion(userID int32, isAdmin bool, schoolID *int32, id int32, question questionEntity.EditQuestion) error {
+	args := m.Called(userID, isAdmin, schoolID, id, question)
 	return args.Error(0)
 }
 
-func (m *MockQuestionService) ListQuestions(filter map[string]string) ([]questionEntity.ListQuestionExample, error) {
-	args := m.Called(filter)
+func (m *MockQuestionService) ListQuestions(schoolID *int32, filter map[string]string) ([]questionEntity.ListQuestionExample, error) {
+	args := m.Called(schoolID, filter)
 	return args.Get(0).([]questionEntity.ListQuestionExample), args.Error(1)
 }
 
-func (m *MockQuestionService) DeleteQuestion(id int32) error {
-	args := m.Called(id)
+func (m *MockQuestionService) DeleteQuestion(userID int32, isAdmin bool, schoolID *int32, id int32) error {
+	args := m.Called(userID, isAdmin, schoolID, id)
 	return args.Error(0)
 }
 
-func (m *MockQuestionService) DeleteAnswer(id int32) error {
-	args := m.Called(id)
+func (m *MockQuestionService) DeleteAnswer(userID int32, isAdmin bool, schoolID *int32, id int32) error {
+	args := m.Called(userID, isAdmin, schoolID, id)
 	return args.Error(0)
 }
 
-func (m *MockQuestionService) AddQuizAnswer(answer questionEntity.SetAnswer) error {
-	args := m.Called(answer)
+func (m *MockQuestionService) AddQuizAnswer(userID int32, isAdmin bool, schoolID *int32, answer questionEntity.SetAnswer) error {
+	args := m.Called(userID, isAdmin, schoolID, answer)
 	return args.Error(0)
 }
 
-func (m *MockQuestionService) ListQuizQuestions(filter map[string]string) ([]questionEntity.ListQuestionQuiz, error) {
-	args := m.Called(filter)
+func (m *MockQuestionService) ListQuizQuestions(schoolID *int32, filter map[string]string) ([]questionEntity.ListQuestionQuiz, error) {
+	args := m.Called(schoolID, filter)
 	return args.Get(0).([]questionEntity.ListQuestionQuiz), args.Error(1)
 }
 
-func (m *MockQuestionService) ListAdmin(filter map[string]string, page, limit int) ([]questionEntity.ListQuestionAdmin, error) {
-	args := m.Called(filter, page, limit)
+func (m *MockQuestionService) ListAdmin(schoolID *int32, filter map[string]string, page, limit int) ([]questionEntity.ListQuestionAdmin, error) {
+	args := m.Called(schoolID, filter, page, limit)
 	return args.Get(0).([]questionEntity.ListQuestionAdmin), args.Error(1)
 }
 
-func (m *MockQuestionService) DetailQuestion(id int32) (questionEntity.DetailQuestionExample, error) {
-	args := m.Called(id)
+func (m *MockQuestionService) ListAnswerKey(schoolID *int32, setID int32) ([]questionEntity.ListQuestionKey, error) {
+	args := m.Called(schoolID, setID)
+	return args.Get(0).([]questionEntity.ListQuestionKey), args.Error(1)
+}
+
+func (m *MockQuestionService) ImportQuestions(userID int32, isAdmin bool, schoolID *int32, setID int32, questions []questionEntity.ImportQuestion) (questionEntity.ImportResult, error) {
+	args := m.Called(userID, isAdmin, schoolID, setID, questions)
+	return args.Get(0).(questionEntity.ImportResult), args.Error(1)
+}
+
+func (m *MockQuestionService) ExportQuestions(schoolID *int32, filter map[string]string) ([]questionEntity.ExportQuestion, error) {
+	args := m.Called(schoolID, filter)
+	return args.Get(0).([]questionEntity.ExportQuestion), args.Error(1)
+}
+
+func (m *MockQuestionService) Worksheet(schoolID *int32, setID int32, variant int, withKey bool) (questionEntity.Worksheet, error) {
+	args := m.Called(schoolID, setID, variant, withKey)
+	return args.Get(0).(questionEntity.Worksheet), args.Error(1)
+}
+
+func (m *MockQuestionService) DetailQuestion(schoolID *int32, id int32) (questionEntity.DetailQuestionExample, error) {
+	args := m.Called(schoolID, id)
 	return args.Get(0).(questionEntity.DetailQuestionExample), args.Error(1)
 }
 
-func (m *MockQuestionService) EditQuizAnswer(id int32, answer questionEntity.EditAnswer) error {
-	args := m.Called(id, answer)
+func (m *MockQuestionService) EditQuizAnswer(userID int32, isAdmin bool, schoolID *int32, id int32, answer questionEntity.EditAnswer) error {
+	args := m.Called(userID, isAdmin, schoolID, id, answer)
 	return args.Error(0)
 }
 
+func school(id int32) *int32 {
+	return &id
+}
+
+func signedToken(userID int, role string) string {
+	claims := jwt.MapClaims{
+		"apps":      "misterblast-core",
+		"email":     "john@example.com",
+		"user_id":   userID,
+		"role":      role,
+		"school_id": 1,
+		"jti":       "jti-test",
+		"exp":       time.Now().Add(time.Hour).Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, _ := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+	return signed
+}
+
+func asTeacher(req *http.Request) *http.Request {
+	req.Header.Set("Authorization", "Bearer "+signedToken(9, middleware.RoleTeacher))
+	return req
+}
+
 func TestAddQuestionHandler(t *testing.T) {
 	app := fiber.New()
 	mockService := new(MockQuestionService)
 	validate := validator.New()
-	handler := handler.NewQuestionHandler(mockService, validate)
-	app.Post("/question", handler.AddQuestionHandler)
+	handler := handler.NewQuestionHandler(mockService, validate, nil)
+	app.Post("/question", middleware.JWTProtected(), handler.AddQuestionHandler)
 
 	question := questionEntity.SetQuestion{SetID: 9, Number: 1, Type: "C4", Content: "Sample Question", IsQuiz: true}
 	questionJSON, _ := json.Marshal(question)
 
-	mockService.On("AddQuestion", question).Return(nil)
+	mockService.On("AddQuestion", int32(9), false, school(1), question).Return(nil)
 
 	req := httptest.NewRequest(http.MethodPost, "/question", bytes.NewReader(questionJSON))
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, _ := app.Test(req)
+	resp, _ := app.Test(asTeacher(req))
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 	mockService.AssertExpectations(t)
 }
@@ -94,18 +151,18 @@ func TestEditQuestionHandler(t *testing.T) {
 	app := fiber.New()
 	mockService := new(MockQuestionService)
 	validate := validator.New()
-	handler := handler.NewQuestionHandler(mockService, validate)
-	app.Put("/question/:id", handler.EditQuestionHandler)
+	handler := handler.NewQuestionHandler(mockService, validate, nil)
+	app.Put("/question/:id", middleware.JWTProtected(), handler.EditQuestionHandler)
 
 	editQuestion := questionEntity.EditQuestion{SetID: 9, Number: 2, Type: "C3", Content: "Updated Content", IsQuiz: false}
 	editJSON, _ := json.Marshal(editQuestion)
 
-	mockService.On("EditQuestion", int32(1), editQuestion).Return(nil)
+	mockService.On("EditQuestion", int32(9), false, school(1), int32(1), editQuestion).Return(nil)
 
 	req := httptest.NewRequest(http.MethodPut, "/question/1", bytes.NewReader(editJSON))
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, _ := app.Test(req)
+	resp, _ := app.Test(asTeacher(req))
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 	mockService.AssertExpectations(t)
 }
@@ -114,10 +171,10 @@ func TestListQuestionsHandler(t *testing.T) {
 	app := fiber.New()
 	mockService := new(MockQuestionService)
 	validate := validator.New()
-	handler := handler.NewQuestionHandler(mockService, validate)
+	handler := handler.NewQuestionHandler(mockService, validate, nil)
 	app.Get("/question", handler.ListQuestionsHandler)
 
-	mockService.On("ListQuestions", mock.Anything).Return([]questionEntity.ListQuestionExample{}, nil)
+	mockService.On("ListQuestions", school(0), mock.Anything).Return([]questionEntity.ListQuestionExample{}, nil)
 
 	req := httptest.NewRequest(http.MethodGet, "/question", nil)
 	resp, _ := app.Test(req)
@@ -130,10 +187,10 @@ func TestDetailQuestionsHandler(t *testing.T) {
 	app := fiber.New()
 	mockService := new(MockQuestionService)
 	validate := validator.New()
-	handler := handler.NewQuestionHandler(mockService, validate)
+	handler := handler.NewQuestionHandler(mockService, validate, nil)
 	app.Get("/question/:id", handler.DetailQuestionsHandler)
 
-	mockService.On("DetailQuestion", mock.Anything).Return(questionEntity.DetailQuestionExample{}, nil)
+	mockService.On("DetailQuestion", school(0), mock.Anything).Return(questionEntity.DetailQuestionExample{}, nil)
 
 	req := httptest.NewRequest(http.MethodGet, "/question/9", nil)
 	resp, _ := app.Test(req)
@@ -146,13 +203,13 @@ func TestDeleteQuestionHandler(t *testing.T) {
 	app := fiber.New()
 	mockService := new(MockQuestionService)
 	validate := validator.New()
-	handler := handler.NewQuestionHandler(mockService, validate)
-	app.Delete("/question/:id", handler.DeleteQuestionHandler)
+	handler := handler.NewQuestionHandler(mockService, validate, nil)
+	app.Delete("/question/:id", middleware.JWTProtected(), handler.DeleteQuestionHandler)
 
-	mockService.On("DeleteQuestion", int32(1)).Return(nil)
+	mockService.On("DeleteQuestion", int32(9), false, school(1), int32(1)).Return(nil)
 
 	req := httptest.NewRequest(http.MethodDelete, "/question/1", nil)
-	resp, _ := app.Test(req)
+	resp, _ := app.Test(asTeacher(req))
 
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 	mockService.AssertExpectations(t)
@@ -162,13 +219,13 @@ func TestDeleteAnswerHandler(t *testing.T) {
 	app := fiber.New()
 	mockService := new(MockQuestionService)
 	validate := validator.New()
-	handler := handler.NewQuestionHandler(mockService, validate)
-	app.Delete("/answer/:id", handler.DeleteAnswerHandler)
+	handler := handler.NewQuestionHandler(mockService, validate, nil)
+	app.Delete("/answer/:id", middleware.JWTProtected(), handler.DeleteAnswerHandler)
 
-	mockService.On("DeleteAnswer", int32(11)).Return(nil)
+	mockService.On("DeleteAnswer", int32(9), false, school(1), int32(11)).Return(nil)
 
 	req := httptest.NewRequest(http.MethodDelete, "/answer/11", nil)
-	resp, _ := app.Test(req)
+	resp, _ := app.Test(asTeacher(req))
 
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 	mockService.AssertExpectations(t)
@@ -178,8 +235,8 @@ func TestEditAnswerHandler(t *testing.T) {
 	app := fiber.New()
 	mockService := new(MockQuestionService)
 	validate := validator.New()
-	handler := handler.NewQuestionHandler(mockService, validate)
-	app.Put("/answer/:id", handler.EditAnswerHandler)
+	handler := handler.NewQuestionHandler(mockService, validate, nil)
+	app.Put("/answer/:id", middleware.JWTProtected(), handler.EditAnswerHandler)
 
 	editAnswer := questionEntity.EditAnswer{QuestionID: 8,
 		Code:     "a",
@@ -188,12 +245,363 @@ func TestEditAnswerHandler(t *testing.T) {
 		IsAnswer: true}
 	editJSON, _ := json.Marshal(editAnswer)
 
-	mockService.On("EditQuizAnswer", int32(1), editAnswer).Return(nil)
+	mockService.On("EditQuizAnswer", int32(9), false, school(1), int32(1), editAnswer).Return(nil)
 
 	req := httptest.NewRequest(http.MethodPut, "/answer/1", bytes.NewReader(editJSON))
 	req.Header.Set("Content-Type", "application/json")
 
+	resp, _ := app.Test(asTeacher(req))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
+func TestListAnswerKeyHandler(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockQuestionService)
+	validate := validator.New()
+	handler := handler.NewQuestionHandler(mockService, validate, nil)
+	app.Get("/answer-key", handler.ListAnswerKeyHandler)
+
+	mockService.On("ListAnswerKey", school(0), int32(3)).Return([]questionEntity.ListQuestionKey{}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/answer-key?set_id=3", nil)
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	mockService.AssertExpectations(t)
+
+	req = httptest.NewRequest(http.MethodGet, "/answer-key", nil)
+	resp, _ = app.Test(req)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func importRequest(t *testing.T, filename string, content []byte) *http.Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	assert.NoError(t, writer.WriteField("set_id", "3"))
+	part, err := writer.CreateFormFile("file", filename)
+	assert.NoError(t, err)
+	_, _ = part.Write(content)
+	assert.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/question/import", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return asTeacher(req)
+}
+
+func TestImportQuestionsHandler_CSV(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockQuestionService)
+	h := handler.NewQuestionHandler(mockService, validator.New(), nil)
+	app.Post("/question/import", middleware.JWTProtected(), h.ImportQuestionsHandler)
+
+	csv := "number,type,kind,content,is_quiz,answer_a,answer_b,correct\n" +
+		"1,C1,,2 + 2?,true,4,5,a\n" +
+		"2,C4,essay,Explain why,true,,,\n"
+
+	mockService.On("ImportQuestions", int32(9), false, school(1), int32(3), mock.MatchedBy(func(qs []questionEntity.ImportQuestion) bool {
+		return len(qs) == 2 && qs[0].Row == 2 && len(qs[0].Answers) == 2 && qs[0].Answers[0].IsAnswer &&
+			!qs[0].Answers[1].IsAnswer && qs[1].Question.Kind == questionEntity.KindEssay && len(qs[1].Answers) == 0
+	})).Return(questionEntity.ImportResult{Questions: 2, Answers: 2}, nil)
+
+	resp, _ := app.Test(importRequest(t, "bank.csv", []byte(csv)))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
+func TestImportQuestionsHandler_RowErrors(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockQuestionService)
+	h := handler.NewQuestionHandler(mockService, validator.New(), nil)
+	app.Post("/question/import", middleware.JWTProtected(), h.ImportQuestionsHandler)
+
+	csv := "number,type,content,answer_a,answer_b,correct\n" +
+		"1,C1,ok,4,5,a\n" +
+		"x,C9,,4,5,\n" +
+		"3,C1,no key,4,5,e\n"
+
+	resp, _ := app.Test(importRequest(t, "bank.csv", []byte(csv)))
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	var body struct {
+		Data []questionEntity.ImportRowError `json:"data"`
+	}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Len(t, body.Data, 2)
+	assert.Equal(t, 3, body.Data[0].Row)
+	assert.Equal(t, "number", body.Data[0].Errors["Number"])
+	assert.Equal(t, "oneof", body.Data[0].Errors["Type"])
+	assert.Equal(t, "answer not found", body.Data[1].Errors["Correct"])
+	mockService.AssertNotCalled(t, "ImportQuestions", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestImportQuestionsHandler_XLSX(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockQuestionService)
+	h := handler.NewQuestionHandler(mockService, validator.New(), nil)
+	app.Post("/question/import", middleware.JWTProtected(), h.ImportQuestionsHandler)
+
+	book := excelize.NewFile()
+	rows := [][]interface{}{
+		{"Number", "Type", "Content", "Answer_A", "Answer_B", "Correct"},
+		{1, "C2", "Ibu kota Indonesia?", "Jakarta", "Bandung", "a"},
+	}
+	for i, row := range rows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		assert.NoError(t, book.SetSheetRow("Sheet1", cell, &row))
+	}
+	var file bytes.Buffer
+	assert.NoError(t, book.Write(&file))
+
+	mockService.On("ImportQuestions", int32(9), false, school(1), int32(3), mock.MatchedBy(func(qs []questionEntity.ImportQuestion) bool {
+		return len(qs) == 1 && qs[0].Question.Number == 1 && qs[0].Answers[0].Content == "Jakarta"
+	})).Return(questionEntity.ImportResult{Questions: 1, Answers: 2}, nil)
+
+	resp, _ := app.Test(importRequest(t, "bank.xlsx", file.Bytes()))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
+func exportFixture() []questionEntity.ExportQuestion {
+	img := "http://img/b.png"
+	return []questionEntity.ExportQuestion{
+		{ID: 1, SetID: 3, SetName: "Set A", Lesson: "Math", Class: "Class 1", Number: 1, Type: "C1", Kind: "single",
+			Content: "2 &lt; 3?", IsQuiz: true, Answers: []questionEntity.Answer{
+				{ID: 10, QuestionID: 1, Code: "a", Content: "Yes", IsAnswer: true},
+				{ID: 11, QuestionID: 1, Code: "b", Content: "No", ImgURL: &img},
+			}},
+		{ID: 2, SetID: 3, SetName: "Set A", Lesson: "Math", Class: "Class 1", Number: 2, Type: "C4", Kind: "essay",
+			Content: "Explain", IsQuiz: true, Answers: []questionEntity.Answer{}},
+	}
+}
+
+func TestExportQuestionsHandler_CSV(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockQuestionService)
+	h := handler.NewQuestionHandler(mockService, validator.New(), nil)
+	app.Get("/question/export", h.ExportQuestionsHandler)
+
+	mockService.On("ExportQuestions", school(0), map[string]string{"set_id": "3"}).Return(exportFixture(), nil)
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/question/export?set_id=3&format=csv", nil))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Content-Disposition"), `filename="questions-set-3.csv"`)
+
+	body, _ := io.ReadAll(resp.Body)
+	rows, err := csv.NewReader(bytes.NewReader(body)).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, rows, 3)
+	assert.Equal(t, "set_id", rows[0][0])
+	assert.Equal(t, "2 < 3?", rows[1][7])
+	assert.Equal(t, "a", rows[1][len(rows[1])-1])
+
+	// The export is a valid import sheet.
+	mockService.On("ImportQuestions", int32(9), false, school(1), int32(3), mock.MatchedBy(func(qs []questionEntity.ImportQuestion) bool {
+		return len(qs) == 2 && qs[0].Answers[0].IsAnswer && *qs[0].Answers[1].ImgURL == "http://img/b.png"
+	})).Return(questionEntity.ImportResult{Questions: 2, Answers: 2}, nil)
+	app.Post("/question/import", middleware.JWTProtected(), h.ImportQuestionsHandler)
+
+	resp, _ = app.Test(importRequest(t, "bank.csv", body))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
+func TestExportQuestionsHandler_JSON(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockQuestionService)
+	h := handler.NewQuestionHandler(mockService, validator.New(), nil)
+	app.Get("/question/export", h.ExportQuestionsHandler)
+
+	mockService.On("ExportQuestions", school(0), map[string]string{"class_id": "1"}).Return(exportFixture(), nil)
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/question/export?class_id=1", nil))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	var body struct {
+		Questions []questionEntity.ExportQuestion `json:"questions"`
+	}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Len(t, body.Questions, 2)
+	assert.Equal(t, "Set A", body.Questions[0].SetName)
+}
+
+func TestExportQuestionsHandler_QTI(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockQuestionService)
+	h := handler.NewQuestionHandler(mockService, validator.New(), nil)
+	app.Get("/question/export", h.ExportQuestionsHandler)
+
+	mockService.On("ExportQuestions", school(0), map[string]string{"set_id": "3"}).Return(exportFixture(), nil)
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/question/export?set_id=3&format=qti", nil))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, _ := io.ReadAll(resp.Body)
+	archive, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	assert.NoError(t, err)
+
+	files := map[string]string{}
+	for _, f := range archive.File {
+		r, _ := f.Open()
+		content, _ := io.ReadAll(r)
+		r.Close()
+		files[f.Name] = string(content)
+	}
+	assert.Contains(t, files["imsmanifest.xml"], `href="items/Q1.xml"`)
+	assert.Contains(t, files["items/Q1.xml"], "<choiceInteraction")
+	assert.Contains(t, files["items/Q1.xml"], "<value>CHOICE_A</value>")
+	assert.Contains(t, files["items/Q1.xml"], "2 &lt; 3?")
+	assert.Contains(t, files["items/Q2.xml"], "<extendedTextInteraction")
+}
+
+func TestExportQuestionsHandler_InvalidRequest(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockQuestionService)
+	h := handler.NewQuestionHandler(mockService, validator.New(), nil)
+	app.Get("/question/export", h.ExportQuestionsHandler)
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/question/export?set_id=3&format=pdf", nil))
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/question/export?set_id=x", nil))
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	mockService.AssertNotCalled(t, "ExportQuestions", mock.Anything)
+}
+
+func worksheetFixture(imgURL string) questionEntity.Worksheet {
+	return questionEntity.Worksheet{SetID: 3, Variant: 2, Questions: []questionEntity.WorksheetQuestion{
+		{ID: 1, Number: 1, Kind: "single", Content: "Berapa 2 + 2?", Options: []questionEntity.WorksheetOption{
+			{Label: "A", Content: "5"},
+			{Label: "B", Content: "4", ImgURL: &imgURL, IsAnswer: true},
+		}},
+		{ID: 2, Number: 2, Kind: "essay", Content: "Jelaskan", Options: []questionEntity.WorksheetOption{}},
+	}}
+}
+
+func TestWorksheetPDFHandler(t *testing.T) {
+	png, _ := base64.StdEncoding.DecodeString("iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNk+M9QDwADhgGAWjR9awAAAABJRU5ErkJggg==")
+	store, _ := storage.NewLocal(t.TempDir(), "/media")
+	imgURL, _ := store.Put(context.Background(), "question/b.png", bytes.NewReader(png), "image/png")
+
+	app := fiber.New()
+	mockService := new(MockQuestionService)
+	h := handler.NewQuestionHandler(mockService, validator.New(), store)
+	app.Get("/quiz/worksheet", h.WorksheetPDFHandler)
+	app.Get("/quiz/worksheet/key", h.AnswerKeyPDFHandler)
+
+	mockService.On("Worksheet", school(0), int32(3), 2, false).Return(worksheetFixture(imgURL), nil)
+	mockService.On("Worksheet", school(0), int32(3), 2, true).Return(worksheetFixture(imgURL), nil)
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/quiz/worksheet?set_id=3&variant=2", nil))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/pdf", resp.Header.Get("Content-Type"))
+	assert.Contains(t, resp.Header.Get("Content-Disposition"), `filename="worksheet-set-3-b.pdf"`)
+	body, _ := io.ReadAll(resp.Body)
+	assert.True(t, bytes.HasPrefix(body, []byte("%PDF-")))
+	assert.Contains(t, string(body), "/Subtype /Image")
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/quiz/worksheet/key?set_id=3&variant=2", nil))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Content-Disposition"), `filename="answer-key-set-3-b.pdf"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestWorksheetPDFHandler_ImagesOutsideStorage(t *testing.T) {
+	var fetched bool
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched = true
+	}))
+	defer internal.Close()
+	store, _ := storage.NewLocal(t.TempDir(), "/media")
+
+	app := fiber.New()
+	mockService := new(MockQuestionService)
+	h := handler.NewQuestionHandler(mockService, validator.New(), store)
+	app.Get("/quiz/worksheet", h.WorksheetPDFHandler)
+
+	mockService.On("Worksheet", school(0), int32(3), 0, false).Return(worksheetFixture(internal.URL+"/media/b.png"), nil)
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/quiz/worksheet?set_id=3", nil))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	assert.NotContains(t, string(body), "/Subtype /Image")
+	assert.False(t, fetched)
+}
+
+func TestWorksheetPDFHandler_Errors(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockQuestionService)
+	h := handler.NewQuestionHandler(mockService, validator.New(), nil)
+	app.Get("/quiz/worksheet", h.WorksheetPDFHandler)
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/quiz/worksheet", nil))
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	mockService.On("Worksheet", school(0), int32(9), 0, false).Return(questionEntity.Worksheet{}, apperr.NewAppError(404, "set has no quiz questions"))
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/quiz/worksheet?set_id=9", nil))
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestAddQuestionHandler_Blocks(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockQuestionService)
+	h := handler.NewQuestionHandler(mockService, validator.New(), nil)
+	app.Post("/question", middleware.JWTProtected(), h.AddQuestionHandler)
+
+	body := `{"set_id":9,"number":1,"type":"C1","blocks":[{"type":"text","text":"Berapa?"},{"type":"image","url":"/media/a.png"}]}`
+	mockService.On("AddQuestion", int32(9), false, school(1), mock.MatchedBy(func(q questionEntity.SetQuestion) bool {
+		return len(q.Blocks) == 2 && q.Blocks[1].URL == "/media/a.png"
+	})).Return(nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/question", bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(asTeacher(req))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	for _, invalid := range []string{
+		`{"set_id":9,"number":1,"type":"C1"}`,
+		`{"set_id":9,"number":1,"type":"C1","blocks":[{"type":"html","text":"<b>x</b>"}]}`,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/question", bytes.NewReader([]byte(invalid)))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(asTeacher(req))
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, invalid)
+	}
+	mockService.AssertNumberOfCalls(t, "AddQuestion", 1)
+}
+
+func TestDeleteQuestionHandler_NotEditable(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockQuestionService)
+	h := handler.NewQuestionHandler(mockService, validator.New(), nil)
+	app.Delete("/question/:id", middleware.JWTProtected(), h.DeleteQuestionHandler)
+
+	mockService.On("DeleteQuestion", int32(9), false, school(1), int32(1)).
+		Return(apperr.NewAppError(403, "you can only change your own questions or those of your own or shared sets"))
+
+	req := httptest.NewRequest(http.MethodDelete, "/question/1", nil)
+	resp, _ := app.Test(asTeacher(req))
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	req = httptest.NewRequest(http.MethodDelete, "/question/1", nil)
+	resp, _ = app.Test(req)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	mockService.AssertNumberOfCalls(t, "DeleteQuestion", 1)
+}
+
+func TestQuestionRouter_QuizStaffOnly(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockQuestionService)
+	handler.NewQuestionHandler(mockService, validator.New(), nil).Router(app)
+
+	req := httptest.NewRequest(http.MethodGet, "/quiz?set_id=3", nil)
+	req.Header.Set("Authorization", "Bearer "+signedToken(11, middleware.RoleStudent))
 	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	mockService.AssertNotCalled(t, "ListQuizQuestions", mock.Anything, mock.Anything)
+
+	mockService.On("ListQuizQuestions", school(1), map[string]string{"set_id": "3"}).Return([]questionEntity.ListQuestionQuiz{}, nil)
+	resp, _ = app.Test(asTeacher(httptest.NewRequest(http.MethodGet, "/quiz?set_id=3", nil)))
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 	mockService.AssertExpectations(t)
 }
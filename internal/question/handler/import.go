@@ -0,0 +1,235 @@
+package handler
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/xuri/excelize/v2"
+
+	"github.com/ghulammuzz/misterblast/internal/question/entity"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/log"
+	"github.com/ghulammuzz/misterblast/pkg/middleware"
+	"github.com/ghulammuzz/misterblast/pkg/response"
+)
+
+// answerCodes are the answer options a sheet may fill, one "answer_<code>"
+// column each, with an optional "img_<code>" column for the image URL. Sheets
+// hold choice and essay questions; the other kinds need match texts or
+// positions and are authored through the API.
+var answerCodes = []string{"a", "b", "c", "d", "esay"}
+
+// ImportQuestionsHandler creates questions and their answers from an uploaded
+// CSV or XLSX sheet. The first row is a header naming the columns number,
+// type, kind, content, is_quiz, answer_a..answer_d, answer_esay, img_<code>
+// and correct (a comma separated list of codes). Either every row is imported
+// or none, with the errors of each rejected row in the response.
+func (h *QuestionHandler) ImportQuestionsHandler(c *fiber.Ctx) error {
+	userID, ok := middleware.UserID(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	setID, err := strconv.Atoi(c.FormValue("set_id"))
+	if err != nil || setID <= 0 {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid set ID", nil)
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "file is required", nil)
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		log.Error("[Handler][ImportQuestions] Error Open: ", err)
+		return response.SendError(c, fiber.StatusBadRequest, "failed to read file", nil)
+	}
+	defer file.Close()
+
+	rows, err := readSheet(header.Filename, file)
+	if err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, err.Error(), nil)
+	}
+
+	questions, rowErrors, err := h.mapImportRows(rows, int32(setID))
+	if err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, err.Error(), nil)
+	}
+	if len(rowErrors) > 0 {
+		return response.SendError(c, fiber.StatusBadRequest, "Validation failed", rowErrors)
+	}
+
+	result, err := h.questionService.ImportQuestions(userID, middleware.IsAdmin(c), middleware.SchoolID(c), int32(setID), questions)
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+	if len(result.Errors) > 0 {
+		return response.SendError(c, fiber.StatusConflict, "Validation failed", result.Errors)
+	}
+
+	return response.SendSuccess(c, "questions imported successfully", result)
+}
+
+// readSheet returns the cells of a CSV file or of the first XLSX worksheet.
+func readSheet(filename string, r io.Reader) ([][]string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		rows, err := reader.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv file: %v", err)
+		}
+		if len(rows) > 0 && len(rows[0]) > 0 {
+			rows[0][0] = strings.TrimPrefix(rows[0][0], "\ufeff")
+		}
+		return rows, nil
+	case ".xlsx":
+		book, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid xlsx file: %v", err)
+		}
+		defer book.Close()
+		rows, err := book.GetRows(book.GetSheetName(0))
+		if err != nil {
+			return nil, fmt.Errorf("invalid xlsx file: %v", err)
+		}
+		return rows, nil
+	default:
+		return nil, fmt.Errorf("unsupported file type, use .csv or .xlsx")
+	}
+}
+
+// mapImportRows turns sheet rows into questions and answers and validates them
+// with the same tags as the single question and answer endpoints.
+func (h *QuestionHandler) mapImportRows(rows [][]string, setID int32) ([]entity.ImportQuestion, []entity.ImportRowError, error) {
+	if len(rows) < 2 {
+		return nil, nil, fmt.Errorf("file has no question rows")
+	}
+	if len(rows)-1 > entity.MaxImportRows {
+		return nil, nil, fmt.Errorf("file has more than %d question rows", entity.MaxImportRows)
+	}
+
+	columns := map[string]int{}
+	for i, name := range rows[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"number", "type", "content"} {
+		if _, ok := columns[required]; !ok {
+			return nil, nil, fmt.Errorf("missing column %q", required)
+		}
+	}
+
+	var questions []entity.ImportQuestion
+	var rowErrors []entity.ImportRowError
+
+	for i, cells := range rows[1:] {
+		cell := func(name string) string {
+			if idx, ok := columns[name]; ok && idx < len(cells) {
+				return strings.TrimSpace(cells[idx])
+			}
+			return ""
+		}
+		if strings.TrimSpace(strings.Join(cells, "")) == "" {
+			continue
+		}
+
+		row := entity.ImportQuestion{Row: i + 2}
+		errs := map[string]string{}
+
+		number, err := strconv.Atoi(cell("number"))
+		if err != nil {
+			errs["Number"] = "number"
+		}
+		isQuiz := false
+		if v := cell("is_quiz"); v != "" {
+			if isQuiz, err = strconv.ParseBool(v); err != nil {
+				errs["IsQuiz"] = "boolean"
+			}
+		}
+
+		row.Question = entity.SetQuestion{
+			Number:  number,
+			Type:    cell("type"),
+			Kind:    strings.ToLower(cell("kind")),
+			Content: cell("content"),
+			IsQuiz:  isQuiz,
+			SetID:   setID,
+		}
+		if err := h.val.Struct(row.Question); err != nil {
+			for field, tag := range app.ValidationErrorResponse(err) {
+				if _, ok := errs[field]; !ok {
+					errs[field] = tag
+				}
+			}
+		}
+
+		correct := map[string]bool{}
+		for _, code := range strings.Split(strings.ToLower(cell("correct")), ",") {
+			if code = strings.TrimSpace(code); code != "" {
+				correct[code] = true
+			}
+		}
+
+		for _, code := range answerCodes {
+			content := cell("answer_" + code)
+			if content == "" {
+				continue
+			}
+			answer := entity.SetAnswer{Code: code, Content: content, IsAnswer: correct[code]}
+			if img := cell("img_" + code); img != "" {
+				answer.ImgURL = &img
+			}
+			if err := h.val.StructExcept(answer, "QuestionID"); err != nil {
+				for field, tag := range app.ValidationErrorResponse(err) {
+					errs["Answer."+code+"."+field] = tag
+				}
+			}
+			row.Answers = append(row.Answers, answer)
+			delete(correct, code)
+		}
+
+		answers := make([]entity.Answer, len(row.Answers))
+		for j, a := range row.Answers {
+			answers[j] = a.Answer()
+		}
+		if err := entity.CheckAnswers(row.Question.Kind, answers); err != nil {
+			errs["Answers"] = err.Error()
+		}
+
+		if len(correct) > 0 {
+			errs["Correct"] = "answer not found"
+		} else if row.Question.Kind != entity.KindEssay {
+			hasCorrect := false
+			for _, a := range row.Answers {
+				hasCorrect = hasCorrect || a.IsAnswer
+			}
+			if len(row.Answers) > 0 && !hasCorrect {
+				errs["Correct"] = "required"
+			}
+		}
+
+		if len(errs) > 0 {
+			rowErrors = append(rowErrors, entity.ImportRowError{Row: row.Row, Errors: errs})
+			continue
+		}
+		questions = append(questions, row)
+	}
+
+	if len(questions) == 0 && len(rowErrors) == 0 {
+		return nil, nil, fmt.Errorf("file has no question rows")
+	}
+
+	return questions, rowErrors, nil
+}
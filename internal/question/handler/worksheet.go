@@ -0,0 +1,364 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ghulammuzz/misterblast/internal/question/entity"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/log"
+	"github.com/ghulammuzz/misterblast/pkg/middleware"
+	"github.com/ghulammuzz/misterblast/pkg/response"
+	"github.com/ghulammuzz/misterblast/pkg/storage"
+)
+
+const (
+	maxWorksheetImageBytes = 5 << 20
+	worksheetImageWidth    = 40.0
+	worksheetEssayLines    = 5
+	// worksheetImageBudget caps the time spent loading images for one
+	// worksheet. Images still missing when it runs out print a placeholder.
+	worksheetImageBudget = 10 * time.Second
+)
+
+// WorksheetPDFHandler renders the quiz questions of a set as a printable
+// worksheet. An optional variant (1-26) prints a shuffled version labelled
+// with its letter.
+func (h *QuestionHandler) WorksheetPDFHandler(c *fiber.Ctx) error {
+	return h.sendWorksheet(c, false)
+}
+
+// AnswerKeyPDFHandler renders the answer key matching the worksheet of the
+// same set and variant.
+func (h *QuestionHandler) AnswerKeyPDFHandler(c *fiber.Ctx) error {
+	return h.sendWorksheet(c, true)
+}
+
+func (h *QuestionHandler) sendWorksheet(c *fiber.Ctx, withKey bool) error {
+	setID := c.QueryInt("set_id")
+	if setID <= 0 {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid set ID", nil)
+	}
+
+	sheet, err := h.questionService.Worksheet(middleware.SchoolID(c), int32(setID), c.QueryInt("variant"), withKey)
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), worksheetImageBudget)
+	defer cancel()
+
+	var buf bytes.Buffer
+	if err := renderWorksheet(&buf, sheet, withKey, &worksheetImages{ctx: ctx, store: h.store, names: map[string]string{}}); err != nil {
+		log.Error("[Handler][Worksheet] Error Render: ", err)
+		return response.SendError(c, fiber.StatusInternalServerError, "failed to render worksheet", nil)
+	}
+
+	name := fmt.Sprintf("worksheet-set-%d", setID)
+	if withKey {
+		name = fmt.Sprintf("answer-key-set-%d", setID)
+	}
+	if label := entity.VariantLabel(sheet.Variant); label != "" {
+		name += "-" + strings.ToLower(label)
+	}
+
+	c.Set(fiber.HeaderContentType, "application/pdf")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s.pdf"`, name))
+	return c.Send(buf.Bytes())
+}
+
+func renderWorksheet(w io.Writer, sheet entity.Worksheet, withKey bool, images *worksheetImages) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 15)
+	// Core fonts are cp1252; translate so accented Indonesian text prints.
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	title := fmt.Sprintf("Set %d", sheet.SetID)
+	if withKey {
+		title = "Answer Key - " + title
+	}
+	if label := entity.VariantLabel(sheet.Variant); label != "" {
+		title += " - Variant " + label
+	}
+
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-12)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.CellFormat(0, 5, fmt.Sprintf("%s - page %d", title, pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 10, tr(title), "", 1, "L", false, 0, "")
+	if !withKey {
+		pdf.SetFont("Helvetica", "", 11)
+		pdf.CellFormat(0, 8, "Name: ______________________   Class: __________   Date: __________", "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	for _, q := range sheet.Questions {
+		if withKey {
+			renderKeyQuestion(pdf, tr, q)
+		} else {
+			renderQuestion(pdf, tr, q, images)
+		}
+		pdf.Ln(3)
+	}
+
+	if err := pdf.Error(); err != nil {
+		return err
+	}
+	return pdf.Output(w)
+}
+
+func renderQuestion(pdf *fpdf.Fpdf, tr func(string) string, q entity.WorksheetQuestion, images *worksheetImages) {
+	pdf.SetFont("Helvetica", "B", 11)
+	if len(q.Blocks) == 0 {
+		pdf.MultiCell(0, 6, tr(fmt.Sprintf("%d. %s", q.Number, html.UnescapeString(q.Content))), "", "L", false)
+	} else {
+		pdf.MultiCell(0, 6, fmt.Sprintf("%d.", q.Number), "", "L", false)
+		renderBlocks(pdf, tr, q.Blocks, images)
+	}
+
+	pdf.SetFont("Helvetica", "", 11)
+	switch q.Kind {
+	case entity.KindEssay:
+		for i := 0; i < worksheetEssayLines; i++ {
+			pdf.SetX(20)
+			pdf.CellFormat(0, 8, "", "B", 1, "L", false, 0, "")
+		}
+		return
+	case entity.KindFillBlank:
+		for i := 1; i <= q.Blanks; i++ {
+			pdf.SetX(20)
+			pdf.CellFormat(0, 8, fmt.Sprintf("(%d) ______________________________", i), "", 1, "L", false, 0, "")
+		}
+		return
+	}
+
+	if hint := kindHint(q.Kind); hint != "" {
+		pdf.SetX(20)
+		pdf.SetFont("Helvetica", "I", 9)
+		pdf.MultiCell(0, 5, hint, "", "L", false)
+		pdf.SetFont("Helvetica", "", 11)
+	}
+
+	for _, o := range q.Options {
+		prefix := o.Label + ". "
+		switch q.Kind {
+		case entity.KindMatching:
+			prefix = o.Label + ". ____  "
+		case entity.KindOrdering:
+			prefix = "[    ]  "
+		}
+		pdf.SetX(20)
+		pdf.MultiCell(0, 6, tr(prefix+o.Content), "", "L", false)
+		if o.ImgURL != nil {
+			renderImage(pdf, *o.ImgURL, images)
+		}
+	}
+
+	for i, m := range q.MatchOptions {
+		pdf.SetX(30)
+		pdf.MultiCell(0, 6, tr(fmt.Sprintf("%d. %s", i+1, m)), "", "L", false)
+	}
+}
+
+// kindHint tells the student how to answer kinds that differ from picking a
+// single option.
+func kindHint(kind string) string {
+	switch kind {
+	case entity.KindMultiple:
+		return "Choose all correct answers."
+	case entity.KindTrueFalse:
+		return "Choose true or false."
+	case entity.KindMatching:
+		return "Write the number of the matching item next to each letter."
+	case entity.KindOrdering:
+		return "Number the items in the right order."
+	}
+	return ""
+}
+
+// renderBlocks prints a rich question stem. LaTeX is printed as source in a
+// monospace font and audio as a note, as neither can be rendered on paper.
+func renderBlocks(pdf *fpdf.Fpdf, tr func(string) string, blocks entity.Blocks, images *worksheetImages) {
+	for _, b := range blocks {
+		pdf.SetX(20)
+		switch b.Type {
+		case entity.BlockText:
+			pdf.SetFont("Helvetica", "B", 11)
+			pdf.MultiCell(0, 6, tr(html.UnescapeString(b.Text)), "", "L", false)
+		case entity.BlockLatex:
+			pdf.SetFont("Courier", "", 10)
+			pdf.MultiCell(0, 6, tr(b.Text), "", "L", false)
+		case entity.BlockImage:
+			renderImage(pdf, b.URL, images)
+		case entity.BlockAudio:
+			pdf.SetFont("Helvetica", "I", 9)
+			pdf.MultiCell(0, 5, tr("[audio] "+b.URL), "", "L", false)
+		}
+	}
+}
+
+func renderKeyQuestion(pdf *fpdf.Fpdf, tr func(string) string, q entity.WorksheetQuestion) {
+	var correct []string
+	switch q.Kind {
+	case entity.KindMatching:
+		for _, o := range q.Options {
+			if o.MatchText != nil {
+				correct = append(correct, fmt.Sprintf("%s. %s = %d", o.Label, o.Content, matchNumber(q.MatchOptions, *o.MatchText)))
+			}
+		}
+	case entity.KindOrdering:
+		options := append([]entity.WorksheetOption(nil), q.Options...)
+		sort.SliceStable(options, func(i, j int) bool { return keyPosition(options[i]) < keyPosition(options[j]) })
+		for _, o := range options {
+			correct = append(correct, o.Label)
+		}
+		if len(correct) > 0 {
+			correct = []string{strings.Join(correct, " - ")}
+		}
+	case entity.KindFillBlank:
+		blanks := map[string][]string{}
+		var labels []string
+		for _, o := range q.Options {
+			if _, ok := blanks[o.Label]; !ok {
+				labels = append(labels, o.Label)
+			}
+			blanks[o.Label] = append(blanks[o.Label], o.Content)
+		}
+		for _, label := range labels {
+			correct = append(correct, fmt.Sprintf("(%s) %s", label, strings.Join(blanks[label], " / ")))
+		}
+	default:
+		for _, o := range q.Options {
+			if q.Kind == entity.KindEssay {
+				correct = append(correct, o.Content)
+			} else if o.IsAnswer {
+				correct = append(correct, o.Label+". "+o.Content)
+			}
+		}
+	}
+	if len(correct) == 0 {
+		correct = []string{"-"}
+		if q.Kind == entity.KindEssay {
+			correct = []string{"(graded by the teacher)"}
+		}
+	}
+
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(12, 6, fmt.Sprintf("%d.", q.Number), "", 0, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.MultiCell(0, 6, tr(strings.Join(correct, "; ")), "", "L", false)
+}
+
+// matchNumber is the printed number of a match option, or 0 if it is missing.
+func matchNumber(options []string, matchText string) int {
+	for i, m := range options {
+		if m == matchText {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+func keyPosition(o entity.WorksheetOption) int {
+	if o.Position == nil {
+		return 0
+	}
+	return *o.Position
+}
+
+// worksheetImages loads the images of one worksheet from media storage. Only
+// URLs of stored objects are read, never arbitrary hosts, and nothing more is
+// loaded once ctx is done.
+type worksheetImages struct {
+	ctx   context.Context
+	store storage.Storage
+	// names maps each url to its registered image, or "" if it failed.
+	names map[string]string
+}
+
+// renderImage places the image at url under the current option, registering
+// each url once per document.
+func renderImage(pdf *fpdf.Fpdf, url string, images *worksheetImages) {
+	name, ok := images.names[url]
+	if !ok {
+		imageType, body, err := images.fetch(url)
+		if err != nil {
+			log.Error("[Handler][Worksheet] Error Fetch Image: ", err)
+		} else {
+			name = fmt.Sprintf("img%d", len(images.names))
+			pdf.RegisterImageOptionsReader(name, fpdf.ImageOptions{ImageType: imageType, ReadDpi: true}, bytes.NewReader(body))
+			if pdf.Err() {
+				log.Error("[Handler][Worksheet] Error Register Image: ", pdf.Error())
+				pdf.ClearError()
+				name = ""
+			}
+		}
+		images.names[url] = name
+	}
+
+	pdf.SetX(25)
+	if name == "" {
+		pdf.SetFont("Helvetica", "I", 9)
+		pdf.CellFormat(0, 5, "[image unavailable]", "", 1, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 11)
+		return
+	}
+	pdf.ImageOptions(name, 25, pdf.GetY(), worksheetImageWidth, 0, true, fpdf.ImageOptions{}, 0, "")
+}
+
+func (images *worksheetImages) fetch(url string) (string, []byte, error) {
+	if err := images.ctx.Err(); err != nil {
+		return "", nil, fmt.Errorf("fetch %s: %w", url, err)
+	}
+	if images.store == nil {
+		return "", nil, fmt.Errorf("fetch %s: no media storage", url)
+	}
+	key, ok := storage.Key(images.store, url)
+	if !ok {
+		return "", nil, fmt.Errorf("fetch %s: not in media storage", url)
+	}
+
+	r, err := images.store.Get(images.ctx, key)
+	if err != nil {
+		return "", nil, fmt.Errorf("fetch %s: %w", url, err)
+	}
+	defer r.Close()
+
+	body, err := io.ReadAll(io.LimitReader(r, maxWorksheetImageBytes+1))
+	if err != nil {
+		return "", nil, err
+	}
+	if len(body) > maxWorksheetImageBytes {
+		return "", nil, fmt.Errorf("fetch %s: image larger than %d bytes", url, maxWorksheetImageBytes)
+	}
+
+	switch http.DetectContentType(body) {
+	case "image/png":
+		return "PNG", body, nil
+	case "image/jpeg":
+		return "JPG", body, nil
+	case "image/gif":
+		return "GIF", body, nil
+	default:
+		return "", nil, fmt.Errorf("fetch %s: unsupported image type", url)
+	}
+}
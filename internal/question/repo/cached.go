@@ -0,0 +1,99 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	questionEntity "github.com/ghulammuzz/misterblast/internal/question/entity"
+	"github.com/ghulammuzz/misterblast/pkg/cache"
+)
+
+// cachedQuestionRepository caches the question and answer reads. Exists,
+// ListNumbers and the ownership reads are passed through uncached since they
+// guard writes.
+type cachedQuestionRepository struct {
+	QuestionRepository
+	questions *cache.Namespace
+}
+
+// NewCachedQuestionRepository caches question reads in c and drops them on
+// every question or answer write.
+func NewCachedQuestionRepository(db *sql.DB, c cache.Cache) QuestionRepository {
+	return &cachedQuestionRepository{
+		QuestionRepository: NewQuestionRepository(db),
+		questions:          cache.NewNamespace(c, cache.NamespaceQuestion, cache.DefaultTTL),
+	}
+}
+
+func (r *cachedQuestionRepository) Add(ownerID int32, question questionEntity.SetQuestion) error {
+	return r.invalidate(r.QuestionRepository.Add(ownerID, question))
+}
+
+func (r *cachedQuestionRepository) Delete(id int32) error {
+	return r.invalidate(r.QuestionRepository.Delete(id))
+}
+
+func (r *cachedQuestionRepository) Edit(id int32, question questionEntity.EditQuestion) error {
+	return r.invalidate(r.QuestionRepository.Edit(id, question))
+}
+
+func (r *cachedQuestionRepository) AddQuizAnswer(answer questionEntity.SetAnswer) error {
+	return r.invalidate(r.QuestionRepository.AddQuizAnswer(answer))
+}
+
+func (r *cachedQuestionRepository) DeleteAnswer(id int32) error {
+	return r.invalidate(r.QuestionRepository.DeleteAnswer(id))
+}
+
+func (r *cachedQuestionRepository) EditAnswer(id int32, answer questionEntity.EditAnswer) error {
+	return r.invalidate(r.QuestionRepository.EditAnswer(id, answer))
+}
+
+func (r *cachedQuestionRepository) Import(ownerID, setID int32, questions []questionEntity.ImportQuestion) (int, error) {
+	answers, err := r.QuestionRepository.Import(ownerID, setID, questions)
+	return answers, r.invalidate(err)
+}
+
+func (r *cachedQuestionRepository) List(schoolID *int32, filter map[string]string) ([]questionEntity.ListQuestionExample, error) {
+	key := "list:" + cache.ScopeKey(schoolID) + "?" + cache.FilterKey(filter)
+	return cache.Remember(context.Background(), r.questions, key, func() ([]questionEntity.ListQuestionExample, error) {
+		return r.QuestionRepository.List(schoolID, filter)
+	})
+}
+
+func (r *cachedQuestionRepository) Detail(schoolID *int32, id int32) (questionEntity.DetailQuestionExample, error) {
+	key := fmt.Sprintf("detail:%s:%d", cache.ScopeKey(schoolID), id)
+	return cache.Remember(context.Background(), r.questions, key, func() (questionEntity.DetailQuestionExample, error) {
+		return r.QuestionRepository.Detail(schoolID, id)
+	})
+}
+
+func (r *cachedQuestionRepository) ListQuizQuestions(schoolID *int32, filter map[string]string) ([]questionEntity.ListQuestionQuiz, error) {
+	key := "quiz:" + cache.ScopeKey(schoolID) + "?" + cache.FilterKey(filter)
+	return cache.Remember(context.Background(), r.questions, key, func() ([]questionEntity.ListQuestionQuiz, error) {
+		return r.QuestionRepository.ListQuizQuestions(schoolID, filter)
+	})
+}
+
+func (r *cachedQuestionRepository) ListAdmin(schoolID *int32, filter map[string]string, page, limit int) ([]questionEntity.ListQuestionAdmin, error) {
+	key := fmt.Sprintf("admin:%s:%d:%d?%s", cache.ScopeKey(schoolID), page, limit, cache.FilterKey(filter))
+	return cache.Remember(context.Background(), r.questions, key, func() ([]questionEntity.ListQuestionAdmin, error) {
+		return r.QuestionRepository.ListAdmin(schoolID, filter, page, limit)
+	})
+}
+
+func (r *cachedQuestionRepository) ListAnswerKey(schoolID *int32, setID int32) ([]questionEntity.ListQuestionKey, error) {
+	key := fmt.Sprintf("key:%s:%d", cache.ScopeKey(schoolID), setID)
+	return cache.Remember(context.Background(), r.questions, key, func() ([]questionEntity.ListQuestionKey, error) {
+		return r.QuestionRepository.ListAnswerKey(schoolID, setID)
+	})
+}
+
+// invalidate drops the cached reads once a write went through.
+func (r *cachedQuestionRepository) invalidate(err error) error {
+	if err == nil {
+		cache.Invalidate(context.Background(), r.questions)
+	}
+	return err
+}
@@ -11,24 +11,41 @@ import (
 
 type QuestionRepository interface {
 	// Questions
-	Add(question questionEntity.SetQuestion) error
-	List(filter map[string]string) ([]questionEntity.ListQuestionExample, error)
+	Add(ownerID int32, question questionEntity.SetQuestion) error
+	List(schoolID *int32, filter map[string]string) ([]questionEntity.ListQuestionExample, error)
 	Delete(id int32) error
-	Detail(id int32) (questionEntity.DetailQuestionExample, error)
+	Detail(schoolID *int32, id int32) (questionEntity.DetailQuestionExample, error)
 	Exists(setID int32, number int) (bool, error)
 	Edit(id int32, question questionEntity.EditQuestion) error
 
 	// Answer
 	AddQuizAnswer(answer questionEntity.SetAnswer) error
-	ListQuizQuestions(filter map[string]string) ([]questionEntity.ListQuestionQuiz, error)
+	ListQuizQuestions(schoolID *int32, filter map[string]string) ([]questionEntity.ListQuestionQuiz, error)
 	DeleteAnswer(id int32) error
 	EditAnswer(id int32, answer questionEntity.EditAnswer) error
 
 	// Admin
-	ListAdmin(filter map[string]string, page, limit int) ([]questionEntity.ListQuestionAdmin, error)
+	ListAdmin(schoolID *int32, filter map[string]string, page, limit int) ([]questionEntity.ListQuestionAdmin, error)
+	ListAnswerKey(schoolID *int32, setID int32) ([]questionEntity.ListQuestionKey, error)
+	QuestionAnswerKey(id int32) (questionEntity.ListQuestionKey, error)
 
+	// Import
+	Import(ownerID, setID int32, questions []questionEntity.ImportQuestion) (int, error)
+	ListNumbers(setID int32) (map[int]bool, error)
+
+	// Ownership
+	SetOwnership(schoolID *int32, setID int32) (questionEntity.Ownership, error)
+	Ownership(schoolID *int32, id int32) (questionEntity.Ownership, error)
+	AnswerOwnership(schoolID *int32, id int32) (questionEntity.Ownership, error)
+
+	// Export
+	Export(schoolID *int32, filter map[string]string) ([]questionEntity.ExportQuestion, error)
 }
 
+// The reads taking a schoolID return the questions of that school along with
+// the shared ones without a school; nil, for super admins, returns every
+// school. Questions are stored in the school of their set.
+
 type questionRepository struct {
 	db *sql.DB
 }
@@ -37,9 +54,11 @@ func NewQuestionRepository(db *sql.DB) QuestionRepository {
 	return &questionRepository{db: db}
 }
 
-func (r *questionRepository) Add(question questionEntity.SetQuestion) error {
-	query := `INSERT INTO questions (number, type, content, is_quiz, set_id) VALUES ($1, $2, $3, $4, $5)`
-	_, err := r.db.Exec(query, question.Number, question.Type, question.Content, question.IsQuiz, question.SetID)
+func (r *questionRepository) Add(ownerID int32, question questionEntity.SetQuestion) error {
+	query := `INSERT INTO questions (number, type, kind, content, is_quiz, set_id, blocks, owner_id, school_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, (SELECT school_id FROM sets WHERE id = $6))`
+	_, err := r.db.Exec(query, question.Number, question.Type, kindOrDefault(question.Kind), question.Content, question.IsQuiz, question.SetID,
+		question.Blocks, ownerID)
 	if err != nil {
 		log.Error("[Repo][AddQuestion] Error inserting question:", err)
 		return app.NewAppError(500, err.Error())
@@ -47,10 +66,16 @@ func (r *questionRepository) Add(question questionEntity.SetQuestion) error {
 	return nil
 }
 
-func (r *questionRepository) Detail(id int32) (questionEntity.DetailQuestionExample, error) {
-	query := `SELECT id, number, type, content, set_id FROM questions WHERE id = $1`
+func (r *questionRepository) Detail(schoolID *int32, id int32) (questionEntity.DetailQuestionExample, error) {
+	query := `SELECT id, number, type, content, set_id, blocks FROM questions WHERE id = $1`
+	args := []interface{}{id}
+	if schoolID != nil {
+		query += ` AND (school_id = $2 OR school_id IS NULL)`
+		args = append(args, *schoolID)
+	}
+
 	var question questionEntity.DetailQuestionExample
-	err := r.db.QueryRow(query, id).Scan(&question.ID, &question.Number, &question.Type, &question.Content, &question.SetID)
+	err := r.db.QueryRow(query, args...).Scan(&question.ID, &question.Number, &question.Type, &question.Content, &question.SetID, &question.Blocks)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return question, app.NewAppError(404, "question not found")
@@ -61,7 +86,7 @@ func (r *questionRepository) Detail(id int32) (questionEntity.DetailQuestionExam
 	return question, nil
 }
 
-func (r *questionRepository) List(filter map[string]string) ([]questionEntity.ListQuestionExample, error) {
+func (r *questionRepository) List(schoolID *int32, filter map[string]string) ([]questionEntity.ListQuestionExample, error) {
 	query := `SELECT id, number, type, content, set_id FROM questions WHERE 1=1`
 	args := []interface{}{}
 	argCounter := 1
@@ -71,6 +96,11 @@ func (r *questionRepository) List(filter map[string]string) ([]questionEntity.Li
 		args = append(args, setID)
 		argCounter++
 	}
+	if schoolID != nil {
+		query += fmt.Sprintf(" AND (school_id = $%d OR school_id IS NULL)", argCounter)
+		args = append(args, *schoolID)
+		argCounter++
+	}
 
 	rows, err := r.db.Query(query, args...)
 	if err != nil {
@@ -128,10 +158,11 @@ func (r *questionRepository) Exists(setID int32, number int) (bool, error) {
 func (r *questionRepository) Edit(id int32, question questionEntity.EditQuestion) error {
 	query := `
 		UPDATE questions 
-		SET number = $1, type = $2, content = $3, is_quiz = $4, set_id = $5 
-		WHERE id = $6`
+		SET number = $1, type = $2, kind = $3, content = $4, is_quiz = $5, set_id = $6, blocks = $7,
+			school_id = (SELECT school_id FROM sets WHERE id = $6)
+		WHERE id = $8`
 
-	_, err := r.db.Exec(query, question.Number, question.Type, question.Content, question.IsQuiz, question.SetID, id)
+	_, err := r.db.Exec(query, question.Number, question.Type, kindOrDefault(question.Kind), question.Content, question.IsQuiz, question.SetID, question.Blocks, id)
 	if err != nil {
 		log.Error("[Repo][EditQuestion] Error updating question:", err)
 		return app.NewAppError(500, err.Error())
@@ -139,3 +170,10 @@ func (r *questionRepository) Edit(id int32, question questionEntity.EditQuestion
 
 	return nil
 }
+
+func kindOrDefault(kind string) string {
+	if kind == "" {
+		return questionEntity.KindSingle
+	}
+	return kind
+}
@@ -1,6 +1,7 @@
 package repo
 
 import (
+	"database/sql"
 	"fmt"
 
 	questionEntity "github.com/ghulammuzz/misterblast/internal/question/entity"
@@ -8,7 +9,7 @@ import (
 	"github.com/ghulammuzz/misterblast/pkg/log"
 )
 
-func (r *questionRepository) ListAdmin(filter map[string]string, page, limit int) ([]questionEntity.ListQuestionAdmin, error) {
+func (r *questionRepository) ListAdmin(schoolID *int32, filter map[string]string, page, limit int) ([]questionEntity.ListQuestionAdmin, error) {
 	query := `
 		SELECT q.id, q.number, q.type, q.content, q.is_quiz, q.set_id,
 			   s.name AS set_name, l.name AS lesson_name, c.name AS class_name
@@ -42,6 +43,11 @@ func (r *questionRepository) ListAdmin(filter map[string]string, page, limit int
 		args = append(args, set)
 		argCounter++
 	}
+	if schoolID != nil {
+		query += fmt.Sprintf(" AND (q.school_id = $%d OR q.school_id IS NULL)", argCounter)
+		args = append(args, *schoolID)
+		argCounter++
+	}
 
 	query += " ORDER BY q.number"
 
@@ -77,3 +83,110 @@ func (r *questionRepository) ListAdmin(filter map[string]string, page, limit int
 
 	return questions, nil
 }
+
+func (r *questionRepository) ListAnswerKey(schoolID *int32, setID int32) ([]questionEntity.ListQuestionKey, error) {
+	query := `
+		SELECT q.id, q.number, q.type, q.kind, q.content, q.set_id,
+			   COALESCE(a.id, 0), COALESCE(a.code, ''), COALESCE(a.content, ''),
+			   COALESCE(a.img_url, ''), COALESCE(a.is_answer, false), a.match_text, a.position
+		FROM questions q
+		LEFT JOIN answers a ON q.id = a.question_id
+		WHERE q.set_id = $1 AND q.is_quiz = true`
+	args := []interface{}{setID}
+	if schoolID != nil {
+		query += ` AND (q.school_id = $2 OR q.school_id IS NULL)`
+		args = append(args, *schoolID)
+	}
+	query += ` ORDER BY q.number, a.code`
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		log.Error("[Repo][ListAnswerKey] Error Query: ", err)
+		return nil, app.NewAppError(500, "failed to fetch answer key")
+	}
+	defer rows.Close()
+
+	return scanAnswerKey(rows, "ListAnswerKey")
+}
+
+// QuestionAnswerKey returns one question with all its answer options, quiz or
+// not, so new options can be checked against the rules of its kind.
+func (r *questionRepository) QuestionAnswerKey(id int32) (questionEntity.ListQuestionKey, error) {
+	query := `
+		SELECT q.id, q.number, q.type, q.kind, q.content, q.set_id,
+			   COALESCE(a.id, 0), COALESCE(a.code, ''), COALESCE(a.content, ''),
+			   COALESCE(a.img_url, ''), COALESCE(a.is_answer, false), a.match_text, a.position
+		FROM questions q
+		LEFT JOIN answers a ON q.id = a.question_id
+		WHERE q.id = $1
+		ORDER BY a.code
+	`
+	rows, err := r.db.Query(query, id)
+	if err != nil {
+		log.Error("[Repo][QuestionAnswerKey] Error Query: ", err)
+		return questionEntity.ListQuestionKey{}, app.NewAppError(500, "failed to fetch answer key")
+	}
+	defer rows.Close()
+
+	questions, err := scanAnswerKey(rows, "QuestionAnswerKey")
+	if err != nil {
+		return questionEntity.ListQuestionKey{}, err
+	}
+	if len(questions) == 0 {
+		return questionEntity.ListQuestionKey{}, app.NewAppError(404, "question not found")
+	}
+	return questions[0], nil
+}
+
+func scanAnswerKey(rows *sql.Rows, name string) ([]questionEntity.ListQuestionKey, error) {
+	questionsMap := make(map[int32]*questionEntity.ListQuestionKey)
+	var questions []*questionEntity.ListQuestionKey
+
+	for rows.Next() {
+		var q questionEntity.ListQuestionKey
+		var a questionEntity.Answer
+		var imgURL string
+		var matchText sql.NullString
+		var position sql.NullInt32
+
+		err := rows.Scan(&q.ID, &q.Number, &q.Type, &q.Kind, &q.Content, &q.SetID,
+			&a.ID, &a.Code, &a.Content, &imgURL, &a.IsAnswer, &matchText, &position)
+		if err != nil {
+			log.Error("[Repo]["+name+"] Error Scan: ", err)
+			return nil, app.NewAppError(500, "failed to scan answer key")
+		}
+
+		if _, exists := questionsMap[q.ID]; !exists {
+			q.Answers = []questionEntity.Answer{}
+			questionsMap[q.ID] = &q
+			questions = append(questions, questionsMap[q.ID])
+		}
+
+		if a.ID != 0 {
+			a.QuestionID = q.ID
+			if imgURL != "" {
+				a.ImgURL = &imgURL
+			}
+			if matchText.Valid {
+				a.MatchText = &matchText.String
+			}
+			if position.Valid {
+				p := int(position.Int32)
+				a.Position = &p
+			}
+			questionsMap[q.ID].Answers = append(questionsMap[q.ID].Answers, a)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Error("[Repo]["+name+"] Error Iterating Rows: ", err)
+		return nil, app.NewAppError(500, "error iterating rows")
+	}
+
+	finalQuestions := make([]questionEntity.ListQuestionKey, len(questions))
+	for i, q := range questions {
+		finalQuestions[i] = *q
+	}
+
+	return finalQuestions, nil
+}
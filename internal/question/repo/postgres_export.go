@@ -0,0 +1,102 @@
+package repo
+
+import (
+	"database/sql"
+	"fmt"
+
+	questionEntity "github.com/ghulammuzz/misterblast/internal/question/entity"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/log"
+)
+
+// Export lists every question of the sets matching filter (set_id, lesson_id
+// or class_id) with their answers, ordered by set and question number.
+func (r *questionRepository) Export(schoolID *int32, filter map[string]string) ([]questionEntity.ExportQuestion, error) {
+	query := `
+		SELECT q.id, q.set_id, s.name, l.name, c.name, q.number, q.type, q.kind, q.content, q.is_quiz,
+			   COALESCE(a.id, 0), COALESCE(a.code, ''), COALESCE(a.content, ''),
+			   COALESCE(a.img_url, ''), COALESCE(a.is_answer, false), a.match_text, a.position
+		FROM questions q
+		JOIN sets s ON q.set_id = s.id
+		JOIN lessons l ON s.lesson_id = l.id
+		JOIN classes c ON s.class_id = c.id
+		LEFT JOIN answers a ON q.id = a.question_id
+		WHERE 1=1`
+	args := []interface{}{}
+	argCounter := 1
+
+	if setID, ok := filter["set_id"]; ok {
+		query += fmt.Sprintf(" AND q.set_id = $%d", argCounter)
+		args = append(args, setID)
+		argCounter++
+	}
+	if lessonID, ok := filter["lesson_id"]; ok {
+		query += fmt.Sprintf(" AND s.lesson_id = $%d", argCounter)
+		args = append(args, lessonID)
+		argCounter++
+	}
+	if classID, ok := filter["class_id"]; ok {
+		query += fmt.Sprintf(" AND s.class_id = $%d", argCounter)
+		args = append(args, classID)
+		argCounter++
+	}
+	if schoolID != nil {
+		query += fmt.Sprintf(" AND (q.school_id = $%d OR q.school_id IS NULL)", argCounter)
+		args = append(args, *schoolID)
+		argCounter++
+	}
+
+	query += " ORDER BY q.set_id, q.number, a.code"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		log.Error("[Repo][ExportQuestions] Error Query: ", err)
+		return nil, app.NewAppError(500, "failed to export questions")
+	}
+	defer rows.Close()
+
+	questions := []questionEntity.ExportQuestion{}
+	for rows.Next() {
+		var q questionEntity.ExportQuestion
+		var a questionEntity.Answer
+		var imgURL string
+		var matchText sql.NullString
+		var position sql.NullInt32
+
+		err := rows.Scan(&q.ID, &q.SetID, &q.SetName, &q.Lesson, &q.Class, &q.Number, &q.Type, &q.Kind, &q.Content, &q.IsQuiz,
+			&a.ID, &a.Code, &a.Content, &imgURL, &a.IsAnswer, &matchText, &position)
+		if err != nil {
+			log.Error("[Repo][ExportQuestions] Error Scan: ", err)
+			return nil, app.NewAppError(500, "failed to scan exported question")
+		}
+
+		// Rows of one question are adjacent thanks to the ordering.
+		if n := len(questions); n == 0 || questions[n-1].ID != q.ID {
+			q.Answers = []questionEntity.Answer{}
+			questions = append(questions, q)
+		}
+
+		if a.ID != 0 {
+			a.QuestionID = q.ID
+			if imgURL != "" {
+				a.ImgURL = &imgURL
+			}
+			if matchText.Valid {
+				a.MatchText = &matchText.String
+			}
+			if position.Valid {
+				p := int(position.Int32)
+				a.Position = &p
+			}
+			last := &questions[len(questions)-1]
+			last.Answers = append(last.Answers, a)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Error("[Repo][ExportQuestions] Error Iterating Rows: ", err)
+		return nil, app.NewAppError(500, "error iterating rows")
+	}
+
+	return questions, nil
+}
@@ -0,0 +1,91 @@
+package repo
+
+import (
+	"errors"
+
+	questionEntity "github.com/ghulammuzz/misterblast/internal/question/entity"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/log"
+	"github.com/lib/pq"
+)
+
+// Import inserts all questions of a spreadsheet and their answers into setID
+// in one transaction. Nothing is written unless every row succeeds.
+func (r *questionRepository) Import(ownerID, setID int32, questions []questionEntity.ImportQuestion) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Error("[Repo][ImportQuestions] Error Begin: ", err)
+		return 0, app.NewAppError(500, "failed to import questions")
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM sets WHERE id = $1)`, setID).Scan(&exists); err != nil {
+		log.Error("[Repo][ImportQuestions] Error QueryRow Set: ", err)
+		return 0, app.NewAppError(500, "failed to import questions")
+	}
+	if !exists {
+		return 0, app.NewAppError(404, "set not found")
+	}
+
+	insertQuestion := `INSERT INTO questions (number, type, kind, content, is_quiz, set_id, owner_id, school_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, (SELECT school_id FROM sets WHERE id = $6)) RETURNING id`
+	insertAnswer := `INSERT INTO answers (question_id, code, content, img_url, is_answer) VALUES ($1, $2, $3, $4, $5)`
+
+	answers := 0
+	for _, row := range questions {
+		q := row.Question
+		var questionID int32
+		err := tx.QueryRow(insertQuestion, q.Number, q.Type, kindOrDefault(q.Kind), q.Content, q.IsQuiz, setID, ownerID).Scan(&questionID)
+		if err != nil {
+			var pqErr *pq.Error
+			if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+				return 0, app.NewAppError(409, "question number already exists in this set")
+			}
+			log.Error("[Repo][ImportQuestions] Error Exec Question: ", err)
+			return 0, app.NewAppError(500, "failed to import questions")
+		}
+
+		for _, a := range row.Answers {
+			if _, err := tx.Exec(insertAnswer, questionID, a.Code, a.Content, a.ImgURL, a.IsAnswer); err != nil {
+				log.Error("[Repo][ImportQuestions] Error Exec Answer: ", err)
+				return 0, app.NewAppError(500, "failed to import questions")
+			}
+			answers++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error("[Repo][ImportQuestions] Error Commit: ", err)
+		return 0, app.NewAppError(500, "failed to import questions")
+	}
+
+	return answers, nil
+}
+
+// ListNumbers returns the question numbers already taken in a set.
+func (r *questionRepository) ListNumbers(setID int32) (map[int]bool, error) {
+	rows, err := r.db.Query(`SELECT number FROM questions WHERE set_id = $1`, setID)
+	if err != nil {
+		log.Error("[Repo][ListNumbers] Error Query: ", err)
+		return nil, app.NewAppError(500, "failed to fetch question numbers")
+	}
+	defer rows.Close()
+
+	numbers := map[int]bool{}
+	for rows.Next() {
+		var number int
+		if err := rows.Scan(&number); err != nil {
+			log.Error("[Repo][ListNumbers] Error Scan: ", err)
+			return nil, app.NewAppError(500, "failed to scan question number")
+		}
+		numbers[number] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Error("[Repo][ListNumbers] Error Iterating Rows: ", err)
+		return nil, app.NewAppError(500, "error iterating rows")
+	}
+
+	return numbers, nil
+}
@@ -0,0 +1,67 @@
+package repo
+
+import (
+	"database/sql"
+
+	questionEntity "github.com/ghulammuzz/misterblast/internal/question/entity"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/log"
+)
+
+// The ownership lookups report what lies outside schoolID as not found, which
+// keeps the shared content without a school out of reach of everyone but
+// super admins.
+
+// SetOwnership returns who may add questions to a set.
+func (r *questionRepository) SetOwnership(schoolID *int32, setID int32) (questionEntity.Ownership, error) {
+	query := `SELECT NULL::INTEGER, s.owner_id, s.is_shared FROM sets s WHERE s.id = $1`
+	return r.ownership("SetOwnership", "set not found", query, schoolID, setID)
+}
+
+// Ownership returns who may change a question and its answers.
+func (r *questionRepository) Ownership(schoolID *int32, id int32) (questionEntity.Ownership, error) {
+	query := `
+		SELECT q.owner_id, s.owner_id, s.is_shared
+		FROM questions q
+		JOIN sets s ON s.id = q.set_id
+		WHERE q.id = $1`
+	return r.ownership("QuestionOwnership", "question not found", query, schoolID, id)
+}
+
+// AnswerOwnership returns who may change the question an answer belongs to.
+func (r *questionRepository) AnswerOwnership(schoolID *int32, id int32) (questionEntity.Ownership, error) {
+	query := `
+		SELECT q.owner_id, s.owner_id, s.is_shared
+		FROM answers a
+		JOIN questions q ON q.id = a.question_id
+		JOIN sets s ON s.id = q.set_id
+		WHERE a.id = $1`
+	return r.ownership("AnswerOwnership", "answer not found", query, schoolID, id)
+}
+
+func (r *questionRepository) ownership(name, notFound, query string, schoolID *int32, id int32) (questionEntity.Ownership, error) {
+	args := []interface{}{id}
+	if schoolID != nil {
+		query += ` AND s.school_id = $2`
+		args = append(args, *schoolID)
+	}
+
+	var ownership questionEntity.Ownership
+	var ownerID, setOwnerID sql.NullInt32
+	err := r.db.QueryRow(query, args...).Scan(&ownerID, &setOwnerID, &ownership.SetShared)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ownership, app.NewAppError(404, notFound)
+		}
+		log.Error("[Repo]["+name+"] Error QueryRow: ", err)
+		return ownership, app.NewAppError(500, "failed to check ownership")
+	}
+	if ownerID.Valid {
+		ownership.OwnerID = &ownerID.Int32
+	}
+	if setOwnerID.Valid {
+		ownership.SetOwnerID = &setOwnerID.Int32
+	}
+
+	return ownership, nil
+}
@@ -2,6 +2,7 @@ package repo
 
 import (
 	"fmt"
+	"sort"
 
 	questionEntity "github.com/ghulammuzz/misterblast/internal/question/entity"
 	"github.com/ghulammuzz/misterblast/pkg/app"
@@ -10,10 +11,11 @@ import (
 
 func (r *questionRepository) AddQuizAnswer(answer questionEntity.SetAnswer) error {
 	query := `
-		INSERT INTO answers (question_id, code, content, img_url, is_answer) 
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO answers (question_id, code, content, img_url, is_answer, match_text, position) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 	`
-	_, err := r.db.Exec(query, answer.QuestionID, answer.Code, answer.Content, answer.ImgURL, answer.IsAnswer)
+	_, err := r.db.Exec(query, answer.QuestionID, answer.Code, answer.Content, answer.ImgURL, answer.IsAnswer,
+		answer.MatchText, answer.Position)
 	if err != nil {
 		log.Error("[Repo][AddQuizAnswer] Error inserting answer: ", err)
 		return app.NewAppError(500, "failed to insert quiz answer")
@@ -22,11 +24,12 @@ func (r *questionRepository) AddQuizAnswer(answer questionEntity.SetAnswer) erro
 	return nil
 }
 
-func (r *questionRepository) ListQuizQuestions(filter map[string]string) ([]questionEntity.ListQuestionQuiz, error) {
+func (r *questionRepository) ListQuizQuestions(schoolID *int32, filter map[string]string) ([]questionEntity.ListQuestionQuiz, error) {
 	query := `
-		SELECT q.id, q.number, q.type, q.content, q.set_id,
+		SELECT q.id, q.number, q.type, q.kind, q.content, q.set_id, q.blocks,
 			   COALESCE(a.id, 0) AS answer_id, COALESCE(a.code, '') AS code, 
-			   COALESCE(a.content, '') AS answer_content, COALESCE(a.img_url, '') AS img_url
+			   COALESCE(a.content, '') AS answer_content, COALESCE(a.img_url, '') AS img_url,
+			   COALESCE(a.match_text, '') AS match_text, COALESCE(a.position, 0) AS position
 		FROM questions q
 		LEFT JOIN answers a ON q.id = a.question_id
 		WHERE q.is_quiz = true
@@ -49,6 +52,11 @@ func (r *questionRepository) ListQuizQuestions(filter map[string]string) ([]ques
 		args = append(args, number)
 		argCounter++
 	}
+	if schoolID != nil {
+		query += fmt.Sprintf(" AND (q.school_id = $%d OR q.school_id IS NULL)", argCounter)
+		args = append(args, *schoolID)
+		argCounter++
+	}
 
 	query += " ORDER BY q.number, a.code"
 
@@ -65,14 +73,16 @@ func (r *questionRepository) ListQuizQuestions(filter map[string]string) ([]ques
 	for rows.Next() {
 		var qID int32
 		var number int
-		var qType, content string
+		var qType, kind, content string
 		var setID int32
+		var blocks questionEntity.Blocks
 		var aID int32
 		var code, aContent string
-		var imgURL string
+		var imgURL, matchText string
+		var position int
 
-		err := rows.Scan(&qID, &number, &qType, &content, &setID,
-			&aID, &code, &aContent, &imgURL)
+		err := rows.Scan(&qID, &number, &qType, &kind, &content, &setID, &blocks,
+			&aID, &code, &aContent, &imgURL, &matchText, &position)
 		if err != nil {
 			log.Error("[Repo][ListQuizQuestions] Error Scan: ", err)
 			return nil, app.NewAppError(500, "failed to scan quiz questions")
@@ -83,28 +93,49 @@ func (r *questionRepository) ListQuizQuestions(filter map[string]string) ([]ques
 				ID:      qID,
 				Number:  number,
 				Type:    qType,
+				Kind:    kind,
 				Content: content,
 				SetID:   setID,
+				Blocks:  blocks,
 				Answers: []questionEntity.ListAnswer{},
 			}
 			questions = append(questions, questionsMap[qID])
 		}
 
-		if aID != 0 {
-			answer := questionEntity.ListAnswer{
-				ID:      aID,
-				Code:    code,
-				Content: aContent,
-			}
-			if imgURL != "" {
-				answer.ImgURL = &imgURL
-			}
-			questionsMap[qID].Answers = append(questionsMap[qID].Answers, answer)
+		if aID == 0 {
+			continue
+		}
+		q := questionsMap[qID]
+		switch kind {
+		case questionEntity.KindFillBlank:
+			// The options of a blank are its accepted answers.
+			q.Blanks = max(q.Blanks, position)
+			continue
+		case questionEntity.KindEssay:
+			// The option of an essay is its model answer.
+			continue
+		case questionEntity.KindMatching:
+			q.MatchOptions = append(q.MatchOptions, matchText)
 		}
+
+		answer := questionEntity.ListAnswer{
+			ID:      aID,
+			Code:    code,
+			Content: aContent,
+		}
+		if imgURL != "" {
+			answer.ImgURL = &imgURL
+		}
+		q.Answers = append(q.Answers, answer)
 	}
 
 	finalQuestions := make([]questionEntity.ListQuestionQuiz, len(questions))
 	for i, q := range questions {
+		sort.Strings(q.MatchOptions)
+		if q.Kind == questionEntity.KindOrdering {
+			// Codes may follow the authored order, so list the items by content.
+			sort.SliceStable(q.Answers, func(i, j int) bool { return q.Answers[i].Content < q.Answers[j].Content })
+		}
 		finalQuestions[i] = *q
 	}
 
@@ -130,10 +161,11 @@ func (r *questionRepository) DeleteAnswer(id int32) error {
 func (r *questionRepository) EditAnswer(id int32, answer questionEntity.EditAnswer) error {
 	query := `
 		UPDATE answers 
-		SET question_id = $1, code = $2, content = $3, img_url = $4, is_answer = $5 
-		WHERE id = $6`
+		SET question_id = $1, code = $2, content = $3, img_url = $4, is_answer = $5, match_text = $6, position = $7 
+		WHERE id = $8`
 
-	_, err := r.db.Exec(query, answer.QuestionID, answer.Code, answer.Content, answer.ImgURL, answer.IsAnswer, id)
+	_, err := r.db.Exec(query, answer.QuestionID, answer.Code, answer.Content, answer.ImgURL, answer.IsAnswer,
+		answer.MatchText, answer.Position, id)
 	if err != nil {
 		log.Error("[Repo][EditAnswer] Error updating answer:", err)
 		return app.NewAppError(500, err.Error())
@@ -1,6 +1,8 @@
 package repo_test
 
 import (
+	"database/sql"
+	"errors"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
@@ -17,11 +19,11 @@ func TestAddQuestion(t *testing.T) {
 	repository := repo.NewQuestionRepository(db)
 
 	mock.ExpectExec(`INSERT INTO questions`).
-		WithArgs(1, "C4", "Sample Question", true, 1).
+		WithArgs(1, "C4", "single", "Sample Question", true, 1, []byte("[]"), 9).
 		WillReturnResult(sqlmock.NewResult(0, 1)) // Tidak mengembalikan ID, hanya affected rows
 
 	question := questionEntity.SetQuestion{SetID: 1, Number: 1, Type: "C4", Content: "Sample Question", IsQuiz: true}
-	err = repository.Add(question)
+	err = repository.Add(9, question)
 
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
@@ -75,7 +77,7 @@ func TestListAdmin(t *testing.T) {
 	mock.ExpectQuery(`SELECT q.id, q.number, q.type, q.content, q.is_quiz, q.set_id`).
 		WillReturnRows(mockRows)
 
-	questions, err := repository.ListAdmin(map[string]string{}, 1, 10)
+	questions, err := repository.ListAdmin(nil, map[string]string{}, 1, 10)
 
 	assert.NoError(t, err)
 	assert.Len(t, questions, 1)
@@ -90,10 +92,10 @@ func TestEditQuestion(t *testing.T) {
 
 	repository := repo.NewQuestionRepository(db)
 
-	editQuestion := questionEntity.EditQuestion{SetID: 9, Number: 2, Type: "C3", Content: "Updated Content", IsQuiz: false}
+	editQuestion := questionEntity.EditQuestion{SetID: 9, Number: 2, Type: "C3", Kind: "essay", Content: "Updated Content", IsQuiz: false}
 
 	mock.ExpectExec(`UPDATE questions SET number =`).
-		WithArgs(editQuestion.Number, editQuestion.Type, editQuestion.Content, editQuestion.IsQuiz, editQuestion.SetID, 1).
+		WithArgs(editQuestion.Number, editQuestion.Type, editQuestion.Kind, editQuestion.Content, editQuestion.IsQuiz, editQuestion.SetID, []byte("[]"), 1).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	err = repository.Edit(1, editQuestion)
@@ -101,3 +103,236 @@ func TestEditQuestion(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestListAnswerKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewQuestionRepository(db)
+
+	mockRows := sqlmock.NewRows([]string{"id", "number", "type", "kind", "content", "set_id", "answer_id", "code", "answer_content", "img_url", "is_answer", "match_text", "position"}).
+		AddRow(1, 1, "C1", "single", "Question 1", 3, 10, "a", "Answer A", "", false, nil, nil).
+		AddRow(1, 1, "C1", "single", "Question 1", 3, 11, "b", "Answer B", "http://img", true, nil, nil).
+		AddRow(2, 2, "C2", "essay", "Question 2", 3, 0, "", "", "", false, nil, nil)
+
+	mock.ExpectQuery(`SELECT q.id, q.number, q.type, q.kind, q.content, q.set_id`).
+		WithArgs(3).
+		WillReturnRows(mockRows)
+
+	questions, err := repository.ListAnswerKey(nil, 3)
+
+	assert.NoError(t, err)
+	assert.Len(t, questions, 2)
+	assert.Len(t, questions[0].Answers, 2)
+	assert.True(t, questions[0].Answers[1].IsAnswer)
+	assert.Equal(t, "http://img", *questions[0].Answers[1].ImgURL)
+	assert.Empty(t, questions[1].Answers)
+	assert.Equal(t, "essay", questions[1].Kind)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestImportQuestions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewQuestionRepository(db)
+	questions := []questionEntity.ImportQuestion{
+		{Row: 2, Question: questionEntity.SetQuestion{Number: 1, Type: "C1", Content: "2 + 2?", IsQuiz: true, SetID: 3},
+			Answers: []questionEntity.SetAnswer{{Code: "a", Content: "4", IsAnswer: true}, {Code: "b", Content: "5"}}},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM sets WHERE id = \$1\)`).WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(`INSERT INTO questions`).WithArgs(1, "C1", "single", "2 + 2?", true, 3, 9).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(10))
+	mock.ExpectExec(`INSERT INTO answers`).WithArgs(10, "a", "4", nil, true).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`INSERT INTO answers`).WithArgs(10, "b", "5", nil, false).WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectCommit()
+
+	answers, err := repository.Import(9, 3, questions)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, answers)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestImportQuestions_RollsBackOnFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewQuestionRepository(db)
+	questions := []questionEntity.ImportQuestion{
+		{Row: 2, Question: questionEntity.SetQuestion{Number: 1, Type: "C1", Content: "a", SetID: 3}},
+		{Row: 3, Question: questionEntity.SetQuestion{Number: 2, Type: "C1", Content: "b", SetID: 3}},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT EXISTS`).WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(`INSERT INTO questions`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(10))
+	mock.ExpectQuery(`INSERT INTO questions`).WillReturnError(errors.New("connection reset"))
+	mock.ExpectRollback()
+
+	_, err = repository.Import(9, 3, questions)
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExportQuestions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewQuestionRepository(db)
+
+	mockRows := sqlmock.NewRows([]string{"id", "set_id", "set", "lesson", "class", "number", "type", "kind", "content", "is_quiz",
+		"answer_id", "code", "answer_content", "img_url", "is_answer", "match_text", "position"}).
+		AddRow(1, 3, "Set A", "Math", "Class 1", 1, "C1", "single", "2 + 2?", true, 10, "a", "4", "", true, nil, nil).
+		AddRow(1, 3, "Set A", "Math", "Class 1", 1, "C1", "single", "2 + 2?", true, 11, "b", "5", "http://img", false, nil, nil).
+		AddRow(2, 3, "Set A", "Math", "Class 1", 2, "C4", "essay", "Explain", true, 0, "", "", "", false, nil, nil)
+
+	mock.ExpectQuery(`SELECT q.id, q.set_id, s.name, l.name, c.name(.|\n)+AND s.lesson_id = \$1`).
+		WithArgs("2").
+		WillReturnRows(mockRows)
+
+	questions, err := repository.Export(nil, map[string]string{"lesson_id": "2"})
+
+	assert.NoError(t, err)
+	assert.Len(t, questions, 2)
+	assert.Len(t, questions[0].Answers, 2)
+	assert.Equal(t, "Math", questions[0].Lesson)
+	assert.Equal(t, "http://img", *questions[0].Answers[1].ImgURL)
+	assert.Empty(t, questions[1].Answers)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListQuizQuestions_Blocks(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewQuestionRepository(db)
+
+	mockRows := sqlmock.NewRows([]string{"id", "number", "type", "kind", "content", "set_id", "blocks", "answer_id", "code", "answer_content", "img_url", "match_text", "position"}).
+		AddRow(1, 1, "C1", "single", "Luas persegi", 3, []byte(`[{"type":"text","text":"Luas persegi"},{"type":"latex","text":"s^2"}]`), 10, "a", "4", "", "", 0).
+		AddRow(2, 2, "C1", "single", "Plain", 3, []byte(`[]`), 0, "", "", "", "", 0)
+
+	mock.ExpectQuery(`SELECT q.id, q.number, q.type, q.kind, q.content, q.set_id, q.blocks`).
+		WithArgs("3").
+		WillReturnRows(mockRows)
+
+	questions, err := repository.ListQuizQuestions(nil, map[string]string{"set_id": "3"})
+
+	assert.NoError(t, err)
+	assert.Len(t, questions, 2)
+	assert.Equal(t, questionEntity.Blocks{{Type: "text", Text: "Luas persegi"}, {Type: "latex", Text: "s^2"}}, questions[0].Blocks)
+	assert.Empty(t, questions[1].Blocks)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListQuizQuestions_Kinds(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewQuestionRepository(db)
+
+	mockRows := sqlmock.NewRows([]string{"id", "number", "type", "kind", "content", "set_id", "blocks", "answer_id", "code", "answer_content", "img_url", "match_text", "position"}).
+		AddRow(1, 1, "C1", "matching", "Pasangkan", 3, []byte(`[]`), 10, "a", "Jepang", "", "Tokyo", 0).
+		AddRow(1, 1, "C1", "matching", "Pasangkan", 3, []byte(`[]`), 11, "b", "Indonesia", "", "Jakarta", 0).
+		AddRow(2, 2, "C1", "fill_blank", "___ adalah ibu kota ___", 3, []byte(`[]`), 20, "1a", "Jakarta", "", "", 1).
+		AddRow(2, 2, "C1", "fill_blank", "___ adalah ibu kota ___", 3, []byte(`[]`), 21, "2a", "Indonesia", "", "", 2).
+		AddRow(2, 2, "C1", "fill_blank", "___ adalah ibu kota ___", 3, []byte(`[]`), 22, "2b", "RI", "", "", 2).
+		AddRow(3, 3, "C1", "ordering", "Urutkan", 3, []byte(`[]`), 30, "a", "Tiga", "", "", 3).
+		AddRow(3, 3, "C1", "ordering", "Urutkan", 3, []byte(`[]`), 31, "b", "Dua", "", "", 2).
+		AddRow(4, 4, "C1", "essay", "Jelaskan fotosintesis", 3, []byte(`[]`), 40, "a", "Tumbuhan mengubah cahaya menjadi energi", "", "", 0)
+
+	mock.ExpectQuery(`SELECT q.id, q.number, q.type, q.kind, q.content, q.set_id, q.blocks`).
+		WithArgs("3").
+		WillReturnRows(mockRows)
+
+	questions, err := repository.ListQuizQuestions(nil, map[string]string{"set_id": "3"})
+
+	assert.NoError(t, err)
+	assert.Len(t, questions, 4)
+	assert.Equal(t, []string{"Jakarta", "Tokyo"}, questions[0].MatchOptions)
+	assert.Len(t, questions[0].Answers, 2)
+	assert.Empty(t, questions[1].Answers)
+	assert.Equal(t, 2, questions[1].Blanks)
+	assert.Equal(t, "Dua", questions[2].Answers[0].Content)
+	assert.Equal(t, "essay", questions[3].Kind)
+	assert.Empty(t, questions[3].Answers)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestQuestionOwnership(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewQuestionRepository(db)
+
+	mock.ExpectQuery(`SELECT q.owner_id, s.owner_id, s.is_shared\s+FROM questions q\s+JOIN sets s`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"owner_id", "owner_id", "is_shared"}).AddRow(nil, 8, true))
+
+	ownership, err := repository.Ownership(nil, 1)
+	assert.NoError(t, err)
+	assert.Nil(t, ownership.OwnerID)
+	assert.Equal(t, int32(8), *ownership.SetOwnerID)
+	assert.True(t, ownership.SetShared)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAnswerOwnership_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewQuestionRepository(db)
+
+	mock.ExpectQuery(`FROM answers a\s+JOIN questions q`).
+		WithArgs(5).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err = repository.AnswerOwnership(nil, 5)
+	assert.Equal(t, "answer not found", err.Error())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestQuestionOwnership_OtherSchool(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewQuestionRepository(db)
+	schoolID := int32(2)
+
+	mock.ExpectQuery(`FROM questions q\s+JOIN sets s(.|\n)+AND s.school_id = \$2`).
+		WithArgs(1, schoolID).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err = repository.Ownership(&schoolID, 1)
+	assert.Equal(t, "question not found", err.Error())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListAnswerKey_SchoolScope(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewQuestionRepository(db)
+	schoolID := int32(2)
+
+	mock.ExpectQuery(`WHERE q.set_id = \$1(.|\n)+AND \(q.school_id = \$2 OR q.school_id IS NULL\)`).
+		WithArgs(3, schoolID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "number", "type", "kind", "content", "set_id", "answer_id", "code", "answer_content", "img_url", "is_answer", "match_text", "position"}))
+
+	questions, err := repository.ListAnswerKey(&schoolID, 3)
+	assert.NoError(t, err)
+	assert.Empty(t, questions)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,102 @@
+package svc
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+
+	questionEntity "github.com/ghulammuzz/misterblast/internal/question/entity"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/storage"
+)
+
+// Commands that make LaTeX renderers emit links, raw HTML or read files.
+var unsafeLatex = regexp.MustCompile(`\\(href|url|html[a-zA-Z]*|includegraphics|input|include|def|let|newcommand|renewcommand|write|openout|immediate)\b`)
+
+// plainText removes control characters other than newlines and tabs.
+func plainText(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) && r != '\n' && r != '\t' {
+			return -1
+		}
+		return r
+	}, s)
+	return strings.TrimSpace(s)
+}
+
+// sanitizeText turns user input into text that is safe to serve: markup is
+// escaped rather than stripped, so it shows as typed and is never rendered,
+// however the tags are nested. Text sent back as it was served is unescaped
+// first, so saving it again does not escape it twice.
+func sanitizeText(s string) string {
+	return html.EscapeString(html.UnescapeString(plainText(s)))
+}
+
+// safeURL accepts only URLs of objects in media storage, so blocks can neither
+// run script through javascript: or data: URLs nor point readers and the
+// worksheet renderer at other hosts.
+func safeURL(store storage.Storage, raw string) bool {
+	if store == nil {
+		return false
+	}
+	_, ok := storage.Key(store, raw)
+	return ok
+}
+
+// sanitizeQuestion cleans the content and blocks of a question. Content left
+// empty next to blocks is filled with the text of the blocks so that lists and
+// exports still have a summary.
+func sanitizeQuestion(store storage.Storage, content string, blocks questionEntity.Blocks) (string, questionEntity.Blocks, error) {
+	if len(blocks) > questionEntity.MaxBlocks {
+		return "", nil, app.NewAppError(400, fmt.Sprintf("a question has at most %d blocks", questionEntity.MaxBlocks))
+	}
+
+	var clean questionEntity.Blocks
+	var summary []string
+	for i, b := range blocks {
+		block := questionEntity.Block{Type: b.Type}
+		switch b.Type {
+		case questionEntity.BlockText:
+			block.Text = sanitizeText(b.Text)
+			if block.Text == "" {
+				return "", nil, app.NewAppError(400, fmt.Sprintf("blocks[%d]: text is required", i))
+			}
+			summary = append(summary, block.Text)
+		case questionEntity.BlockLatex:
+			// LaTeX keeps & for alignment; without < and > it cannot hold markup.
+			block.Text = plainText(strings.NewReplacer("<", "\\lt ", ">", "\\gt ").Replace(b.Text))
+			if block.Text == "" {
+				return "", nil, app.NewAppError(400, fmt.Sprintf("blocks[%d]: latex is required", i))
+			}
+			if cmd := unsafeLatex.FindString(block.Text); cmd != "" {
+				return "", nil, app.NewAppError(400, fmt.Sprintf("blocks[%d]: latex command %s is not allowed", i, cmd))
+			}
+		case questionEntity.BlockImage, questionEntity.BlockAudio:
+			if !safeURL(store, strings.TrimSpace(b.URL)) {
+				return "", nil, app.NewAppError(400, fmt.Sprintf("blocks[%d]: url must be a file uploaded to media storage", i))
+			}
+			block.URL = strings.TrimSpace(b.URL)
+			if b.Type == questionEntity.BlockImage {
+				block.Alt = sanitizeText(b.Alt)
+			}
+		default:
+			return "", nil, app.NewAppError(400, fmt.Sprintf("blocks[%d]: unknown block type %q", i, b.Type))
+		}
+		clean = append(clean, block)
+	}
+
+	content = sanitizeText(content)
+	if content == "" {
+		content = strings.Join(summary, " ")
+	}
+	if content == "" && len(clean) > 0 {
+		content = "[" + clean[0].Type + "]"
+	}
+	if content == "" {
+		return "", nil, app.NewAppError(400, "content or blocks is required")
+	}
+
+	return content, clean, nil
+}
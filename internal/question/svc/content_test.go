@@ -0,0 +1,29 @@
+package svc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitizeText(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{"Berapa 2 + 2?", "Berapa 2 + 2?"},
+		{"<script>alert(1)</script>", "&lt;script&gt;alert(1)&lt;/script&gt;"},
+		{"<<script>script>alert(1)<</script>/script>", "&lt;&lt;script&gt;script&gt;alert(1)&lt;&lt;/script&gt;/script&gt;"},
+		{"<scr<b>ipt>alert(1)</scr</b>ipt>", "&lt;scr&lt;b&gt;ipt&gt;alert(1)&lt;/scr&lt;/b&gt;ipt&gt;"},
+		{"<img src=x onerror=alert(1)>", "&lt;img src=x onerror=alert(1)&gt;"},
+		{`<a href="javascript:alert(1)">x</a>`, "&lt;a href=&#34;javascript:alert(1)&#34;&gt;x&lt;/a&gt;"},
+		{"<!-- <script> -->alert(1)", "&lt;!-- &lt;script&gt; --&gt;alert(1)"},
+		{"&lt;script&gt;", "&lt;script&gt;"},
+		{"&amp;lt;script&amp;gt;", "&amp;lt;script&amp;gt;"},
+		{"  2 < 3 & 4 > 1\x00 ", "2 &lt; 3 &amp; 4 &gt; 1"},
+	} {
+		got := sanitizeText(tc.in)
+		assert.Equal(t, tc.want, got, tc.in)
+		assert.NotContains(t, got, "<", tc.in)
+		assert.Equal(t, got, sanitizeText(got), "saving served text again must not change it")
+	}
+}
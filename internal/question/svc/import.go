@@ -0,0 +1,65 @@
+package svc
+
+import (
+	"fmt"
+
+	questionEntity "github.com/ghulammuzz/misterblast/internal/question/entity"
+	"github.com/ghulammuzz/misterblast/pkg/log"
+)
+
+// ImportQuestions rejects rows whose number is repeated in the sheet or
+// already used in the set, reporting every such row. Only a clean sheet is
+// written, all in one transaction.
+func (s *questionService) ImportQuestions(userID int32, isAdmin bool, schoolID *int32, setID int32, questions []questionEntity.ImportQuestion) (questionEntity.ImportResult, error) {
+	var result questionEntity.ImportResult
+
+	if err := editable(userID, isAdmin, schoolID, s.repo.SetOwnership, setID); err != nil {
+		return result, err
+	}
+
+	taken, err := s.repo.ListNumbers(setID)
+	if err != nil {
+		return result, err
+	}
+
+	firstRow := map[int]int{}
+	for i, q := range questions {
+		questions[i].Question.Content = sanitizeText(q.Question.Content)
+		if questions[i].Question.Content == "" {
+			result.Errors = append(result.Errors, questionEntity.ImportRowError{
+				Row:    q.Row,
+				Errors: map[string]string{"Content": "required"},
+			})
+			continue
+		}
+
+		number := q.Question.Number
+		switch {
+		case taken[number]:
+			result.Errors = append(result.Errors, questionEntity.ImportRowError{
+				Row:    q.Row,
+				Errors: map[string]string{"Number": "question number already exists in this set"},
+			})
+		case firstRow[number] != 0:
+			result.Errors = append(result.Errors, questionEntity.ImportRowError{
+				Row:    q.Row,
+				Errors: map[string]string{"Number": fmt.Sprintf("duplicates row %d", firstRow[number])},
+			})
+		default:
+			firstRow[number] = q.Row
+		}
+	}
+	if len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	answers, err := s.repo.Import(userID, setID, questions)
+	if err != nil {
+		log.Error("[Svc][ImportQuestions] Error: ", err)
+		return result, err
+	}
+
+	result.Questions = len(questions)
+	result.Answers = answers
+	return result, nil
+}
@@ -4,42 +4,116 @@ import (
 	questionEntity "github.com/ghulammuzz/misterblast/internal/question/entity"
 	"github.com/ghulammuzz/misterblast/internal/question/repo"
 	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/storage"
 )
 
+var errNotEditable = app.NewAppError(403, "you can only change your own questions or those of your own or shared sets")
+
+// QuestionService takes the caller, whether they are an admin and their
+// school on every write; see questionEntity.Ownership for who may change a
+// question. Reads take the school too and also return the shared questions
+// without a school.
 type QuestionService interface {
 	// Questions
-	AddQuestion(question questionEntity.SetQuestion) error
-	ListQuestions(filter map[string]string) ([]questionEntity.ListQuestionExample, error)
-	ListQuizQuestions(filter map[string]string) ([]questionEntity.ListQuestionQuiz, error)
-	DeleteQuestion(id int32) error
-	DetailQuestion(id int32) (questionEntity.DetailQuestionExample, error)
-	EditQuestion(id int32, question questionEntity.EditQuestion) error
+	AddQuestion(userID int32, isAdmin bool, schoolID *int32, question questionEntity.SetQuestion) error
+	ListQuestions(schoolID *int32, filter map[string]string) ([]questionEntity.ListQuestionExample, error)
+	ListQuizQuestions(schoolID *int32, filter map[string]string) ([]questionEntity.ListQuestionQuiz, error)
+	DeleteQuestion(userID int32, isAdmin bool, schoolID *int32, id int32) error
+	DetailQuestion(schoolID *int32, id int32) (questionEntity.DetailQuestionExample, error)
+	EditQuestion(userID int32, isAdmin bool, schoolID *int32, id int32, question questionEntity.EditQuestion) error
 
 	// Answer
-	AddQuizAnswer(answer questionEntity.SetAnswer) error
-	DeleteAnswer(id int32) error
-	EditQuizAnswer(id int32, answer questionEntity.EditAnswer) error
+	AddQuizAnswer(userID int32, isAdmin bool, schoolID *int32, answer questionEntity.SetAnswer) error
+	DeleteAnswer(userID int32, isAdmin bool, schoolID *int32, id int32) error
+	EditQuizAnswer(userID int32, isAdmin bool, schoolID *int32, id int32, answer questionEntity.EditAnswer) error
 
 	// Admin
-	ListAdmin(filter map[string]string, page, limit int) ([]questionEntity.ListQuestionAdmin, error)
+	ListAdmin(schoolID *int32, filter map[string]string, page, limit int) ([]questionEntity.ListQuestionAdmin, error)
+	ListAnswerKey(schoolID *int32, setID int32) ([]questionEntity.ListQuestionKey, error)
+
+	// Import
+	ImportQuestions(userID int32, isAdmin bool, schoolID *int32, setID int32, questions []questionEntity.ImportQuestion) (questionEntity.ImportResult, error)
+
+	// Export
+	ExportQuestions(schoolID *int32, filter map[string]string) ([]questionEntity.ExportQuestion, error)
+
+	// Worksheet
+	Worksheet(schoolID *int32, setID int32, variant int, withKey bool) (questionEntity.Worksheet, error)
 }
 
 type questionService struct {
-	repo repo.QuestionRepository
+	repo  repo.QuestionRepository
+	store storage.Storage
 }
 
-func NewQuestionService(repo repo.QuestionRepository) QuestionService {
-	return &questionService{repo: repo}
+func NewQuestionService(repo repo.QuestionRepository, store storage.Storage) QuestionService {
+	return &questionService{repo: repo, store: store}
 }
-func (s *questionService) AddQuizAnswer(answer questionEntity.SetAnswer) error {
+
+func (s *questionService) AddQuizAnswer(userID int32, isAdmin bool, schoolID *int32, answer questionEntity.SetAnswer) error {
+	if err := editable(userID, isAdmin, schoolID, s.repo.Ownership, answer.QuestionID); err != nil {
+		return err
+	}
+	if err := s.checkAnswer(answer.Answer()); err != nil {
+		return err
+	}
 	return s.repo.AddQuizAnswer(answer)
 }
 
-func (s *questionService) EditQuizAnswer(id int32, question questionEntity.EditAnswer) error {
-	return s.repo.EditAnswer(id, question)
+// EditQuizAnswer may move the answer to another question, so both its
+// current and its new question must be editable.
+func (s *questionService) EditQuizAnswer(userID int32, isAdmin bool, schoolID *int32, id int32, answer questionEntity.EditAnswer) error {
+	if err := editable(userID, isAdmin, schoolID, s.repo.AnswerOwnership, id); err != nil {
+		return err
+	}
+	if err := editable(userID, isAdmin, schoolID, s.repo.Ownership, answer.QuestionID); err != nil {
+		return err
+	}
+	if err := s.checkAnswer(answer.Answer(id)); err != nil {
+		return err
+	}
+	return s.repo.EditAnswer(id, answer)
+}
+
+// editable fails with errNotEditable unless the caller may edit what lookup
+// returns the ownership of. lookup reports what lies outside schoolID as not
+// found.
+func editable(userID int32, isAdmin bool, schoolID *int32, lookup func(schoolID *int32, id int32) (questionEntity.Ownership, error), id int32) error {
+	ownership, err := lookup(schoolID, id)
+	if err != nil {
+		return err
+	}
+	if !ownership.CanEdit(userID, isAdmin) {
+		return errNotEditable
+	}
+	return nil
+}
+
+// checkAnswer checks the other options of the answer's question together with
+// answer against the rules of the question kind.
+func (s *questionService) checkAnswer(answer questionEntity.Answer) error {
+	question, err := s.repo.QuestionAnswerKey(answer.QuestionID)
+	if err != nil {
+		return err
+	}
+
+	answers := []questionEntity.Answer{answer}
+	for _, a := range question.Answers {
+		if a.ID != answer.ID || answer.ID == 0 {
+			answers = append(answers, a)
+		}
+	}
+	if err := questionEntity.CheckAnswers(question.Kind, answers); err != nil {
+		return app.NewAppError(400, err.Error())
+	}
+	return nil
 }
 
-func (s *questionService) AddQuestion(q questionEntity.SetQuestion) error {
+func (s *questionService) AddQuestion(userID int32, isAdmin bool, schoolID *int32, q questionEntity.SetQuestion) error {
+	if err := editable(userID, isAdmin, schoolID, s.repo.SetOwnership, q.SetID); err != nil {
+		return err
+	}
+
 	exists, err := s.repo.Exists(q.SetID, q.Number)
 	if err != nil {
 		return err
@@ -48,41 +122,88 @@ func (s *questionService) AddQuestion(q questionEntity.SetQuestion) error {
 		return app.NewAppError(409, "question number already exists in this set")
 	}
 
-	return s.repo.Add(q)
+	if q.Content, q.Blocks, err = sanitizeQuestion(s.store, q.Content, q.Blocks); err != nil {
+		return err
+	}
+	return s.repo.Add(userID, q)
 }
 
-func (s *questionService) ListQuestions(filter map[string]string) ([]questionEntity.ListQuestionExample, error) {
-	return s.repo.List(filter)
+func (s *questionService) ListQuestions(schoolID *int32, filter map[string]string) ([]questionEntity.ListQuestionExample, error) {
+	return s.repo.List(schoolID, filter)
 }
 
-func (s *questionService) DeleteQuestion(id int32) error {
+func (s *questionService) DeleteQuestion(userID int32, isAdmin bool, schoolID *int32, id int32) error {
+	if err := editable(userID, isAdmin, schoolID, s.repo.Ownership, id); err != nil {
+		return err
+	}
 	return s.repo.Delete(id)
 }
 
 // Quiz
 
-func (s *questionService) ListQuizQuestions(filter map[string]string) ([]questionEntity.ListQuestionQuiz, error) {
-	return s.repo.ListQuizQuestions(filter)
+func (s *questionService) ListQuizQuestions(schoolID *int32, filter map[string]string) ([]questionEntity.ListQuestionQuiz, error) {
+	return s.repo.ListQuizQuestions(schoolID, filter)
 }
 
 // admin
 
-func (s *questionService) ListAdmin(filter map[string]string, page, limit int) ([]questionEntity.ListQuestionAdmin, error) {
-	questions, err := s.repo.ListAdmin(filter, page, limit)
+func (s *questionService) ListAdmin(schoolID *int32, filter map[string]string, page, limit int) ([]questionEntity.ListQuestionAdmin, error) {
+	questions, err := s.repo.ListAdmin(schoolID, filter, page, limit)
 	if err != nil {
 		return nil, err
 	}
 	return questions, nil
 }
 
-func (s *questionService) EditQuestion(id int32, question questionEntity.EditQuestion) error {
+func (s *questionService) ListAnswerKey(schoolID *int32, setID int32) ([]questionEntity.ListQuestionKey, error) {
+	return s.repo.ListAnswerKey(schoolID, setID)
+}
+
+// EditQuestion may move the question to another set, which must be editable
+// too.
+func (s *questionService) EditQuestion(userID int32, isAdmin bool, schoolID *int32, id int32, question questionEntity.EditQuestion) error {
+	if err := editable(userID, isAdmin, schoolID, s.repo.Ownership, id); err != nil {
+		return err
+	}
+
+	var err error
+	if question.Content, question.Blocks, err = sanitizeQuestion(s.store, question.Content, question.Blocks); err != nil {
+		return err
+	}
+
+	// A new kind must still fit the options already authored.
+	current, err := s.repo.QuestionAnswerKey(id)
+	if err != nil {
+		return err
+	}
+	if err := questionEntity.CheckAnswers(question.Kind, current.Answers); err != nil {
+		return app.NewAppError(409, err.Error())
+	}
+	if question.SetID != current.SetID {
+		if err := editable(userID, isAdmin, schoolID, s.repo.SetOwnership, question.SetID); err != nil {
+			return err
+		}
+	}
+
 	return s.repo.Edit(id, question)
 }
 
-func (s *questionService) DeleteAnswer(id int32) error {
+func (s *questionService) DeleteAnswer(userID int32, isAdmin bool, schoolID *int32, id int32) error {
+	if err := editable(userID, isAdmin, schoolID, s.repo.AnswerOwnership, id); err != nil {
+		return err
+	}
 	return s.repo.DeleteAnswer(id)
 }
 
-func (s *questionService) DetailQuestion(id int32) (questionEntity.DetailQuestionExample, error) {
-	return s.repo.Detail(id)
+func (s *questionService) DetailQuestion(schoolID *int32, id int32) (questionEntity.DetailQuestionExample, error) {
+	return s.repo.Detail(schoolID, id)
+}
+
+// export
+
+func (s *questionService) ExportQuestions(schoolID *int32, filter map[string]string) ([]questionEntity.ExportQuestion, error) {
+	if len(filter) == 0 {
+		return nil, app.NewAppError(400, "set_id, lesson_id or class_id is required")
+	}
+	return s.repo.Export(schoolID, filter)
 }
@@ -1,14 +1,20 @@
 package svc_test
 
 import (
+	"fmt"
 	"testing"
 
 	questionEntity "github.com/ghulammuzz/misterblast/internal/question/entity"
 	"github.com/ghulammuzz/misterblast/internal/question/svc"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/storage"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
+// media is the storage block URLs must point into.
+var media = &storage.Local{BaseURL: "/media"}
+
 // MockRepo untuk menggantikan repo dalam pengujian
 type MockQuestionRepo struct {
 	mock.Mock
@@ -19,8 +25,8 @@ func (m *MockQuestionRepo) AddQuizAnswer(question questionEntity.SetAnswer) erro
 	return args.Error(0)
 }
 
-func (m *MockQuestionRepo) Add(q questionEntity.SetQuestion) error {
-	args := m.Called(q)
+func (m *MockQuestionRepo) Add(ownerID int32, q questionEntity.SetQuestion) error {
+	args := m.Called(ownerID, q)
 	return args.Error(0)
 }
 
@@ -29,13 +35,13 @@ func (m *MockQuestionRepo) Exists(setID int32, number int) (bool, error) {
 	return args.Bool(0), args.Error(1)
 }
 
-func (m *MockQuestionRepo) List(filter map[string]string) ([]questionEntity.ListQuestionExample, error) {
-	args := m.Called(filter)
+func (m *MockQuestionRepo) List(schoolID *int32, filter map[string]string) ([]questionEntity.ListQuestionExample, error) {
+	args := m.Called(schoolID, filter)
 	return args.Get(0).([]questionEntity.ListQuestionExample), args.Error(1)
 }
 
-func (m *MockQuestionRepo) Detail(id int32) (questionEntity.DetailQuestionExample, error) {
-	args := m.Called()
+func (m *MockQuestionRepo) Detail(schoolID *int32, id int32) (questionEntity.DetailQuestionExample, error) {
+	args := m.Called(schoolID, id)
 	return args.Get(0).(questionEntity.DetailQuestionExample), args.Error(1)
 }
 
@@ -49,16 +55,41 @@ func (m *MockQuestionRepo) DeleteAnswer(id int32) error {
 	return args.Error(0)
 }
 
-func (m *MockQuestionRepo) ListQuizQuestions(filter map[string]string) ([]questionEntity.ListQuestionQuiz, error) {
-	args := m.Called(filter)
+func (m *MockQuestionRepo) ListQuizQuestions(schoolID *int32, filter map[string]string) ([]questionEntity.ListQuestionQuiz, error) {
+	args := m.Called(schoolID, filter)
 	return args.Get(0).([]questionEntity.ListQuestionQuiz), args.Error(1)
 }
 
-func (m *MockQuestionRepo) ListAdmin(filter map[string]string, page, limit int) ([]questionEntity.ListQuestionAdmin, error) {
-	args := m.Called(filter, page, limit)
+func (m *MockQuestionRepo) ListAdmin(schoolID *int32, filter map[string]string, page, limit int) ([]questionEntity.ListQuestionAdmin, error) {
+	args := m.Called(schoolID, filter, page, limit)
 	return args.Get(0).([]questionEntity.ListQuestionAdmin), args.Error(1)
 }
 
+func (m *MockQuestionRepo) ListAnswerKey(schoolID *int32, setID int32) ([]questionEntity.ListQuestionKey, error) {
+	args := m.Called(schoolID, setID)
+	return args.Get(0).([]questionEntity.ListQuestionKey), args.Error(1)
+}
+
+func (m *MockQuestionRepo) QuestionAnswerKey(id int32) (questionEntity.ListQuestionKey, error) {
+	args := m.Called(id)
+	return args.Get(0).(questionEntity.ListQuestionKey), args.Error(1)
+}
+
+func (m *MockQuestionRepo) Import(ownerID, setID int32, questions []questionEntity.ImportQuestion) (int, error) {
+	args := m.Called(ownerID, setID, questions)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockQuestionRepo) ListNumbers(setID int32) (map[int]bool, error) {
+	args := m.Called(setID)
+	return args.Get(0).(map[int]bool), args.Error(1)
+}
+
+func (m *MockQuestionRepo) Export(schoolID *int32, filter map[string]string) ([]questionEntity.ExportQuestion, error) {
+	args := m.Called(schoolID, filter)
+	return args.Get(0).([]questionEntity.ExportQuestion), args.Error(1)
+}
+
 func (m *MockQuestionRepo) Edit(id int32, question questionEntity.EditQuestion) error {
 	args := m.Called(id, question)
 	return args.Error(0)
@@ -69,17 +100,41 @@ func (m *MockQuestionRepo) EditAnswer(id int32, answer questionEntity.EditAnswer
 	return args.Error(0)
 }
 
+func (m *MockQuestionRepo) SetOwnership(schoolID *int32, setID int32) (questionEntity.Ownership, error) {
+	args := m.Called(schoolID, setID)
+	return args.Get(0).(questionEntity.Ownership), args.Error(1)
+}
+
+func (m *MockQuestionRepo) Ownership(schoolID *int32, id int32) (questionEntity.Ownership, error) {
+	args := m.Called(schoolID, id)
+	return args.Get(0).(questionEntity.Ownership), args.Error(1)
+}
+
+func (m *MockQuestionRepo) AnswerOwnership(schoolID *int32, id int32) (questionEntity.Ownership, error) {
+	args := m.Called(schoolID, id)
+	return args.Get(0).(questionEntity.Ownership), args.Error(1)
+}
+
+func school(id int32) *int32 {
+	return &id
+}
+
+// setOwnedBy is the ownership of content in a private set of userID.
+func setOwnedBy(userID int32) questionEntity.Ownership {
+	return questionEntity.Ownership{SetOwnerID: &userID}
+}
+
 func TestListAdminService(t *testing.T) {
 	mockRepo := new(MockQuestionRepo)
-	service := svc.NewQuestionService(mockRepo)
+	service := svc.NewQuestionService(mockRepo, media)
 
 	mockData := []questionEntity.ListQuestionAdmin{
 		{ID: 1, Number: 1, Type: "C5", Content: "Question 1", IsQuiz: true, SetID: 1, SetName: "Set 1", LessonName: "Lesson 1", ClassName: "Class 1"},
 	}
 
-	mockRepo.On("ListAdmin", mock.Anything, 1, 10).Return(mockData, nil)
+	mockRepo.On("ListAdmin", school(1), mock.Anything, 1, 10).Return(mockData, nil)
 
-	questions, err := service.ListAdmin(map[string]string{}, 1, 10)
+	questions, err := service.ListAdmin(school(1), map[string]string{}, 1, 10)
 	assert.NoError(t, err)
 	assert.Len(t, questions, 1)
 	assert.Equal(t, "Question 1", questions[0].Content)
@@ -87,48 +142,50 @@ func TestListAdminService(t *testing.T) {
 
 func TestDetailQuestionService(t *testing.T) {
 	mockRepo := new(MockQuestionRepo)
-	service := svc.NewQuestionService(mockRepo)
+	service := svc.NewQuestionService(mockRepo, media)
 
 	mockData := questionEntity.DetailQuestionExample{
 		ID: 1, Number: 1, Type: "C5", Content: "Question 1aaa", SetID: 9,
 	}
 
-	mockRepo.On("Detail", mock.Anything).Return(mockData, nil)
+	mockRepo.On("Detail", school(1), int32(1)).Return(mockData, nil)
 
-	questions, err := service.DetailQuestion(1)
+	questions, err := service.DetailQuestion(school(1), 1)
 	assert.NoError(t, err)
 	assert.Equal(t, "Question 1aaa", questions.Content)
 }
 
 func TestAddQuestionService(t *testing.T) {
 	mockRepo := new(MockQuestionRepo)
-	service := svc.NewQuestionService(mockRepo)
+	service := svc.NewQuestionService(mockRepo, media)
 
 	question := questionEntity.SetQuestion{SetID: 1, Number: 1, Content: "New Question"}
 
+	mockRepo.On("SetOwnership", school(1), question.SetID).Return(setOwnedBy(9), nil)
 	mockRepo.On("Exists", question.SetID, question.Number).Return(false, nil)
-	mockRepo.On("Add", question).Return(nil)
+	mockRepo.On("Add", int32(9), question).Return(nil)
 
-	err := service.AddQuestion(question)
+	err := service.AddQuestion(9, false, school(1), question)
 	assert.NoError(t, err)
 	mockRepo.AssertCalled(t, "Exists", question.SetID, question.Number)
-	mockRepo.AssertCalled(t, "Add", question)
+	mockRepo.AssertCalled(t, "Add", int32(9), question)
 }
 
 func TestDeleteQuestionService(t *testing.T) {
 	mockRepo := new(MockQuestionRepo)
-	service := svc.NewQuestionService(mockRepo)
+	service := svc.NewQuestionService(mockRepo, media)
 
+	mockRepo.On("Ownership", school(1), int32(1)).Return(setOwnedBy(9), nil)
 	mockRepo.On("Delete", int32(1)).Return(nil)
 
-	err := service.DeleteQuestion(1)
+	err := service.DeleteQuestion(9, false, school(1), 1)
 	assert.NoError(t, err)
 	mockRepo.AssertCalled(t, "Delete", int32(1))
 }
 
 func TestEditQuestionService(t *testing.T) {
 	mockRepo := new(MockQuestionRepo)
-	service := svc.NewQuestionService(mockRepo)
+	service := svc.NewQuestionService(mockRepo, media)
 
 	question := questionEntity.EditQuestion{
 		Number:  1,
@@ -138,17 +195,68 @@ func TestEditQuestionService(t *testing.T) {
 		SetID:   1,
 	}
 
+	mockRepo.On("Ownership", school(1), int32(1)).Return(setOwnedBy(9), nil)
+	mockRepo.On("QuestionAnswerKey", int32(1)).Return(questionEntity.ListQuestionKey{ID: 1, SetID: 1, Kind: questionEntity.KindSingle}, nil)
 	mockRepo.On("Edit", int32(1), question).Return(nil)
 
-	err := service.EditQuestion(1, question)
+	err := service.EditQuestion(9, false, school(1), 1, question)
 
 	assert.NoError(t, err)
 	mockRepo.AssertCalled(t, "Edit", int32(1), question)
 }
 
+func TestEditQuestionService_KindMismatch(t *testing.T) {
+	mockRepo := new(MockQuestionRepo)
+	service := svc.NewQuestionService(mockRepo, media)
+
+	question := questionEntity.EditQuestion{Number: 1, Type: "C2", Kind: questionEntity.KindOrdering, Content: "Urutkan", SetID: 1}
+
+	mockRepo.On("Ownership", school(1), int32(1)).Return(setOwnedBy(9), nil)
+	mockRepo.On("QuestionAnswerKey", int32(1)).Return(questionEntity.ListQuestionKey{ID: 1, Kind: questionEntity.KindSingle,
+		Answers: []questionEntity.Answer{{ID: 1, QuestionID: 1, Code: "a", IsAnswer: true}}}, nil)
+
+	err := service.EditQuestion(9, false, school(1), 1, question)
+	assert.Error(t, err)
+	assert.Equal(t, "ordering answers require a position", err.Error())
+	mockRepo.AssertNotCalled(t, "Edit", mock.Anything, mock.Anything)
+}
+
+func TestAddQuizAnswerService_SecondCorrect(t *testing.T) {
+	mockRepo := new(MockQuestionRepo)
+	service := svc.NewQuestionService(mockRepo, media)
+
+	answer := questionEntity.SetAnswer{QuestionID: 8, Code: "b", Content: "Salah", IsAnswer: true}
+
+	mockRepo.On("Ownership", school(1), int32(8)).Return(setOwnedBy(9), nil)
+	mockRepo.On("QuestionAnswerKey", int32(8)).Return(questionEntity.ListQuestionKey{ID: 8, Kind: questionEntity.KindTrueFalse,
+		Answers: []questionEntity.Answer{{ID: 1, QuestionID: 8, Code: "a", Content: "Benar", IsAnswer: true}}}, nil)
+
+	err := service.AddQuizAnswer(9, false, school(1), answer)
+	assert.Error(t, err)
+	assert.Equal(t, "true_false questions have only one correct answer", err.Error())
+	mockRepo.AssertNotCalled(t, "AddQuizAnswer", mock.Anything)
+}
+
+func TestAddQuizAnswerService_Matching(t *testing.T) {
+	mockRepo := new(MockQuestionRepo)
+	service := svc.NewQuestionService(mockRepo, media)
+
+	matchText := "Jakarta"
+	answer := questionEntity.SetAnswer{QuestionID: 8, Code: "a", Content: "Indonesia", MatchText: &matchText}
+
+	mockRepo.On("Ownership", school(1), int32(8)).Return(setOwnedBy(9), nil)
+	mockRepo.On("QuestionAnswerKey", int32(8)).Return(questionEntity.ListQuestionKey{ID: 8, Kind: questionEntity.KindMatching,
+		Answers: []questionEntity.Answer{}}, nil)
+	mockRepo.On("AddQuizAnswer", answer).Return(nil)
+
+	err := service.AddQuizAnswer(9, false, school(1), answer)
+	assert.NoError(t, err)
+	mockRepo.AssertCalled(t, "AddQuizAnswer", answer)
+}
+
 func TestEditAnswerService(t *testing.T) {
 	mockRepo := new(MockQuestionRepo)
-	service := svc.NewQuestionService(mockRepo)
+	service := svc.NewQuestionService(mockRepo, media)
 
 	answer := questionEntity.EditAnswer{
 		QuestionID: 8,
@@ -158,9 +266,13 @@ func TestEditAnswerService(t *testing.T) {
 		IsAnswer:   true,
 	}
 
+	mockRepo.On("AnswerOwnership", school(1), int32(1)).Return(setOwnedBy(9), nil)
+	mockRepo.On("Ownership", school(1), int32(8)).Return(setOwnedBy(9), nil)
+	mockRepo.On("QuestionAnswerKey", int32(8)).Return(questionEntity.ListQuestionKey{ID: 8, Kind: questionEntity.KindSingle,
+		Answers: []questionEntity.Answer{{ID: 1, QuestionID: 8, Code: "a", IsAnswer: true}, {ID: 2, QuestionID: 8, Code: "b"}}}, nil)
 	mockRepo.On("EditAnswer", int32(1), answer).Return(nil)
 
-	err := service.EditQuizAnswer(1, answer)
+	err := service.EditQuizAnswer(9, false, school(1), 1, answer)
 
 	assert.NoError(t, err)
 	mockRepo.AssertCalled(t, "EditAnswer", int32(1), answer)
@@ -168,11 +280,278 @@ func TestEditAnswerService(t *testing.T) {
 
 func TestDeleteAnswerService(t *testing.T) {
 	mockRepo := new(MockQuestionRepo)
-	service := svc.NewQuestionService(mockRepo)
+	service := svc.NewQuestionService(mockRepo, media)
 
+	mockRepo.On("AnswerOwnership", school(1), int32(8)).Return(setOwnedBy(9), nil)
 	mockRepo.On("DeleteAnswer", int32(8)).Return(nil)
 
-	err := service.DeleteAnswer(8)
+	err := service.DeleteAnswer(9, false, school(1), 8)
 	assert.NoError(t, err)
 	mockRepo.AssertCalled(t, "DeleteAnswer", int32(8))
 }
+
+func TestListAnswerKeyService(t *testing.T) {
+	mockRepo := new(MockQuestionRepo)
+	service := svc.NewQuestionService(mockRepo, media)
+
+	mockData := []questionEntity.ListQuestionKey{
+		{ID: 1, Number: 1, Type: "C1", Content: "Question 1", SetID: 3, Answers: []questionEntity.Answer{
+			{ID: 10, QuestionID: 1, Code: "a", Content: "Answer A", IsAnswer: true},
+		}},
+	}
+
+	mockRepo.On("ListAnswerKey", school(1), int32(3)).Return(mockData, nil)
+
+	questions, err := service.ListAnswerKey(school(1), 3)
+	assert.NoError(t, err)
+	assert.Len(t, questions, 1)
+	assert.True(t, questions[0].Answers[0].IsAnswer)
+}
+
+func TestImportQuestionsService(t *testing.T) {
+	mockRepo := new(MockQuestionRepo)
+	service := svc.NewQuestionService(mockRepo, media)
+
+	questions := []questionEntity.ImportQuestion{
+		{Row: 2, Question: questionEntity.SetQuestion{Number: 1, Type: "C1", Content: "2 + 2?", SetID: 1},
+			Answers: []questionEntity.SetAnswer{{Code: "a", Content: "4", IsAnswer: true}}},
+		{Row: 3, Question: questionEntity.SetQuestion{Number: 2, Type: "C1", Content: "3 + 3?", SetID: 1}},
+	}
+	mockRepo.On("SetOwnership", school(1), int32(1)).Return(setOwnedBy(9), nil)
+	mockRepo.On("ListNumbers", int32(1)).Return(map[int]bool{}, nil)
+	mockRepo.On("Import", int32(9), int32(1), questions).Return(1, nil)
+
+	result, err := service.ImportQuestions(9, false, school(1), 1, questions)
+	assert.NoError(t, err)
+	assert.Equal(t, questionEntity.ImportResult{Questions: 2, Answers: 1}, result)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestImportQuestionsService_NumberConflicts(t *testing.T) {
+	mockRepo := new(MockQuestionRepo)
+	service := svc.NewQuestionService(mockRepo, media)
+
+	questions := []questionEntity.ImportQuestion{
+		{Row: 2, Question: questionEntity.SetQuestion{Number: 1, Type: "C1", Content: "a", SetID: 1}},
+		{Row: 3, Question: questionEntity.SetQuestion{Number: 2, Type: "C1", Content: "b", SetID: 1}},
+		{Row: 4, Question: questionEntity.SetQuestion{Number: 2, Type: "C1", Content: "c", SetID: 1}},
+	}
+	mockRepo.On("SetOwnership", school(1), int32(1)).Return(setOwnedBy(9), nil)
+	mockRepo.On("ListNumbers", int32(1)).Return(map[int]bool{1: true}, nil)
+
+	result, err := service.ImportQuestions(9, false, school(1), 1, questions)
+	assert.NoError(t, err)
+	assert.Len(t, result.Errors, 2)
+	assert.Equal(t, 2, result.Errors[0].Row)
+	assert.Equal(t, "duplicates row 3", result.Errors[1].Errors["Number"])
+	mockRepo.AssertNotCalled(t, "Import", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestExportQuestionsService(t *testing.T) {
+	mockRepo := new(MockQuestionRepo)
+	service := svc.NewQuestionService(mockRepo, media)
+
+	filter := map[string]string{"lesson_id": "2"}
+	mockRepo.On("Export", school(1), filter).Return([]questionEntity.ExportQuestion{{ID: 1, SetID: 3, Number: 1}}, nil)
+
+	questions, err := service.ExportQuestions(school(1), filter)
+	assert.NoError(t, err)
+	assert.Len(t, questions, 1)
+
+	_, err = service.ExportQuestions(school(1), map[string]string{})
+	assert.Error(t, err)
+	mockRepo.AssertNumberOfCalls(t, "Export", 1)
+}
+
+func worksheetQuestions() []questionEntity.ListQuestionQuiz {
+	var questions []questionEntity.ListQuestionQuiz
+	for i := 1; i <= 6; i++ {
+		q := questionEntity.ListQuestionQuiz{ID: int32(i), Number: i, Kind: "single", Content: fmt.Sprintf("Q%d", i), SetID: 3}
+		for j, code := range []string{"a", "b", "c", "d"} {
+			q.Answers = append(q.Answers, questionEntity.ListAnswer{ID: int32(i*10 + j), Code: code, Content: code})
+		}
+		questions = append(questions, q)
+	}
+	return questions
+}
+
+func TestWorksheetService(t *testing.T) {
+	mockRepo := new(MockQuestionRepo)
+	service := svc.NewQuestionService(mockRepo, media)
+
+	filter := map[string]string{"set_id": "3"}
+	key := []questionEntity.ListQuestionKey{{ID: 1, Answers: []questionEntity.Answer{{ID: 12, IsAnswer: true}}}}
+	// Each call gets its own copy, as the service shuffles in place.
+	for i := 0; i < 3; i++ {
+		mockRepo.On("ListQuizQuestions", school(1), filter).Return(worksheetQuestions(), nil).Once()
+	}
+	mockRepo.On("ListAnswerKey", school(1), int32(3)).Return(key, nil)
+
+	original, err := service.Worksheet(school(1), 3, 0, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "Q1", original.Questions[0].Content)
+	assert.Equal(t, "A", original.Questions[0].Options[0].Label)
+
+	sheet, err := service.Worksheet(school(1), 3, 2, false)
+	assert.NoError(t, err)
+	answerKey, err := service.Worksheet(school(1), 3, 2, true)
+	assert.NoError(t, err)
+
+	// The key of a variant lists the same order as its worksheet.
+	for i, q := range sheet.Questions {
+		assert.Equal(t, i+1, q.Number)
+		assert.Equal(t, q.ID, answerKey.Questions[i].ID)
+		for j, o := range q.Options {
+			assert.Equal(t, o.Content, answerKey.Questions[i].Options[j].Content)
+			assert.False(t, o.IsAnswer)
+		}
+	}
+
+	marked := 0
+	for _, q := range answerKey.Questions {
+		for _, o := range q.Options {
+			if o.IsAnswer {
+				marked++
+				assert.Equal(t, int32(1), q.ID)
+				assert.Equal(t, "c", o.Content)
+			}
+		}
+	}
+	assert.Equal(t, 1, marked)
+}
+
+func TestWorksheetService_EssayKey(t *testing.T) {
+	mockRepo := new(MockQuestionRepo)
+	service := svc.NewQuestionService(mockRepo, media)
+
+	filter := map[string]string{"set_id": "3"}
+	quiz := []questionEntity.ListQuestionQuiz{{ID: 1, Kind: "essay", Content: "Jelaskan", Answers: []questionEntity.ListAnswer{}}}
+	key := []questionEntity.ListQuestionKey{{ID: 1, Kind: "essay", Answers: []questionEntity.Answer{{ID: 10, Code: "a", Content: "Model"}}}}
+	mockRepo.On("ListQuizQuestions", school(1), filter).Return(quiz, nil)
+	mockRepo.On("ListAnswerKey", school(1), int32(3)).Return(key, nil)
+
+	sheet, err := service.Worksheet(school(1), 3, 0, false)
+	assert.NoError(t, err)
+	assert.Empty(t, sheet.Questions[0].Options)
+
+	answerKey, err := service.Worksheet(school(1), 3, 0, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []questionEntity.WorksheetOption{{Label: "A", Content: "Model", IsAnswer: true}}, answerKey.Questions[0].Options)
+}
+
+func TestWorksheetService_Errors(t *testing.T) {
+	mockRepo := new(MockQuestionRepo)
+	service := svc.NewQuestionService(mockRepo, media)
+
+	_, err := service.Worksheet(school(1), 3, 27, false)
+	assert.Error(t, err)
+
+	mockRepo.On("ListQuizQuestions", school(1), map[string]string{"set_id": "4"}).Return([]questionEntity.ListQuestionQuiz{}, nil)
+	_, err = service.Worksheet(school(1), 4, 0, false)
+	assert.Equal(t, 404, err.(*app.AppError).Code)
+}
+
+func TestAddQuestionService_Blocks(t *testing.T) {
+	mockRepo := new(MockQuestionRepo)
+	service := svc.NewQuestionService(mockRepo, media)
+
+	question := questionEntity.SetQuestion{SetID: 1, Number: 1, Blocks: questionEntity.Blocks{
+		{Type: "text", Text: "Hitung <b>luas</b> <script>alert(1)</script>persegi"},
+		{Type: "image", URL: "/media/question/2026/10/a.png", Alt: "<i>persegi</i>", Text: "dropped"},
+		{Type: "latex", Text: `s^2 < 10`},
+		{Type: "audio", URL: "/media/question/2026/10/a.mp3"},
+	}}
+
+	mockRepo.On("SetOwnership", school(1), int32(1)).Return(setOwnedBy(9), nil)
+	mockRepo.On("Exists", int32(1), 1).Return(false, nil)
+	mockRepo.On("Add", int32(9), mock.MatchedBy(func(q questionEntity.SetQuestion) bool {
+		return q.Content == "Hitung &lt;b&gt;luas&lt;/b&gt; &lt;script&gt;alert(1)&lt;/script&gt;persegi" &&
+			q.Blocks[0].Text == q.Content &&
+			q.Blocks[1].Alt == "&lt;i&gt;persegi&lt;/i&gt;" && q.Blocks[1].Text == "" &&
+			q.Blocks[2].Text == `s^2 \lt  10`
+	})).Return(nil)
+
+	assert.NoError(t, service.AddQuestion(9, false, school(1), question))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAddQuestionService_UnsafeBlocks(t *testing.T) {
+	mockRepo := new(MockQuestionRepo)
+	service := svc.NewQuestionService(mockRepo, media)
+	mockRepo.On("SetOwnership", school(1), int32(1)).Return(setOwnedBy(9), nil)
+	mockRepo.On("Exists", int32(1), 1).Return(false, nil)
+
+	for _, block := range []questionEntity.Block{
+		{Type: "image", URL: "javascript:alert(1)"},
+		{Type: "audio", URL: "data:audio/mp3;base64,AAAA"},
+		{Type: "image", URL: "//evil.example.com/a.png"},
+		{Type: "image", URL: "https://example.com/a.png"},
+		{Type: "image", URL: "http://169.254.169.254/latest/meta-data"},
+		{Type: "image", URL: "/api/users"},
+		{Type: "image", URL: "/media/../config.env"},
+		{Type: "latex", Text: `\href{javascript:alert(1)}{x}`},
+		{Type: "text", Text: "\x00\x07"},
+		{Type: "video", URL: "https://example.com/a.mp4"},
+	} {
+		err := service.AddQuestion(9, false, school(1), questionEntity.SetQuestion{SetID: 1, Number: 1, Content: "Q", Blocks: questionEntity.Blocks{block}})
+		assert.Equal(t, 400, err.(*app.AppError).Code, block)
+	}
+	mockRepo.AssertNotCalled(t, "Add", mock.Anything, mock.Anything)
+}
+
+func TestAddQuestionService_NotEditable(t *testing.T) {
+	mockRepo := new(MockQuestionRepo)
+	service := svc.NewQuestionService(mockRepo, media)
+
+	question := questionEntity.SetQuestion{SetID: 1, Number: 1, Content: "Q"}
+	mockRepo.On("SetOwnership", school(1), int32(1)).Return(setOwnedBy(8), nil)
+
+	err := service.AddQuestion(9, false, school(1), question)
+	assert.Equal(t, 403, err.(*app.AppError).Code)
+
+	mockRepo.On("Exists", int32(1), 1).Return(false, nil)
+	mockRepo.On("Add", int32(2), question).Return(nil)
+	assert.NoError(t, service.AddQuestion(2, true, school(1), question))
+	mockRepo.AssertNumberOfCalls(t, "Add", 1)
+}
+
+func TestDeleteQuestionService_Ownership(t *testing.T) {
+	owner, other := int32(9), int32(8)
+	for _, tc := range []struct {
+		name      string
+		ownership questionEntity.Ownership
+		allowed   bool
+	}{
+		{"own question in foreign set", questionEntity.Ownership{OwnerID: &owner, SetOwnerID: &other}, true},
+		{"foreign set", setOwnedBy(8), false},
+		{"shared set", questionEntity.Ownership{SetOwnerID: &other, SetShared: true}, true},
+		{"legacy content", questionEntity.Ownership{}, false},
+	} {
+		mockRepo := new(MockQuestionRepo)
+		service := svc.NewQuestionService(mockRepo, media)
+		mockRepo.On("Ownership", school(1), int32(1)).Return(tc.ownership, nil)
+		mockRepo.On("Delete", int32(1)).Return(nil)
+
+		err := service.DeleteQuestion(9, false, school(1), 1)
+		if tc.allowed {
+			assert.NoError(t, err, tc.name)
+		} else {
+			assert.Equal(t, 403, err.(*app.AppError).Code, tc.name)
+			mockRepo.AssertNotCalled(t, "Delete", mock.Anything)
+		}
+	}
+}
+
+func TestEditQuestionService_MoveToForeignSet(t *testing.T) {
+	mockRepo := new(MockQuestionRepo)
+	service := svc.NewQuestionService(mockRepo, media)
+
+	question := questionEntity.EditQuestion{Number: 1, Type: "C1", Content: "Q", SetID: 2}
+	mockRepo.On("Ownership", school(1), int32(1)).Return(setOwnedBy(9), nil)
+	mockRepo.On("QuestionAnswerKey", int32(1)).Return(questionEntity.ListQuestionKey{ID: 1, SetID: 1, Kind: questionEntity.KindSingle}, nil)
+	mockRepo.On("SetOwnership", school(1), int32(2)).Return(setOwnedBy(8), nil)
+
+	err := service.EditQuestion(9, false, school(1), 1, question)
+	assert.Equal(t, 403, err.(*app.AppError).Code)
+	mockRepo.AssertNotCalled(t, "Edit", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,99 @@
+package svc
+
+import (
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+
+	questionEntity "github.com/ghulammuzz/misterblast/internal/question/entity"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+)
+
+func (s *questionService) Worksheet(schoolID *int32, setID int32, variant int, withKey bool) (questionEntity.Worksheet, error) {
+	if variant < 0 || variant > questionEntity.MaxWorksheetVariants {
+		return questionEntity.Worksheet{}, app.NewAppError(400, "variant must be between 0 and 26")
+	}
+
+	questions, err := s.repo.ListQuizQuestions(schoolID, map[string]string{"set_id": strconv.Itoa(int(setID))})
+	if err != nil {
+		return questionEntity.Worksheet{}, err
+	}
+	if len(questions) == 0 {
+		return questionEntity.Worksheet{}, app.NewAppError(404, "set has no quiz questions")
+	}
+
+	keyAnswers := map[int32]questionEntity.Answer{}
+	// Blanks and essays have no options on the quiz, so their accepted and
+	// model answers come from the key alone.
+	keyOnly := map[int32][]questionEntity.Answer{}
+	if withKey {
+		key, err := s.repo.ListAnswerKey(schoolID, setID)
+		if err != nil {
+			return questionEntity.Worksheet{}, err
+		}
+		for _, q := range key {
+			for _, a := range q.Answers {
+				keyAnswers[a.ID] = a
+				if q.Kind == questionEntity.KindFillBlank && a.Position != nil || q.Kind == questionEntity.KindEssay {
+					keyOnly[q.ID] = append(keyOnly[q.ID], a)
+				}
+			}
+		}
+	}
+
+	// The seed only depends on the set and the variant so that reprinting a
+	// variant, or printing its key later, gives the same order.
+	var rng *rand.Rand
+	if variant > 0 {
+		rng = rand.New(rand.NewSource(int64(setID)<<8 | int64(variant)))
+		rng.Shuffle(len(questions), func(i, j int) { questions[i], questions[j] = questions[j], questions[i] })
+	}
+
+	sheet := questionEntity.Worksheet{SetID: setID, Variant: variant, Questions: make([]questionEntity.WorksheetQuestion, 0, len(questions))}
+	for i, q := range questions {
+		answers := q.Answers
+		if rng != nil {
+			rng.Shuffle(len(answers), func(i, j int) { answers[i], answers[j] = answers[j], answers[i] })
+			rng.Shuffle(len(q.MatchOptions), func(i, j int) {
+				q.MatchOptions[i], q.MatchOptions[j] = q.MatchOptions[j], q.MatchOptions[i]
+			})
+		}
+
+		wq := questionEntity.WorksheetQuestion{ID: q.ID, Number: i + 1, Kind: q.Kind, Content: q.Content, Blocks: q.Blocks,
+			Options: []questionEntity.WorksheetOption{}, MatchOptions: q.MatchOptions, Blanks: q.Blanks}
+		for j, a := range answers {
+			key := keyAnswers[a.ID]
+			wq.Options = append(wq.Options, questionEntity.WorksheetOption{
+				Label:     string(rune('A' + j)),
+				Content:   a.Content,
+				ImgURL:    a.ImgURL,
+				IsAnswer:  key.IsAnswer,
+				MatchText: key.MatchText,
+				Position:  key.Position,
+			})
+		}
+		if q.Kind == questionEntity.KindEssay {
+			for _, a := range keyOnly[q.ID] {
+				wq.Options = append(wq.Options, questionEntity.WorksheetOption{
+					Label:    strings.ToUpper(a.Code),
+					Content:  a.Content,
+					IsAnswer: true,
+				})
+			}
+		} else {
+			sort.SliceStable(keyOnly[q.ID], func(i, j int) bool { return *keyOnly[q.ID][i].Position < *keyOnly[q.ID][j].Position })
+			for _, a := range keyOnly[q.ID] {
+				wq.Options = append(wq.Options, questionEntity.WorksheetOption{
+					Label:    strconv.Itoa(*a.Position),
+					Content:  a.Content,
+					IsAnswer: true,
+					Position: a.Position,
+				})
+			}
+		}
+		sheet.Questions = append(sheet.Questions, wq)
+	}
+
+	return sheet, nil
+}
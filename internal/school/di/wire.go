@@ -0,0 +1,21 @@
+package di
+
+import (
+	"database/sql"
+
+	schoolHandler "github.com/ghulammuzz/misterblast/internal/school/handler"
+	schoolRepo "github.com/ghulammuzz/misterblast/internal/school/repo"
+	schoolSvc "github.com/ghulammuzz/misterblast/internal/school/svc"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/wire"
+)
+
+func InitializedSchoolServiceFake(sb *sql.DB, val *validator.Validate) *schoolHandler.SchoolHandler {
+	wire.Build(
+		schoolHandler.NewSchoolHandler,
+		schoolSvc.NewSchoolService,
+		schoolRepo.NewSchoolRepository,
+	)
+
+	return &schoolHandler.SchoolHandler{}
+}
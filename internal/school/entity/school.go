@@ -0,0 +1,9 @@
+package entity
+
+// School is a tenant. Users, classrooms and the content they own belong to
+// one school and are invisible to the others.
+type School struct {
+	ID        int32  `json:"id"`
+	Name      string `json:"name"`
+	CreatedAt int64  `json:"created_at"`
+}
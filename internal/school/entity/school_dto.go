@@ -0,0 +1,5 @@
+package entity
+
+type SetSchool struct {
+	Name string `json:"name" validate:"required,min=2,max=100"`
+}
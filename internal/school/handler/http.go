@@ -0,0 +1,112 @@
+package handler
+
+import (
+	schoolEntity "github.com/ghulammuzz/misterblast/internal/school/entity"
+	schoolSvc "github.com/ghulammuzz/misterblast/internal/school/svc"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/middleware"
+	"github.com/ghulammuzz/misterblast/pkg/response"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type SchoolHandler struct {
+	schoolService schoolSvc.SchoolService
+	val           *validator.Validate
+}
+
+func NewSchoolHandler(schoolService schoolSvc.SchoolService, val *validator.Validate) *SchoolHandler {
+	return &SchoolHandler{schoolService: schoolService, val: val}
+}
+
+// Router mounts the school routes. Schools are managed by super admins only;
+// the list is public so that the registration form can offer it.
+func (h *SchoolHandler) Router(r fiber.Router) {
+	auth := middleware.JWTProtected()
+	superAdmin := middleware.RequireRole(middleware.RoleSuperAdmin)
+
+	r.Post("/schools", auth, superAdmin, h.AddSchoolHandler)
+	r.Put("/schools/:id", auth, superAdmin, h.EditSchoolHandler)
+	r.Delete("/schools/:id", auth, superAdmin, h.DeleteSchoolHandler)
+	r.Get("/schools", h.ListSchoolsHandler)
+}
+
+func (h *SchoolHandler) AddSchoolHandler(c *fiber.Ctx) error {
+	var school schoolEntity.SetSchool
+	if err := c.BodyParser(&school); err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid request body", nil)
+	}
+
+	if err := h.val.Struct(school); err != nil {
+		validationErrors := app.ValidationErrorResponse(err)
+		return response.SendError(c, fiber.StatusBadRequest, "validation failed", validationErrors)
+	}
+
+	id, err := h.schoolService.AddSchool(school)
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "school added successfully", fiber.Map{"id": id})
+}
+
+func (h *SchoolHandler) EditSchoolHandler(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid id", nil)
+	}
+
+	var school schoolEntity.SetSchool
+	if err := c.BodyParser(&school); err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid request body", nil)
+	}
+
+	if err := h.val.Struct(school); err != nil {
+		validationErrors := app.ValidationErrorResponse(err)
+		return response.SendError(c, fiber.StatusBadRequest, "validation failed", validationErrors)
+	}
+
+	if err := h.schoolService.EditSchool(int32(id), school); err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "school updated successfully", nil)
+}
+
+func (h *SchoolHandler) DeleteSchoolHandler(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid id", nil)
+	}
+
+	if err := h.schoolService.DeleteSchool(int32(id)); err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "school deleted successfully", nil)
+}
+
+func (h *SchoolHandler) ListSchoolsHandler(c *fiber.Ctx) error {
+	schools, err := h.schoolService.ListSchools()
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "schools retrieved successfully", schools)
+}
@@ -0,0 +1,130 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	schoolEntity "github.com/ghulammuzz/misterblast/internal/school/entity"
+	"github.com/ghulammuzz/misterblast/internal/school/handler"
+	"github.com/ghulammuzz/misterblast/pkg/middleware"
+)
+
+type MockSchoolService struct {
+	mock.Mock
+}
+
+func (m *MockSchoolService) AddSchool(school schoolEntity.SetSchool) (int32, error) {
+	args := m.Called(school)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (m *MockSchoolService) EditSchool(id int32, school schoolEntity.SetSchool) error {
+	args := m.Called(id, school)
+	return args.Error(0)
+}
+
+func (m *MockSchoolService) DeleteSchool(id int32) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockSchoolService) ListSchools() ([]schoolEntity.School, error) {
+	args := m.Called()
+	return args.Get(0).([]schoolEntity.School), args.Error(1)
+}
+
+func signedToken(userID int, role string) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id":   userID,
+		"role":      role,
+		"school_id": 1,
+		"jti":       "jti-test",
+		"exp":       time.Now().Add(time.Hour).Unix(),
+	})
+	signed, _ := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+	return signed
+}
+
+func TestAddSchoolHandler(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockSchoolService)
+	handler.NewSchoolHandler(mockService, validator.New()).Router(app)
+
+	school := schoolEntity.SetSchool{Name: "SD Negeri 1"}
+	mockService.On("AddSchool", school).Return(int32(2), nil)
+
+	body, _ := json.Marshal(school)
+	tests := []struct {
+		name   string
+		role   string
+		status int
+	}{
+		{"school admin", middleware.RoleSchoolAdmin, http.StatusForbidden},
+		{"super admin", middleware.RoleSuperAdmin, http.StatusOK},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/schools", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+signedToken(1, tc.role))
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tc.status, resp.StatusCode)
+		})
+	}
+	mockService.AssertNumberOfCalls(t, "AddSchool", 1)
+}
+
+func TestAddSchoolHandler_Validation(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockSchoolService)
+	app.Post("/schools", handler.NewSchoolHandler(mockService, validator.New()).AddSchoolHandler)
+
+	req := httptest.NewRequest(http.MethodPost, "/schools", bytes.NewReader([]byte(`{"name":""}`)))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	mockService.AssertNotCalled(t, "AddSchool", mock.Anything)
+}
+
+func TestDeleteSchoolHandler(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockSchoolService)
+	app.Delete("/schools/:id", handler.NewSchoolHandler(mockService, validator.New()).DeleteSchoolHandler)
+
+	mockService.On("DeleteSchool", int32(2)).Return(nil)
+
+	req := httptest.NewRequest(http.MethodDelete, "/schools/2", nil)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
+func TestListSchoolsHandler(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockSchoolService)
+	handler.NewSchoolHandler(mockService, validator.New()).Router(app)
+
+	mockService.On("ListSchools").Return([]schoolEntity.School{{ID: 1, Name: "Default School"}}, nil)
+
+	// The list is public for the registration form.
+	req := httptest.NewRequest(http.MethodGet, "/schools", nil)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
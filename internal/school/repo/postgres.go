@@ -0,0 +1,112 @@
+package repo
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	schoolEntity "github.com/ghulammuzz/misterblast/internal/school/entity"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/log"
+)
+
+type SchoolRepository interface {
+	Add(school schoolEntity.SetSchool) (int32, error)
+	Edit(id int32, school schoolEntity.SetSchool) error
+	Delete(id int32) error
+	List() ([]schoolEntity.School, error)
+}
+
+type schoolRepository struct {
+	db *sql.DB
+}
+
+func NewSchoolRepository(db *sql.DB) SchoolRepository {
+	return &schoolRepository{db: db}
+}
+
+func (r *schoolRepository) Add(school schoolEntity.SetSchool) (int32, error) {
+	var id int32
+	query := `INSERT INTO schools (name) VALUES ($1) RETURNING id`
+	if err := r.db.QueryRow(query, school.Name).Scan(&id); err != nil {
+		log.Error("[Repo][AddSchool] Error QueryRow: ", err)
+		return 0, app.NewAppError(500, "failed to insert school")
+	}
+
+	return id, nil
+}
+
+func (r *schoolRepository) Edit(id int32, school schoolEntity.SetSchool) error {
+	query := `UPDATE schools SET name = $1 WHERE id = $2`
+	result, err := r.db.Exec(query, school.Name, id)
+	if err != nil {
+		log.Error("[Repo][EditSchool] Error Exec: ", err)
+		return app.NewAppError(500, "failed to update school")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Error("[Repo][EditSchool] Error RowsAffected: ", err)
+		return app.NewAppError(500, "failed to check rows affected")
+	}
+	if rowsAffected == 0 {
+		return app.ErrNotFound
+	}
+
+	return nil
+}
+
+// Delete refuses schools that still have users; their data would otherwise
+// fall back to the shared namespace.
+func (r *schoolRepository) Delete(id int32) error {
+	query := `DELETE FROM schools WHERE id = $1`
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+			return app.NewAppError(409, "school still has users")
+		}
+		log.Error("[Repo][DeleteSchool] Error Exec: ", err)
+		return app.NewAppError(500, "failed to delete school")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Error("[Repo][DeleteSchool] Error RowsAffected: ", err)
+		return app.NewAppError(500, "failed to check rows affected")
+	}
+	if rowsAffected == 0 {
+		return app.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *schoolRepository) List() ([]schoolEntity.School, error) {
+	query := `SELECT id, name, created_at FROM schools ORDER BY name`
+	rows, err := r.db.Query(query)
+	if err != nil {
+		log.Error("[Repo][ListSchool] Error Query: ", err)
+		return nil, app.NewAppError(500, "failed to fetch schools")
+	}
+	defer rows.Close()
+
+	schools := []schoolEntity.School{}
+	for rows.Next() {
+		var school schoolEntity.School
+		if err := rows.Scan(&school.ID, &school.Name, &school.CreatedAt); err != nil {
+			log.Error("[Repo][ListSchool] Error Scan: ", err)
+			return nil, app.NewAppError(500, "failed to scan school")
+		}
+		schools = append(schools, school)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Error("[Repo][ListSchool] Error Iterating Rows: ", err)
+		return nil, fmt.Errorf("error after iterating rows: %w", err)
+	}
+
+	return schools, nil
+}
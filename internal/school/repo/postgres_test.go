@@ -0,0 +1,82 @@
+package repo_test
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ghulammuzz/misterblast/internal/school/entity"
+	"github.com/ghulammuzz/misterblast/internal/school/repo"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddSchool(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	repository := repo.NewSchoolRepository(mockDB)
+
+	mock.ExpectQuery("INSERT INTO schools \\(name\\) VALUES \\(\\$1\\) RETURNING id").
+		WithArgs("SD Negeri 1").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+
+	id, err := repository.Add(entity.SetSchool{Name: "SD Negeri 1"})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEditSchool_NotFound(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	repository := repo.NewSchoolRepository(mockDB)
+
+	mock.ExpectExec("UPDATE schools SET name = \\$1 WHERE id = \\$2").
+		WithArgs("SD Negeri 2", int32(9)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repository.Edit(9, entity.SetSchool{Name: "SD Negeri 2"})
+	assert.EqualError(t, err, "resource not found")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteSchool(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	repository := repo.NewSchoolRepository(mockDB)
+
+	mock.ExpectExec("DELETE FROM schools WHERE id = \\$1").
+		WithArgs(int32(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM schools WHERE id = \\$1").
+		WithArgs(int32(3)).
+		WillReturnError(&pq.Error{Code: "23503"})
+
+	assert.NoError(t, repository.Delete(2))
+	assert.EqualError(t, repository.Delete(3), "school still has users")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListSchool(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	repository := repo.NewSchoolRepository(mockDB)
+
+	mock.ExpectQuery("SELECT id, name, created_at FROM schools ORDER BY name").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "created_at"}).
+			AddRow(1, "Default School", 1700000000).
+			AddRow(2, "SD Negeri 1", 1700000100))
+
+	schools, err := repository.List()
+	assert.NoError(t, err)
+	assert.Len(t, schools, 2)
+	assert.Equal(t, "SD Negeri 1", schools[1].Name)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
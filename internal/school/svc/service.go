@@ -0,0 +1,51 @@
+package svc
+
+import (
+	schoolEntity "github.com/ghulammuzz/misterblast/internal/school/entity"
+	schoolRepo "github.com/ghulammuzz/misterblast/internal/school/repo"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/log"
+)
+
+type SchoolService interface {
+	AddSchool(school schoolEntity.SetSchool) (int32, error)
+	EditSchool(id int32, school schoolEntity.SetSchool) error
+	DeleteSchool(id int32) error
+	ListSchools() ([]schoolEntity.School, error)
+}
+
+type schoolService struct {
+	repo schoolRepo.SchoolRepository
+}
+
+func NewSchoolService(repo schoolRepo.SchoolRepository) SchoolService {
+	return &schoolService{repo: repo}
+}
+
+func (s *schoolService) AddSchool(school schoolEntity.SetSchool) (int32, error) {
+	id, err := s.repo.Add(school)
+	if err != nil {
+		log.Error("[Svc][AddSchool] Error: ", err)
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (s *schoolService) EditSchool(id int32, school schoolEntity.SetSchool) error {
+	if id <= 0 {
+		return app.NewAppError(400, "invalid id")
+	}
+	return s.repo.Edit(id, school)
+}
+
+func (s *schoolService) DeleteSchool(id int32) error {
+	if id <= 0 {
+		return app.NewAppError(400, "invalid id")
+	}
+	return s.repo.Delete(id)
+}
+
+func (s *schoolService) ListSchools() ([]schoolEntity.School, error) {
+	return s.repo.List()
+}
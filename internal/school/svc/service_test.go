@@ -0,0 +1,56 @@
+package svc_test
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ghulammuzz/misterblast/internal/school/entity"
+	"github.com/ghulammuzz/misterblast/internal/school/repo"
+	"github.com/ghulammuzz/misterblast/internal/school/svc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddSchool(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	service := svc.NewSchoolService(repo.NewSchoolRepository(mockDB))
+
+	mock.ExpectQuery("INSERT INTO schools").
+		WithArgs("SD Negeri 1").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+
+	id, err := service.AddSchool(entity.SetSchool{Name: "SD Negeri 1"})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteSchool_InvalidID(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	service := svc.NewSchoolService(repo.NewSchoolRepository(mockDB))
+
+	assert.EqualError(t, service.DeleteSchool(0), "invalid id")
+	assert.EqualError(t, service.EditSchool(-1, entity.SetSchool{Name: "SD"}), "invalid id")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListSchools(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockDB.Close()
+
+	service := svc.NewSchoolService(repo.NewSchoolRepository(mockDB))
+
+	mock.ExpectQuery("SELECT id, name, created_at FROM schools").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "created_at"}).AddRow(1, "Default School", 1700000000))
+
+	schools, err := service.ListSchools()
+	assert.NoError(t, err)
+	assert.Equal(t, []entity.School{{ID: 1, Name: "Default School", CreatedAt: 1700000000}}, schools)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,144 @@
+// Package seed loads demo and QA content into the catalog tables from fixture
+// files and generates synthetic sets for load testing.
+package seed
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"gopkg.in/yaml.v3"
+
+	questionEntity "github.com/ghulammuzz/misterblast/internal/question/entity"
+)
+
+// FixtureVersion is the fixture format understood by this build. Files with
+// another version are rejected rather than half applied.
+const FixtureVersion = 1
+
+type Fixture struct {
+	Version int      `json:"version" yaml:"version"`
+	Classes []string `json:"classes" yaml:"classes" validate:"dive,oneof=1 2 3 4 5 6"`
+	Lessons []string `json:"lessons" yaml:"lessons" validate:"dive,required,max=100"`
+	Sets    []Set    `json:"sets" yaml:"sets" validate:"dive"`
+}
+
+type Set struct {
+	Name      string     `json:"name" yaml:"name" validate:"required,min=2,max=20"`
+	Lesson    string     `json:"lesson" yaml:"lesson" validate:"required"`
+	Class     string     `json:"class" yaml:"class" validate:"oneof=1 2 3 4 5 6"`
+	IsQuiz    bool       `json:"is_quiz" yaml:"is_quiz"`
+	Questions []Question `json:"questions" yaml:"questions" validate:"dive"`
+}
+
+type Question struct {
+	Number  int      `json:"number" yaml:"number" validate:"required,min=1"`
+	Type    string   `json:"type" yaml:"type" validate:"required,oneof=C1 C2 C3 C4 C5 C6"`
+	Kind    string   `json:"kind" yaml:"kind" validate:"omitempty,oneof=single multiple true_false matching ordering fill_blank essay"`
+	Content string   `json:"content" yaml:"content" validate:"required"`
+	IsQuiz  bool     `json:"is_quiz" yaml:"is_quiz"`
+	Answers []Answer `json:"answers" yaml:"answers" validate:"dive"`
+}
+
+type Answer struct {
+	Code      string  `json:"code" yaml:"code" validate:"required,alphanum,max=10"`
+	Content   string  `json:"content" yaml:"content" validate:"required"`
+	ImgURL    *string `json:"img_url" yaml:"img_url"`
+	IsAnswer  bool    `json:"is_answer" yaml:"is_answer"`
+	MatchText *string `json:"match_text" yaml:"match_text" validate:"omitempty,max=500"`
+	Position  *int    `json:"position" yaml:"position" validate:"omitempty,min=1,max=50"`
+}
+
+var validate = validator.New()
+
+// LoadFile reads a .json, .yaml or .yml fixture and validates it.
+func LoadFile(path string) (Fixture, error) {
+	var fixture Fixture
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fixture, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, &fixture)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &fixture)
+	default:
+		return fixture, fmt.Errorf("%s: unsupported fixture format", path)
+	}
+	if err != nil {
+		return fixture, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if err := fixture.Validate(); err != nil {
+		return fixture, fmt.Errorf("%s: %w", path, err)
+	}
+	return fixture, nil
+}
+
+// LoadDir reads every fixture file in dir in file name order, so fixtures can
+// be prefixed with a sequence number like migrations.
+func LoadDir(dir string) ([]Fixture, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".json", ".yaml", ".yml":
+			if !entry.IsDir() {
+				names = append(names, entry.Name())
+			}
+		}
+	}
+	sort.Strings(names)
+
+	fixtures := make([]Fixture, 0, len(names))
+	for _, name := range names {
+		fixture, err := LoadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		fixtures = append(fixtures, fixture)
+	}
+	return fixtures, nil
+}
+
+// Validate checks the fixture format version and every field against the
+// same rules the API enforces.
+func (f Fixture) Validate() error {
+	if f.Version != FixtureVersion {
+		return fmt.Errorf("unsupported fixture version %d, expected %d", f.Version, FixtureVersion)
+	}
+	if err := validate.Struct(f); err != nil {
+		return err
+	}
+
+	for _, set := range f.Sets {
+		numbers := map[int]bool{}
+		for _, q := range set.Questions {
+			if numbers[q.Number] {
+				return fmt.Errorf("set %q: question number %d used twice", set.Name, q.Number)
+			}
+			numbers[q.Number] = true
+
+			answers := make([]questionEntity.Answer, len(q.Answers))
+			for i, a := range q.Answers {
+				answers[i] = questionEntity.Answer{Code: a.Code, Content: a.Content, IsAnswer: a.IsAnswer,
+					MatchText: a.MatchText, Position: a.Position}
+			}
+			if err := questionEntity.CheckAnswers(q.Kind, answers); err != nil {
+				return fmt.Errorf("set %q: question %d: %w", set.Name, q.Number, err)
+			}
+		}
+	}
+	return nil
+}
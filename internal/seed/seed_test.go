@@ -0,0 +1,110 @@
+package seed_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ghulammuzz/misterblast/internal/seed"
+)
+
+func TestLoadDir_ShippedFixtures(t *testing.T) {
+	fixtures, err := seed.LoadDir("../../fixtures")
+	require.NoError(t, err)
+	require.NotEmpty(t, fixtures)
+	assert.Equal(t, []string{"1", "2", "3", "4", "5", "6"}, fixtures[0].Classes)
+}
+
+func TestLoadFile_JSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sets.json")
+	body := `{"version":1,"sets":[{"name":"Pecahan","lesson":"Matematika","class":"3","questions":[
+		{"number":1,"type":"C1","content":"1/2 + 1/2?","answers":[{"code":"a","content":"1","is_answer":true}]}]}]}`
+	require.NoError(t, os.WriteFile(path, []byte(body), 0o644))
+
+	fixture, err := seed.LoadFile(path)
+	require.NoError(t, err)
+	require.Len(t, fixture.Sets, 1)
+	assert.True(t, fixture.Sets[0].Questions[0].Answers[0].IsAnswer)
+}
+
+func TestValidate(t *testing.T) {
+	valid := seed.Fixture{Version: 1, Sets: []seed.Set{{Name: "Pecahan", Lesson: "Matematika", Class: "3",
+		Questions: []seed.Question{{Number: 1, Type: "C1", Content: "?"}}}}}
+	require.NoError(t, valid.Validate())
+
+	tests := map[string]func(f *seed.Fixture){
+		"unknown version": func(f *seed.Fixture) { f.Version = 2 },
+		"invalid class":   func(f *seed.Fixture) { f.Sets[0].Class = "7" },
+		"invalid type":    func(f *seed.Fixture) { f.Sets[0].Questions[0].Type = "C9" },
+		"duplicate number": func(f *seed.Fixture) {
+			f.Sets[0].Questions = append(f.Sets[0].Questions, f.Sets[0].Questions[0])
+		},
+	}
+	for name, mutate := range tests {
+		t.Run(name, func(t *testing.T) {
+			f := valid
+			f.Sets = []seed.Set{valid.Sets[0]}
+			f.Sets[0].Questions = append([]seed.Question{}, valid.Sets[0].Questions...)
+			mutate(&f)
+			assert.Error(t, f.Validate())
+		})
+	}
+}
+
+func TestSynthetic(t *testing.T) {
+	a := seed.Synthetic(3, 5, 42)
+	b := seed.Synthetic(3, 5, 42)
+
+	require.NoError(t, a.Validate())
+	assert.Equal(t, a, b, "same seed, same fixture")
+	require.Len(t, a.Sets, 3)
+	for _, q := range a.Sets[0].Questions {
+		correct := 0
+		for _, answer := range q.Answers {
+			if answer.IsAnswer {
+				correct++
+			}
+		}
+		assert.Equal(t, 1, correct)
+	}
+}
+
+func TestSeeder_Apply(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	fixture := seed.Fixture{Version: 1, Sets: []seed.Set{{Name: "Pecahan", Lesson: "Matematika", Class: "3", IsQuiz: true,
+		Questions: []seed.Question{{Number: 1, Type: "C1", Content: "1/2 + 1/2?", IsQuiz: true,
+			Answers: []seed.Answer{{Code: "a", Content: "1", IsAnswer: true}}}}}}}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id FROM classes WHERE name = \$1`).WithArgs("3").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	mock.ExpectQuery(`SELECT id FROM lessons WHERE name = \$1`).WithArgs("Matematika").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(`INSERT INTO lessons \(name\) VALUES \(\$1\) RETURNING id`).WithArgs("Matematika").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(`SELECT id FROM sets WHERE name = \$1 AND lesson_id = \$2 AND class_id = \$3`).
+		WithArgs("Pecahan", 1, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+	mock.ExpectExec(`UPDATE sets SET is_quiz = \$1 WHERE id = \$2`).WithArgs(true, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO questions .* ON CONFLICT \(set_id, number\) DO UPDATE`).
+		WithArgs(1, "C1", "single", "1/2 + 1/2?", true, 7).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "inserted"}).AddRow(11, true))
+	mock.ExpectQuery(`SELECT id FROM answers WHERE question_id = \$1 AND code = \$2`).WithArgs(11, "a").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectExec(`INSERT INTO answers`).WithArgs(11, "a", "1", nil, true, nil, nil).
+		WillReturnResult(sqlmock.NewResult(21, 1))
+	mock.ExpectCommit()
+
+	stats, err := seed.NewSeeder(db).Apply(fixture)
+	require.NoError(t, err)
+	assert.Equal(t, seed.Stats{Lessons: 1, Questions: 1, Answers: 1}, stats)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,173 @@
+package seed
+
+import (
+	"database/sql"
+	"fmt"
+
+	questionEntity "github.com/ghulammuzz/misterblast/internal/question/entity"
+)
+
+// Stats counts the rows a fixture created. Rows that already existed are
+// updated in place and not counted.
+type Stats struct {
+	Classes   int
+	Lessons   int
+	Sets      int
+	Questions int
+	Answers   int
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("%d classes, %d lessons, %d sets, %d questions, %d answers",
+		s.Classes, s.Lessons, s.Sets, s.Questions, s.Answers)
+}
+
+type Seeder struct {
+	db *sql.DB
+}
+
+func NewSeeder(db *sql.DB) *Seeder {
+	return &Seeder{db: db}
+}
+
+// Apply writes the fixture in one transaction. Rows are matched on their
+// natural keys (class and lesson name, set name within lesson and class,
+// question number within set, answer code within question) so applying the
+// same fixture twice leaves the database unchanged.
+func (s *Seeder) Apply(fixture Fixture) (Stats, error) {
+	var stats Stats
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return stats, err
+	}
+	defer tx.Rollback()
+
+	classes := map[string]int32{}
+	lessons := map[string]int32{}
+
+	for _, name := range fixture.Classes {
+		if _, err := namedID(tx, "classes", name, classes, &stats.Classes); err != nil {
+			return stats, err
+		}
+	}
+	for _, name := range fixture.Lessons {
+		if _, err := namedID(tx, "lessons", name, lessons, &stats.Lessons); err != nil {
+			return stats, err
+		}
+	}
+
+	for _, set := range fixture.Sets {
+		classID, err := namedID(tx, "classes", set.Class, classes, &stats.Classes)
+		if err != nil {
+			return stats, err
+		}
+		lessonID, err := namedID(tx, "lessons", set.Lesson, lessons, &stats.Lessons)
+		if err != nil {
+			return stats, err
+		}
+
+		setID, err := upsertSet(tx, set, lessonID, classID, &stats)
+		if err != nil {
+			return stats, err
+		}
+
+		for _, q := range set.Questions {
+			questionID, err := upsertQuestion(tx, setID, q, &stats)
+			if err != nil {
+				return stats, err
+			}
+			for _, a := range q.Answers {
+				if err := upsertAnswer(tx, questionID, a, &stats); err != nil {
+					return stats, err
+				}
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return stats, err
+	}
+	return stats, nil
+}
+
+// namedID returns the id of the classes or lessons row called name, inserting
+// it when missing.
+func namedID(tx *sql.Tx, table, name string, known map[string]int32, created *int) (int32, error) {
+	if id, ok := known[name]; ok {
+		return id, nil
+	}
+
+	var id int32
+	err := tx.QueryRow(`SELECT id FROM `+table+` WHERE name = $1`, name).Scan(&id)
+	if err == sql.ErrNoRows {
+		err = tx.QueryRow(`INSERT INTO `+table+` (name) VALUES ($1) RETURNING id`, name).Scan(&id)
+		*created++
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%s %q: %w", table, name, err)
+	}
+
+	known[name] = id
+	return id, nil
+}
+
+func upsertSet(tx *sql.Tx, set Set, lessonID, classID int32, stats *Stats) (int32, error) {
+	var id int32
+	err := tx.QueryRow(`SELECT id FROM sets WHERE name = $1 AND lesson_id = $2 AND class_id = $3`,
+		set.Name, lessonID, classID).Scan(&id)
+	switch {
+	case err == sql.ErrNoRows:
+		err = tx.QueryRow(`INSERT INTO sets (name, lesson_id, class_id, is_quiz) VALUES ($1, $2, $3, $4) RETURNING id`,
+			set.Name, lessonID, classID, set.IsQuiz).Scan(&id)
+		stats.Sets++
+	case err == nil:
+		_, err = tx.Exec(`UPDATE sets SET is_quiz = $1 WHERE id = $2`, set.IsQuiz, id)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("set %q: %w", set.Name, err)
+	}
+	return id, nil
+}
+
+func upsertQuestion(tx *sql.Tx, setID int32, q Question, stats *Stats) (int32, error) {
+	kind := q.Kind
+	if kind == "" {
+		kind = questionEntity.KindSingle
+	}
+
+	// xmax is 0 only for freshly inserted rows.
+	var id int32
+	var inserted bool
+	err := tx.QueryRow(`
+		INSERT INTO questions (number, type, kind, content, is_quiz, set_id) VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (set_id, number) DO UPDATE
+		SET type = EXCLUDED.type, kind = EXCLUDED.kind, content = EXCLUDED.content, is_quiz = EXCLUDED.is_quiz
+		RETURNING id, (xmax = 0)`,
+		q.Number, q.Type, kind, q.Content, q.IsQuiz, setID).Scan(&id, &inserted)
+	if err != nil {
+		return 0, fmt.Errorf("question %d of set %d: %w", q.Number, setID, err)
+	}
+	if inserted {
+		stats.Questions++
+	}
+	return id, nil
+}
+
+func upsertAnswer(tx *sql.Tx, questionID int32, a Answer, stats *Stats) error {
+	var id int32
+	err := tx.QueryRow(`SELECT id FROM answers WHERE question_id = $1 AND code = $2`, questionID, a.Code).Scan(&id)
+	switch {
+	case err == sql.ErrNoRows:
+		_, err = tx.Exec(`INSERT INTO answers (question_id, code, content, img_url, is_answer, match_text, position) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			questionID, a.Code, a.Content, a.ImgURL, a.IsAnswer, a.MatchText, a.Position)
+		stats.Answers++
+	case err == nil:
+		_, err = tx.Exec(`UPDATE answers SET content = $1, img_url = $2, is_answer = $3, match_text = $4, position = $5 WHERE id = $6`,
+			a.Content, a.ImgURL, a.IsAnswer, a.MatchText, a.Position, id)
+	}
+	if err != nil {
+		return fmt.Errorf("answer %q of question %d: %w", a.Code, questionID, err)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package seed
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+var syntheticLessons = []string{"Matematika", "IPA", "IPS", "Bahasa Indonesia", "Bahasa Inggris", "PKN"}
+
+var syntheticTypes = []string{"C1", "C2", "C3", "C4", "C5", "C6"}
+
+// Synthetic builds a fixture of n quiz sets with questionsPerSet single choice
+// questions each, spread over all classes and lessons. The same seed always
+// yields the same fixture, so reruns update rather than duplicate the sets.
+func Synthetic(n, questionsPerSet int, seed int64) Fixture {
+	rng := rand.New(rand.NewSource(seed))
+	fixture := Fixture{Version: FixtureVersion}
+
+	for i := 0; i < n; i++ {
+		set := Set{
+			Name:   fmt.Sprintf("Synthetic %05d", i+1),
+			Lesson: syntheticLessons[rng.Intn(len(syntheticLessons))],
+			Class:  fmt.Sprint(rng.Intn(6) + 1),
+			IsQuiz: true,
+		}
+
+		for number := 1; number <= questionsPerSet; number++ {
+			x, y := rng.Intn(100), rng.Intn(100)
+			correct := rng.Intn(4)
+			question := Question{
+				Number:  number,
+				Type:    syntheticTypes[rng.Intn(len(syntheticTypes))],
+				Content: fmt.Sprintf("Berapakah %d + %d?", x, y),
+				IsQuiz:  true,
+			}
+			for j, code := range []string{"a", "b", "c", "d"} {
+				question.Answers = append(question.Answers, Answer{
+					Code:     code,
+					Content:  fmt.Sprint(x + y + j - correct),
+					IsAnswer: j == correct,
+				})
+			}
+			set.Questions = append(set.Questions, question)
+		}
+
+		fixture.Sets = append(fixture.Sets, set)
+	}
+
+	return fixture
+}
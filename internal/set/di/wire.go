@@ -6,15 +6,16 @@ import (
 	setHandler "github.com/ghulammuzz/misterblast/internal/set/handler"
 	setRepo "github.com/ghulammuzz/misterblast/internal/set/repo"
 	setSvc "github.com/ghulammuzz/misterblast/internal/set/svc"
+	"github.com/ghulammuzz/misterblast/pkg/cache"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/wire"
 )
 
-func InitializedSetServiceFake(sb *sql.DB, val *validator.Validate) *setHandler.SetHandler {
+func InitializedSetServiceFake(sb *sql.DB, val *validator.Validate, c cache.Cache) *setHandler.SetHandler {
 	wire.Build(
 		setHandler.NewSetHandler,
 		setSvc.NewSetService,
-		setRepo.NewSetRepository,
+		setRepo.NewCachedSetRepository,
 	)
 
 	return &setHandler.SetHandler{}
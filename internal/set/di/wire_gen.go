@@ -11,13 +11,14 @@ import (
 	"github.com/ghulammuzz/misterblast/internal/set/handler"
 	"github.com/ghulammuzz/misterblast/internal/set/repo"
 	"github.com/ghulammuzz/misterblast/internal/set/svc"
+	"github.com/ghulammuzz/misterblast/pkg/cache"
 	"github.com/go-playground/validator/v10"
 )
 
 // Injectors from wire.go:
 
-func InitializedSetService(sb *sql.DB, val *validator.Validate) *handler.SetHandler {
-	setRepository := repo.NewSetRepository(sb)
+func InitializedSetService(sb *sql.DB, val *validator.Validate, c cache.Cache) *handler.SetHandler {
+	setRepository := repo.NewCachedSetRepository(sb, c)
 	setService := svc.NewSetService(setRepository)
 	setHandler := handler.NewSetHandler(setService, val)
 	return setHandler
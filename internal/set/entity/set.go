@@ -4,3 +4,23 @@ type Set struct {
 	ID   int32  `json:"id"`
 	Name string `json:"name"`
 }
+
+// Ownership tells who may change a set. Admins and its owner always may;
+// other teachers may edit a shared set and its questions, but not delete or
+// unshare it. Sets from before ownership have no owner.
+type Ownership struct {
+	OwnerID  *int32
+	IsShared bool
+}
+
+func (o Ownership) IsOwner(userID int32) bool {
+	return o.OwnerID != nil && *o.OwnerID == userID
+}
+
+func (o Ownership) CanEdit(userID int32, isAdmin bool) bool {
+	return isAdmin || o.IsShared || o.IsOwner(userID)
+}
+
+func (o Ownership) CanManage(userID int32, isAdmin bool) bool {
+	return isAdmin || o.IsOwner(userID)
+}
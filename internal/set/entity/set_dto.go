@@ -1,16 +1,46 @@
 package entity
 
 type SetSet struct {
-	Name     string `json:"name" validate:"required,min=2,max=20"`
-	IsQuiz   bool   `json:"is_quiz"`
-	LessonID int32  `json:"lesson_id" validate:"required"`
-	ClassID  int32  `json:"class_id" validate:"required"`
+	Name             string `json:"name" validate:"required,min=2,max=20"`
+	IsQuiz           bool   `json:"is_quiz"`
+	LessonID         int32  `json:"lesson_id" validate:"required"`
+	ClassID          int32  `json:"class_id" validate:"required"`
+	ShuffleQuestions bool   `json:"shuffle_questions"`
+	ShuffleOptions   bool   `json:"shuffle_options"`
+	TimeLimit        *int   `json:"time_limit" validate:"omitempty,min=1,max=1440"`
+	OpensAt          *int64 `json:"opens_at" validate:"omitempty,min=1"`
+	ClosesAt         *int64 `json:"closes_at" validate:"omitempty,min=1"`
+	IsShared         bool   `json:"is_shared"`
+}
+
+// SetSettings controls how the quiz of a set is delivered. Shuffling draws a
+// new order for every attempt, which stays the same for that attempt.
+// TimeLimit is in minutes; OpensAt and ClosesAt are unix seconds bounding
+// when attempts may be started. A nil value means no limit.
+type SetSettings struct {
+	ShuffleQuestions bool   `json:"shuffle_questions"`
+	ShuffleOptions   bool   `json:"shuffle_options"`
+	TimeLimit        *int   `json:"time_limit" validate:"omitempty,min=1,max=1440"`
+	OpensAt          *int64 `json:"opens_at" validate:"omitempty,min=1"`
+	ClosesAt         *int64 `json:"closes_at" validate:"omitempty,min=1"`
+}
+
+// SetSharing lets every teacher edit the set and its questions.
+type SetSharing struct {
+	IsShared bool `json:"is_shared"`
 }
 
 type ListSet struct {
-	ID     int32  `json:"id"`
-	Name   string `json:"name"`
-	Lesson string `json:"lesson"`
-	Class  string `json:"class"`
-	IsQuiz bool   `json:"is_quiz"`
+	ID               int32  `json:"id"`
+	Name             string `json:"name"`
+	Lesson           string `json:"lesson"`
+	Class            string `json:"class"`
+	IsQuiz           bool   `json:"is_quiz"`
+	ShuffleQuestions bool   `json:"shuffle_questions"`
+	ShuffleOptions   bool   `json:"shuffle_options"`
+	TimeLimit        *int   `json:"time_limit"`
+	OpensAt          *int64 `json:"opens_at"`
+	ClosesAt         *int64 `json:"closes_at"`
+	OwnerID          *int32 `json:"owner_id"`
+	IsShared         bool   `json:"is_shared"`
 }
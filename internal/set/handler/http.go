@@ -5,6 +5,7 @@ import (
 	"github.com/ghulammuzz/misterblast/internal/set/svc"
 	"github.com/ghulammuzz/misterblast/pkg/app"
 	"github.com/ghulammuzz/misterblast/pkg/log"
+	"github.com/ghulammuzz/misterblast/pkg/middleware"
 	"github.com/ghulammuzz/misterblast/pkg/response"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
@@ -20,12 +21,22 @@ func NewSetHandler(setService svc.SetService, val *validator.Validate) *SetHandl
 }
 
 func (h *SetHandler) Router(r fiber.Router) {
-	r.Post("/set", h.AddSetHandler)
-	r.Delete("/set/:id", h.DeleteSetHandler)
-	r.Get("/set", h.ListSetsHandler)
+	auth := middleware.JWTProtected()
+	staff := middleware.RequireRole(middleware.StaffRoles...)
+
+	r.Post("/set", auth, staff, h.AddSetHandler)
+	r.Delete("/set/:id", auth, staff, h.DeleteSetHandler)
+	r.Put("/set/:id/settings", auth, staff, h.EditSettingsHandler)
+	r.Put("/set/:id/sharing", auth, staff, h.EditSharingHandler)
+	r.Get("/set", middleware.JWTOptional(), h.ListSetsHandler)
 }
 
 func (h *SetHandler) AddSetHandler(c *fiber.Ctx) error {
+	userID, ok := middleware.UserID(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
 	var set entity.SetSet
 
 	if err := c.BodyParser(&set); err != nil {
@@ -38,7 +49,7 @@ func (h *SetHandler) AddSetHandler(c *fiber.Ctx) error {
 		return response.SendError(c, fiber.StatusBadRequest, "Validation failed", validationErrors)
 	}
 
-	if err := h.setService.AddSet(set); err != nil {
+	if err := h.setService.AddSet(userID, set); err != nil {
 		appErr, ok := err.(*app.AppError)
 		if !ok {
 			appErr = app.ErrInternal
@@ -50,12 +61,17 @@ func (h *SetHandler) AddSetHandler(c *fiber.Ctx) error {
 }
 
 func (h *SetHandler) DeleteSetHandler(c *fiber.Ctx) error {
+	userID, ok := middleware.UserID(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
 	id, err := c.ParamsInt("id")
 	if err != nil {
 		return response.SendError(c, fiber.StatusBadRequest, "invalid id", nil)
 	}
 
-	if err := h.setService.DeleteSet(int32(id)); err != nil {
+	if err := h.setService.DeleteSet(userID, middleware.IsAdmin(c), middleware.SchoolID(c), int32(id)); err != nil {
 		appErr, ok := err.(*app.AppError)
 		if !ok {
 			appErr = app.ErrInternal
@@ -66,6 +82,68 @@ func (h *SetHandler) DeleteSetHandler(c *fiber.Ctx) error {
 	return response.SendSuccess(c, "set deleted successfully", nil)
 }
 
+// EditSettingsHandler changes how the quiz of a set is delivered and when it
+// can be taken. Attempts already started keep their settings and deadline.
+func (h *SetHandler) EditSettingsHandler(c *fiber.Ctx) error {
+	userID, ok := middleware.UserID(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid id", nil)
+	}
+
+	var settings entity.SetSettings
+	if err := c.BodyParser(&settings); err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid request body", nil)
+	}
+
+	if err := h.val.Struct(settings); err != nil {
+		validationErrors := app.ValidationErrorResponse(err)
+		return response.SendError(c, fiber.StatusBadRequest, "Validation failed", validationErrors)
+	}
+
+	if err := h.setService.EditSettings(userID, middleware.IsAdmin(c), middleware.SchoolID(c), int32(id), settings); err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "set settings updated successfully", nil)
+}
+
+// EditSharingHandler shares a set with every teacher or takes it back.
+func (h *SetHandler) EditSharingHandler(c *fiber.Ctx) error {
+	userID, ok := middleware.UserID(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid id", nil)
+	}
+
+	var sharing entity.SetSharing
+	if err := c.BodyParser(&sharing); err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "invalid request body", nil)
+	}
+
+	if err := h.setService.EditSharing(userID, middleware.IsAdmin(c), middleware.SchoolID(c), int32(id), sharing); err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "set sharing updated successfully", nil)
+}
+
 func (h *SetHandler) ListSetsHandler(c *fiber.Ctx) error {
 	filter := map[string]string{}
 	if class := c.Query("class"); class != "" {
@@ -77,8 +155,11 @@ func (h *SetHandler) ListSetsHandler(c *fiber.Ctx) error {
 	if isQuiz := c.Query("is_quiz"); isQuiz != "" {
 		filter["is_quiz"] = isQuiz
 	}
+	if ownerID := c.Query("owner_id"); ownerID != "" {
+		filter["owner_id"] = ownerID
+	}
 
-	sets, err := h.setService.ListSets(filter)
+	sets, err := h.setService.ListSets(middleware.SchoolID(c), filter)
 	if err != nil {
 		appErr, ok := err.(*app.AppError)
 		if !ok {
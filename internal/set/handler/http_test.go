@@ -5,15 +5,20 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http/httptest"
+	"os"
 	"testing"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
 	"github.com/ghulammuzz/misterblast/internal/set/entity"
 	"github.com/ghulammuzz/misterblast/internal/set/handler"
+	apperr "github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/middleware"
 )
 
 // Mock Service
@@ -21,35 +26,65 @@ type MockSetService struct {
 	mock.Mock
 }
 
-func (m *MockSetService) AddSet(set entity.SetSet) error {
-	args := m.Called(set)
+func (m *MockSetService) AddSet(ownerID int32, set entity.SetSet) error {
+	args := m.Called(ownerID, set)
 	return args.Error(0)
 }
 
-func (m *MockSetService) DeleteSet(id int32) error {
-	args := m.Called(id)
+func (m *MockSetService) DeleteSet(userID int32, isAdmin bool, schoolID *int32, id int32) error {
+	args := m.Called(userID, isAdmin, schoolID, id)
 	return args.Error(0)
 }
 
-func (m *MockSetService) ListSets(filter map[string]string) ([]entity.ListSet, error) {
-	args := m.Called(filter)
+func (m *MockSetService) ListSets(schoolID *int32, filter map[string]string) ([]entity.ListSet, error) {
+	args := m.Called(schoolID, filter)
 	return args.Get(0).([]entity.ListSet), args.Error(1)
 }
 
+func (m *MockSetService) EditSettings(userID int32, isAdmin bool, schoolID *int32, id int32, settings entity.SetSettings) error {
+	args := m.Called(userID, isAdmin, schoolID, id, settings)
+	return args.Error(0)
+}
+
+func (m *MockSetService) EditSharing(userID int32, isAdmin bool, schoolID *int32, id int32, sharing entity.SetSharing) error {
+	args := m.Called(userID, isAdmin, schoolID, id, sharing)
+	return args.Error(0)
+}
+
+func school(id int32) *int32 {
+	return &id
+}
+
+func signedToken(userID int, role string) string {
+	claims := jwt.MapClaims{
+		"apps":      "misterblast-core",
+		"email":     "john@example.com",
+		"user_id":   userID,
+		"role":      role,
+		"school_id": 1,
+		"jti":       "jti-test",
+		"exp":       time.Now().Add(time.Hour).Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, _ := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+	return signed
+}
+
 func TestAddSetHandler(t *testing.T) {
 	app := fiber.New()
 	mockService := new(MockSetService)
 	validate := validator.New()
 	h := handler.NewSetHandler(mockService, validate)
 
-	app.Post("/set", h.AddSetHandler)
+	app.Post("/set", middleware.JWTProtected(), h.AddSetHandler)
 
 	set := entity.SetSet{Name: "Set A", LessonID: 1, ClassID: 1}
-	mockService.On("AddSet", set).Return(nil)
+	mockService.On("AddSet", int32(9), set).Return(nil)
 
 	body, _ := json.Marshal(set)
 	req := httptest.NewRequest("POST", "/set", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+signedToken(9, middleware.RoleTeacher))
 
 	resp, _ := app.Test(req)
 	assert.Equal(t, 200, resp.StatusCode)
@@ -62,11 +97,12 @@ func TestDeleteSetHandler(t *testing.T) {
 	validate := validator.New()
 	h := handler.NewSetHandler(mockService, validate)
 
-	app.Delete("/set/:id", h.DeleteSetHandler)
+	app.Delete("/set/:id", middleware.JWTProtected(), h.DeleteSetHandler)
 
-	mockService.On("DeleteSet", int32(1)).Return(nil)
+	mockService.On("DeleteSet", int32(9), true, school(1), int32(1)).Return(nil)
 
 	req := httptest.NewRequest("DELETE", "/set/1", nil)
+	req.Header.Set("Authorization", "Bearer "+signedToken(9, middleware.RoleSchoolAdmin))
 	resp, _ := app.Test(req)
 
 	assert.Equal(t, 200, resp.StatusCode)
@@ -85,7 +121,7 @@ func TestListSetsHandler(t *testing.T) {
 		{ID: 1, Name: "Set A", Lesson: "Math", Class: "Class 1"},
 		{ID: 2, Name: "Set B", Lesson: "Science", Class: "Class 2"},
 	}
-	mockService.On("ListSets", mock.Anything).Return(mockSets, nil)
+	mockService.On("ListSets", school(0), mock.Anything).Return(mockSets, nil)
 
 	req := httptest.NewRequest("GET", "/set", nil)
 	resp, _ := app.Test(req)
@@ -102,7 +138,7 @@ func TestListSetsHandler_Error(t *testing.T) {
 
 	app.Get("/set", h.ListSetsHandler)
 
-	mockService.On("ListSets", mock.Anything).Return([]entity.ListSet{}, errors.New("database error"))
+	mockService.On("ListSets", mock.Anything, mock.Anything).Return([]entity.ListSet{}, errors.New("database error"))
 
 	req := httptest.NewRequest("GET", "/set", nil)
 	resp, _ := app.Test(req)
@@ -110,3 +146,91 @@ func TestListSetsHandler_Error(t *testing.T) {
 	assert.Equal(t, 500, resp.StatusCode)
 	mockService.AssertExpectations(t)
 }
+
+func TestEditSettingsHandler(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockSetService)
+	validate := validator.New()
+	h := handler.NewSetHandler(mockService, validate)
+
+	app.Put("/set/:id/settings", middleware.JWTProtected(), h.EditSettingsHandler)
+
+	settings := entity.SetSettings{ShuffleOptions: true}
+	mockService.On("EditSettings", int32(9), false, school(1), int32(4), settings).Return(nil)
+
+	body, _ := json.Marshal(settings)
+	req := httptest.NewRequest("PUT", "/set/4/settings", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+signedToken(9, middleware.RoleTeacher))
+
+	resp, _ := app.Test(req)
+	assert.Equal(t, 200, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
+func TestEditSettingsHandler_InvalidTimeLimit(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockSetService)
+	h := handler.NewSetHandler(mockService, validator.New())
+
+	app.Put("/set/:id/settings", middleware.JWTProtected(), h.EditSettingsHandler)
+
+	req := httptest.NewRequest("PUT", "/set/4/settings", bytes.NewReader([]byte(`{"time_limit": 5000}`)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+signedToken(9, middleware.RoleTeacher))
+
+	resp, _ := app.Test(req)
+	assert.Equal(t, 400, resp.StatusCode)
+	mockService.AssertNotCalled(t, "EditSettings", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEditSharingHandler_NotOwner(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockSetService)
+	h := handler.NewSetHandler(mockService, validator.New())
+
+	app.Put("/set/:id/sharing", middleware.JWTProtected(), h.EditSharingHandler)
+
+	mockService.On("EditSharing", int32(9), false, school(1), int32(4), entity.SetSharing{IsShared: true}).
+		Return(apperr.NewAppError(403, "only the owner can delete or share this set"))
+
+	req := httptest.NewRequest("PUT", "/set/4/sharing", bytes.NewReader([]byte(`{"is_shared": true}`)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+signedToken(9, middleware.RoleTeacher))
+
+	resp, _ := app.Test(req)
+	assert.Equal(t, 403, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
+func TestSetRouter_StudentForbidden(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockSetService)
+	handler.NewSetHandler(mockService, validator.New()).Router(app)
+
+	req := httptest.NewRequest("DELETE", "/set/4", nil)
+	req.Header.Set("Authorization", "Bearer "+signedToken(11, middleware.RoleStudent))
+
+	resp, _ := app.Test(req)
+	assert.Equal(t, 403, resp.StatusCode)
+	mockService.AssertNotCalled(t, "DeleteSet", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSetRouter_ListScopedToSchool(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockSetService)
+	handler.NewSetHandler(mockService, validator.New()).Router(app)
+
+	mockService.On("ListSets", school(1), mock.Anything).Return([]entity.ListSet{}, nil).Once()
+	mockService.On("ListSets", school(0), mock.Anything).Return([]entity.ListSet{}, nil).Once()
+
+	req := httptest.NewRequest("GET", "/set", nil)
+	req.Header.Set("Authorization", "Bearer "+signedToken(9, middleware.RoleTeacher))
+	resp, _ := app.Test(req)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	// Anonymous callers only see the sets without a school.
+	resp, _ = app.Test(httptest.NewRequest("GET", "/set", nil))
+	assert.Equal(t, 200, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,70 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+
+	setEntity "github.com/ghulammuzz/misterblast/internal/set/entity"
+	"github.com/ghulammuzz/misterblast/pkg/cache"
+)
+
+type cachedSetRepository struct {
+	repo SetRepository
+	sets *cache.Namespace
+	// questions are filtered and listed by their set.
+	questions *cache.Namespace
+}
+
+// NewCachedSetRepository caches set reads in c and drops them, along with the
+// question reads that embed set data, on every write.
+func NewCachedSetRepository(db *sql.DB, c cache.Cache) SetRepository {
+	return &cachedSetRepository{
+		repo:      NewSetRepository(db),
+		sets:      cache.NewNamespace(c, cache.NamespaceSet, cache.DefaultTTL),
+		questions: cache.NewNamespace(c, cache.NamespaceQuestion, cache.DefaultTTL),
+	}
+}
+
+func (r *cachedSetRepository) Add(ownerID int32, set setEntity.SetSet) error {
+	if err := r.repo.Add(ownerID, set); err != nil {
+		return err
+	}
+	cache.Invalidate(context.Background(), r.sets, r.questions)
+	return nil
+}
+
+func (r *cachedSetRepository) Delete(id int32) error {
+	if err := r.repo.Delete(id); err != nil {
+		return err
+	}
+	cache.Invalidate(context.Background(), r.sets, r.questions)
+	return nil
+}
+
+func (r *cachedSetRepository) EditSettings(id int32, settings setEntity.SetSettings) error {
+	if err := r.repo.EditSettings(id, settings); err != nil {
+		return err
+	}
+	cache.Invalidate(context.Background(), r.sets)
+	return nil
+}
+
+func (r *cachedSetRepository) EditSharing(id int32, shared bool) error {
+	if err := r.repo.EditSharing(id, shared); err != nil {
+		return err
+	}
+	cache.Invalidate(context.Background(), r.sets)
+	return nil
+}
+
+// Ownership is not cached so permission checks see changes right away.
+func (r *cachedSetRepository) Ownership(schoolID *int32, id int32) (setEntity.Ownership, error) {
+	return r.repo.Ownership(schoolID, id)
+}
+
+func (r *cachedSetRepository) List(schoolID *int32, filter map[string]string) ([]setEntity.ListSet, error) {
+	key := "list:" + cache.ScopeKey(schoolID) + "?" + cache.FilterKey(filter)
+	return cache.Remember(context.Background(), r.sets, key, func() ([]setEntity.ListSet, error) {
+		return r.repo.List(schoolID, filter)
+	})
+}
@@ -10,9 +10,12 @@ import (
 )
 
 type SetRepository interface {
-	Add(class setEntity.SetSet) error
+	Add(ownerID int32, class setEntity.SetSet) error
 	Delete(id int32) error
-	List(filter map[string]string) ([]setEntity.ListSet, error)
+	List(schoolID *int32, filter map[string]string) ([]setEntity.ListSet, error)
+	EditSettings(id int32, settings setEntity.SetSettings) error
+	EditSharing(id int32, shared bool) error
+	Ownership(schoolID *int32, id int32) (setEntity.Ownership, error)
 }
 
 type setRepository struct {
@@ -23,10 +26,14 @@ func NewSetRepository(db *sql.DB) SetRepository {
 	return &setRepository{db: db}
 }
 
-func (c *setRepository) Add(class setEntity.SetSet) error {
+// Add stores a set in the school of its owner.
+func (c *setRepository) Add(ownerID int32, class setEntity.SetSet) error {
 
-	query := `INSERT INTO sets (name, lesson_id, class_id, is_quiz) VALUES ($1, $2, $3, $4)`
-	_, err := c.db.Exec(query, class.Name, class.LessonID, class.ClassID, class.IsQuiz)
+	query := `INSERT INTO sets (name, lesson_id, class_id, is_quiz, shuffle_questions, shuffle_options, time_limit, opens_at, closes_at,
+		owner_id, is_shared, school_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, (SELECT school_id FROM users WHERE id = $10))`
+	_, err := c.db.Exec(query, class.Name, class.LessonID, class.ClassID, class.IsQuiz, class.ShuffleQuestions, class.ShuffleOptions,
+		class.TimeLimit, class.OpensAt, class.ClosesAt, ownerID, class.IsShared)
 	if err != nil {
 		log.Error("[Repo][AddSet] Error Exec: ", err)
 		return app.NewAppError(500, "failed to insert class")
@@ -55,8 +62,77 @@ func (c *setRepository) Delete(id int32) error {
 	return nil
 }
 
-func (r *setRepository) List(filter map[string]string) ([]setEntity.ListSet, error) {
-	query := `SELECT s.id, s.name, l.name AS lesson, c.name AS class, s.is_quiz FROM sets s
+func (c *setRepository) EditSettings(id int32, settings setEntity.SetSettings) error {
+	query := `UPDATE sets SET shuffle_questions = $1, shuffle_options = $2, time_limit = $3, opens_at = $4, closes_at = $5 WHERE id = $6`
+	result, err := c.db.Exec(query, settings.ShuffleQuestions, settings.ShuffleOptions,
+		settings.TimeLimit, settings.OpensAt, settings.ClosesAt, id)
+	if err != nil {
+		log.Error("[Repo][EditSetSettings] Error Exec: ", err)
+		return app.NewAppError(500, "failed to update set settings")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Error("[Repo][EditSetSettings] Error RowsAffected: ", err)
+		return app.NewAppError(500, "failed to check rows affected")
+	}
+	if rowsAffected == 0 {
+		return app.ErrNotFound
+	}
+
+	return nil
+}
+
+func (c *setRepository) EditSharing(id int32, shared bool) error {
+	result, err := c.db.Exec(`UPDATE sets SET is_shared = $1 WHERE id = $2`, shared, id)
+	if err != nil {
+		log.Error("[Repo][EditSetSharing] Error Exec: ", err)
+		return app.NewAppError(500, "failed to update set sharing")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Error("[Repo][EditSetSharing] Error RowsAffected: ", err)
+		return app.NewAppError(500, "failed to check rows affected")
+	}
+	if rowsAffected == 0 {
+		return app.ErrNotFound
+	}
+
+	return nil
+}
+
+// Ownership reports sets outside schoolID as not found, which keeps the shared
+// sets without a school out of reach of everyone but super admins.
+func (c *setRepository) Ownership(schoolID *int32, id int32) (setEntity.Ownership, error) {
+	var ownership setEntity.Ownership
+	var ownerID sql.NullInt32
+	query := `SELECT owner_id, is_shared FROM sets WHERE id = $1`
+	args := []interface{}{id}
+	if schoolID != nil {
+		query += ` AND school_id = $2`
+		args = append(args, *schoolID)
+	}
+	err := c.db.QueryRow(query, args...).Scan(&ownerID, &ownership.IsShared)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ownership, app.NewAppError(404, "set not found")
+		}
+		log.Error("[Repo][SetOwnership] Error QueryRow: ", err)
+		return ownership, app.NewAppError(500, "failed to fetch set")
+	}
+	if ownerID.Valid {
+		ownership.OwnerID = &ownerID.Int32
+	}
+
+	return ownership, nil
+}
+
+// List returns the sets of schoolID along with the shared sets without a
+// school; nil lists every school.
+func (r *setRepository) List(schoolID *int32, filter map[string]string) ([]setEntity.ListSet, error) {
+	query := `SELECT s.id, s.name, l.name AS lesson, c.name AS class, s.is_quiz, s.shuffle_questions, s.shuffle_options,
+	s.time_limit, s.opens_at, s.closes_at, s.owner_id, s.is_shared FROM sets s
 	JOIN lessons l ON s.lesson_id = l.id
 	JOIN classes c ON s.class_id = c.id WHERE 1=1`
 	args := []interface{}{}
@@ -77,6 +153,16 @@ func (r *setRepository) List(filter map[string]string) ([]setEntity.ListSet, err
 		args = append(args, isQuiz == "true") // Konversi string ke boolean
 		argCounter++
 	}
+	if ownerID, ok := filter["owner_id"]; ok {
+		query += fmt.Sprintf(" AND s.owner_id = $%d", argCounter)
+		args = append(args, ownerID)
+		argCounter++
+	}
+	if schoolID != nil {
+		query += fmt.Sprintf(" AND (s.school_id = $%d OR s.school_id IS NULL)", argCounter)
+		args = append(args, *schoolID)
+		argCounter++
+	}
 
 	rows, err := r.db.Query(query, args...)
 	if err != nil {
@@ -88,10 +174,27 @@ func (r *setRepository) List(filter map[string]string) ([]setEntity.ListSet, err
 	var sets []setEntity.ListSet
 	for rows.Next() {
 		var set setEntity.ListSet
-		if err := rows.Scan(&set.ID, &set.Name, &set.Lesson, &set.Class, &set.IsQuiz); err != nil {
+		var timeLimit sql.NullInt32
+		var opensAt, closesAt sql.NullInt64
+		var ownerID sql.NullInt32
+		if err := rows.Scan(&set.ID, &set.Name, &set.Lesson, &set.Class, &set.IsQuiz, &set.ShuffleQuestions, &set.ShuffleOptions,
+			&timeLimit, &opensAt, &closesAt, &ownerID, &set.IsShared); err != nil {
 			log.Error("[Repo][ListSets] Error Scan: ", err)
 			return nil, app.NewAppError(500, "failed to scan set")
 		}
+		if timeLimit.Valid {
+			minutes := int(timeLimit.Int32)
+			set.TimeLimit = &minutes
+		}
+		if opensAt.Valid {
+			set.OpensAt = &opensAt.Int64
+		}
+		if closesAt.Valid {
+			set.ClosesAt = &closesAt.Int64
+		}
+		if ownerID.Valid {
+			set.OwnerID = &ownerID.Int32
+		}
 		sets = append(sets, set)
 	}
 
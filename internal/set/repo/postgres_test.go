@@ -1,6 +1,7 @@
 package repo_test
 
 import (
+	"database/sql"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
@@ -16,12 +17,12 @@ func TestAddSet(t *testing.T) {
 
 	repository := repo.NewSetRepository(db)
 
-	mock.ExpectExec("INSERT INTO sets").
-		WithArgs("Set A", 1, 1, false).
+	mock.ExpectExec(`INSERT INTO sets .+ VALUES \(.+, \(SELECT school_id FROM users WHERE id = \$10\)\)`).
+		WithArgs("Set A", 1, 1, false, false, false, nil, nil, nil, 9, true).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	set := entity.SetSet{Name: "Set A", LessonID: 1, ClassID: 1, IsQuiz: false}
-	err = repository.Add(set)
+	set := entity.SetSet{Name: "Set A", LessonID: 1, ClassID: 1, IsQuiz: false, IsShared: true}
+	err = repository.Add(9, set)
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -49,18 +50,24 @@ func TestListSets(t *testing.T) {
 
 	repository := repo.NewSetRepository(db)
 
-	rows := sqlmock.NewRows([]string{"id", "name", "lesson", "class", "is_quiz"}).
-		AddRow(1, "Set A", "Math", "Class 1", false).
-		AddRow(2, "Set B", "Science", "Class 2", true)
+	rows := sqlmock.NewRows([]string{"id", "name", "lesson", "class", "is_quiz", "shuffle_questions", "shuffle_options", "time_limit", "opens_at", "closes_at", "owner_id", "is_shared"}).
+		AddRow(1, "Set A", "Math", "Class 1", false, false, false, nil, nil, nil, nil, false).
+		AddRow(2, "Set B", "Science", "Class 2", true, true, false, 30, 1700000000, 1700086400, 9, true)
 
-	mock.ExpectQuery("SELECT s.id, s.name, l.name AS lesson, c.name AS class, s.is_quiz FROM sets").
+	mock.ExpectQuery(`SELECT s.id, s.name, l.name AS lesson, c.name AS class, s.is_quiz, s.shuffle_questions, s.shuffle_options,\s+s.time_limit, s.opens_at, s.closes_at, s.owner_id, s.is_shared FROM sets`).
 		WillReturnRows(rows)
 
 	filter := map[string]string{}
-	sets, err := repository.List(filter)
+	sets, err := repository.List(nil, filter)
 	assert.NoError(t, err)
 	assert.Len(t, sets, 2)
 	assert.Equal(t, "Set A", sets[0].Name)
+	assert.Nil(t, sets[0].TimeLimit)
+	assert.Equal(t, 30, *sets[1].TimeLimit)
+	assert.Equal(t, int64(1700086400), *sets[1].ClosesAt)
+	assert.Nil(t, sets[0].OwnerID)
+	assert.Equal(t, int32(9), *sets[1].OwnerID)
+	assert.True(t, sets[1].IsShared)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -71,18 +78,88 @@ func TestListWithFilters(t *testing.T) {
 
 	repository := repo.NewSetRepository(db)
 
-	rows := sqlmock.NewRows([]string{"id", "name", "lesson", "class", "is_quiz"}).
-		AddRow(1, "Set A", "Math", "Class 1", false)
+	rows := sqlmock.NewRows([]string{"id", "name", "lesson", "class", "is_quiz", "shuffle_questions", "shuffle_options", "time_limit", "opens_at", "closes_at", "owner_id", "is_shared"}).
+		AddRow(1, "Set A", "Math", "Class 1", false, false, false, nil, nil, nil, 9, false)
 
-	mock.ExpectQuery(`SELECT s.id, s.name, l.name AS lesson, c.name AS class, s.is_quiz FROM sets s`+
+	mock.ExpectQuery(`SELECT s.id, s.name, l.name AS lesson, c.name AS class, s.is_quiz, s.shuffle_questions, s.shuffle_options,\s+s.time_limit, s.opens_at, s.closes_at, s.owner_id, s.is_shared FROM sets s`+
 		` JOIN lessons l ON s.lesson_id = l.id`+
-		` JOIN classes c ON s.class_id = c.id WHERE 1=1 AND l.name = \$1 AND c.name = \$2`).
-		WithArgs("Math", "Class 1").
+		` JOIN classes c ON s.class_id = c.id WHERE 1=1 AND l.name = \$1 AND c.name = \$2`+
+		` AND \(s.school_id = \$3 OR s.school_id IS NULL\)`).
+		WithArgs("Math", "Class 1", int32(2)).
 		WillReturnRows(rows)
 
+	schoolID := int32(2)
 	filters := map[string]string{"lesson": "Math", "class": "Class 1"}
-	sets, err := repository.List(filters)
+	sets, err := repository.List(&schoolID, filters)
 	assert.NoError(t, err)
 	assert.Len(t, sets, 1)
 	assert.Equal(t, "Set A", sets[0].Name)
 }
+
+func TestEditSettings(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewSetRepository(db)
+
+	mock.ExpectExec(`UPDATE sets SET shuffle_questions = \$1, shuffle_options = \$2, time_limit = \$3, opens_at = \$4, closes_at = \$5 WHERE id = \$6`).
+		WithArgs(true, false, nil, nil, nil, 4).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repository.EditSettings(4, entity.SetSettings{ShuffleQuestions: true})
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetOwnership(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewSetRepository(db)
+
+	mock.ExpectQuery(`SELECT owner_id, is_shared FROM sets WHERE id = \$1`).
+		WithArgs(4).
+		WillReturnRows(sqlmock.NewRows([]string{"owner_id", "is_shared"}).AddRow(9, true))
+
+	ownership, err := repository.Ownership(nil, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(9), *ownership.OwnerID)
+	assert.True(t, ownership.IsShared)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetOwnership_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewSetRepository(db)
+
+	// A set of another school is not found.
+	mock.ExpectQuery(`SELECT owner_id, is_shared FROM sets WHERE id = \$1 AND school_id = \$2`).
+		WithArgs(4, int32(2)).
+		WillReturnError(sql.ErrNoRows)
+
+	schoolID := int32(2)
+	_, err = repository.Ownership(&schoolID, 4)
+	assert.EqualError(t, err, "set not found")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEditSharing(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repository := repo.NewSetRepository(db)
+
+	mock.ExpectExec(`UPDATE sets SET is_shared = \$1 WHERE id = \$2`).
+		WithArgs(true, 4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repository.EditSharing(4, true)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
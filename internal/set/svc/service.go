@@ -3,12 +3,23 @@ package svc
 import (
 	setEntity "github.com/ghulammuzz/misterblast/internal/set/entity"
 	setRepo "github.com/ghulammuzz/misterblast/internal/set/repo"
+	"github.com/ghulammuzz/misterblast/pkg/app"
 )
 
+var (
+	errNotEditable = app.NewAppError(403, "you can only change your own or shared sets")
+	errNotOwner    = app.NewAppError(403, "only the owner can delete or share this set")
+)
+
+// SetService takes the caller, whether they are an admin and their school on
+// every write; see setEntity.Ownership for who may change a set. Sets outside
+// the school are not found.
 type SetService interface {
-	AddSet(set setEntity.SetSet) error
-	DeleteSet(id int32) error
-	ListSets(filter map[string]string) ([]setEntity.ListSet, error)
+	AddSet(ownerID int32, set setEntity.SetSet) error
+	DeleteSet(userID int32, isAdmin bool, schoolID *int32, id int32) error
+	ListSets(schoolID *int32, filter map[string]string) ([]setEntity.ListSet, error)
+	EditSettings(userID int32, isAdmin bool, schoolID *int32, id int32, settings setEntity.SetSettings) error
+	EditSharing(userID int32, isAdmin bool, schoolID *int32, id int32, sharing setEntity.SetSharing) error
 }
 
 type setService struct {
@@ -19,14 +30,57 @@ func NewSetService(repo setRepo.SetRepository) SetService {
 	return &setService{repo: repo}
 }
 
-func (s *setService) AddSet(set setEntity.SetSet) error {
-	return s.repo.Add(set)
+func (s *setService) AddSet(ownerID int32, set setEntity.SetSet) error {
+	if err := checkWindow(set.OpensAt, set.ClosesAt); err != nil {
+		return err
+	}
+	return s.repo.Add(ownerID, set)
 }
 
-func (s *setService) DeleteSet(id int32) error {
+func (s *setService) DeleteSet(userID int32, isAdmin bool, schoolID *int32, id int32) error {
+	ownership, err := s.repo.Ownership(schoolID, id)
+	if err != nil {
+		return err
+	}
+	if !ownership.CanManage(userID, isAdmin) {
+		return errNotOwner
+	}
 	return s.repo.Delete(id)
 }
 
-func (s *setService) ListSets(filter map[string]string) ([]setEntity.ListSet, error) {
-	return s.repo.List(filter)
+func (s *setService) EditSettings(userID int32, isAdmin bool, schoolID *int32, id int32, settings setEntity.SetSettings) error {
+	if err := checkWindow(settings.OpensAt, settings.ClosesAt); err != nil {
+		return err
+	}
+
+	ownership, err := s.repo.Ownership(schoolID, id)
+	if err != nil {
+		return err
+	}
+	if !ownership.CanEdit(userID, isAdmin) {
+		return errNotEditable
+	}
+	return s.repo.EditSettings(id, settings)
+}
+
+func (s *setService) EditSharing(userID int32, isAdmin bool, schoolID *int32, id int32, sharing setEntity.SetSharing) error {
+	ownership, err := s.repo.Ownership(schoolID, id)
+	if err != nil {
+		return err
+	}
+	if !ownership.CanManage(userID, isAdmin) {
+		return errNotOwner
+	}
+	return s.repo.EditSharing(id, sharing.IsShared)
+}
+
+func checkWindow(opensAt, closesAt *int64) error {
+	if opensAt != nil && closesAt != nil && *closesAt <= *opensAt {
+		return app.NewAppError(400, "closes_at must be after opens_at")
+	}
+	return nil
+}
+
+func (s *setService) ListSets(schoolID *int32, filter map[string]string) ([]setEntity.ListSet, error) {
+	return s.repo.List(schoolID, filter)
 }
@@ -9,6 +9,7 @@ import (
 
 	"github.com/ghulammuzz/misterblast/internal/set/entity"
 	"github.com/ghulammuzz/misterblast/internal/set/svc"
+	"github.com/ghulammuzz/misterblast/pkg/app"
 )
 
 // Mock Repository
@@ -16,8 +17,8 @@ type MockSetRepository struct {
 	mock.Mock
 }
 
-func (m *MockSetRepository) Add(set entity.SetSet) error {
-	args := m.Called(set)
+func (m *MockSetRepository) Add(ownerID int32, set entity.SetSet) error {
+	args := m.Called(ownerID, set)
 	return args.Error(0)
 }
 
@@ -26,19 +27,42 @@ func (m *MockSetRepository) Delete(id int32) error {
 	return args.Error(0)
 }
 
-func (m *MockSetRepository) List(filter map[string]string) ([]entity.ListSet, error) {
-	args := m.Called(filter)
+func (m *MockSetRepository) List(schoolID *int32, filter map[string]string) ([]entity.ListSet, error) {
+	args := m.Called(schoolID, filter)
 	return args.Get(0).([]entity.ListSet), args.Error(1)
 }
 
+func (m *MockSetRepository) EditSettings(id int32, settings entity.SetSettings) error {
+	args := m.Called(id, settings)
+	return args.Error(0)
+}
+
+func (m *MockSetRepository) EditSharing(id int32, shared bool) error {
+	args := m.Called(id, shared)
+	return args.Error(0)
+}
+
+func (m *MockSetRepository) Ownership(schoolID *int32, id int32) (entity.Ownership, error) {
+	args := m.Called(schoolID, id)
+	return args.Get(0).(entity.Ownership), args.Error(1)
+}
+
+func school(id int32) *int32 {
+	return &id
+}
+
+func ownedBy(userID int32, shared bool) entity.Ownership {
+	return entity.Ownership{OwnerID: &userID, IsShared: shared}
+}
+
 func TestAddSet(t *testing.T) {
 	mockRepo := new(MockSetRepository)
 	service := svc.NewSetService(mockRepo)
 
 	set := entity.SetSet{Name: "Set A", LessonID: 1, ClassID: 1}
-	mockRepo.On("Add", set).Return(nil)
+	mockRepo.On("Add", int32(9), set).Return(nil)
 
-	err := service.AddSet(set)
+	err := service.AddSet(9, set)
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
@@ -47,9 +71,34 @@ func TestDeleteSet(t *testing.T) {
 	mockRepo := new(MockSetRepository)
 	service := svc.NewSetService(mockRepo)
 
+	mockRepo.On("Ownership", school(1), int32(1)).Return(ownedBy(9, false), nil)
 	mockRepo.On("Delete", int32(1)).Return(nil)
 
-	err := service.DeleteSet(1)
+	err := service.DeleteSet(9, false, school(1), 1)
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDeleteSet_SharedNotOwner(t *testing.T) {
+	mockRepo := new(MockSetRepository)
+	service := svc.NewSetService(mockRepo)
+
+	mockRepo.On("Ownership", school(1), int32(1)).Return(ownedBy(8, true), nil)
+
+	err := service.DeleteSet(9, false, school(1), 1)
+	assert.Error(t, err)
+	assert.Equal(t, 403, err.(*app.AppError).Code)
+	mockRepo.AssertNotCalled(t, "Delete", mock.Anything)
+}
+
+func TestDeleteSet_Admin(t *testing.T) {
+	mockRepo := new(MockSetRepository)
+	service := svc.NewSetService(mockRepo)
+
+	mockRepo.On("Ownership", school(1), int32(1)).Return(entity.Ownership{}, nil)
+	mockRepo.On("Delete", int32(1)).Return(nil)
+
+	err := service.DeleteSet(2, true, school(1), 1)
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
@@ -62,9 +111,9 @@ func TestListSets(t *testing.T) {
 		{ID: 1, Name: "Set A", Lesson: "Math", Class: "Class 1"},
 		{ID: 2, Name: "Set B", Lesson: "Science", Class: "Class 2"},
 	}
-	mockRepo.On("List", mock.Anything).Return(mockSets, nil)
+	mockRepo.On("List", school(1), mock.Anything).Return(mockSets, nil)
 
-	sets, err := service.ListSets(map[string]string{})
+	sets, err := service.ListSets(school(1), map[string]string{})
 	assert.NoError(t, err)
 	assert.Len(t, sets, 2)
 	mockRepo.AssertExpectations(t)
@@ -74,10 +123,71 @@ func TestListSets_Error(t *testing.T) {
 	mockRepo := new(MockSetRepository)
 	service := svc.NewSetService(mockRepo)
 
-	mockRepo.On("List", mock.Anything).Return([]entity.ListSet{}, errors.New("database error"))
+	mockRepo.On("List", school(1), mock.Anything).Return([]entity.ListSet{}, errors.New("database error"))
 
-	sets, err := service.ListSets(map[string]string{})
+	sets, err := service.ListSets(school(1), map[string]string{})
 	assert.Error(t, err)
 	assert.Empty(t, sets)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestEditSettings(t *testing.T) {
+	mockRepo := new(MockSetRepository)
+	service := svc.NewSetService(mockRepo)
+
+	settings := entity.SetSettings{ShuffleQuestions: true, ShuffleOptions: true}
+	mockRepo.On("Ownership", school(1), int32(4)).Return(ownedBy(9, false), nil)
+	mockRepo.On("EditSettings", int32(4), settings).Return(nil)
+
+	err := service.EditSettings(9, false, school(1), 4, settings)
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestEditSettings_NotOwner(t *testing.T) {
+	mockRepo := new(MockSetRepository)
+	service := svc.NewSetService(mockRepo)
+
+	mockRepo.On("Ownership", school(1), int32(4)).Return(ownedBy(8, false), nil)
+
+	err := service.EditSettings(9, false, school(1), 4, entity.SetSettings{ShuffleQuestions: true})
+	assert.Error(t, err)
+	assert.Equal(t, 403, err.(*app.AppError).Code)
+	mockRepo.AssertNotCalled(t, "EditSettings", mock.Anything, mock.Anything)
+}
+
+func TestEditSettings_Shared(t *testing.T) {
+	mockRepo := new(MockSetRepository)
+	service := svc.NewSetService(mockRepo)
+
+	settings := entity.SetSettings{ShuffleQuestions: true}
+	mockRepo.On("Ownership", school(1), int32(4)).Return(ownedBy(8, true), nil)
+	mockRepo.On("EditSettings", int32(4), settings).Return(nil)
+
+	err := service.EditSettings(9, false, school(1), 4, settings)
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestEditSharing_Owner(t *testing.T) {
+	mockRepo := new(MockSetRepository)
+	service := svc.NewSetService(mockRepo)
+
+	mockRepo.On("Ownership", school(1), int32(4)).Return(ownedBy(9, false), nil)
+	mockRepo.On("EditSharing", int32(4), true).Return(nil)
+
+	err := service.EditSharing(9, false, school(1), 4, entity.SetSharing{IsShared: true})
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestEditSettings_ClosesBeforeOpening(t *testing.T) {
+	mockRepo := new(MockSetRepository)
+	service := svc.NewSetService(mockRepo)
+
+	opensAt, closesAt := int64(1700086400), int64(1700000000)
+	err := service.EditSettings(9, false, school(1), 4, entity.SetSettings{OpensAt: &opensAt, ClosesAt: &closesAt})
+	assert.Error(t, err)
+	assert.Equal(t, 400, err.(*app.AppError).Code)
+	mockRepo.AssertNotCalled(t, "EditSettings", mock.Anything, mock.Anything)
+}
@@ -15,6 +15,7 @@ func InitializedUserServiceFake(sb *sql.DB, val *validator.Validate) *userHandle
 		userHandler.NewUserHandler,
 		userSvc.NewUserService,
 		userRepo.NewUserRepository,
+		userRepo.NewTokenRepository,
 	)
 
 	return &userHandler.UserHandler{}
@@ -18,7 +18,8 @@ import (
 
 func InitializedUserService(sb *sql.DB, val *validator.Validate) *handler.UserHandler {
 	userRepository := repo.NewUserRepository(sb)
-	userService := svc.NewUserService(userRepository)
+	tokenRepository := repo.NewTokenRepository(sb)
+	userService := svc.NewUserService(userRepository, tokenRepository)
 	userHandler := handler.NewUserHandler(userService, val)
 	return userHandler
 }
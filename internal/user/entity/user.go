@@ -1,10 +1,34 @@
 package entity
 
+const (
+	RoleStudent     = "student"
+	RoleTeacher     = "teacher"
+	RoleSchoolAdmin = "school_admin"
+	RoleSuperAdmin  = "super_admin"
+)
+
+var roleRank = map[string]int{RoleStudent: 0, RoleTeacher: 1, RoleSchoolAdmin: 2, RoleSuperAdmin: 3}
+
+// CanGrant reports whether a user with role may give role granted to someone
+// or take it away. Super admins may grant every role, others only the roles
+// below their own.
+func CanGrant(role, granted string) bool {
+	return role == RoleSuperAdmin || roleRank[role] > roleRank[granted]
+}
+
 type User struct {
 	ID         int32  `json:"id"`
 	Name       string `json:"name"`
 	Email      string `json:"email"`
 	Password   string `json:"password"`
-	IsAdmin    bool   `json:"is_admin"`
+	Role       string `json:"role"`
 	IsVerified bool   `json:"is_verified"`
 }
+
+type RefreshToken struct {
+	ID        int32  `json:"id"`
+	UserID    int32  `json:"user_id"`
+	TokenHash string `json:"-"`
+	ExpiresAt int64  `json:"expires_at"`
+	RevokedAt *int64 `json:"revoked_at,omitempty"`
+}
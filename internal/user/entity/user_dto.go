@@ -5,10 +5,14 @@ type UserLogin struct {
 	Password string `json:"password" validate:"required,min=6,max=20"`
 }
 
+// Register is a new account. SchoolID is never taken from the request: sign
+// ups start outside any school and only see the shared content until a
+// school invites them or enrolls them in a classroom.
 type Register struct {
 	Name     string `json:"name" validate:"required,min=2,max=20"`
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=6,max=20"`
+	SchoolID *int32 `json:"-"`
 }
 
 type EditUser struct {
@@ -37,15 +41,32 @@ type UserJWT struct {
 	Name       string `json:"name"`
 	Email      string `json:"email"`
 	Password   string `json:"password"`
-	IsAdmin    bool   `json:"is_admin"`
+	Role       string `json:"role"`
+	SchoolID   *int32 `json:"school_id"`
 	IsVerified bool   `json:"is_verified"`
 }
 
+// LoginResponse keeps IsAdmin for older clients; it is set for every role
+// but student.
 type LoginResponse struct {
-	ID         int32  `json:"id"`
-	Email      string `json:"email"`
-	IsAdmin    bool   `json:"is_admin"`
-	IsVerified bool   `json:"is_verified"`
+	ID         int32      `json:"id"`
+	Email      string     `json:"email"`
+	Role       string     `json:"role"`
+	SchoolID   *int32     `json:"school_id"`
+	IsAdmin    bool       `json:"is_admin"`
+	IsVerified bool       `json:"is_verified"`
+	Token      *TokenPair `json:"token,omitempty"`
+}
+
+type TokenPair struct {
+	AccessToken      string `json:"access_token"`
+	ExpiresAt        int64  `json:"expires_at"`
+	RefreshToken     string `json:"refresh_token"`
+	RefreshExpiresAt int64  `json:"refresh_expires_at"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
 }
 
 type UserAuth struct {
@@ -53,10 +74,16 @@ type UserAuth struct {
 	Name       string `json:"name"`
 	Email      string `json:"email"`
 	ImgUrl     string `json:"img_url"`
+	Role       string `json:"role"`
+	SchoolID   *int32 `json:"school_id"`
 	IsAdmin    bool   `json:"is_admin"`
 	IsVerified bool   `json:"is_verified"`
 }
 
+type EditRole struct {
+	Role string `json:"role" validate:"required,oneof=student teacher school_admin super_admin"`
+}
+
 // ADMIN
 
 type AdminActivation struct {
@@ -65,7 +92,12 @@ type AdminActivation struct {
 	OTP   int32  `json:"otp"`
 }
 
+// RegisterAdmin invites a teacher into the school of the inviting admin;
+// super admins pick the school with SchoolID.
 type RegisterAdmin struct {
-	Name  string `json:"name" validate:"required,min=2,max=20"`
-	Email string `json:"email" validate:"required,email"`
+	Name     string `json:"name" validate:"required,min=2,max=20"`
+	Email    string `json:"email" validate:"required,email"`
+	SchoolID *int32 `json:"school_id,omitempty" validate:"omitempty,min=1"`
+	// Lang selects the invitation mail language, "id" (default) or "en".
+	Lang string `json:"lang,omitempty" validate:"omitempty,oneof=id en"`
 }
@@ -1 +1,31 @@
 package entity_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ghulammuzz/misterblast/internal/user/entity"
+)
+
+func TestCanGrant(t *testing.T) {
+	tests := []struct {
+		role    string
+		granted string
+		allowed bool
+	}{
+		{entity.RoleSuperAdmin, entity.RoleSuperAdmin, true},
+		{entity.RoleSchoolAdmin, entity.RoleTeacher, true},
+		{entity.RoleSchoolAdmin, entity.RoleSchoolAdmin, false},
+		{entity.RoleSchoolAdmin, entity.RoleSuperAdmin, false},
+		{entity.RoleTeacher, entity.RoleStudent, true},
+		{entity.RoleTeacher, entity.RoleTeacher, false},
+		{entity.RoleStudent, entity.RoleStudent, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.role+"/"+tc.granted, func(t *testing.T) {
+			assert.Equal(t, tc.allowed, entity.CanGrant(tc.role, tc.granted))
+		})
+	}
+}
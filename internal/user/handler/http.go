@@ -24,14 +24,25 @@ func NewUserHandler(userService svc.UserService, val *validator.Validate) *UserH
 }
 
 func (h *UserHandler) Router(r fiber.Router) {
-	r.Post("/register", h.RegisterHandler)
-	r.Post("/admin-check", h.RegisterAdminHandler)
-	r.Post("/login", h.LoginHandler)
-	r.Get("/users", h.ListUsersHandler)
-	r.Get("/users/:id", h.DetailUserHandler)
-	r.Delete("/users/:id", h.DeleteUserHandler)
-	r.Put("/users/:id", h.EditUserHandler)
-	r.Get("/me", middleware.JWTProtected(), h.MeUserHandler)
+	auth := middleware.JWTProtected()
+	admin := middleware.RequireRole(middleware.AdminRoles...)
+	self := middleware.SelfOrAdmin("id")
+	registerLimit := middleware.RateLimit(middleware.RateLimitConfig{Name: "register", Limit: 5, Window: time.Hour})
+	loginLimit := middleware.RateLimit(middleware.RateLimitConfig{Name: "login", Limit: 10, Window: time.Minute})
+	refreshLimit := middleware.RateLimit(middleware.RateLimitConfig{Name: "token-refresh", Limit: 30, Window: time.Minute})
+	logoutLimit := middleware.RateLimit(middleware.RateLimitConfig{Name: "logout", Limit: 30, Window: time.Minute})
+
+	r.Post("/register", registerLimit, h.RegisterHandler)
+	r.Post("/admin-check", auth, admin, h.RegisterAdminHandler)
+	r.Post("/login", loginLimit, h.LoginHandler)
+	r.Post("/logout", logoutLimit, h.LogoutHandler)
+	r.Post("/token/refresh", refreshLimit, h.RefreshTokenHandler)
+	r.Get("/users", auth, admin, h.ListUsersHandler)
+	r.Get("/users/:id", auth, self, h.DetailUserHandler)
+	r.Delete("/users/:id", auth, admin, h.DeleteUserHandler)
+	r.Put("/users/:id", auth, self, h.EditUserHandler)
+	r.Put("/users/:id/role", auth, admin, h.EditRoleHandler)
+	r.Get("/me", auth, h.MeUserHandler)
 }
 
 func (h *UserHandler) RegisterHandler(c *fiber.Ctx) error {
@@ -71,7 +82,7 @@ func (h *UserHandler) RegisterAdminHandler(c *fiber.Ctx) error {
 		return response.SendError(c, fiber.StatusBadRequest, "Validation failed", validationErrors)
 	}
 
-	if err := h.userService.RegisterAdmin(admin); err != nil {
+	if err := h.userService.RegisterAdmin(middleware.SchoolID(c), admin); err != nil {
 		appErr, ok := err.(*app.AppError)
 		if !ok {
 			appErr = app.ErrInternal
@@ -95,7 +106,7 @@ func (h *UserHandler) LoginHandler(c *fiber.Ctx) error {
 		return response.SendError(c, fiber.StatusBadRequest, "Validation failed", validationErrors)
 	}
 
-	userData, token, err := h.userService.Login(user)
+	userData, err := h.userService.Login(user)
 	if err != nil {
 		appErr, ok := err.(*app.AppError)
 		if !ok {
@@ -104,16 +115,91 @@ func (h *UserHandler) LoginHandler(c *fiber.Ctx) error {
 		return response.SendError(c, appErr.Code, appErr.Message, nil)
 	}
 
+	setTokenCookies(c, userData.Token)
+
+	return response.SendSuccess(c, "Login successful", userData)
+}
+
+func (h *UserHandler) RefreshTokenHandler(c *fiber.Ctx) error {
+	var req entity.RefreshTokenRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return response.SendError(c, fiber.StatusBadRequest, "Invalid request body", nil)
+		}
+	}
+	if req.RefreshToken == "" {
+		req.RefreshToken = c.Cookies(middleware.RefreshTokenCookie)
+	}
+
+	token, err := h.userService.RefreshToken(req.RefreshToken)
+	if err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	setTokenCookies(c, token)
+
+	return response.SendSuccess(c, "Token refreshed successfully", token)
+}
+
+// LogoutHandler ends the session of the access token, the refresh token or
+// both. Either is enough, so a client whose access token expired can still
+// log out with the refresh token.
+func (h *UserHandler) LogoutHandler(c *fiber.Ctx) error {
+	var jti string
+	var exp float64
+	if claims, ok := middleware.SessionClaims(c); ok {
+		jti, _ = claims["jti"].(string)
+		exp, _ = claims["exp"].(float64)
+	}
+
+	var req entity.RefreshTokenRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return response.SendError(c, fiber.StatusBadRequest, "Invalid request body", nil)
+		}
+	}
+	if req.RefreshToken == "" {
+		req.RefreshToken = c.Cookies(middleware.RefreshTokenCookie)
+	}
+
+	if jti == "" && req.RefreshToken == "" {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	if err := h.userService.Logout(jti, int64(exp), req.RefreshToken); err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	c.ClearCookie(middleware.AccessTokenCookie, middleware.RefreshTokenCookie)
+
+	return response.SendSuccess(c, "Logout successful", nil)
+}
+
+func setTokenCookies(c *fiber.Ctx, token *entity.TokenPair) {
 	c.Cookie(&fiber.Cookie{
-		Name:     "token",
-		Value:    token,
-		Expires:  time.Now().Add(7 * 24 * 60 * 60 * 1000),
+		Name:     middleware.AccessTokenCookie,
+		Value:    token.AccessToken,
+		Expires:  time.Unix(token.ExpiresAt, 0),
+		HTTPOnly: true,
+		Secure:   true,
+		SameSite: "Strict",
+	})
+	c.Cookie(&fiber.Cookie{
+		Name:     middleware.RefreshTokenCookie,
+		Value:    token.RefreshToken,
+		Expires:  time.Unix(token.RefreshExpiresAt, 0),
 		HTTPOnly: true,
 		Secure:   true,
 		SameSite: "Strict",
 	})
-
-	return response.SendSuccess(c, "Login successful", userData)
 }
 
 func (h *UserHandler) ListUsersHandler(c *fiber.Ctx) error {
@@ -126,7 +212,7 @@ func (h *UserHandler) ListUsersHandler(c *fiber.Ctx) error {
 	page := c.QueryInt("page", 1)
 	limit := c.QueryInt("limit", 10)
 
-	users, err := h.userService.ListUser(filter, page, limit)
+	users, err := h.userService.ListUser(middleware.SchoolID(c), filter, page, limit)
 	if err != nil {
 		appErr, ok := err.(*app.AppError)
 		if !ok {
@@ -144,7 +230,7 @@ func (h *UserHandler) DetailUserHandler(c *fiber.Ctx) error {
 		return response.SendError(c, fiber.StatusBadRequest, "Invalid user ID", nil)
 	}
 
-	user, err := h.userService.DetailUser(int32(id))
+	user, err := h.userService.DetailUser(userScope(c, int32(id)), int32(id))
 	if err != nil {
 		appErr, ok := err.(*app.AppError)
 		if !ok {
@@ -156,7 +242,21 @@ func (h *UserHandler) DetailUserHandler(c *fiber.Ctx) error {
 	return response.SendSuccess(c, "User retrieved successfully", user)
 }
 
+// userScope is the school a request on user id is confined to. Users always
+// reach their own account, including those not placed in a school yet.
+func userScope(c *fiber.Ctx, id int32) *int32 {
+	if callerID, ok := middleware.UserID(c); ok && callerID == id {
+		return nil
+	}
+	return middleware.SchoolID(c)
+}
+
 func (h *UserHandler) EditUserHandler(c *fiber.Ctx) error {
+	callerID, ok := middleware.UserID(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
 	id, err := c.ParamsInt("id")
 	if err != nil {
 		return response.SendError(c, fiber.StatusBadRequest, "Invalid user ID", nil)
@@ -173,7 +273,7 @@ func (h *UserHandler) EditUserHandler(c *fiber.Ctx) error {
 		return response.SendError(c, fiber.StatusBadRequest, "Validation failed", validationErrors)
 	}
 
-	if err := h.userService.EditUser(int32(id), user); err != nil {
+	if err := h.userService.EditUser(callerID, middleware.Role(c), userScope(c, int32(id)), int32(id), user); err != nil {
 		appErr, ok := err.(*app.AppError)
 		if !ok {
 			appErr = app.ErrInternal
@@ -184,6 +284,38 @@ func (h *UserHandler) EditUserHandler(c *fiber.Ctx) error {
 	return response.SendSuccess(c, "User updated successfully", nil)
 }
 
+func (h *UserHandler) EditRoleHandler(c *fiber.Ctx) error {
+	callerID, ok := middleware.UserID(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return response.SendError(c, fiber.StatusBadRequest, "Invalid user ID", nil)
+	}
+
+	var role entity.EditRole
+	if err := c.BodyParser(&role); err != nil {
+		return response.SendError(c, fiber.StatusBadRequest, "Invalid request body", nil)
+	}
+
+	if err := h.val.Struct(role); err != nil {
+		validationErrors := app.ValidationErrorResponse(err)
+		return response.SendError(c, fiber.StatusBadRequest, "Validation failed", validationErrors)
+	}
+
+	if err := h.userService.EditRole(callerID, middleware.Role(c), middleware.SchoolID(c), int32(id), role); err != nil {
+		appErr, ok := err.(*app.AppError)
+		if !ok {
+			appErr = app.ErrInternal
+		}
+		return response.SendError(c, appErr.Code, appErr.Message, nil)
+	}
+
+	return response.SendSuccess(c, "User role updated successfully", nil)
+}
+
 func (h *UserHandler) MeUserHandler(c *fiber.Ctx) error {
 
 	userToken := c.Locals("user").(*jwt.Token)
@@ -209,12 +341,17 @@ func (h *UserHandler) MeUserHandler(c *fiber.Ctx) error {
 }
 
 func (h *UserHandler) DeleteUserHandler(c *fiber.Ctx) error {
+	callerID, ok := middleware.UserID(c)
+	if !ok {
+		return response.SendError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+	}
+
 	id, err := c.ParamsInt("id")
 	if err != nil {
 		return response.SendError(c, fiber.StatusBadRequest, "invalid id", nil)
 	}
 
-	if err := h.userService.DeleteUser(int32(id)); err != nil {
+	if err := h.userService.DeleteUser(callerID, middleware.Role(c), middleware.SchoolID(c), int32(id)); err != nil {
 		appErr, ok := err.(*app.AppError)
 		if !ok {
 			appErr = app.ErrInternal
@@ -20,6 +20,7 @@ import (
 
 	"github.com/ghulammuzz/misterblast/internal/user/entity"
 	"github.com/ghulammuzz/misterblast/internal/user/handler"
+	apperr "github.com/ghulammuzz/misterblast/pkg/app"
 	"github.com/ghulammuzz/misterblast/pkg/middleware"
 )
 
@@ -32,17 +33,30 @@ func (m *MockUserService) Register(user entity.Register) error {
 	return args.Error(0)
 }
 
-func (m *MockUserService) RegisterAdmin(admin entity.RegisterAdmin) error {
-	args := m.Called(admin)
+func (m *MockUserService) RegisterAdmin(schoolID *int32, admin entity.RegisterAdmin) error {
+	args := m.Called(schoolID, admin)
 	return args.Error(0)
 }
 
-func (m *MockUserService) Login(user entity.UserLogin) (*entity.LoginResponse, string, error) {
+func (m *MockUserService) Login(user entity.UserLogin) (*entity.LoginResponse, error) {
 	args := m.Called(user)
 	if args.Get(0) == nil {
-		return nil, "", args.Error(2)
+		return nil, args.Error(1)
 	}
-	return args.Get(0).(*entity.LoginResponse), args.String(1), args.Error(2)
+	return args.Get(0).(*entity.LoginResponse), args.Error(1)
+}
+
+func (m *MockUserService) RefreshToken(refreshToken string) (*entity.TokenPair, error) {
+	args := m.Called(refreshToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.TokenPair), args.Error(1)
+}
+
+func (m *MockUserService) Logout(jti string, expiresAt int64, refreshToken string) error {
+	args := m.Called(jti, expiresAt, refreshToken)
+	return args.Error(0)
 }
 
 func (m *MockUserService) AuthUser(userID int32) (entity.UserAuth, error) {
@@ -53,26 +67,31 @@ func (m *MockUserService) AuthUser(userID int32) (entity.UserAuth, error) {
 	return args.Get(0).(entity.UserAuth), args.Error(1)
 }
 
-func (m *MockUserService) DeleteUser(userID int32) error {
-	args := m.Called(userID)
+func (m *MockUserService) DeleteUser(callerID int32, callerRole string, schoolID *int32, userID int32) error {
+	args := m.Called(callerID, callerRole, schoolID, userID)
 	return args.Error(0)
 }
 
-func (m *MockUserService) DetailUser(userID int32) (entity.DetailUser, error) {
-	args := m.Called(userID)
+func (m *MockUserService) DetailUser(schoolID *int32, userID int32) (entity.DetailUser, error) {
+	args := m.Called(schoolID, userID)
 	if args.Get(0) == nil {
 		return entity.DetailUser{}, args.Error(1)
 	}
 	return args.Get(0).(entity.DetailUser), args.Error(1)
 }
 
-func (m *MockUserService) EditUser(userID int32, user entity.EditUser) error {
-	args := m.Called(userID, user)
+func (m *MockUserService) EditUser(callerID int32, callerRole string, schoolID *int32, userID int32, user entity.EditUser) error {
+	args := m.Called(callerID, callerRole, schoolID, userID, user)
+	return args.Error(0)
+}
+
+func (m *MockUserService) EditRole(callerID int32, callerRole string, schoolID *int32, id int32, role entity.EditRole) error {
+	args := m.Called(callerID, callerRole, schoolID, id, role)
 	return args.Error(0)
 }
 
-func (m *MockUserService) ListUser(filters map[string]string, limit int, offset int) ([]entity.ListUser, error) {
-	args := m.Called(filters, limit, offset)
+func (m *MockUserService) ListUser(schoolID *int32, filters map[string]string, limit int, offset int) ([]entity.ListUser, error) {
+	args := m.Called(schoolID, filters, limit, offset)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -97,6 +116,23 @@ func TestRegisterHandler(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestRegisterHandler_IgnoresSchool(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockUserService)
+	h := handler.NewUserHandler(mockService, validator.New())
+	app.Post("/register", h.RegisterHandler)
+
+	mockService.On("Register", entity.Register{Name: "John Doe", Email: "john@example.com", Password: "password"}).Return(nil)
+
+	body := `{"name": "John Doe", "email": "john@example.com", "password": "password", "school_id": 1}`
+	req := httptest.NewRequest(http.MethodPost, "/register", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
 func TestRegisterAdminHandler(t *testing.T) {
 	app := fiber.New()
 	mockService := new(MockUserService)
@@ -104,7 +140,7 @@ func TestRegisterAdminHandler(t *testing.T) {
 	app.Post("/admin-check", h.RegisterAdminHandler)
 
 	user := entity.RegisterAdmin{Name: "John Doe", Email: "john@example.com"}
-	mockService.On("RegisterAdmin", user).Return(nil)
+	mockService.On("RegisterAdmin", school(0), user).Return(nil)
 
 	body, _ := json.Marshal(user)
 	req := httptest.NewRequest(http.MethodPost, "/admin-check", bytes.NewBuffer(body))
@@ -122,28 +158,111 @@ func TestLoginHandler(t *testing.T) {
 	app.Post("/login", h.LoginHandler)
 
 	user := entity.UserLogin{Email: "john@example.com", Password: "password"}
-	userJWT := &entity.LoginResponse{ID: 1, Email: "john@example.com", IsAdmin: false, IsVerified: true}
-	token := "valid_token"
-	mockService.On("Login", user).Return(userJWT, token, nil)
+	token := &entity.TokenPair{AccessToken: "valid_token", ExpiresAt: time.Now().Add(time.Minute).Unix(),
+		RefreshToken: "refresh_token", RefreshExpiresAt: time.Now().Add(time.Hour).Unix()}
+	userJWT := &entity.LoginResponse{ID: 1, Email: "john@example.com", IsAdmin: false, IsVerified: true, Token: token}
+	mockService.On("Login", user).Return(userJWT, nil)
 
 	body, _ := json.Marshal(user)
 	req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewBuffer(body))
 	req.Header.Set("Content-Type", "application/json")
 	resp, _ := app.Test(req)
 
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	cookies := map[string]string{}
+	for _, cookie := range resp.Cookies() {
+		cookies[cookie.Name] = cookie.Value
+	}
+	assert.Equal(t, "valid_token", cookies[middleware.AccessTokenCookie])
+	assert.Equal(t, "refresh_token", cookies[middleware.RefreshTokenCookie])
+	mockService.AssertExpectations(t)
+}
+
+func TestRefreshTokenHandler(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockUserService)
+	h := handler.NewUserHandler(mockService, validator.New())
+	app.Post("/token/refresh", h.RefreshTokenHandler)
+
+	mockService.On("RefreshToken", "old_refresh").Return(&entity.TokenPair{AccessToken: "new_access", RefreshToken: "new_refresh"}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/token/refresh", nil)
+	req.AddCookie(&http.Cookie{Name: middleware.RefreshTokenCookie, Value: "old_refresh"})
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
+func TestRefreshTokenHandler_Invalid(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockUserService)
+	h := handler.NewUserHandler(mockService, validator.New())
+	app.Post("/token/refresh", h.RefreshTokenHandler)
+
+	mockService.On("RefreshToken", "stolen").Return(nil, apperr.NewAppError(401, "invalid refresh token"))
+
+	req := httptest.NewRequest(http.MethodPost, "/token/refresh", bytes.NewBufferString(`{"refresh_token":"stolen"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestLogoutHandler(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockUserService)
+	h := handler.NewUserHandler(mockService, validator.New())
+	app.Post("/logout", h.LogoutHandler)
+
+	exp := time.Now().Add(time.Minute).Unix()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": 1, "jti": "jti-1", "exp": exp})
+	signedToken, _ := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+
+	mockService.On("Logout", "jti-1", exp, "refresh").Return(nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/logout", nil)
+	req.AddCookie(&http.Cookie{Name: middleware.AccessTokenCookie, Value: signedToken})
+	req.AddCookie(&http.Cookie{Name: middleware.RefreshTokenCookie, Value: "refresh"})
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
+func TestLogoutHandler_ExpiredAccessToken(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockUserService)
+	h := handler.NewUserHandler(mockService, validator.New())
+	app.Post("/logout", h.LogoutHandler)
+
+	exp := time.Now().Add(-time.Hour).Unix()
+	expired := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": 1, "jti": "jti-1", "exp": exp})
+	signedExpired, _ := expired.SignedString([]byte(os.Getenv("JWT_SECRET")))
+
+	mockService.On("Logout", "jti-1", exp, "refresh").Return(nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/logout", bytes.NewBufferString(`{"refresh_token":"refresh"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+signedExpired)
+	resp, _ := app.Test(req)
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 	mockService.AssertExpectations(t)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodPost, "/logout", nil))
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
 }
 
 func TestDeleteUserHandler(t *testing.T) {
 	app := fiber.New()
 	mockService := new(MockUserService)
 	h := handler.NewUserHandler(mockService, validator.New())
-	app.Delete("/users/:id", h.DeleteUserHandler)
+	app.Delete("/users/:id", middleware.JWTProtected(), h.DeleteUserHandler)
 
-	mockService.On("DeleteUser", int32(1)).Return(nil)
+	mockService.On("DeleteUser", int32(9), middleware.RoleSchoolAdmin, school(1), int32(1)).Return(nil)
 
 	req := httptest.NewRequest(http.MethodDelete, "/users/1", nil)
+	req.Header.Set("Authorization", "Bearer "+signToken(9, middleware.RoleSchoolAdmin))
 	resp, _ := app.Test(req)
 
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
@@ -157,7 +276,7 @@ func TestDetailUserHandler(t *testing.T) {
 	app.Get("/users/:id", h.DetailUserHandler)
 
 	user := entity.DetailUser{ID: 1, Name: "John Doe", Email: "john@example.com"}
-	mockService.On("DetailUser", int32(1)).Return(user, nil)
+	mockService.On("DetailUser", school(0), int32(1)).Return(user, nil)
 
 	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
 	resp, _ := app.Test(req)
@@ -170,14 +289,15 @@ func TestEditUserHandler(t *testing.T) {
 	app := fiber.New()
 	mockService := new(MockUserService)
 	h := handler.NewUserHandler(mockService, validator.New())
-	app.Put("/users/:id", h.EditUserHandler)
+	app.Put("/users/:id", middleware.JWTProtected(), h.EditUserHandler)
 
 	userEdit := entity.EditUser{Name: "John Updated", Email: "john@edit.com"}
-	mockService.On("EditUser", int32(1), userEdit).Return(nil)
+	mockService.On("EditUser", int32(1), middleware.RoleStudent, (*int32)(nil), int32(1), userEdit).Return(nil)
 
 	body, _ := json.Marshal(userEdit)
 	req := httptest.NewRequest(http.MethodPut, "/users/1", bytes.NewBuffer(body))
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+signToken(1, middleware.RoleStudent))
 	resp, _ := app.Test(req)
 
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
@@ -191,7 +311,7 @@ func TestListUserHandler(t *testing.T) {
 	app.Get("/users", h.ListUsersHandler)
 
 	mockUsers := []entity.ListUser{{ID: 1, Name: "John Doe", Email: "john@example.com", ImgUrl: ""}}
-	mockService.On("ListUser", mock.Anything, mock.Anything, mock.Anything).Return(mockUsers, nil)
+	mockService.On("ListUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(mockUsers, nil)
 
 	req := httptest.NewRequest(http.MethodGet, "/users", nil)
 	req.Header.Set("Content-Type", "application/json")
@@ -228,11 +348,12 @@ func TestMeUserHandler(t *testing.T) {
 
 	t.Run("Success - Valid Token", func(t *testing.T) {
 		claims := jwt.MapClaims{
-			"apps":     "misterblast-core",
-			"email":    "john@example.com",
-			"user_id":  1,
-			"is_admin": false,
-			"exp":      time.Now().Add(time.Hour * 24 * 7).Unix(),
+			"apps":    "misterblast-core",
+			"email":   "john@example.com",
+			"user_id": 1,
+			"role":    "student",
+			"jti":     "jti-test",
+			"exp":     time.Now().Add(time.Hour * 24 * 7).Unix(),
 		}
 		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 		signedToken, _ := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
@@ -273,7 +394,7 @@ func TestMeUserHandler(t *testing.T) {
 	})
 
 	t.Run("Fail - User Not Found", func(t *testing.T) {
-		claims := jwt.MapClaims{"user_id": float64(2)}
+		claims := jwt.MapClaims{"user_id": float64(2), "jti": "jti-test"}
 		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 		signedToken, _ := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
 
@@ -286,3 +407,164 @@ func TestMeUserHandler(t *testing.T) {
 		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
 	})
 }
+
+func school(id int32) *int32 {
+	return &id
+}
+
+func signToken(userID int, role string) string {
+	claims := jwt.MapClaims{
+		"apps":      "misterblast-core",
+		"email":     "john@example.com",
+		"user_id":   userID,
+		"role":      role,
+		"school_id": 1,
+		"jti":       "jti-test",
+		"exp":       time.Now().Add(time.Hour).Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signedToken, _ := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+	return signedToken
+}
+
+func TestUserRouterPolicies(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockUserService)
+	h := handler.NewUserHandler(mockService, validator.New())
+	h.Router(app)
+
+	mockService.On("ListUser", school(1), mock.Anything, mock.Anything, mock.Anything).Return([]entity.ListUser{}, nil)
+	mockService.On("DetailUser", school(1), int32(1)).Return(entity.DetailUser{ID: 1}, nil)
+	mockService.On("DetailUser", (*int32)(nil), int32(1)).Return(entity.DetailUser{ID: 1}, nil)
+	mockService.On("DeleteUser", int32(9), middleware.RoleSchoolAdmin, school(1), int32(1)).Return(nil)
+	mockService.On("EditRole", int32(9), middleware.RoleSchoolAdmin, school(1), int32(1), entity.EditRole{Role: "teacher"}).Return(nil)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		status int
+	}{
+		{"list users anonymous", http.MethodGet, "/users", "", http.StatusUnauthorized},
+		{"list users as student", http.MethodGet, "/users", signToken(1, middleware.RoleStudent), http.StatusForbidden},
+		{"list users as admin", http.MethodGet, "/users", signToken(9, middleware.RoleSchoolAdmin), http.StatusOK},
+		{"detail own user", http.MethodGet, "/users/1", signToken(1, middleware.RoleStudent), http.StatusOK},
+		{"detail other user", http.MethodGet, "/users/1", signToken(2, middleware.RoleStudent), http.StatusForbidden},
+		{"detail other user as admin", http.MethodGet, "/users/1", signToken(9, middleware.RoleSchoolAdmin), http.StatusOK},
+		{"delete user as student", http.MethodDelete, "/users/1", signToken(1, middleware.RoleStudent), http.StatusForbidden},
+		{"delete user as admin", http.MethodDelete, "/users/1", signToken(9, middleware.RoleSchoolAdmin), http.StatusOK},
+		{"list users as teacher", http.MethodGet, "/users", signToken(3, middleware.RoleTeacher), http.StatusForbidden},
+		{"change role as teacher", http.MethodPut, "/users/1/role", signToken(3, middleware.RoleTeacher), http.StatusForbidden},
+		{"change role as admin", http.MethodPut, "/users/1/role", signToken(9, middleware.RoleSchoolAdmin), http.StatusOK},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, bytes.NewReader([]byte(`{"role":"teacher"}`)))
+			req.Header.Set("Content-Type", "application/json")
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tc.status, resp.StatusCode)
+		})
+	}
+}
+
+func TestUserHandler_SelfWithoutSchool(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockUserService)
+	handler.NewUserHandler(mockService, validator.New()).Router(app)
+
+	claims := jwt.MapClaims{"user_id": 4, "role": middleware.RoleStudent, "jti": "jti-test", "exp": time.Now().Add(time.Hour).Unix()}
+	token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(os.Getenv("JWT_SECRET")))
+
+	edit := entity.EditUser{Name: "Ani", Email: "ani@example.com"}
+	mockService.On("DetailUser", (*int32)(nil), int32(4)).Return(entity.DetailUser{ID: 4}, nil)
+	mockService.On("EditUser", int32(4), middleware.RoleStudent, (*int32)(nil), int32(4), edit).Return(nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/users/4", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, _ := json.Marshal(edit)
+	req = httptest.NewRequest(http.MethodPut, "/users/4", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, _ = app.Test(req)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
+func TestListUsersHandler_SchoolScope(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockUserService)
+	h := handler.NewUserHandler(mockService, validator.New())
+	h.Router(app)
+
+	mockService.On("ListUser", school(1), mock.Anything, 1, 10).Return([]entity.ListUser{}, nil).Once()
+	mockService.On("ListUser", (*int32)(nil), mock.Anything, 1, 10).Return([]entity.ListUser{}, nil).Once()
+
+	for _, role := range []string{middleware.RoleSchoolAdmin, middleware.RoleSuperAdmin} {
+		req := httptest.NewRequest(http.MethodGet, "/users", nil)
+		req.Header.Set("Authorization", "Bearer "+signToken(9, role))
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+	mockService.AssertExpectations(t)
+}
+
+type revokedList map[string]bool
+
+func (r revokedList) IsRevoked(jti string) (bool, error) {
+	return r[jti], nil
+}
+
+func TestJWTProtected_RevokedToken(t *testing.T) {
+	middleware.SetRevocationList(revokedList{"revoked-jti": true})
+	defer middleware.SetRevocationList(nil)
+
+	app := fiber.New()
+	mockService := new(MockUserService)
+	h := handler.NewUserHandler(mockService, validator.New())
+	app.Get("/me", middleware.JWTProtected(), h.MeUserHandler)
+
+	mockService.On("AuthUser", int32(1)).Return(entity.UserAuth{ID: 1}, nil)
+
+	for jti, status := range map[string]int{"revoked-jti": http.StatusUnauthorized, "live-jti": http.StatusOK} {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"user_id": 1, "jti": jti, "exp": time.Now().Add(time.Minute).Unix(),
+		})
+		signedToken, _ := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+signedToken)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, status, resp.StatusCode, jti)
+	}
+}
+
+func TestJWTProtected_LegacyTokenWithoutJTI(t *testing.T) {
+	middleware.SetRevocationList(revokedList{})
+	defer middleware.SetRevocationList(nil)
+
+	app := fiber.New()
+	h := handler.NewUserHandler(new(MockUserService), validator.New())
+	app.Get("/me", middleware.JWTProtected(), h.MeUserHandler)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": 1, "is_admin": true, "exp": time.Now().Add(time.Minute).Unix(),
+	})
+	signedToken, _ := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+signedToken)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
@@ -2,25 +2,33 @@ package repo
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 
+	"github.com/lib/pq"
+
+	emailRepo "github.com/ghulammuzz/misterblast/internal/email/repo"
 	userEntity "github.com/ghulammuzz/misterblast/internal/user/entity"
 	"github.com/ghulammuzz/misterblast/pkg/app"
 	"github.com/ghulammuzz/misterblast/pkg/log"
+	"github.com/ghulammuzz/misterblast/pkg/mailer"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type UserRepository interface {
-	Add(user userEntity.Register, IsVerified bool) error
+	Add(user userEntity.Register, role string, IsVerified bool) error
+	Invite(user userEntity.Register, role string, invitation mailer.Message) error
 	Check(user userEntity.UserLogin) (*userEntity.UserJWT, error)
 	Exists(id int32) (bool, error)
-	List(filter map[string]string, page, limit int) ([]userEntity.ListUser, error)
-	Detail(id int32) (userEntity.DetailUser, error)
-	Edit(id int32, user userEntity.EditUser) error
-	Delete(id int32) error
+	List(schoolID *int32, filter map[string]string, page, limit int) ([]userEntity.ListUser, error)
+	Detail(schoolID *int32, id int32) (userEntity.DetailUser, error)
+	Edit(schoolID *int32, id int32, user userEntity.EditUser) error
+	Delete(schoolID *int32, id int32) error
 	Auth(id int32) (userEntity.UserAuth, error)
 	AdminActivation(adminID int32) error
 	GetIDByEmail(email string) (int32, error)
+	UpdatePassword(id int32, password string) error
+	EditRole(id int32, role string) error
 }
 
 type userRepository struct {
@@ -31,6 +39,16 @@ func NewUserRepository(db *sql.DB) UserRepository {
 	return &userRepository{DB: db}
 }
 
+// inSchool narrows a users query to the users of schoolID; nil, for super
+// admins, leaves it unscoped.
+func inSchool(query string, args []interface{}, schoolID *int32) (string, []interface{}) {
+	if schoolID == nil {
+		return query, args
+	}
+	args = append(args, *schoolID)
+	return query + fmt.Sprintf(" AND school_id = $%d", len(args)), args
+}
+
 func (r *userRepository) Exists(id int32) (bool, error) {
 	var exists bool
 	query := `SELECT EXISTS(SELECT 1 FROM users WHERE id=$1)`
@@ -41,22 +59,58 @@ func (r *userRepository) Exists(id int32) (bool, error) {
 	return exists, nil
 }
 
-func (r *userRepository) Add(user userEntity.Register, IsVerified bool) error {
+func (r *userRepository) Add(user userEntity.Register, role string, IsVerified bool) error {
+	return insertUser(r.DB, user, role, IsVerified)
+}
 
-	query := `INSERT INTO users (name, email, password, img_url, is_verified) VALUES ($1, $2, $3, $4, $5)`
+// Invite adds an unverified user and queues the invitation in the email
+// outbox within the same transaction.
+func (r *userRepository) Invite(user userEntity.Register, role string, invitation mailer.Message) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		log.Error("[Repo][Invite] Error Begin: ", err)
+		return app.NewAppError(500, "failed to invite user")
+	}
+	defer tx.Rollback()
+
+	if err := insertUser(tx, user, role, false); err != nil {
+		return err
+	}
+
+	if err := emailRepo.Enqueue(tx, invitation, false); err != nil {
+		log.Error("[Repo][Invite] Error Enqueue: ", err)
+		return app.NewAppError(500, "failed to queue invitation")
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error("[Repo][Invite] Error Commit: ", err)
+		return app.NewAppError(500, "failed to invite user")
+	}
+	return nil
+}
+
+func insertUser(db interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}, user userEntity.Register, role string, IsVerified bool) error {
+	query := `INSERT INTO users (name, email, password, img_url, role, is_verified, school_id) VALUES ($1, $2, $3, $4, $5, $6, $7)`
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
 	if err != nil {
 		return err
 	}
-	_, err = r.DB.Exec(query, user.Name, user.Email, hashedPassword, nil, IsVerified)
+	_, err = db.Exec(query, user.Name, user.Email, hashedPassword, nil, role, IsVerified, user.SchoolID)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+		return app.NewAppError(404, "school not found")
+	}
 	return err
 }
 
 func (r *userRepository) Check(user userEntity.UserLogin) (*userEntity.UserJWT, error) {
 	userResult := userEntity.UserJWT{}
-	query := "SELECT id, email, password, is_admin, is_verified FROM users WHERE email=$1"
-	err := r.DB.QueryRow(query, user.Email).Scan(&userResult.ID, &userResult.Email, &userResult.Password, &userResult.IsAdmin, &userResult.IsVerified)
+	var schoolID sql.NullInt32
+	query := "SELECT id, email, password, role, school_id, is_verified FROM users WHERE email=$1"
+	err := r.DB.QueryRow(query, user.Email).Scan(&userResult.ID, &userResult.Email, &userResult.Password, &userResult.Role, &schoolID, &userResult.IsVerified)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, app.NewAppError(404, "user not found")
@@ -67,11 +121,14 @@ func (r *userRepository) Check(user userEntity.UserLogin) (*userEntity.UserJWT,
 	if err := bcrypt.CompareHashAndPassword([]byte(userResult.Password), []byte(user.Password)); err != nil {
 		return nil, app.NewAppError(400, "wrong password")
 	}
+	if schoolID.Valid {
+		userResult.SchoolID = &schoolID.Int32
+	}
 
 	return &userResult, nil
 }
 
-func (r *userRepository) List(filter map[string]string, page, limit int) ([]userEntity.ListUser, error) {
+func (r *userRepository) List(schoolID *int32, filter map[string]string, page, limit int) ([]userEntity.ListUser, error) {
 	query := `SELECT id, name, email, COALESCE(img_url, '') FROM users WHERE 1=1`
 	args := []interface{}{limit, (page - 1) * limit}
 	argCount := 2
@@ -82,6 +139,7 @@ func (r *userRepository) List(filter map[string]string, page, limit int) ([]user
 		argCount += 2
 	}
 
+	query, args = inSchool(query, args, schoolID)
 	query += ` LIMIT $1 OFFSET $2`
 
 	rows, err := r.DB.Query(query, args...)
@@ -101,10 +159,10 @@ func (r *userRepository) List(filter map[string]string, page, limit int) ([]user
 	return users, nil
 }
 
-func (r *userRepository) Detail(id int32) (userEntity.DetailUser, error) {
-	query := `SELECT id, name, email, COALESCE(img_url, '') FROM users WHERE id=$1`
+func (r *userRepository) Detail(schoolID *int32, id int32) (userEntity.DetailUser, error) {
+	query, args := inSchool(`SELECT id, name, email, COALESCE(img_url, '') FROM users WHERE id=$1`, []interface{}{id}, schoolID)
 	var user userEntity.DetailUser
-	err := r.DB.QueryRow(query, id).Scan(&user.ID, &user.Name, &user.Email, &user.ImgUrl)
+	err := r.DB.QueryRow(query, args...).Scan(&user.ID, &user.Name, &user.Email, &user.ImgUrl)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return user, app.NewAppError(404, "question not found")
@@ -114,20 +172,51 @@ func (r *userRepository) Detail(id int32) (userEntity.DetailUser, error) {
 	return user, nil
 }
 
-func (r *userRepository) Edit(id int32, user userEntity.EditUser) error {
-	query := `UPDATE users SET name=$1, email=$2, password=$3, img_url=$4, updated_at=EXTRACT(EPOCH FROM NOW()) WHERE id=$5`
-
+func (r *userRepository) Edit(schoolID *int32, id int32, user userEntity.EditUser) error {
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
 	if err != nil {
 		return err
 	}
-	_, err = r.DB.Exec(query, user.Name, user.Email, hashedPassword, user.ImgUrl, id)
-	return err
+
+	query, args := inSchool(`UPDATE users SET name=$1, email=$2, password=$3, img_url=$4, updated_at=EXTRACT(EPOCH FROM NOW()) WHERE id=$5`,
+		[]interface{}{user.Name, user.Email, hashedPassword, user.ImgUrl, id}, schoolID)
+	result, err := r.DB.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return app.ErrNotFound
+	}
+	return nil
+}
+
+func (r *userRepository) UpdatePassword(id int32, password string) error {
+	query := `UPDATE users SET password=$1, updated_at=EXTRACT(EPOCH FROM NOW()) WHERE id=$2`
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		log.Error("[Repo][UpdatePassword] Error GenerateFromPassword: ", err)
+		return app.NewAppError(500, "failed to hash password")
+	}
+
+	result, err := r.DB.Exec(query, hashedPassword, id)
+	if err != nil {
+		log.Error("[Repo][UpdatePassword] Error Exec: ", err)
+		return app.NewAppError(500, "failed to update password")
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return app.NewAppError(404, "user not found")
+	}
+
+	return nil
 }
 
-func (r *userRepository) Delete(id int32) error {
-	query := `DELETE FROM users WHERE id = $1`
-	result, err := r.DB.Exec(query, id)
+func (r *userRepository) Delete(schoolID *int32, id int32) error {
+	query, args := inSchool(`DELETE FROM users WHERE id = $1`, []interface{}{id}, schoolID)
+	result, err := r.DB.Exec(query, args...)
 	if err != nil {
 		log.Error("[Repo][DeleteUser] Error Exec: ", err)
 		return app.NewAppError(500, "failed to delete user")
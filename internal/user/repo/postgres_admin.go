@@ -19,14 +19,33 @@ func (r *userRepository) AdminActivation(adminID int32) error {
 }
 
 func (r *userRepository) Auth(id int32) (userEntity.UserAuth, error) {
-	query := `SELECT id, name, email, COALESCE(img_url, ''), is_admin, is_verified  FROM users WHERE id=$1`
+	query := `SELECT id, name, email, COALESCE(img_url, ''), role, school_id, is_verified  FROM users WHERE id=$1`
 	var user userEntity.UserAuth
-	err := r.DB.QueryRow(query, id).Scan(&user.ID, &user.Name, &user.Email, &user.ImgUrl, &user.IsAdmin, &user.IsVerified)
+	var schoolID sql.NullInt32
+	err := r.DB.QueryRow(query, id).Scan(&user.ID, &user.Name, &user.Email, &user.ImgUrl, &user.Role, &schoolID, &user.IsVerified)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return user, app.NewAppError(404, "user not found")
 		}
 		return userEntity.UserAuth{}, app.NewAppError(500, err.Error())
 	}
+	user.IsAdmin = user.Role != userEntity.RoleStudent
+	if schoolID.Valid {
+		user.SchoolID = &schoolID.Int32
+	}
 	return user, nil
 }
+
+func (r *userRepository) EditRole(id int32, role string) error {
+	query := `UPDATE users SET role=$1, updated_at=EXTRACT(EPOCH FROM NOW()) WHERE id=$2`
+	res, err := r.DB.Exec(query, role, id)
+	if err != nil {
+		log.Error("[Repo][userRepo.EditRole] Error Exec: ", err)
+		return app.NewAppError(500, "failed to update user role")
+	}
+
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return app.NewAppError(404, "user not found")
+	}
+	return nil
+}
@@ -31,14 +31,34 @@ func TestUserRepository_Auth(t *testing.T) {
 	repo := userRepo.NewUserRepository(mockDB)
 	id := int32(1)
 
-	mock.ExpectQuery("SELECT id, name, email, COALESCE\\(img_url, ''\\), is_admin, is_verified FROM users WHERE id=\\$1").
+	mock.ExpectQuery("SELECT id, name, email, COALESCE\\(img_url, ''\\), role, school_id, is_verified\\s+FROM users WHERE id=\\$1").
 		WithArgs(id).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email", "img_url", "is_admin", "is_verified"}).
-			AddRow(1, "John Doe", "john@example.com", "", false, true))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email", "img_url", "role", "school_id", "is_verified"}).
+			AddRow(1, "John Doe", "john@example.com", "", "school_admin", nil, true))
 
 	user, err := repo.Auth(id)
 	assert.NoError(t, err)
 	assert.Equal(t, int32(1), user.ID)
 	assert.Equal(t, "John Doe", user.Name)
 	assert.Equal(t, "john@example.com", user.Email)
+	assert.Equal(t, "school_admin", user.Role)
+	assert.True(t, user.IsAdmin)
+}
+
+func TestUserRepository_EditRole(t *testing.T) {
+	mockDB, mock := setupMockDB(t)
+	defer mockDB.Close()
+
+	repo := userRepo.NewUserRepository(mockDB)
+
+	mock.ExpectExec("UPDATE users SET role=\\$1, updated_at=EXTRACT\\(EPOCH FROM NOW\\(\\)\\) WHERE id=\\$2").
+		WithArgs("teacher", int32(4)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE users SET role=\\$1").
+		WithArgs("teacher", int32(5)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, repo.EditRole(4, "teacher"))
+	assert.EqualError(t, repo.EditRole(5, "teacher"), "user not found")
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
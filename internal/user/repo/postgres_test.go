@@ -7,6 +7,7 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	userEntity "github.com/ghulammuzz/misterblast/internal/user/entity"
 	userRepo "github.com/ghulammuzz/misterblast/internal/user/repo"
+	"github.com/ghulammuzz/misterblast/pkg/mailer"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -47,14 +48,37 @@ func TestUserRepository_Add(t *testing.T) {
 	}
 	isVerified := true
 
-	mock.ExpectExec(`INSERT INTO users \(name, email, password, img_url, is_verified\) VALUES \(\$1, \$2, \$3, \$4, \$5\)`).
-		WithArgs(user.Name, user.Email, sqlmock.AnyArg(), nil, isVerified).
+	mock.ExpectExec(`INSERT INTO users \(name, email, password, img_url, role, is_verified, school_id\) VALUES \(\$1, \$2, \$3, \$4, \$5, \$6, \$7\)`).
+		WithArgs(user.Name, user.Email, sqlmock.AnyArg(), nil, userEntity.RoleStudent, isVerified, nil).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	err := repo.Add(user, isVerified)
+	err := repo.Add(user, userEntity.RoleStudent, isVerified)
 	assert.NoError(t, err)
 }
 
+func TestUserRepository_Invite(t *testing.T) {
+	mockDB, mock := setupMockDB(t)
+	defer mockDB.Close()
+
+	repo := userRepo.NewUserRepository(mockDB)
+	schoolID := int32(3)
+	user := userEntity.Register{Name: "Jane", Email: "jane@example.com", Password: "secret", SchoolID: &schoolID}
+	msg := mailer.Message{To: user.Email, Subject: "Invitation", Text: "Hi Jane", HTML: "<p>Hi Jane</p>"}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO users`).
+		WithArgs(user.Name, user.Email, sqlmock.AnyArg(), nil, userEntity.RoleTeacher, false, &schoolID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`INSERT INTO email_outbox`).
+		WithArgs(msg.To, msg.Subject, msg.Text, msg.HTML, sqlmock.AnyArg(), sqlmock.AnyArg(), false).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err := repo.Invite(user, userEntity.RoleTeacher, msg)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestUserRepository_Check(t *testing.T) {
 	mockDB, mock := setupMockDB(t)
 	defer mockDB.Close()
@@ -66,15 +90,17 @@ func TestUserRepository_Check(t *testing.T) {
 	}
 
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
-	mock.ExpectQuery("SELECT id, email, password, is_admin, is_verified FROM users WHERE email=\\$1").
+	mock.ExpectQuery("SELECT id, email, password, role, school_id, is_verified FROM users WHERE email=\\$1").
 		WithArgs(user.Email).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "email", "password", "is_admin", "is_verified"}).
-			AddRow(1, user.Email, hashedPassword, false, true))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email", "password", "role", "school_id", "is_verified"}).
+			AddRow(1, user.Email, hashedPassword, userEntity.RoleTeacher, 2, true))
 
 	result, err := repo.Check(user)
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
 	assert.Equal(t, user.Email, result.Email)
+	assert.Equal(t, userEntity.RoleTeacher, result.Role)
+	assert.Equal(t, int32(2), *result.SchoolID)
 }
 
 func TestUserRepository_Delete(t *testing.T) {
@@ -88,6 +114,39 @@ func TestUserRepository_Delete(t *testing.T) {
 		WithArgs(id).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
-	err := repo.Delete(id)
+	err := repo.Delete(nil, id)
+	assert.NoError(t, err)
+}
+
+func TestUserRepository_Delete_OtherSchool(t *testing.T) {
+	mockDB, mock := setupMockDB(t)
+	defer mockDB.Close()
+
+	repo := userRepo.NewUserRepository(mockDB)
+	schoolID := int32(2)
+
+	mock.ExpectExec("DELETE FROM users WHERE id = \\$1 AND school_id = \\$2").
+		WithArgs(int32(1), schoolID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repo.Delete(&schoolID, 1)
+	assert.EqualError(t, err, "resource not found")
+}
+
+func TestUserRepository_List_SchoolScope(t *testing.T) {
+	mockDB, mock := setupMockDB(t)
+	defer mockDB.Close()
+
+	repo := userRepo.NewUserRepository(mockDB)
+	schoolID := int32(2)
+
+	mock.ExpectQuery("FROM users WHERE 1=1 AND \\(LOWER\\(name\\) LIKE LOWER\\(\\$3\\) OR LOWER\\(email\\) LIKE LOWER\\(\\$4\\)\\) AND school_id = \\$5 LIMIT \\$1 OFFSET \\$2").
+		WithArgs(10, 0, "%john%", "%john%", schoolID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email", "img_url"}).
+			AddRow(1, "John Doe", "john@example.com", ""))
+
+	users, err := repo.List(&schoolID, map[string]string{"search": "john"}, 1, 10)
 	assert.NoError(t, err)
+	assert.Len(t, users, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
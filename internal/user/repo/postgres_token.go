@@ -0,0 +1,137 @@
+package repo
+
+import (
+	"database/sql"
+	"time"
+
+	userEntity "github.com/ghulammuzz/misterblast/internal/user/entity"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/log"
+)
+
+// TokenRepository keeps the server-side state of issued tokens: hashed refresh
+// tokens and the ids of access tokens revoked before their expiry.
+type TokenRepository interface {
+	AddRefreshToken(userID int32, tokenHash string, expiresAt int64) error
+	GetRefreshToken(tokenHash string) (userEntity.RefreshToken, error)
+	RotateRefreshToken(oldHash string, userID int32, newHash string, expiresAt int64) error
+	RevokeRefreshToken(tokenHash string) error
+	RevokeUserRefreshTokens(userID int32) error
+	RevokeAccessToken(jti string, expiresAt int64) error
+	IsRevoked(jti string) (bool, error)
+}
+
+type tokenRepository struct {
+	DB *sql.DB
+}
+
+func NewTokenRepository(db *sql.DB) TokenRepository {
+	return &tokenRepository{DB: db}
+}
+
+func (r *tokenRepository) AddRefreshToken(userID int32, tokenHash string, expiresAt int64) error {
+	query := `INSERT INTO refresh_tokens (user_id, token_hash, expires_at, created_at) VALUES ($1, $2, $3, $4)`
+	if _, err := r.DB.Exec(query, userID, tokenHash, expiresAt, time.Now().Unix()); err != nil {
+		log.Error("[Repo][AddRefreshToken] Error Exec: ", err)
+		return app.NewAppError(500, "failed to store refresh token")
+	}
+	return nil
+}
+
+func (r *tokenRepository) GetRefreshToken(tokenHash string) (userEntity.RefreshToken, error) {
+	query := `SELECT id, user_id, token_hash, expires_at, revoked_at FROM refresh_tokens WHERE token_hash = $1`
+	var token userEntity.RefreshToken
+	var revokedAt sql.NullInt64
+	err := r.DB.QueryRow(query, tokenHash).Scan(&token.ID, &token.UserID, &token.TokenHash, &token.ExpiresAt, &revokedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return token, app.NewAppError(401, "invalid refresh token")
+		}
+		log.Error("[Repo][GetRefreshToken] Error QueryRow: ", err)
+		return token, app.NewAppError(500, "failed to fetch refresh token")
+	}
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Int64
+	}
+	return token, nil
+}
+
+// RotateRefreshToken revokes the presented refresh token and stores its
+// replacement in one transaction. Losing the race against a concurrent
+// rotation of the same token is reported as an invalid token.
+func (r *tokenRepository) RotateRefreshToken(oldHash string, userID int32, newHash string, expiresAt int64) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		log.Error("[Repo][RotateRefreshToken] Error Begin: ", err)
+		return app.NewAppError(500, "failed to rotate refresh token")
+	}
+	defer tx.Rollback()
+
+	now := time.Now().Unix()
+	res, err := tx.Exec(`UPDATE refresh_tokens SET revoked_at = $1 WHERE token_hash = $2 AND revoked_at IS NULL`, now, oldHash)
+	if err != nil {
+		log.Error("[Repo][RotateRefreshToken] Error Exec: ", err)
+		return app.NewAppError(500, "failed to rotate refresh token")
+	}
+
+	rowsAffected, _ := res.RowsAffected()
+	if rowsAffected == 0 {
+		return app.NewAppError(401, "invalid refresh token")
+	}
+
+	query := `INSERT INTO refresh_tokens (user_id, token_hash, expires_at, created_at) VALUES ($1, $2, $3, $4)`
+	if _, err := tx.Exec(query, userID, newHash, expiresAt, now); err != nil {
+		log.Error("[Repo][RotateRefreshToken] Error Exec Insert: ", err)
+		return app.NewAppError(500, "failed to rotate refresh token")
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error("[Repo][RotateRefreshToken] Error Commit: ", err)
+		return app.NewAppError(500, "failed to rotate refresh token")
+	}
+
+	return nil
+}
+
+func (r *tokenRepository) RevokeRefreshToken(tokenHash string) error {
+	query := `UPDATE refresh_tokens SET revoked_at = $1 WHERE token_hash = $2 AND revoked_at IS NULL`
+	if _, err := r.DB.Exec(query, time.Now().Unix(), tokenHash); err != nil {
+		log.Error("[Repo][RevokeRefreshToken] Error Exec: ", err)
+		return app.NewAppError(500, "failed to revoke refresh token")
+	}
+	return nil
+}
+
+func (r *tokenRepository) RevokeUserRefreshTokens(userID int32) error {
+	query := `UPDATE refresh_tokens SET revoked_at = $1 WHERE user_id = $2 AND revoked_at IS NULL`
+	if _, err := r.DB.Exec(query, time.Now().Unix(), userID); err != nil {
+		log.Error("[Repo][RevokeUserRefreshTokens] Error Exec: ", err)
+		return app.NewAppError(500, "failed to revoke refresh tokens")
+	}
+	return nil
+}
+
+// RevokeAccessToken adds the token id to the revocation list. Entries whose
+// token has expired anyway are pruned on the way.
+func (r *tokenRepository) RevokeAccessToken(jti string, expiresAt int64) error {
+	query := `INSERT INTO revoked_tokens (jti, expires_at) VALUES ($1, $2) ON CONFLICT (jti) DO NOTHING`
+	if _, err := r.DB.Exec(query, jti, expiresAt); err != nil {
+		log.Error("[Repo][RevokeAccessToken] Error Exec: ", err)
+		return app.NewAppError(500, "failed to revoke access token")
+	}
+
+	if _, err := r.DB.Exec(`DELETE FROM revoked_tokens WHERE expires_at < $1`, time.Now().Unix()); err != nil {
+		log.Warn("[Repo][RevokeAccessToken] Error Exec Prune: ", err)
+	}
+	return nil
+}
+
+func (r *tokenRepository) IsRevoked(jti string) (bool, error) {
+	var revoked bool
+	query := `SELECT EXISTS(SELECT 1 FROM revoked_tokens WHERE jti = $1)`
+	if err := r.DB.QueryRow(query, jti).Scan(&revoked); err != nil {
+		log.Error("[Repo][IsRevoked] Error QueryRow: ", err)
+		return false, app.NewAppError(500, "failed to check token revocation")
+	}
+	return revoked, nil
+}
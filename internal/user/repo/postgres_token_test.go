@@ -0,0 +1,80 @@
+package repo_test
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	userRepo "github.com/ghulammuzz/misterblast/internal/user/repo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenRepository_GetRefreshToken(t *testing.T) {
+	mockDB, mock := setupMockDB(t)
+	defer mockDB.Close()
+
+	repo := userRepo.NewTokenRepository(mockDB)
+
+	mock.ExpectQuery("SELECT id, user_id, token_hash, expires_at, revoked_at FROM refresh_tokens WHERE token_hash = \\$1").
+		WithArgs("hash").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "token_hash", "expires_at", "revoked_at"}).
+			AddRow(3, 1, "hash", 1700000000, nil))
+
+	token, err := repo.GetRefreshToken("hash")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), token.UserID)
+	assert.Nil(t, token.RevokedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTokenRepository_RotateRefreshToken(t *testing.T) {
+	mockDB, mock := setupMockDB(t)
+	defer mockDB.Close()
+
+	repo := userRepo.NewTokenRepository(mockDB)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE refresh_tokens SET revoked_at = \\$1 WHERE token_hash = \\$2 AND revoked_at IS NULL").
+		WithArgs(sqlmock.AnyArg(), "old").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO refresh_tokens").
+		WithArgs(1, "new", 1700000000, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(4, 1))
+	mock.ExpectCommit()
+
+	err := repo.RotateRefreshToken("old", 1, "new", 1700000000)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTokenRepository_RotateRefreshToken_AlreadyRotated(t *testing.T) {
+	mockDB, mock := setupMockDB(t)
+	defer mockDB.Close()
+
+	repo := userRepo.NewTokenRepository(mockDB)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE refresh_tokens SET revoked_at").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err := repo.RotateRefreshToken("old", 1, "new", 1700000000)
+	assert.Error(t, err)
+	assert.Equal(t, "invalid refresh token", err.Error())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTokenRepository_IsRevoked(t *testing.T) {
+	mockDB, mock := setupMockDB(t)
+	defer mockDB.Close()
+
+	repo := userRepo.NewTokenRepository(mockDB)
+
+	mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM revoked_tokens WHERE jti = \\$1\\)").
+		WithArgs("jti-1").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	revoked, err := repo.IsRevoked("jti-1")
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -3,65 +3,134 @@ package svc
 import (
 	userEntity "github.com/ghulammuzz/misterblast/internal/user/entity"
 	userRepo "github.com/ghulammuzz/misterblast/internal/user/repo"
-	"github.com/ghulammuzz/misterblast/pkg/jwt"
+	"github.com/ghulammuzz/misterblast/pkg/app"
 )
 
 type UserService interface {
 	Register(user userEntity.Register) error
-	RegisterAdmin(user userEntity.RegisterAdmin) error
-	Login(user userEntity.UserLogin) (*userEntity.LoginResponse, string, error)
-	ListUser(filter map[string]string, page, limit int) ([]userEntity.ListUser, error)
-	DetailUser(id int32) (userEntity.DetailUser, error)
+	RegisterAdmin(schoolID *int32, user userEntity.RegisterAdmin) error
+	Login(user userEntity.UserLogin) (*userEntity.LoginResponse, error)
+	RefreshToken(refreshToken string) (*userEntity.TokenPair, error)
+	Logout(jti string, expiresAt int64, refreshToken string) error
+	ListUser(schoolID *int32, filter map[string]string, page, limit int) ([]userEntity.ListUser, error)
+	DetailUser(schoolID *int32, id int32) (userEntity.DetailUser, error)
 	AuthUser(id int32) (userEntity.UserAuth, error)
-	EditUser(id int32, user userEntity.EditUser) error
-	DeleteUser(id int32) error
+	EditUser(callerID int32, callerRole string, schoolID *int32, id int32, user userEntity.EditUser) error
+	DeleteUser(callerID int32, callerRole string, schoolID *int32, id int32) error
+	EditRole(callerID int32, callerRole string, schoolID *int32, id int32, role userEntity.EditRole) error
 }
 type userService struct {
-	userRepo userRepo.UserRepository
+	userRepo  userRepo.UserRepository
+	tokenRepo userRepo.TokenRepository
 }
 
-func NewUserService(userRepo userRepo.UserRepository) UserService {
-	return &userService{userRepo: userRepo}
+func NewUserService(userRepo userRepo.UserRepository, tokenRepo userRepo.TokenRepository) UserService {
+	return &userService{userRepo: userRepo, tokenRepo: tokenRepo}
 }
 
-func (s *userService) Login(user userEntity.UserLogin) (*userEntity.LoginResponse, string, error) {
+func (s *userService) Login(user userEntity.UserLogin) (*userEntity.LoginResponse, error) {
 
 	var userResponse userEntity.LoginResponse
 
 	userResult, err := s.userRepo.Check(user)
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
 
 	userResponse.ID = userResult.ID
 	userResponse.Email = userResult.Email
-	userResponse.IsAdmin = userResult.IsAdmin
+	userResponse.Role = userResult.Role
+	userResponse.SchoolID = userResult.SchoolID
+	userResponse.IsAdmin = userResult.Role != userEntity.RoleStudent
 	userResponse.IsVerified = userResult.IsVerified
 
-	token, err := jwt.GenerateJWT(*userResult)
+	token, err := s.issueTokens(*userResult)
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
+	userResponse.Token = token
 
-	return &userResponse, token, nil
+	return &userResponse, nil
 }
 
-func (s *userService) ListUser(filter map[string]string, page, limit int) ([]userEntity.ListUser, error) {
-	return s.userRepo.List(filter, page, limit)
+// ListUser, DetailUser, EditUser and DeleteUser only reach the users of
+// schoolID; nil, for super admins and users on their own account, reaches
+// every school.
+func (s *userService) ListUser(schoolID *int32, filter map[string]string, page, limit int) ([]userEntity.ListUser, error) {
+	return s.userRepo.List(schoolID, filter, page, limit)
 }
 
-func (s *userService) DetailUser(id int32) (userEntity.DetailUser, error) {
-	return s.userRepo.Detail(id)
+func (s *userService) DetailUser(schoolID *int32, id int32) (userEntity.DetailUser, error) {
+	return s.userRepo.Detail(schoolID, id)
 }
 
-func (s *userService) EditUser(id int32, user userEntity.EditUser) error {
-	return s.userRepo.Edit(id, user)
+// EditUser and DeleteUser let callers other than the user only act on users
+// ranking below them, so an admin cannot take over or remove a peer admin.
+func (s *userService) EditUser(callerID int32, callerRole string, schoolID *int32, id int32, user userEntity.EditUser) error {
+	if err := s.outranks(callerID, callerRole, schoolID, id); err != nil {
+		return err
+	}
+	return s.userRepo.Edit(schoolID, id, user)
 }
 
 func (s *userService) AuthUser(id int32) (userEntity.UserAuth, error) {
 	return s.userRepo.Auth(id)
 }
 
-func (s *userService) DeleteUser(id int32) error {
-	return s.userRepo.Delete(id)
+func (s *userService) DeleteUser(callerID int32, callerRole string, schoolID *int32, id int32) error {
+	if err := s.outranks(callerID, callerRole, schoolID, id); err != nil {
+		return err
+	}
+	return s.userRepo.Delete(schoolID, id)
+}
+
+// outranks checks that the caller may manage user id: themselves, or a user
+// of schoolID whose role ranks below theirs.
+func (s *userService) outranks(callerID int32, callerRole string, schoolID *int32, id int32) error {
+	if callerID == id {
+		return nil
+	}
+
+	user, err := s.userRepo.Auth(id)
+	if err != nil {
+		return err
+	}
+	if !sameSchool(schoolID, user.SchoolID) {
+		return app.NewAppError(404, "user not found")
+	}
+	if !userEntity.CanGrant(callerRole, user.Role) {
+		return app.NewAppError(403, "cannot manage a user of your rank or above")
+	}
+	return nil
+}
+
+// EditRole changes the role of another user. Callers may only change users
+// and grant roles ranking below their own, so only super admins appoint
+// admins. Users of other schools than schoolID are not found. Tokens of the
+// user carry the new role from their next refresh.
+func (s *userService) EditRole(callerID int32, callerRole string, schoolID *int32, id int32, role userEntity.EditRole) error {
+	if callerID == id {
+		return app.NewAppError(400, "cannot change your own role")
+	}
+
+	user, err := s.userRepo.Auth(id)
+	if err != nil {
+		return err
+	}
+	if !sameSchool(schoolID, user.SchoolID) {
+		return app.NewAppError(404, "user not found")
+	}
+	if !userEntity.CanGrant(callerRole, user.Role) || !userEntity.CanGrant(callerRole, role.Role) {
+		return app.NewAppError(403, "cannot grant this role")
+	}
+
+	return s.userRepo.EditRole(id, role.Role)
+}
+
+// sameSchool reports whether a user of userSchool lies within schoolID.
+func sameSchool(schoolID, userSchool *int32) bool {
+	if schoolID == nil {
+		return true
+	}
+	return userSchool != nil && *userSchool == *schoolID
 }
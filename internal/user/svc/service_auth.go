@@ -5,22 +5,43 @@ import (
 	"os"
 
 	userEntity "github.com/ghulammuzz/misterblast/internal/user/entity"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/log"
+	"github.com/ghulammuzz/misterblast/pkg/mailer"
 )
 
 func (s *userService) Register(user userEntity.Register) error {
 	if len(user.Password) < 6 {
 		return errors.New("password must be at least 6 characters")
 	}
-	return s.userRepo.Add(user, true)
+	return s.userRepo.Add(user, userEntity.RoleStudent, true)
 }
 
-func (s *userService) RegisterAdmin(user userEntity.RegisterAdmin) error {
+// RegisterAdmin invites a teacher. Admins are appointed by changing the role
+// of an existing account. The teacher joins schoolID, the school of the
+// inviting admin; super admins, with a nil schoolID, name it in the request.
+func (s *userService) RegisterAdmin(schoolID *int32, user userEntity.RegisterAdmin) error {
+	if schoolID == nil {
+		if user.SchoolID == nil {
+			return app.NewAppError(400, "school_id is required")
+		}
+		schoolID = user.SchoolID
+	}
 
 	// check in csv or excel
 
-	return s.userRepo.Add(userEntity.Register{
+	msg, err := mailer.Render(mailer.TemplateAdminInvitation, user.Lang, mailer.InvitationData{Name: user.Name, Email: user.Email})
+	if err != nil {
+		log.Error("[Svc][RegisterAdmin] Error Render: ", err)
+		return app.ErrInternal
+	}
+	msg.To = user.Email
+
+	// The invitation is delivered by the outbox worker, not within this request.
+	return s.userRepo.Invite(userEntity.Register{
 		Name:     user.Name,
 		Email:    user.Email,
 		Password: os.Getenv("PASSWORD_ALG"),
-	}, false)
+		SchoolID: schoolID,
+	}, userEntity.RoleTeacher, msg)
 }
@@ -1,13 +1,17 @@
 package svc_test
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
 	userEntity "github.com/ghulammuzz/misterblast/internal/user/entity"
 	userSvc "github.com/ghulammuzz/misterblast/internal/user/svc"
+	"github.com/ghulammuzz/misterblast/pkg/jwt"
+	"github.com/ghulammuzz/misterblast/pkg/mailer"
 )
 
 type MockUserRepository struct {
@@ -19,8 +23,8 @@ func (m *MockUserRepository) Exists(id int32) (bool, error) {
 	return args.Bool(0), args.Error(1)
 }
 
-func (m *MockUserRepository) Detail(id int32) (userEntity.DetailUser, error) {
-	args := m.Called(id)
+func (m *MockUserRepository) Detail(schoolID *int32, id int32) (userEntity.DetailUser, error) {
+	args := m.Called(schoolID, id)
 	if args.Get(0) == nil {
 		return userEntity.DetailUser{}, args.Error(1)
 	}
@@ -32,8 +36,18 @@ func (m *MockUserRepository) AdminActivation(id int32) error {
 	return args.Error(0)
 }
 
-func (m *MockUserRepository) Add(user userEntity.Register, isVerified bool) error {
-	args := m.Called(user, isVerified)
+func (m *MockUserRepository) Add(user userEntity.Register, role string, isVerified bool) error {
+	args := m.Called(user, role, isVerified)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) Invite(user userEntity.Register, role string, invitation mailer.Message) error {
+	args := m.Called(user, role, invitation)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) EditRole(id int32, role string) error {
+	args := m.Called(id, role)
 	return args.Error(0)
 }
 
@@ -45,8 +59,8 @@ func (m *MockUserRepository) Check(user userEntity.UserLogin) (*userEntity.UserJ
 	return args.Get(0).(*userEntity.UserJWT), args.Error(1)
 }
 
-func (m *MockUserRepository) Delete(id int32) error {
-	args := m.Called(id)
+func (m *MockUserRepository) Delete(schoolID *int32, id int32) error {
+	args := m.Called(schoolID, id)
 	return args.Error(0)
 }
 
@@ -55,8 +69,8 @@ func (m *MockUserRepository) Auth(id int32) (userEntity.UserAuth, error) {
 	return args.Get(0).(userEntity.UserAuth), args.Error(1)
 }
 
-func (m *MockUserRepository) Edit(id int32, user userEntity.EditUser) error {
-	args := m.Called(id, user)
+func (m *MockUserRepository) Edit(schoolID *int32, id int32, user userEntity.EditUser) error {
+	args := m.Called(schoolID, id, user)
 	return args.Error(0)
 }
 
@@ -65,14 +79,62 @@ func (m *MockUserRepository) GetIDByEmail(email string) (int32, error) {
 	return args.Get(0).(int32), args.Error(1)
 }
 
-func (m *MockUserRepository) List(filter map[string]string, page, limit int) ([]userEntity.ListUser, error) {
-	args := m.Called(filter, page, limit)
+func (m *MockUserRepository) UpdatePassword(id int32, password string) error {
+	args := m.Called(id, password)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) List(schoolID *int32, filter map[string]string, page, limit int) ([]userEntity.ListUser, error) {
+	args := m.Called(schoolID, filter, page, limit)
 	return args.Get(0).([]userEntity.ListUser), args.Error(1)
 }
 
+func school(id int32) *int32 {
+	return &id
+}
+
+type MockTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockTokenRepository) AddRefreshToken(userID int32, tokenHash string, expiresAt int64) error {
+	args := m.Called(userID, tokenHash, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepository) GetRefreshToken(tokenHash string) (userEntity.RefreshToken, error) {
+	args := m.Called(tokenHash)
+	return args.Get(0).(userEntity.RefreshToken), args.Error(1)
+}
+
+func (m *MockTokenRepository) RotateRefreshToken(oldHash string, userID int32, newHash string, expiresAt int64) error {
+	args := m.Called(oldHash, userID, newHash, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepository) RevokeRefreshToken(tokenHash string) error {
+	args := m.Called(tokenHash)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepository) RevokeUserRefreshTokens(userID int32) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepository) RevokeAccessToken(jti string, expiresAt int64) error {
+	args := m.Called(jti, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepository) IsRevoked(jti string) (bool, error) {
+	args := m.Called(jti)
+	return args.Bool(0), args.Error(1)
+}
+
 func TestUserService_Register(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	service := userSvc.NewUserService(mockRepo)
+	service := userSvc.NewUserService(mockRepo, new(MockTokenRepository))
 
 	user := userEntity.Register{
 		Name:     "John Doe",
@@ -80,15 +142,42 @@ func TestUserService_Register(t *testing.T) {
 		Password: "password123",
 	}
 
-	mockRepo.On("Add", user, true).Return(nil)
+	mockRepo.On("Add", user, userEntity.RoleStudent, true).Return(nil)
 	err := service.Register(user)
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
 
+func TestUserService_RegisterAdmin(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := userSvc.NewUserService(mockRepo, new(MockTokenRepository))
+
+	admin := userEntity.RegisterAdmin{Name: "Jane", Email: "jane@example.com", Lang: "en"}
+
+	mockRepo.On("Invite", mock.MatchedBy(func(user userEntity.Register) bool {
+		return user.Email == admin.Email && user.SchoolID != nil && *user.SchoolID == 3
+	}), userEntity.RoleTeacher, mock.MatchedBy(func(msg mailer.Message) bool {
+		return msg.To == admin.Email && strings.Contains(msg.Subject, "admin") && strings.Contains(msg.HTML, "Jane")
+	})).Return(nil)
+
+	err := service.RegisterAdmin(school(3), admin)
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUserService_RegisterAdmin_SchoolRequired(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := userSvc.NewUserService(mockRepo, new(MockTokenRepository))
+
+	err := service.RegisterAdmin(nil, userEntity.RegisterAdmin{Name: "Jane", Email: "jane@example.com"})
+	assert.EqualError(t, err, "school_id is required")
+	mockRepo.AssertNotCalled(t, "Invite", mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestUserService_Login(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	service := userSvc.NewUserService(mockRepo)
+	mockTokenRepo := new(MockTokenRepository)
+	service := userSvc.NewUserService(mockRepo, mockTokenRepo)
 
 	user := userEntity.UserLogin{
 		Email:    "john@example.com",
@@ -98,34 +187,122 @@ func TestUserService_Login(t *testing.T) {
 	userJWT := &userEntity.UserJWT{
 		ID:         1,
 		Email:      user.Email,
-		IsAdmin:    false,
+		Role:       userEntity.RoleTeacher,
 		IsVerified: true,
 	}
 
 	mockRepo.On("Check", user).Return(userJWT, nil)
+	mockTokenRepo.On("AddRefreshToken", int32(1), mock.Anything, mock.Anything).Return(nil)
 
-	resp, token, err := service.Login(user)
+	resp, err := service.Login(user)
 	assert.NoError(t, err)
-	assert.NotEmpty(t, token)
+	assert.NotEmpty(t, resp.Token.AccessToken)
+	assert.NotEmpty(t, resp.Token.RefreshToken)
 	assert.Equal(t, user.Email, resp.Email)
+	assert.Equal(t, userEntity.RoleTeacher, resp.Role)
+	assert.True(t, resp.IsAdmin)
 	mockRepo.AssertExpectations(t)
+	mockTokenRepo.AssertExpectations(t)
+}
+
+func TestUserService_RefreshToken(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	mockTokenRepo := new(MockTokenRepository)
+	service := userSvc.NewUserService(mockRepo, mockTokenRepo)
+
+	hash := jwt.HashToken("refresh")
+	mockTokenRepo.On("GetRefreshToken", hash).Return(userEntity.RefreshToken{
+		ID: 3, UserID: 1, TokenHash: hash, ExpiresAt: time.Now().Add(time.Hour).Unix(),
+	}, nil)
+	mockRepo.On("Auth", int32(1)).Return(userEntity.UserAuth{ID: 1, Email: "john@example.com"}, nil)
+	mockTokenRepo.On("RotateRefreshToken", hash, int32(1), mock.Anything, mock.Anything).Return(nil)
+
+	token, err := service.RefreshToken("refresh")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, token.AccessToken)
+	assert.NotEqual(t, "refresh", token.RefreshToken)
+	mockTokenRepo.AssertExpectations(t)
+}
+
+func TestUserService_RefreshToken_Reused(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	mockTokenRepo := new(MockTokenRepository)
+	service := userSvc.NewUserService(mockRepo, mockTokenRepo)
+
+	hash := jwt.HashToken("refresh")
+	revokedAt := time.Now().Unix()
+	mockTokenRepo.On("GetRefreshToken", hash).Return(userEntity.RefreshToken{
+		ID: 3, UserID: 1, TokenHash: hash, ExpiresAt: time.Now().Add(time.Hour).Unix(), RevokedAt: &revokedAt,
+	}, nil)
+	mockTokenRepo.On("RevokeUserRefreshTokens", int32(1)).Return(nil)
+
+	_, err := service.RefreshToken("refresh")
+	assert.Error(t, err)
+	assert.Equal(t, "invalid refresh token", err.Error())
+	mockTokenRepo.AssertExpectations(t)
+	mockTokenRepo.AssertNotCalled(t, "RotateRefreshToken", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUserService_RefreshToken_Expired(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	mockTokenRepo := new(MockTokenRepository)
+	service := userSvc.NewUserService(mockRepo, mockTokenRepo)
+
+	hash := jwt.HashToken("refresh")
+	mockTokenRepo.On("GetRefreshToken", hash).Return(userEntity.RefreshToken{
+		ID: 3, UserID: 1, TokenHash: hash, ExpiresAt: time.Now().Add(-time.Hour).Unix(),
+	}, nil)
+
+	_, err := service.RefreshToken("refresh")
+	assert.Error(t, err)
+	assert.Equal(t, "refresh token expired", err.Error())
+}
+
+func TestUserService_Logout(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	mockTokenRepo := new(MockTokenRepository)
+	service := userSvc.NewUserService(mockRepo, mockTokenRepo)
+
+	mockTokenRepo.On("RevokeAccessToken", "jti-1", int64(1700000000)).Return(nil)
+	mockTokenRepo.On("RevokeRefreshToken", jwt.HashToken("refresh")).Return(nil)
+
+	err := service.Logout("jti-1", 1700000000, "refresh")
+	assert.NoError(t, err)
+	mockTokenRepo.AssertExpectations(t)
 }
 
 func TestUserService_DeleteUser(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	service := userSvc.NewUserService(mockRepo)
+	service := userSvc.NewUserService(mockRepo, new(MockTokenRepository))
 
 	id := int32(1)
-	mockRepo.On("Delete", id).Return(nil)
+	mockRepo.On("Auth", id).Return(userEntity.UserAuth{ID: id, Role: userEntity.RoleStudent, SchoolID: school(1)}, nil)
+	mockRepo.On("Delete", school(1), id).Return(nil)
 
-	err := service.DeleteUser(id)
+	err := service.DeleteUser(9, userEntity.RoleSchoolAdmin, school(1), id)
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
 
+func TestUserService_PeerAdminForbidden(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := userSvc.NewUserService(mockRepo, new(MockTokenRepository))
+
+	mockRepo.On("Auth", int32(5)).Return(userEntity.UserAuth{ID: 5, Role: userEntity.RoleSchoolAdmin, SchoolID: school(1)}, nil)
+
+	err := service.EditUser(9, userEntity.RoleSchoolAdmin, school(1), 5, userEntity.EditUser{Name: "Taken Over"})
+	assert.EqualError(t, err, "cannot manage a user of your rank or above")
+
+	err = service.DeleteUser(9, userEntity.RoleSchoolAdmin, school(1), 5)
+	assert.EqualError(t, err, "cannot manage a user of your rank or above")
+
+	mockRepo.AssertNotCalled(t, "Edit", mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
 func TestUserService_AuthUser(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	service := userSvc.NewUserService(mockRepo)
+	service := userSvc.NewUserService(mockRepo, new(MockTokenRepository))
 
 	id := int32(1)
 	userAuth := userEntity.UserAuth{
@@ -146,15 +323,15 @@ func TestUserService_AuthUser(t *testing.T) {
 }
 func TestUserService_ListUser(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	service := userSvc.NewUserService(mockRepo)
+	service := userSvc.NewUserService(mockRepo, new(MockTokenRepository))
 
 	filter := map[string]string{"role": "user"}
 	page, limit := 1, 10
 	mockUsers := []userEntity.ListUser{{ID: 1, Name: "John Doe", Email: "john@example.com"}}
 
-	mockRepo.On("List", filter, page, limit).Return(mockUsers, nil)
+	mockRepo.On("List", school(1), filter, page, limit).Return(mockUsers, nil)
 
-	resp, err := service.ListUser(filter, page, limit)
+	resp, err := service.ListUser(school(1), filter, page, limit)
 	assert.NoError(t, err)
 	assert.Equal(t, mockUsers, resp)
 	mockRepo.AssertExpectations(t)
@@ -162,14 +339,14 @@ func TestUserService_ListUser(t *testing.T) {
 
 func TestUserService_DetailUser(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	service := userSvc.NewUserService(mockRepo)
+	service := userSvc.NewUserService(mockRepo, new(MockTokenRepository))
 
 	id := int32(1)
 	mockUser := userEntity.DetailUser{ID: id, Name: "John Doe", Email: "john@example.com"}
 
-	mockRepo.On("Detail", id).Return(mockUser, nil)
+	mockRepo.On("Detail", school(1), id).Return(mockUser, nil)
 
-	resp, err := service.DetailUser(id)
+	resp, err := service.DetailUser(school(1), id)
 	assert.NoError(t, err)
 	assert.Equal(t, mockUser, resp)
 	mockRepo.AssertExpectations(t)
@@ -177,14 +354,55 @@ func TestUserService_DetailUser(t *testing.T) {
 
 func TestUserService_EditUser(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	service := userSvc.NewUserService(mockRepo)
+	service := userSvc.NewUserService(mockRepo, new(MockTokenRepository))
 
 	id := int32(1)
 	userEdit := userEntity.EditUser{Name: "John Updated"}
 
-	mockRepo.On("Edit", id, userEdit).Return(nil)
+	mockRepo.On("Edit", (*int32)(nil), id, userEdit).Return(nil)
+
+	err := service.EditUser(id, userEntity.RoleStudent, nil, id, userEdit)
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUserService_EditRole(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := userSvc.NewUserService(mockRepo, new(MockTokenRepository))
+
+	mockRepo.On("Auth", int32(4)).Return(userEntity.UserAuth{ID: 4, Role: userEntity.RoleStudent, SchoolID: school(1)}, nil)
+	mockRepo.On("EditRole", int32(4), userEntity.RoleTeacher).Return(nil)
 
-	err := service.EditUser(id, userEdit)
+	err := service.EditRole(1, userEntity.RoleSchoolAdmin, school(1), 4, userEntity.EditRole{Role: userEntity.RoleTeacher})
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestUserService_EditRole_OtherSchool(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := userSvc.NewUserService(mockRepo, new(MockTokenRepository))
+
+	mockRepo.On("Auth", int32(4)).Return(userEntity.UserAuth{ID: 4, Role: userEntity.RoleStudent, SchoolID: school(2)}, nil)
+
+	err := service.EditRole(1, userEntity.RoleSchoolAdmin, school(1), 4, userEntity.EditRole{Role: userEntity.RoleTeacher})
+	assert.EqualError(t, err, "user not found")
+	mockRepo.AssertNotCalled(t, "EditRole", mock.Anything, mock.Anything)
+}
+
+func TestUserService_EditRole_Forbidden(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := userSvc.NewUserService(mockRepo, new(MockTokenRepository))
+
+	mockRepo.On("Auth", int32(4)).Return(userEntity.UserAuth{ID: 4, Role: userEntity.RoleStudent, SchoolID: school(1)}, nil)
+	mockRepo.On("Auth", int32(5)).Return(userEntity.UserAuth{ID: 5, Role: userEntity.RoleSchoolAdmin, SchoolID: school(1)}, nil)
+
+	err := service.EditRole(1, userEntity.RoleSchoolAdmin, school(1), 4, userEntity.EditRole{Role: userEntity.RoleSchoolAdmin})
+	assert.EqualError(t, err, "cannot grant this role")
+
+	err = service.EditRole(1, userEntity.RoleSchoolAdmin, school(1), 5, userEntity.EditRole{Role: userEntity.RoleStudent})
+	assert.EqualError(t, err, "cannot grant this role")
+
+	err = service.EditRole(1, userEntity.RoleSuperAdmin, nil, 1, userEntity.EditRole{Role: userEntity.RoleStudent})
+	assert.EqualError(t, err, "cannot change your own role")
+	mockRepo.AssertNotCalled(t, "EditRole", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,107 @@
+package svc
+
+import (
+	"time"
+
+	userEntity "github.com/ghulammuzz/misterblast/internal/user/entity"
+	"github.com/ghulammuzz/misterblast/pkg/app"
+	"github.com/ghulammuzz/misterblast/pkg/jwt"
+	"github.com/ghulammuzz/misterblast/pkg/log"
+)
+
+// RefreshToken exchanges a valid refresh token for a new token pair. Refresh
+// tokens are single use: presenting one that was already rotated means it
+// leaked, so every session of that user is revoked.
+func (s *userService) RefreshToken(refreshToken string) (*userEntity.TokenPair, error) {
+	if refreshToken == "" {
+		return nil, app.NewAppError(401, "refresh token not found")
+	}
+
+	hash := jwt.HashToken(refreshToken)
+	stored, err := s.tokenRepo.GetRefreshToken(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	if stored.RevokedAt != nil {
+		log.Warn("[Svc][RefreshToken] Reused refresh token, revoking sessions of user: ", stored.UserID)
+		if err := s.tokenRepo.RevokeUserRefreshTokens(stored.UserID); err != nil {
+			return nil, err
+		}
+		return nil, app.NewAppError(401, "invalid refresh token")
+	}
+	if stored.ExpiresAt <= time.Now().Unix() {
+		return nil, app.NewAppError(401, "refresh token expired")
+	}
+
+	user, err := s.userRepo.Auth(stored.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, expiresAt, err := jwt.GenerateJWT(userEntity.UserJWT{ID: user.ID, Email: user.Email, Role: user.Role, SchoolID: user.SchoolID})
+	if err != nil {
+		log.Error("[Svc][RefreshToken] Error GenerateJWT: ", err)
+		return nil, app.ErrInternal
+	}
+
+	newRefreshToken, refreshExpiresAt, err := jwt.GenerateRefreshToken()
+	if err != nil {
+		log.Error("[Svc][RefreshToken] Error GenerateRefreshToken: ", err)
+		return nil, app.ErrInternal
+	}
+
+	if err := s.tokenRepo.RotateRefreshToken(hash, stored.UserID, jwt.HashToken(newRefreshToken), refreshExpiresAt); err != nil {
+		return nil, err
+	}
+
+	return &userEntity.TokenPair{
+		AccessToken:      accessToken,
+		ExpiresAt:        expiresAt,
+		RefreshToken:     newRefreshToken,
+		RefreshExpiresAt: refreshExpiresAt,
+	}, nil
+}
+
+// Logout revokes the caller's access token until it would have expired and,
+// when given, the refresh token of the same session.
+func (s *userService) Logout(jti string, expiresAt int64, refreshToken string) error {
+	if jti != "" {
+		if err := s.tokenRepo.RevokeAccessToken(jti, expiresAt); err != nil {
+			return err
+		}
+	}
+
+	if refreshToken != "" {
+		if err := s.tokenRepo.RevokeRefreshToken(jwt.HashToken(refreshToken)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *userService) issueTokens(user userEntity.UserJWT) (*userEntity.TokenPair, error) {
+	accessToken, expiresAt, err := jwt.GenerateJWT(user)
+	if err != nil {
+		log.Error("[Svc][IssueTokens] Error GenerateJWT: ", err)
+		return nil, app.ErrInternal
+	}
+
+	refreshToken, refreshExpiresAt, err := jwt.GenerateRefreshToken()
+	if err != nil {
+		log.Error("[Svc][IssueTokens] Error GenerateRefreshToken: ", err)
+		return nil, app.ErrInternal
+	}
+
+	if err := s.tokenRepo.AddRefreshToken(user.ID, jwt.HashToken(refreshToken), refreshExpiresAt); err != nil {
+		return nil, err
+	}
+
+	return &userEntity.TokenPair{
+		AccessToken:      accessToken,
+		ExpiresAt:        expiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: refreshExpiresAt,
+	}, nil
+}
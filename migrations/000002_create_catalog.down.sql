@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS answers;
+DROP TABLE IF EXISTS questions;
+DROP TABLE IF EXISTS sets;
+DROP TABLE IF EXISTS lessons;
+DROP TABLE IF EXISTS classes;
@@ -0,0 +1,44 @@
+CREATE TABLE classes (
+    id   SERIAL PRIMARY KEY,
+    name VARCHAR(50) NOT NULL,
+    CONSTRAINT classes_name_key UNIQUE (name)
+);
+
+CREATE TABLE lessons (
+    id   SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    CONSTRAINT lessons_name_key UNIQUE (name)
+);
+
+CREATE TABLE sets (
+    id        SERIAL PRIMARY KEY,
+    name      VARCHAR(255) NOT NULL,
+    lesson_id INTEGER NOT NULL REFERENCES lessons (id) ON DELETE CASCADE,
+    class_id  INTEGER NOT NULL REFERENCES classes (id) ON DELETE CASCADE,
+    is_quiz   BOOLEAN NOT NULL DEFAULT false
+);
+
+CREATE INDEX sets_lesson_id_idx ON sets (lesson_id);
+CREATE INDEX sets_class_id_idx ON sets (class_id);
+
+CREATE TABLE questions (
+    id      SERIAL PRIMARY KEY,
+    number  INTEGER NOT NULL,
+    type    VARCHAR(10) NOT NULL,
+    kind    VARCHAR(20) NOT NULL DEFAULT 'single',
+    content TEXT NOT NULL,
+    is_quiz BOOLEAN NOT NULL DEFAULT false,
+    set_id  INTEGER NOT NULL REFERENCES sets (id) ON DELETE CASCADE,
+    CONSTRAINT questions_set_id_number_key UNIQUE (set_id, number)
+);
+
+CREATE TABLE answers (
+    id          SERIAL PRIMARY KEY,
+    question_id INTEGER NOT NULL REFERENCES questions (id) ON DELETE CASCADE,
+    code        VARCHAR(10) NOT NULL,
+    content     TEXT NOT NULL,
+    img_url     TEXT,
+    is_answer   BOOLEAN NOT NULL DEFAULT false
+);
+
+CREATE INDEX answers_question_id_idx ON answers (question_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS otp_ip_events;
+DROP TABLE IF EXISTS user_otps;
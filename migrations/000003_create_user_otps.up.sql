@@ -0,0 +1,22 @@
+CREATE TABLE user_otps (
+    id           SERIAL PRIMARY KEY,
+    admin_id     INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    purpose      VARCHAR(32) NOT NULL,
+    otp_code     VARCHAR(128) NOT NULL,
+    expires_at   BIGINT NOT NULL,
+    sent_at      BIGINT NOT NULL,
+    attempts     INTEGER NOT NULL DEFAULT 0,
+    used_at      BIGINT,
+    locked_until BIGINT,
+    CONSTRAINT user_otps_admin_id_purpose_key UNIQUE (admin_id, purpose)
+);
+
+CREATE TABLE otp_ip_events (
+    id         BIGSERIAL PRIMARY KEY,
+    ip         VARCHAR(64) NOT NULL,
+    kind       VARCHAR(16) NOT NULL,
+    created_at BIGINT NOT NULL
+);
+
+CREATE INDEX otp_ip_events_ip_kind_created_at_idx ON otp_ip_events (ip, kind, created_at);
+CREATE INDEX otp_ip_events_created_at_idx ON otp_ip_events (created_at);
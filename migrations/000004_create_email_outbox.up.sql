@@ -0,0 +1,15 @@
+CREATE TABLE email_outbox (
+    id              SERIAL PRIMARY KEY,
+    recipient       VARCHAR(255) NOT NULL,
+    subject         VARCHAR(255) NOT NULL,
+    text_body       TEXT NOT NULL,
+    html_body       TEXT NOT NULL,
+    status          VARCHAR(16) NOT NULL,
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    last_error      TEXT,
+    next_attempt_at BIGINT NOT NULL,
+    created_at      BIGINT NOT NULL,
+    sent_at         BIGINT
+);
+
+CREATE INDEX email_outbox_status_next_attempt_at_idx ON email_outbox (status, next_attempt_at);
@@ -0,0 +1,31 @@
+CREATE TABLE quiz_attempts (
+    id           SERIAL PRIMARY KEY,
+    user_id      INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    set_id       INTEGER NOT NULL REFERENCES sets (id) ON DELETE CASCADE,
+    status       VARCHAR(20) NOT NULL,
+    correct      INTEGER NOT NULL DEFAULT 0,
+    total        INTEGER NOT NULL DEFAULT 0,
+    score        DOUBLE PRECISION NOT NULL DEFAULT 0,
+    started_at   BIGINT NOT NULL,
+    submitted_at BIGINT
+);
+
+CREATE INDEX quiz_attempts_user_id_started_at_idx ON quiz_attempts (user_id, started_at DESC);
+CREATE INDEX quiz_attempts_set_id_idx ON quiz_attempts (set_id);
+
+CREATE TABLE attempt_answers (
+    attempt_id  INTEGER NOT NULL REFERENCES quiz_attempts (id) ON DELETE CASCADE,
+    question_id INTEGER NOT NULL REFERENCES questions (id) ON DELETE CASCADE,
+    answer_id   INTEGER REFERENCES answers (id) ON DELETE SET NULL,
+    is_correct  BOOLEAN NOT NULL DEFAULT false,
+    essay_text  TEXT,
+    points      DOUBLE PRECISION,
+    feedback    TEXT,
+    graded_by   INTEGER REFERENCES users (id) ON DELETE SET NULL,
+    graded_at   BIGINT,
+    PRIMARY KEY (attempt_id, question_id)
+);
+
+-- The essay grading queue only ever looks at ungraded essays.
+CREATE INDEX attempt_answers_pending_essays_idx ON attempt_answers (attempt_id)
+    WHERE essay_text IS NOT NULL AND points IS NULL;
@@ -0,0 +1,16 @@
+CREATE TABLE media (
+    id           SERIAL PRIMARY KEY,
+    user_id      INTEGER REFERENCES users (id) ON DELETE SET NULL,
+    purpose      VARCHAR(16) NOT NULL,
+    storage_key  VARCHAR(255) NOT NULL UNIQUE,
+    thumb_key    VARCHAR(255) NOT NULL,
+    url          TEXT NOT NULL,
+    thumb_url    TEXT NOT NULL,
+    content_type VARCHAR(64) NOT NULL,
+    size         BIGINT NOT NULL,
+    width        INTEGER NOT NULL,
+    height       INTEGER NOT NULL,
+    created_at   BIGINT NOT NULL
+);
+
+CREATE INDEX media_user_id_idx ON media (user_id);
@@ -0,0 +1 @@
+ALTER TABLE questions ADD COLUMN blocks JSONB NOT NULL DEFAULT '[]';
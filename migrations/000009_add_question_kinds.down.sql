@@ -0,0 +1,3 @@
+ALTER TABLE attempt_answers DROP COLUMN IF EXISTS response;
+ALTER TABLE answers DROP COLUMN IF EXISTS position;
+ALTER TABLE answers DROP COLUMN IF EXISTS match_text;
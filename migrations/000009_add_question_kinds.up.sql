@@ -0,0 +1,7 @@
+-- Matching answers pair their content with match_text. Ordering answers hold
+-- their place in the sequence and fill_blank answers their blank in position.
+ALTER TABLE answers ADD COLUMN match_text TEXT;
+ALTER TABLE answers ADD COLUMN position INTEGER;
+
+-- Answers to multiple, matching, ordering and fill_blank questions.
+ALTER TABLE attempt_answers ADD COLUMN response JSONB;
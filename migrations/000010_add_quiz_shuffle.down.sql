@@ -0,0 +1,5 @@
+ALTER TABLE quiz_attempts DROP COLUMN IF EXISTS shuffle_options;
+ALTER TABLE quiz_attempts DROP COLUMN IF EXISTS shuffle_questions;
+ALTER TABLE quiz_attempts DROP COLUMN IF EXISTS seed;
+ALTER TABLE sets DROP COLUMN IF EXISTS shuffle_options;
+ALTER TABLE sets DROP COLUMN IF EXISTS shuffle_questions;
@@ -0,0 +1,8 @@
+ALTER TABLE sets ADD COLUMN shuffle_questions BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE sets ADD COLUMN shuffle_options BOOLEAN NOT NULL DEFAULT false;
+
+-- Each attempt keeps the set's shuffle settings as they were when it started
+-- and the seed its order is drawn from, so reloading shows the same order.
+ALTER TABLE quiz_attempts ADD COLUMN seed BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE quiz_attempts ADD COLUMN shuffle_questions BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE quiz_attempts ADD COLUMN shuffle_options BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS quiz_attempts_expiring_idx;
+ALTER TABLE quiz_attempts DROP COLUMN IF EXISTS deadline;
+ALTER TABLE sets DROP COLUMN IF EXISTS closes_at;
+ALTER TABLE sets DROP COLUMN IF EXISTS opens_at;
+ALTER TABLE sets DROP COLUMN IF EXISTS time_limit;
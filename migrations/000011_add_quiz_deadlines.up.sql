@@ -0,0 +1,12 @@
+-- time_limit is in minutes; opens_at and closes_at are unix seconds. NULL
+-- means no limit.
+ALTER TABLE sets ADD COLUMN time_limit INTEGER;
+ALTER TABLE sets ADD COLUMN opens_at BIGINT;
+ALTER TABLE sets ADD COLUMN closes_at BIGINT;
+
+-- The deadline is fixed when the attempt starts: the time limit or the set's
+-- closing time, whichever comes first.
+ALTER TABLE quiz_attempts ADD COLUMN deadline BIGINT;
+
+CREATE INDEX quiz_attempts_expiring_idx ON quiz_attempts (deadline)
+    WHERE status = 'in_progress' AND deadline IS NOT NULL;
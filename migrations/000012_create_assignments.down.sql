@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS quiz_attempts_user_id_set_id_idx;
+DROP TABLE IF EXISTS assignment_students;
+DROP TABLE IF EXISTS assignments;
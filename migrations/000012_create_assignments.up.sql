@@ -0,0 +1,21 @@
+CREATE TABLE assignments (
+    id         SERIAL PRIMARY KEY,
+    set_id     INTEGER NOT NULL REFERENCES sets (id) ON DELETE CASCADE,
+    title      VARCHAR(100) NOT NULL,
+    due_at     BIGINT NOT NULL,
+    created_by INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at BIGINT NOT NULL
+);
+
+CREATE INDEX assignments_created_by_idx ON assignments (created_by);
+
+CREATE TABLE assignment_students (
+    assignment_id INTEGER NOT NULL REFERENCES assignments (id) ON DELETE CASCADE,
+    user_id       INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    PRIMARY KEY (assignment_id, user_id)
+);
+
+CREATE INDEX assignment_students_user_id_idx ON assignment_students (user_id);
+
+-- Submission lookups find a student's finished attempts of a set.
+CREATE INDEX quiz_attempts_user_id_set_id_idx ON quiz_attempts (user_id, set_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS assignments_classroom_id_idx;
+ALTER TABLE assignments DROP COLUMN IF EXISTS classroom_id;
+DROP TABLE IF EXISTS classroom_members;
+DROP TABLE IF EXISTS classrooms;
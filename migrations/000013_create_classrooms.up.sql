@@ -0,0 +1,28 @@
+-- A classroom is a teacher's group of students in one school year, e.g. 4B
+-- of SDN 01 in 2026/2027, at a grade from classes.
+CREATE TABLE classrooms (
+    id            SERIAL PRIMARY KEY,
+    name          VARCHAR(50) NOT NULL,
+    class_id      INTEGER NOT NULL REFERENCES classes (id),
+    school        VARCHAR(100) NOT NULL,
+    academic_year VARCHAR(9) NOT NULL,
+    teacher_id    INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    invite_code   VARCHAR(8) NOT NULL,
+    created_at    BIGINT NOT NULL,
+    CONSTRAINT classrooms_invite_code_key UNIQUE (invite_code)
+);
+
+CREATE INDEX classrooms_teacher_id_idx ON classrooms (teacher_id);
+
+CREATE TABLE classroom_members (
+    classroom_id INTEGER NOT NULL REFERENCES classrooms (id) ON DELETE CASCADE,
+    user_id      INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    joined_at    BIGINT NOT NULL,
+    PRIMARY KEY (classroom_id, user_id)
+);
+
+CREATE INDEX classroom_members_user_id_idx ON classroom_members (user_id);
+
+ALTER TABLE assignments ADD COLUMN classroom_id INTEGER REFERENCES classrooms (id) ON DELETE SET NULL;
+
+CREATE INDEX assignments_classroom_id_idx ON assignments (classroom_id);
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS questions_owner_id_idx;
+DROP INDEX IF EXISTS sets_owner_id_idx;
+ALTER TABLE questions DROP COLUMN IF EXISTS owner_id;
+ALTER TABLE sets DROP COLUMN IF EXISTS is_shared;
+ALTER TABLE sets DROP COLUMN IF EXISTS owner_id;
+
+ALTER TABLE users ADD COLUMN is_admin BOOLEAN NOT NULL DEFAULT false;
+UPDATE users SET is_admin = true WHERE role <> 'student';
+ALTER TABLE users DROP COLUMN role;
@@ -0,0 +1,15 @@
+-- Admins could do everything so far and keep that as super admins; teachers
+-- are invited or promoted from here on.
+ALTER TABLE users ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'student'
+    CHECK (role IN ('student', 'teacher', 'school_admin', 'super_admin'));
+UPDATE users SET role = 'super_admin' WHERE is_admin;
+ALTER TABLE users DROP COLUMN is_admin;
+
+-- Content from before ownership has no owner and is left to admins. A shared
+-- set and its questions can be edited by every teacher.
+ALTER TABLE sets ADD COLUMN owner_id INTEGER REFERENCES users (id) ON DELETE SET NULL;
+ALTER TABLE sets ADD COLUMN is_shared BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE questions ADD COLUMN owner_id INTEGER REFERENCES users (id) ON DELETE SET NULL;
+
+CREATE INDEX sets_owner_id_idx ON sets (owner_id);
+CREATE INDEX questions_owner_id_idx ON questions (owner_id);
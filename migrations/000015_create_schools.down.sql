@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS questions_school_id_idx;
+DROP INDEX IF EXISTS sets_school_id_idx;
+DROP INDEX IF EXISTS classrooms_school_id_idx;
+DROP INDEX IF EXISTS users_school_id_idx;
+
+ALTER TABLE questions DROP COLUMN IF EXISTS school_id;
+ALTER TABLE sets DROP COLUMN IF EXISTS school_id;
+ALTER TABLE classrooms DROP COLUMN IF EXISTS school_id;
+ALTER TABLE users DROP COLUMN IF EXISTS school_id;
+
+DROP TABLE IF EXISTS schools;
@@ -0,0 +1,29 @@
+-- A school is a tenant: its users, classrooms and content are only seen from
+-- within it. Super admins belong to no school and see all of them; content
+-- without a school, such as the seeded catalog, is shared by every school.
+CREATE TABLE schools (
+    id         SERIAL PRIMARY KEY,
+    name       VARCHAR(100) NOT NULL,
+    created_at BIGINT NOT NULL DEFAULT EXTRACT(EPOCH FROM NOW())::BIGINT
+);
+
+-- Everyone but the super admins was in one school so far.
+INSERT INTO schools (name) VALUES ('Default School');
+
+ALTER TABLE users ADD COLUMN school_id INTEGER REFERENCES schools (id);
+UPDATE users SET school_id = (SELECT MIN(id) FROM schools) WHERE role <> 'super_admin';
+
+ALTER TABLE classrooms ADD COLUMN school_id INTEGER REFERENCES schools (id);
+UPDATE classrooms r SET school_id = u.school_id FROM users u WHERE u.id = r.teacher_id;
+
+-- Sets and questions take the school of their owner and set; those from
+-- before ownership stay shared.
+ALTER TABLE sets ADD COLUMN school_id INTEGER REFERENCES schools (id);
+UPDATE sets s SET school_id = u.school_id FROM users u WHERE u.id = s.owner_id;
+ALTER TABLE questions ADD COLUMN school_id INTEGER REFERENCES schools (id);
+UPDATE questions q SET school_id = s.school_id FROM sets s WHERE s.id = q.set_id;
+
+CREATE INDEX users_school_id_idx ON users (school_id);
+CREATE INDEX classrooms_school_id_idx ON classrooms (school_id);
+CREATE INDEX sets_school_id_idx ON sets (school_id);
+CREATE INDEX questions_school_id_idx ON questions (school_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS email_outbox_created_at_idx;
+ALTER TABLE email_outbox DROP COLUMN IF EXISTS sensitive;
@@ -0,0 +1,6 @@
+-- Mails carrying secrets, such as one-time codes, lose their bodies once they
+-- are sent or dead-lettered. Sent mails never need their bodies again.
+ALTER TABLE email_outbox ADD COLUMN sensitive BOOLEAN NOT NULL DEFAULT false;
+UPDATE email_outbox SET text_body = '', html_body = '' WHERE status = 'sent';
+
+CREATE INDEX email_outbox_created_at_idx ON email_outbox (created_at) WHERE status <> 'pending';
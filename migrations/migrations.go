@@ -0,0 +1,9 @@
+// Package migrations embeds the versioned SQL schema migrations. Files are
+// named <version>_<name>.up.sql and <version>_<name>.down.sql and are applied
+// by pkg/migrate in version order.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/ghulammuzz/misterblast/pkg/log"
+)
+
+// Cache stores raw values under string keys until their ttl passes.
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// Namespaces of the cached catalog reads.
+const (
+	NamespaceClass    = "class"
+	NamespaceLesson   = "lesson"
+	NamespaceSet      = "set"
+	NamespaceQuestion = "question"
+)
+
+// DefaultTTL bounds how long a cached read may outlive a missed invalidation.
+const DefaultTTL = 10 * time.Minute
+
+// Namespace groups cached reads of one kind of content so they can be dropped
+// together. Every key embeds the namespace generation; Invalidate bumps the
+// generation, which orphans the old entries until they expire or are evicted.
+type Namespace struct {
+	cache Cache
+	name  string
+	ttl   time.Duration
+}
+
+func NewNamespace(c Cache, name string, ttl time.Duration) *Namespace {
+	return &Namespace{cache: c, name: name, ttl: ttl}
+}
+
+func (n *Namespace) generationKey() string {
+	return n.name + ":gen"
+}
+
+func (n *Namespace) key(ctx context.Context, key string) (string, error) {
+	gen, ok, err := n.cache.Get(ctx, n.generationKey())
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		// A lost generation starts a fresh one rather than falling back to a
+		// default, which could resurrect entries from before an invalidation.
+		if gen, err = n.bump(ctx); err != nil {
+			return "", err
+		}
+	}
+	return n.name + ":" + string(gen) + ":" + key, nil
+}
+
+func (n *Namespace) bump(ctx context.Context) ([]byte, error) {
+	gen := []byte(strconv.FormatInt(time.Now().UnixNano(), 10))
+	// The generation must outlive the entries it guards.
+	return gen, n.cache.Set(ctx, n.generationKey(), gen, 2*n.ttl)
+}
+
+// Invalidate drops every entry cached in the namespace so far.
+func (n *Namespace) Invalidate(ctx context.Context) error {
+	_, err := n.bump(ctx)
+	return err
+}
+
+// Remember returns the cached value of key in n, calling load and caching its
+// result on a miss. Cache failures are logged and fall back to load so that a
+// broken cache never fails a read.
+func Remember[T any](ctx context.Context, n *Namespace, key string, load func() (T, error)) (T, error) {
+	fullKey, err := n.key(ctx, key)
+	if err != nil {
+		log.Error("[Cache][Remember] Error Get generation: ", err)
+		return load()
+	}
+
+	raw, ok, err := n.cache.Get(ctx, fullKey)
+	if err != nil {
+		log.Error("[Cache][Remember] Error Get: ", err)
+	}
+	if ok {
+		var value T
+		if err := json.Unmarshal(raw, &value); err == nil {
+			return value, nil
+		}
+	}
+
+	value, err := load()
+	if err != nil {
+		return value, err
+	}
+
+	if raw, err := json.Marshal(value); err != nil {
+		log.Error("[Cache][Remember] Error Marshal: ", err)
+	} else if err := n.cache.Set(ctx, fullKey, raw, n.ttl); err != nil {
+		log.Error("[Cache][Remember] Error Set: ", err)
+	}
+
+	return value, nil
+}
+
+// Invalidate drops the given namespaces, logging instead of failing so that a
+// write which already reached the database is still reported as a success.
+func Invalidate(ctx context.Context, namespaces ...*Namespace) {
+	for _, n := range namespaces {
+		if err := n.Invalidate(ctx); err != nil {
+			log.Error("[Cache][Invalidate] Error Set generation: ", err)
+		}
+	}
+}
+
+// FilterKey turns a list filter into a stable key part.
+func FilterKey(filter map[string]string) string {
+	values := url.Values{}
+	for k, v := range filter {
+		values.Set(k, v)
+	}
+	return values.Encode()
+}
+
+// ScopeKey turns the school a read is confined to into a key part, so that
+// schools never share cached results. nil is the unscoped read of super
+// admins.
+func ScopeKey(schoolID *int32) string {
+	if schoolID == nil {
+		return "all"
+	}
+	return "school:" + strconv.Itoa(int(*schoolID))
+}
@@ -0,0 +1,89 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ghulammuzz/misterblast/pkg/cache"
+)
+
+func TestLRU_EvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	c := cache.NewLRU(2)
+
+	require.NoError(t, c.Set(ctx, "a", []byte("1"), time.Minute))
+	require.NoError(t, c.Set(ctx, "b", []byte("2"), time.Minute))
+	_, _, _ = c.Get(ctx, "a")
+	require.NoError(t, c.Set(ctx, "c", []byte("3"), time.Minute))
+
+	_, ok, _ := c.Get(ctx, "b")
+	assert.False(t, ok, "b was least recently used")
+	value, ok, _ := c.Get(ctx, "a")
+	assert.True(t, ok)
+	assert.Equal(t, "1", string(value))
+}
+
+func TestLRU_Expires(t *testing.T) {
+	ctx := context.Background()
+	c := cache.NewLRU(10)
+
+	require.NoError(t, c.Set(ctx, "a", []byte("1"), 20*time.Millisecond))
+	time.Sleep(30 * time.Millisecond)
+
+	_, ok, _ := c.Get(ctx, "a")
+	assert.False(t, ok)
+}
+
+func TestRemember(t *testing.T) {
+	ctx := context.Background()
+	ns := cache.NewNamespace(cache.NewLRU(10), cache.NamespaceSet, time.Minute)
+
+	calls := 0
+	load := func() ([]string, error) {
+		calls++
+		return []string{"set"}, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		value, err := cache.Remember(ctx, ns, "list", load)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"set"}, value)
+	}
+	assert.Equal(t, 1, calls)
+
+	cache.Invalidate(ctx, ns)
+	_, err := cache.Remember(ctx, ns, "list", load)
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestRemember_ErrorNotCached(t *testing.T) {
+	ctx := context.Background()
+	ns := cache.NewNamespace(cache.NewLRU(10), cache.NamespaceSet, time.Minute)
+
+	_, err := cache.Remember(ctx, ns, "list", func() ([]string, error) { return nil, errors.New("db down") })
+	assert.Error(t, err)
+
+	value, err := cache.Remember(ctx, ns, "list", func() ([]string, error) { return []string{"set"}, nil })
+	require.NoError(t, err)
+	assert.Equal(t, []string{"set"}, value)
+}
+
+func TestFilterKey(t *testing.T) {
+	a := cache.FilterKey(map[string]string{"lesson": "math", "class": "4"})
+	b := cache.FilterKey(map[string]string{"class": "4", "lesson": "math"})
+	assert.Equal(t, a, b)
+	assert.NotEqual(t, cache.FilterKey(map[string]string{"a": "1&b=2"}), cache.FilterKey(map[string]string{"a": "1", "b": "2"}))
+}
+
+func TestScopeKey(t *testing.T) {
+	a, b := int32(1), int32(2)
+	assert.NotEqual(t, cache.ScopeKey(&a), cache.ScopeKey(&b))
+	assert.NotEqual(t, cache.ScopeKey(nil), cache.ScopeKey(&a))
+	assert.Equal(t, cache.ScopeKey(&a), cache.ScopeKey(&a))
+}
@@ -1,6 +1,10 @@
 package jwt
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"os"
 	"time"
 
@@ -8,17 +12,59 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+const (
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 7 * 24 * time.Hour
+)
+
 var jwtSecret = []byte(os.Getenv("JWT_SECRET"))
 
-func GenerateJWT(userResult entity.UserJWT) (string, error) {
+// GenerateJWT signs a short-lived access token and returns it with its expiry.
+// The jti claim lets the token be revoked before it expires.
+func GenerateJWT(userResult entity.UserJWT) (string, int64, error) {
+	jti, err := randomToken(16)
+	if err != nil {
+		return "", 0, err
+	}
+
+	expiresAt := time.Now().Add(AccessTokenTTL).Unix()
 	claims := jwt.MapClaims{
-		"apps":     "misterblast-core",
-		"email":    userResult.Email,
-		"user_id":  userResult.ID,
-		"is_admin": userResult.IsAdmin,
-		"exp":      time.Now().Add(time.Hour * 24 * 7).Unix(),
+		"apps":      "misterblast-core",
+		"email":     userResult.Email,
+		"user_id":   userResult.ID,
+		"role":      userResult.Role,
+		"school_id": userResult.SchoolID,
+		"jti":       jti,
+		"exp":       expiresAt,
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	signed, err := token.SignedString(jwtSecret)
+	if err != nil {
+		return "", 0, err
+	}
+	return signed, expiresAt, nil
+}
+
+// GenerateRefreshToken returns an opaque random refresh token. Only its hash
+// is ever stored server-side.
+func GenerateRefreshToken() (string, int64, error) {
+	token, err := randomToken(32)
+	if err != nil {
+		return "", 0, err
+	}
+	return token, time.Now().Add(RefreshTokenTTL).Unix(), nil
+}
+
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomToken(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
 }
@@ -0,0 +1,37 @@
+package mailer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+type fileMailer struct {
+	dir  string
+	from string
+	seq  atomic.Uint64
+}
+
+// NewFileMailer writes every message as an .eml file into dir instead of
+// delivering it. Meant for local development and tests.
+func NewFileMailer(dir, from string) (Mailer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create outbox dir: %w", err)
+	}
+	return &fileMailer{dir: dir, from: from}, nil
+}
+
+func (m *fileMailer) Send(msg Message) error {
+	data, err := build(m.from, msg)
+	if err != nil {
+		return fmt.Errorf("build message: %w", err)
+	}
+
+	recipient := strings.NewReplacer("@", "_at_", "/", "_", "\\", "_").Replace(msg.To)
+	name := fmt.Sprintf("%d-%d-%s.eml", time.Now().UnixNano(), m.seq.Add(1), recipient)
+
+	return os.WriteFile(filepath.Join(m.dir, name), data, 0o644)
+}
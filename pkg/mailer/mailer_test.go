@@ -0,0 +1,59 @@
+package mailer_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ghulammuzz/misterblast/pkg/mailer"
+)
+
+func TestRender(t *testing.T) {
+	for _, name := range []string{mailer.TemplateOTP, mailer.TemplatePasswordReset} {
+		for _, lang := range []string{mailer.LangID, mailer.LangEN} {
+			msg, err := mailer.Render(name, lang, mailer.OTPData{Code: "042137", Minutes: 10})
+			require.NoError(t, err, name+"/"+lang)
+			assert.NotEmpty(t, msg.Subject)
+			assert.Contains(t, msg.Text, "042137")
+			assert.Contains(t, msg.HTML, "042137")
+			assert.Contains(t, msg.HTML, `<html lang="`+lang+`">`)
+		}
+	}
+
+	msg, err := mailer.Render(mailer.TemplateAdminInvitation, "fr", mailer.InvitationData{Name: "<b>Budi</b>", Email: "budi@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "Undangan Admin Misterblast", msg.Subject)
+	assert.Contains(t, msg.HTML, "&lt;b&gt;Budi&lt;/b&gt;")
+}
+
+func TestLangFromHeader(t *testing.T) {
+	assert.Equal(t, mailer.LangID, mailer.LangFromHeader(""))
+	assert.Equal(t, mailer.LangEN, mailer.LangFromHeader("en-US,en;q=0.9,id;q=0.8"))
+	assert.Equal(t, mailer.LangID, mailer.LangFromHeader("id-ID,id;q=0.9,en;q=0.8"))
+	assert.Equal(t, mailer.LangID, mailer.LangFromHeader("fr-FR"))
+}
+
+func TestFileMailer(t *testing.T) {
+	dir := t.TempDir()
+	m, err := mailer.NewFileMailer(dir, "noreply@misterblast.id")
+	require.NoError(t, err)
+
+	err = m.Send(mailer.Message{To: "siswa@example.com", Subject: "Kode Verifikasi", Text: "kode: 123456", HTML: "<p>123456</p>"})
+	require.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	data, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	content := string(data)
+	assert.True(t, strings.HasPrefix(content, "From: noreply@misterblast.id\r\n"))
+	assert.Contains(t, content, "To: siswa@example.com")
+	assert.Contains(t, content, "multipart/alternative")
+	assert.Contains(t, content, "text/html; charset=utf-8")
+}
@@ -0,0 +1,91 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+const (
+	LangID = "id"
+	LangEN = "en"
+
+	TemplateOTP             = "otp"
+	TemplatePasswordReset   = "password_reset"
+	TemplateAdminInvitation = "admin_invitation"
+)
+
+type OTPData struct {
+	Code    string
+	Minutes int
+}
+
+type InvitationData struct {
+	Name  string
+	Email string
+}
+
+//go:embed templates
+var templateFS embed.FS
+
+// Render builds the subject and both bodies of a templated mail. Each template
+// has a <lang>/<name>.txt file defining "subject" plus the text body and a
+// <lang>/<name>.html file defining "content" for the shared HTML layout.
+func Render(name, lang string, data interface{}) (Message, error) {
+	if lang != LangEN {
+		lang = LangID
+	}
+	base := fmt.Sprintf("templates/%s/%s", lang, name)
+
+	text, err := texttemplate.ParseFS(templateFS, base+".txt")
+	if err != nil {
+		return Message{}, fmt.Errorf("parse text template %s: %w", base, err)
+	}
+
+	var subject, body bytes.Buffer
+	if err := text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Message{}, fmt.Errorf("render subject %s: %w", base, err)
+	}
+	if err := text.Execute(&body, data); err != nil {
+		return Message{}, fmt.Errorf("render text %s: %w", base, err)
+	}
+
+	html, err := htmltemplate.ParseFS(templateFS, "templates/layout.html", base+".html")
+	if err != nil {
+		return Message{}, fmt.Errorf("parse html template %s: %w", base, err)
+	}
+
+	var page bytes.Buffer
+	layout := struct {
+		Lang    string
+		Subject string
+		Data    interface{}
+	}{lang, subject.String(), data}
+	if err := html.ExecuteTemplate(&page, "layout", layout); err != nil {
+		return Message{}, fmt.Errorf("render html %s: %w", base, err)
+	}
+
+	return Message{
+		Subject: subject.String(),
+		Text:    strings.TrimSpace(body.String()) + "\n",
+		HTML:    page.String(),
+	}, nil
+}
+
+// LangFromHeader picks the mail language from an Accept-Language header.
+// Indonesian is the default; English is used when it is preferred over it.
+func LangFromHeader(header string) string {
+	for _, part := range strings.Split(header, ",") {
+		tag := strings.ToLower(strings.TrimSpace(strings.SplitN(part, ";", 2)[0]))
+		switch {
+		case strings.HasPrefix(tag, LangID):
+			return LangID
+		case strings.HasPrefix(tag, LangEN):
+			return LangEN
+		}
+	}
+	return LangID
+}
@@ -4,34 +4,116 @@ import (
 	"os"
 	"strings"
 
+	"github.com/ghulammuzz/misterblast/pkg/log"
 	"github.com/ghulammuzz/misterblast/pkg/response"
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
 )
 
+const (
+	AccessTokenCookie  = "token"
+	RefreshTokenCookie = "refresh_token"
+)
+
+// RevocationList reports whether an access token id (jti) was revoked before
+// its expiry, e.g. on logout. It must be backed by storage every instance
+// shares, such as the revoked_tokens table, or a token revoked on one instance
+// stays valid on the others.
+type RevocationList interface {
+	IsRevoked(jti string) (bool, error)
+}
+
+var revocationList RevocationList
+
+// SetRevocationList makes JWTProtected reject revoked tokens. Without one,
+// tokens are only checked for signature and expiry.
+func SetRevocationList(list RevocationList) {
+	revocationList = list
+}
+
+// TokenFromRequest reads the bearer token from the Authorization header and
+// falls back to the access token cookie set on login.
+func TokenFromRequest(c *fiber.Ctx) string {
+	if header := c.Get("Authorization"); header != "" {
+		return strings.TrimPrefix(header, "Bearer ")
+	}
+	return c.Cookies(AccessTokenCookie)
+}
+
+func parseToken(tokenString string, options ...jwt.ParserOption) (*jwt.Token, error) {
+	return jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fiber.ErrUnauthorized
+		}
+		return []byte(os.Getenv("JWT_SECRET")), nil
+	}, options...)
+}
+
+// SessionClaims returns the claims of the request's token after checking its
+// signature but not its expiry, so that a session can still be ended once
+// the access token has run out.
+func SessionClaims(c *fiber.Ctx) (jwt.MapClaims, bool) {
+	tokenString := TokenFromRequest(c)
+	if tokenString == "" {
+		return nil, false
+	}
+
+	token, err := parseToken(tokenString, jwt.WithoutClaimsValidation())
+	if err != nil || !token.Valid {
+		return nil, false
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	return claims, ok
+}
+
 func JWTProtected() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		tokenString := c.Get("Authorization")
+		tokenString := TokenFromRequest(c)
 		if tokenString == "" {
 			return response.SendError(c, 401, "Unauthorized", "token not found")
 
 		}
 
-		tokenString = strings.TrimPrefix(tokenString, "Bearer ")
-
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fiber.ErrUnauthorized
-			}
-			return []byte(os.Getenv("JWT_SECRET")), nil
-		})
+		token, err := parseToken(tokenString)
 
 		if err != nil || !token.Valid {
 			return response.SendError(c, 401, "Unauthorized", err.Error())
 		}
 
+		// Tokens without a jti cannot be revoked, so they are not accepted.
+		claims, _ := token.Claims.(jwt.MapClaims)
+		jti, ok := claims["jti"].(string)
+		if !ok || jti == "" {
+			return response.SendError(c, 401, "Unauthorized", "token not revocable")
+		}
+
+		if revocationList != nil {
+			revoked, err := revocationList.IsRevoked(jti)
+			if err != nil {
+				log.Error("[Middleware][JWTProtected] Error IsRevoked: ", err)
+				return response.SendError(c, 500, "Internal Server Error", "failed to verify token")
+			}
+			if revoked {
+				return response.SendError(c, 401, "Unauthorized", "token revoked")
+			}
+		}
+
 		c.Locals("user", token)
 
 		return c.Next()
 	}
 }
+
+// JWTOptional stores the token like JWTProtected when the request carries one
+// and otherwise lets it through anonymously. It serves public routes whose
+// results depend on the caller's school.
+func JWTOptional() fiber.Handler {
+	protected := JWTProtected()
+	return func(c *fiber.Ctx) error {
+		if TokenFromRequest(c) == "" {
+			return c.Next()
+		}
+		return protected(c)
+	}
+}
@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ghulammuzz/misterblast/pkg/log"
+	"github.com/ghulammuzz/misterblast/pkg/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Limiter decides whether one more hit on key fits into a sliding window of
+// at most limit hits. When it does not, retryAfter tells when the oldest hit
+// leaves the window.
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (allowed bool, remaining int, retryAfter time.Duration, err error)
+}
+
+var rateLimiter Limiter = NewMemoryLimiter()
+
+// SetRateLimiter replaces the limiter used by RateLimit. The default is an
+// in-process MemoryLimiter.
+func SetRateLimiter(limiter Limiter) {
+	rateLimiter = limiter
+}
+
+type RateLimitConfig struct {
+	// Name separates the counters of different route groups.
+	Name   string
+	Limit  int
+	Window time.Duration
+	// Key identifies the caller, defaulting to the client IP.
+	Key func(c *fiber.Ctx) string
+}
+
+// RateLimit rejects requests with 429 once the caller exceeded cfg.Limit hits
+// within cfg.Window. Limiter errors are logged and the request is let through.
+func RateLimit(cfg RateLimitConfig) fiber.Handler {
+	key := cfg.Key
+	if key == nil {
+		key = func(c *fiber.Ctx) string { return c.IP() }
+	}
+
+	return func(c *fiber.Ctx) error {
+		allowed, remaining, retryAfter, err := rateLimiter.Allow(c.UserContext(), "ratelimit:"+cfg.Name+":"+key(c), cfg.Limit, cfg.Window)
+		if err != nil {
+			log.Error("[Middleware][RateLimit] Error Allow: ", err)
+			return c.Next()
+		}
+
+		c.Set("X-RateLimit-Limit", strconv.Itoa(cfg.Limit))
+		c.Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+
+		if !allowed {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			return response.SendError(c, fiber.StatusTooManyRequests, "Too Many Requests", "rate limit exceeded")
+		}
+
+		return c.Next()
+	}
+}
+
+// MemoryLimiter keeps the hit timestamps of every key in process memory. It is
+// used in tests, for single instance deployments and while Redis is down.
+type MemoryLimiter struct {
+	mu        sync.Mutex
+	hits      map[string][]time.Time
+	lastSweep time.Time
+	maxWindow time.Duration
+	now       func() time.Time
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{hits: make(map[string][]time.Time), now: time.Now}
+}
+
+func (l *MemoryLimiter) Allow(_ context.Context, key string, limit int, window time.Duration) (bool, int, time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if window > l.maxWindow {
+		l.maxWindow = window
+	}
+	l.sweep(now)
+
+	hits := prune(l.hits[key], now.Add(-window))
+	if len(hits) >= limit {
+		l.hits[key] = hits
+		return false, 0, hits[0].Add(window).Sub(now), nil
+	}
+
+	l.hits[key] = append(hits, now)
+	return true, limit - len(hits) - 1, 0, nil
+}
+
+// sweep drops keys idle for longer than the largest window in use, at most
+// once per such window, so the map does not grow with every client ever seen.
+func (l *MemoryLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.maxWindow {
+		return
+	}
+	l.lastSweep = now
+
+	for key, hits := range l.hits {
+		if len(hits) == 0 || !hits[len(hits)-1].After(now.Add(-l.maxWindow)) {
+			delete(l.hits, key)
+		}
+	}
+}
+
+func prune(hits []time.Time, since time.Time) []time.Time {
+	i := 0
+	for i < len(hits) && !hits[i].After(since) {
+		i++
+	}
+	return hits[i:]
+}
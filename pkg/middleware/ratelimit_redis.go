@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/ghulammuzz/misterblast/pkg/log"
+	"github.com/redis/go-redis/v9"
+)
+
+// slidingWindow keeps one sorted set member per hit, scored by its time in
+// milliseconds, and only adds the new hit when it fits into the window.
+var slidingWindow = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+if count >= limit then
+	local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+	return {0, 0, tonumber(oldest[2]) + window - now}
+end
+
+redis.call('ZADD', KEYS[1], now, ARGV[4])
+redis.call('PEXPIRE', KEYS[1], window)
+return {1, limit - count - 1, 0}
+`)
+
+// RedisLimiter shares the sliding window between all instances of the app.
+// While Redis is unreachable it answers from an in-process fallback.
+type RedisLimiter struct {
+	client   *redis.Client
+	fallback Limiter
+	seq      atomic.Uint64
+}
+
+func NewRedisLimiter(client *redis.Client, fallback Limiter) *RedisLimiter {
+	return &RedisLimiter{client: client, fallback: fallback}
+}
+
+func (l *RedisLimiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, int, time.Duration, error) {
+	now := time.Now().UnixMilli()
+	member := fmt.Sprintf("%d-%d", now, l.seq.Add(1))
+
+	res, err := slidingWindow.Run(ctx, l.client, []string{key}, now, window.Milliseconds(), limit, member).Int64Slice()
+	if err != nil {
+		log.Error("[Middleware][RedisLimiter] Error Run, using fallback: ", err)
+		return l.fallback.Allow(ctx, key, limit, window)
+	}
+
+	return res[0] == 1, int(res[1]), time.Duration(res[2]) * time.Millisecond, nil
+}
@@ -0,0 +1,74 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ghulammuzz/misterblast/pkg/middleware"
+)
+
+func TestMemoryLimiter_SlidingWindow(t *testing.T) {
+	limiter := middleware.NewMemoryLimiter()
+	ctx := context.Background()
+	window := 100 * time.Millisecond
+
+	for i := 0; i < 3; i++ {
+		allowed, remaining, _, err := limiter.Allow(ctx, "k", 3, window)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+		assert.Equal(t, 2-i, remaining)
+	}
+
+	allowed, _, retryAfter, err := limiter.Allow(ctx, "k", 3, window)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.True(t, retryAfter > 0 && retryAfter <= window)
+
+	allowed, _, _, _ = limiter.Allow(ctx, "other", 3, window)
+	assert.True(t, allowed, "keys are limited independently")
+
+	time.Sleep(window)
+	allowed, _, _, _ = limiter.Allow(ctx, "k", 3, window)
+	assert.True(t, allowed, "hits leave the window")
+}
+
+func TestRateLimit(t *testing.T) {
+	middleware.SetRateLimiter(middleware.NewMemoryLimiter())
+
+	app := fiber.New()
+	app.Post("/login", middleware.RateLimit(middleware.RateLimitConfig{Name: "login", Limit: 2, Window: time.Minute}),
+		func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+
+	for i := 0; i < 2; i++ {
+		resp, _ := app.Test(httptest.NewRequest(http.MethodPost, "/login", nil))
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodPost, "/login", nil))
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "2", resp.Header.Get("X-RateLimit-Limit"))
+	assert.Equal(t, "0", resp.Header.Get("X-RateLimit-Remaining"))
+	assert.NotEmpty(t, resp.Header.Get("Retry-After"))
+}
+
+func TestRedisLimiter_FallbackWhenDown(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", DialTimeout: 100 * time.Millisecond, MaxRetries: -1})
+	defer client.Close()
+	limiter := middleware.NewRedisLimiter(client, middleware.NewMemoryLimiter())
+
+	allowed, _, _, err := limiter.Allow(context.Background(), "k", 1, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, _, _, err = limiter.Allow(context.Background(), "k", 1, time.Minute)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"github.com/ghulammuzz/misterblast/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Roles as issued in the role claim. Teachers author content and run
+// classes; school and super admins additionally manage accounts and may
+// change anyone's content.
+const (
+	RoleStudent     = "student"
+	RoleTeacher     = "teacher"
+	RoleSchoolAdmin = "school_admin"
+	RoleSuperAdmin  = "super_admin"
+)
+
+var (
+	// AllRoles lets every signed-in user through.
+	AllRoles = []string{RoleStudent, RoleTeacher, RoleSchoolAdmin, RoleSuperAdmin}
+	// StaffRoles may author content and manage classes.
+	StaffRoles = []string{RoleTeacher, RoleSchoolAdmin, RoleSuperAdmin}
+	// AdminRoles may manage accounts and everyone's content.
+	AdminRoles = []string{RoleSchoolAdmin, RoleSuperAdmin}
+)
+
+// Claims returns the claims of the token stored by JWTProtected.
+func Claims(c *fiber.Ctx) (jwt.MapClaims, bool) {
+	token, ok := c.Locals("user").(*jwt.Token)
+	if !ok || !token.Valid {
+		return nil, false
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	return claims, ok
+}
+
+func UserID(c *fiber.Ctx) (int32, bool) {
+	claims, ok := Claims(c)
+	if !ok {
+		return 0, false
+	}
+
+	userID, ok := claims["user_id"].(float64)
+	if !ok {
+		return 0, false
+	}
+
+	return int32(userID), true
+}
+
+func Role(c *fiber.Ctx) string {
+	claims, ok := Claims(c)
+	if !ok {
+		return ""
+	}
+
+	role, _ := claims["role"].(string)
+	return role
+}
+
+// SchoolID returns the school whose data the caller is confined to. Super
+// admins work across schools and get nil. Callers without a school, whether
+// anonymous or not yet placed in one, get 0, which matches no school and
+// leaves them the shared content only.
+func SchoolID(c *fiber.Ctx) *int32 {
+	if Role(c) == RoleSuperAdmin {
+		return nil
+	}
+
+	var schoolID int32
+	if claims, ok := Claims(c); ok {
+		if id, ok := claims["school_id"].(float64); ok {
+			schoolID = int32(id)
+		}
+	}
+	return &schoolID
+}
+
+// IsStaff reports whether the caller may author content.
+func IsStaff(c *fiber.Ctx) bool {
+	return Role(c) == RoleTeacher || IsAdmin(c)
+}
+
+// IsAdmin reports whether the caller is a school or super admin.
+func IsAdmin(c *fiber.Ctx) bool {
+	role := Role(c)
+	return role == RoleSchoolAdmin || role == RoleSuperAdmin
+}
+
+// RequireRole only lets the request through when the caller has one of the
+// given roles. It must be chained after JWTProtected.
+func RequireRole(roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if _, ok := Claims(c); !ok {
+			return response.SendError(c, fiber.StatusUnauthorized, "Unauthorized", "token not found")
+		}
+
+		role := Role(c)
+		for _, allowed := range roles {
+			if role == allowed {
+				return c.Next()
+			}
+		}
+
+		return response.SendError(c, fiber.StatusForbidden, "Forbidden", "insufficient role")
+	}
+}
+
+// SelfOrAdmin lets admins through and otherwise requires the route parameter
+// to match the caller's own user id. It must be chained after JWTProtected.
+func SelfOrAdmin(param string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID, ok := UserID(c)
+		if !ok {
+			return response.SendError(c, fiber.StatusUnauthorized, "Unauthorized", "token not found")
+		}
+
+		if IsAdmin(c) {
+			return c.Next()
+		}
+
+		id, err := c.ParamsInt(param)
+		if err != nil || int32(id) != userID {
+			return response.SendError(c, fiber.StatusForbidden, "Forbidden", "access restricted to own account")
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,283 @@
+// Package migrate applies versioned SQL migrations to Postgres. The current
+// version is kept in a single row of schema_migrations together with a dirty
+// flag that is set when a migration fails and must be cleared with Force.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/ghulammuzz/misterblast/pkg/log"
+)
+
+// lockID keys the advisory lock that stops two instances from migrating at
+// the same time.
+const lockID = 7264381
+
+var fileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type Status struct {
+	Version int64
+	Dirty   bool
+	Applied []Migration
+	Pending []Migration
+}
+
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// New reads the migrations in fsys. Every version needs both an up and a down
+// file.
+func New(db *sql.DB, fsys fs.FS) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Load parses the migration files in fsys, ordered by version.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		body, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// Up applies up to steps pending migrations, all of them when steps <= 0.
+func (m *Migrator) Up(ctx context.Context, steps int) error {
+	return m.locked(ctx, func(conn *sql.Conn, version int64) error {
+		applied := 0
+		for _, migration := range m.migrations {
+			if migration.Version <= version {
+				continue
+			}
+			if steps > 0 && applied == steps {
+				break
+			}
+			if err := m.apply(ctx, conn, migration.Version, migration.Name, "up", migration.Up, migration.Version); err != nil {
+				return err
+			}
+			applied++
+		}
+		if applied == 0 {
+			log.Info("[Migrate] No pending migrations")
+		}
+		return nil
+	})
+}
+
+// Down reverts the last steps applied migrations, one when steps <= 0.
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	if steps <= 0 {
+		steps = 1
+	}
+
+	return m.locked(ctx, func(conn *sql.Conn, version int64) error {
+		for i := len(m.migrations) - 1; i >= 0 && steps > 0; i-- {
+			migration := m.migrations[i]
+			if migration.Version > version {
+				continue
+			}
+
+			var previous int64
+			if i > 0 {
+				previous = m.migrations[i-1].Version
+			}
+			if err := m.apply(ctx, conn, migration.Version, migration.Name, "down", migration.Down, previous); err != nil {
+				return err
+			}
+			version = previous
+			steps--
+		}
+		return nil
+	})
+}
+
+// Force sets the recorded version without running any migration and clears
+// the dirty flag, after the schema was repaired by hand.
+func (m *Migrator) Force(ctx context.Context, version int64) error {
+	conn, err := m.conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := setVersion(ctx, conn, version, false); err != nil {
+		return err
+	}
+	log.Info(fmt.Sprintf("[Migrate] Forced version %d", version))
+	return nil
+}
+
+func (m *Migrator) Status(ctx context.Context) (Status, error) {
+	conn, err := m.conn(ctx)
+	if err != nil {
+		return Status{}, err
+	}
+	defer conn.Close()
+
+	version, dirty, err := currentVersion(ctx, conn)
+	if err != nil {
+		return Status{}, err
+	}
+
+	status := Status{Version: version, Dirty: dirty}
+	for _, migration := range m.migrations {
+		if migration.Version <= version {
+			status.Applied = append(status.Applied, migration)
+		} else {
+			status.Pending = append(status.Pending, migration)
+		}
+	}
+	return status, nil
+}
+
+// locked runs fn on a single connection holding the migration lock, refusing
+// to touch a dirty schema.
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sql.Conn, version int64) error) error {
+	conn, err := m.conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, lockID); err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, lockID)
+
+	version, dirty, err := currentVersion(ctx, conn)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("schema is dirty at version %d, fix it and run force", version)
+	}
+
+	return fn(conn, version)
+}
+
+// apply runs one migration and records the resulting version in the same
+// transaction. A failure marks the schema dirty at the failed version.
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, version int64, name, direction, body string, result int64) error {
+	log.Info(fmt.Sprintf("[Migrate] Running %d_%s %s", version, name, direction))
+
+	err := func() error {
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		if _, err := tx.ExecContext(ctx, body); err != nil {
+			return err
+		}
+		if err := setVersion(ctx, tx, result, false); err != nil {
+			return err
+		}
+		return tx.Commit()
+	}()
+	if err != nil {
+		if dirtyErr := setVersion(ctx, conn, version, true); dirtyErr != nil {
+			log.Error("[Migrate] Error marking schema dirty: ", dirtyErr)
+		}
+		return fmt.Errorf("migration %d_%s %s: %w", version, name, direction, err)
+	}
+
+	return nil
+}
+
+func (m *Migrator) conn(ctx context.Context) (*sql.Conn, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("open connection: %w", err)
+	}
+
+	query := `CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT NOT NULL, dirty BOOLEAN NOT NULL)`
+	if _, err := conn.ExecContext(ctx, query); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("create schema_migrations: %w", err)
+	}
+	return conn, nil
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func currentVersion(ctx context.Context, conn *sql.Conn) (int64, bool, error) {
+	var version int64
+	var dirty bool
+	err := conn.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("read schema version: %w", err)
+	}
+	return version, dirty, nil
+}
+
+func setVersion(ctx context.Context, db execer, version int64, dirty bool) error {
+	if _, err := db.ExecContext(ctx, `DELETE FROM schema_migrations`); err != nil {
+		return fmt.Errorf("reset schema version: %w", err)
+	}
+	if _, err := db.ExecContext(ctx, `INSERT INTO schema_migrations (version, dirty) VALUES ($1, $2)`, version, dirty); err != nil {
+		return fmt.Errorf("record schema version: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package migrate_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ghulammuzz/misterblast/migrations"
+	"github.com/ghulammuzz/misterblast/pkg/migrate"
+)
+
+var testFS = fstest.MapFS{
+	"000002_add_sets.up.sql":    {Data: []byte("CREATE TABLE sets ();")},
+	"000002_add_sets.down.sql":  {Data: []byte("DROP TABLE sets;")},
+	"000001_add_users.up.sql":   {Data: []byte("CREATE TABLE users ();")},
+	"000001_add_users.down.sql": {Data: []byte("DROP TABLE users;")},
+	"README.md":                 {Data: []byte("ignored")},
+}
+
+func TestLoad(t *testing.T) {
+	list, err := migrate.Load(testFS)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, int64(1), list[0].Version)
+	assert.Equal(t, "add_users", list[0].Name)
+	assert.Equal(t, "DROP TABLE sets;", list[1].Down)
+}
+
+func TestLoad_MissingDown(t *testing.T) {
+	_, err := migrate.Load(fstest.MapFS{"000001_add_users.up.sql": {Data: []byte("CREATE TABLE users ();")}})
+	assert.Error(t, err)
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	list, err := migrate.Load(migrations.FS)
+	require.NoError(t, err)
+	require.NotEmpty(t, list)
+	for i, m := range list {
+		assert.Equal(t, int64(i+1), m.Version, "versions are contiguous")
+	}
+}
+
+func expectConn(mock sqlmock.Sqlmock, version int64, dirty bool) {
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`SELECT pg_advisory_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+	rows := sqlmock.NewRows([]string{"version", "dirty"})
+	if version > 0 {
+		rows.AddRow(version, dirty)
+	}
+	mock.ExpectQuery(`SELECT version, dirty FROM schema_migrations`).WillReturnRows(rows)
+}
+
+func expectApply(mock sqlmock.Sqlmock, body string, version int64) {
+	mock.ExpectBegin()
+	mock.ExpectExec(body).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM schema_migrations`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO schema_migrations`).WithArgs(version, false).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+}
+
+func TestUp(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	migrator, err := migrate.New(db, testFS)
+	require.NoError(t, err)
+
+	expectConn(mock, 1, false)
+	expectApply(mock, `CREATE TABLE sets`, 2)
+	mock.ExpectExec(`SELECT pg_advisory_unlock`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	require.NoError(t, migrator.Up(context.Background(), 0))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUp_FailureMarksDirty(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	migrator, err := migrate.New(db, testFS)
+	require.NoError(t, err)
+
+	expectConn(mock, 0, false)
+	mock.ExpectBegin()
+	mock.ExpectExec(`CREATE TABLE users`).WillReturnError(errors.New("syntax error"))
+	mock.ExpectRollback()
+	mock.ExpectExec(`DELETE FROM schema_migrations`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO schema_migrations`).WithArgs(int64(1), true).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`SELECT pg_advisory_unlock`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = migrator.Up(context.Background(), 0)
+	assert.ErrorContains(t, err, "1_add_users up")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUp_RefusesDirtySchema(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	migrator, err := migrate.New(db, testFS)
+	require.NoError(t, err)
+
+	expectConn(mock, 1, true)
+	mock.ExpectExec(`SELECT pg_advisory_unlock`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.ErrorContains(t, migrator.Up(context.Background(), 0), "dirty")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDown(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	migrator, err := migrate.New(db, testFS)
+	require.NoError(t, err)
+
+	expectConn(mock, 2, false)
+	expectApply(mock, `DROP TABLE sets`, 1)
+	mock.ExpectExec(`SELECT pg_advisory_unlock`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	require.NoError(t, migrator.Down(context.Background(), 1))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,56 @@
+// Package storage keeps uploaded files behind a driver independent interface:
+// the local filesystem for development and any S3-compatible object store in
+// production.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+)
+
+// ErrInvalidKey is returned for keys that are empty, absolute or climb out of
+// the storage root.
+var ErrInvalidKey = errors.New("storage: invalid key")
+
+// ErrNotFound is returned by Get for keys with no object.
+var ErrNotFound = errors.New("storage: object not found")
+
+// Storage stores objects under slash separated keys. Implementations must be
+// safe for concurrent use.
+type Storage interface {
+	// Put stores the content of r under key and returns its public URL.
+	Put(ctx context.Context, key string, r io.Reader, contentType string) (string, error)
+	// Get opens the object stored under key. The caller closes it.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	// URL is the public URL of key, whether or not it exists.
+	URL(key string) string
+}
+
+// Key is the key of an object given its public URL, as returned by Put. It
+// reports false for URLs outside the store.
+func Key(s Storage, url string) (string, bool) {
+	key, ok := strings.CutPrefix(url, s.URL(""))
+	if !ok || !validKey(key) {
+		return "", false
+	}
+	return key, true
+}
+
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+func joinURL(base, key string) string {
+	return strings.TrimSuffix(base, "/") + "/" + key
+}
@@ -0,0 +1,158 @@
+package storage
+
+import (
+	"context"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocal(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewLocal(dir, "/media/")
+	assert.NoError(t, err)
+
+	url, err := store.Put(context.Background(), "question/2026/10/a.png", strings.NewReader("png"), "image/png")
+	assert.NoError(t, err)
+	assert.Equal(t, "/media/question/2026/10/a.png", url)
+
+	data, err := os.ReadFile(filepath.Join(dir, "question", "2026", "10", "a.png"))
+	assert.NoError(t, err)
+	assert.Equal(t, "png", string(data))
+
+	r, err := store.Get(context.Background(), "question/2026/10/a.png")
+	assert.NoError(t, err)
+	data, _ = io.ReadAll(r)
+	r.Close()
+	assert.Equal(t, "png", string(data))
+
+	assert.NoError(t, store.Delete(context.Background(), "question/2026/10/a.png"))
+	assert.NoError(t, store.Delete(context.Background(), "question/2026/10/a.png"))
+	_, err = os.Stat(filepath.Join(dir, "question", "2026", "10", "a.png"))
+	assert.True(t, os.IsNotExist(err))
+	_, err = store.Get(context.Background(), "question/2026/10/a.png")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	for _, key := range []string{"", "/etc/passwd", "../x.png", "a/../../x.png", "a//b.png"} {
+		_, err := store.Put(context.Background(), key, strings.NewReader("x"), "image/png")
+		assert.ErrorIs(t, err, ErrInvalidKey, key)
+		_, err = store.Get(context.Background(), key)
+		assert.ErrorIs(t, err, ErrInvalidKey, key)
+	}
+}
+
+func TestKey(t *testing.T) {
+	local := &Local{Dir: t.TempDir(), BaseURL: "/media"}
+	s3, _ := NewS3(S3Config{Endpoint: "http://minio:9000", Bucket: "media", AccessKey: "AKID", SecretKey: "secret", PublicURL: "https://cdn.example.com/"})
+
+	for _, tc := range []struct {
+		store Storage
+		url   string
+		key   string
+		ok    bool
+	}{
+		{local, "/media/question/a.png", "question/a.png", true},
+		{local, "/media/../etc/passwd", "", false},
+		{local, "/mediax/a.png", "", false},
+		{local, "http://169.254.169.254/latest/meta-data", "", false},
+		{s3, "https://cdn.example.com/avatar/b.jpg", "avatar/b.jpg", true},
+		{s3, "https://cdn.example.com.evil.test/avatar/b.jpg", "", false},
+		{s3, "http://minio:9000/media/avatar/b.jpg", "", false},
+	} {
+		key, ok := Key(tc.store, tc.url)
+		assert.Equal(t, tc.ok, ok, tc.url)
+		assert.Equal(t, tc.key, key, tc.url)
+	}
+}
+
+// s3StandIn is a minimal S3 endpoint that checks request signatures the way
+// the real service does and keeps objects in memory.
+type s3StandIn struct {
+	mu      sync.Mutex
+	objects map[string]string
+	secret  string
+}
+
+func (s *s3StandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	date := r.Header.Get("X-Amz-Date")
+	payloadHash := r.Header.Get("X-Amz-Content-Sha256")
+	if payloadHash != sha256Hex(body) {
+		http.Error(w, "XAmzContentSHA256Mismatch", http.StatusBadRequest)
+		return
+	}
+
+	canonical := strings.Join([]string{r.Method, r.URL.EscapedPath(), "", "host:" + r.Host,
+		"x-amz-content-sha256:" + payloadHash, "x-amz-date:" + date, "",
+		"host;x-amz-content-sha256;x-amz-date", payloadHash}, "\n")
+	scope := date[:8] + "/us-east-1/s3/aws4_request"
+	toSign := "AWS4-HMAC-SHA256\n" + date + "\n" + scope + "\n" + sha256Hex([]byte(canonical))
+	signature := hex.EncodeToString(hmacSHA256(signingKey(s.secret, date[:8], "us-east-1", "s3"), toSign))
+	if !strings.HasSuffix(r.Header.Get("Authorization"), "Signature="+signature) ||
+		!strings.Contains(r.Header.Get("Authorization"), "Credential=AKID/"+scope) {
+		http.Error(w, "SignatureDoesNotMatch", http.StatusForbidden)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch r.Method {
+	case http.MethodGet:
+		object, ok := s.objects[r.URL.Path]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		io.WriteString(w, object)
+	case http.MethodPut:
+		s.objects[r.URL.Path] = string(body)
+	case http.MethodDelete:
+		delete(s.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestS3(t *testing.T) {
+	standIn := &s3StandIn{objects: map[string]string{}, secret: "secret"}
+	server := httptest.NewServer(standIn)
+	defer server.Close()
+
+	store, err := NewS3(S3Config{Endpoint: server.URL, Bucket: "media", AccessKey: "AKID", SecretKey: "secret"})
+	assert.NoError(t, err)
+	store.now = func() time.Time { return time.Date(2026, 10, 18, 8, 0, 0, 0, time.UTC) }
+
+	url, err := store.Put(context.Background(), "avatar/2026/10/b c.jpg", strings.NewReader("jpeg"), "image/jpeg")
+	assert.NoError(t, err)
+	assert.Equal(t, server.URL+"/media/avatar/2026/10/b c.jpg", url)
+	assert.Equal(t, "jpeg", standIn.objects["/media/avatar/2026/10/b c.jpg"])
+
+	r, err := store.Get(context.Background(), "avatar/2026/10/b c.jpg")
+	assert.NoError(t, err)
+	data, _ := io.ReadAll(r)
+	r.Close()
+	assert.Equal(t, "jpeg", string(data))
+
+	assert.NoError(t, store.Delete(context.Background(), "avatar/2026/10/b c.jpg"))
+	assert.Empty(t, standIn.objects)
+	_, err = store.Get(context.Background(), "avatar/2026/10/b c.jpg")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	wrong, _ := NewS3(S3Config{Endpoint: server.URL, Bucket: "media", AccessKey: "AKID", SecretKey: "wrong"})
+	_, err = wrong.Put(context.Background(), "a.png", strings.NewReader("png"), "image/png")
+	assert.ErrorContains(t, err, "status 403")
+}
+
+func TestSigningKey(t *testing.T) {
+	// Example from the AWS Signature Version 4 documentation.
+	key := signingKey("wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "20120215", "us-east-1", "iam")
+	assert.Equal(t, "f4780e2d9f65fa895f9c67b32ce1baf0b0d8a43505a000a1a9e090d414db404d", hex.EncodeToString(key))
+}